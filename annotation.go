@@ -0,0 +1,356 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// WithCorrelationID annotates the error with the ID of the request (or
+// other unit of work) it happened in, so that the error can be linked
+// back to it in another process:
+//
+//	err = errors.WithCorrelationID(err, requestID)
+//
+// Use CorrelationIDFrom to get the ID. Unlike the types of the errors
+// in the chain, the ID is kept when the error is serialized (with
+// ErrorToBytes, ToJSON or Encode) and restored on the error parsed on
+// the other end. The error, and any error with frames that wraps it,
+// prints the ID on a line of its own after its frames when printed with
+// the `%+v` verb:
+//
+//	failed
+//	pkg.Function
+//		/path/to/file.go:10
+//	correlation: 6f1c2b
+//
+// If the error is nil, WithCorrelationID returns nil, and if the ID is
+// empty, it returns the error as is.
+func WithCorrelationID(err error, id string) error {
+	if err == nil || id == "" {
+		return err
+	}
+	return &withAnnotation{error: err, value: id}
+}
+
+// CorrelationIDFrom returns the correlation ID of the outermost error in
+// the chain annotated with WithCorrelationID. The second result is false
+// if there is none.
+func CorrelationIDFrom(err error) (id string, ok bool) {
+	for depth := 0; err != nil; err, depth = unwrapAt(err, depth), depth+1 {
+		if w, ok := err.(*withAnnotation); ok {
+			return w.value, true
+		}
+	}
+	return "", false
+}
+
+// AnnotationCodec serializes a kind of annotation of an error chain, so
+// that it is kept by ToJSON and ErrorFromJSON (or ErrorToBytes and
+// ErrorFromBytes). See RegisterAnnotation.
+type AnnotationCodec struct {
+	// Encode returns the data of the annotation as JSON, if the error is
+	// annotated with it, or else false. It is called with each error in
+	// the chain in turn (without unwrapping it), until it returns true.
+	Encode func(err error) (data json.RawMessage, ok bool)
+
+	// Decode returns the error annotated with the data returned by
+	// Encode.
+	Decode func(err error, data json.RawMessage) (error, error)
+}
+
+// annotationCodecs are the registered kinds of annotation, in the
+// order they were registered.
+var annotationCodecs struct {
+	sync.RWMutex
+	kinds  []string
+	codecs map[string]AnnotationCodec
+}
+
+// RegisterAnnotation registers how a kind of annotation (eg, an error
+// code or severity set by a wrapper of your own) is serialized, so that
+// it is kept by the serialized forms of an error and restored on the
+// error parsed on the other end:
+//
+//	errors.RegisterAnnotation("acme.severity", errors.AnnotationCodec{
+//		Encode: func(err error) (json.RawMessage, bool) {
+//			s, ok := err.(*severityError)
+//			if !ok {
+//				return nil, false
+//			}
+//			data, _ := json.Marshal(s.severity)
+//			return data, true
+//		},
+//		Decode: func(err error, data json.RawMessage) (error, error) {
+//			s := &severityError{error: err}
+//			return s, json.Unmarshal(data, &s.severity)
+//		},
+//	})
+//
+// The JSON object of an error has an "annotations" array of each kind
+// found in its chain, eg:
+//
+//	{"message":"...","frames":[...],"annotations":[{"kind":"acme.severity","data":"high"}]}
+//
+// and the text form has a line for each of them, after the frames:
+//
+//	annotation: {"kind":"acme.severity","data":"high"}
+//
+// The correlation ID (see WithCorrelationID) is a built-in kind,
+// "correlation_id", that is written in forms of its own for
+// compatibility: as the "correlation_id" field of the JSON object, and
+// as a "correlation: " line of the text form. Both forms are parsed.
+//
+// The kinds are not required to be registered where the error is
+// parsed: an annotation of a kind that is not registered (eg, if it was
+// written by a newer version of a program), or that fails to decode, is
+// kept as it is, and serialized again untouched. Register kinds in an
+// init function, since errors parsed before then are not decoded.
+//
+// RegisterAnnotation panics if the kind is empty or already registered,
+// or if the codec is incomplete.
+func RegisterAnnotation(kind string, codec AnnotationCodec) {
+	if kind == "" || codec.Encode == nil || codec.Decode == nil {
+		panic(NewWithStackTrace(
+			"errors.RegisterAnnotation used incorrectly: kind must not be empty and codec must be complete"))
+	}
+	annotationCodecs.Lock()
+	defer annotationCodecs.Unlock()
+	if _, ok := annotationCodecs.codecs[kind]; ok {
+		panic(NewWithStackTrace(
+			fmt.Sprintf("errors.RegisterAnnotation used incorrectly: kind %q already registered", kind)))
+	}
+	if annotationCodecs.codecs == nil {
+		annotationCodecs.codecs = make(map[string]AnnotationCodec)
+	}
+	annotationCodecs.kinds = append(annotationCodecs.kinds, kind)
+	annotationCodecs.codecs[kind] = codec
+}
+
+// annotationJSON is the serialized form of an annotation registered
+// with RegisterAnnotation.
+type annotationJSON struct {
+	Kind string          `json:"kind"`
+	Data json.RawMessage `json:"data"`
+}
+
+// annotationLinePrefix starts the line of an annotation registered with
+// RegisterAnnotation in the text form of an error.
+const annotationLinePrefix = "annotation: "
+
+// encodeAnnotations returns the outermost annotation of each kind in
+// the error chain, in order: both of the registered kinds and those that
+// could not be decoded when the error was parsed.
+func encodeAnnotations(err error) (annotations []annotationJSON) {
+	annotationCodecs.RLock()
+	defer annotationCodecs.RUnlock()
+	for depth := 0; err != nil; err, depth = unwrapAt(err, depth), depth+1 {
+		if w, ok := err.(*opaqueAnnotation); ok {
+			if !hasAnnotation(annotations, w.kind) {
+				annotations = append(annotations, annotationJSON{Kind: w.kind, Data: w.data})
+			}
+			continue
+		}
+		for _, kind := range annotationCodecs.kinds {
+			if hasAnnotation(annotations, kind) {
+				continue
+			}
+			if data, ok := annotationCodecs.codecs[kind].Encode(err); ok && json.Valid(data) {
+				annotations = append(annotations, annotationJSON{Kind: kind, Data: data})
+			}
+		}
+	}
+	return
+}
+
+func hasAnnotation(annotations []annotationJSON, kind string) bool {
+	for _, a := range annotations {
+		if a.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeAnnotations returns the error annotated with the annotations,
+// so that the first is the outermost. Those of kinds that are not
+// registered, or that fail to decode, are kept opaque.
+func decodeAnnotations(err error, annotations []annotationJSON) error {
+	for i := len(annotations) - 1; i >= 0; i-- {
+		a := annotations[i]
+		if a.Kind == "" || len(a.Data) == 0 {
+			continue
+		}
+		annotationCodecs.RLock()
+		codec, ok := annotationCodecs.codecs[a.Kind]
+		annotationCodecs.RUnlock()
+		if ok {
+			if decoded, decodeErr := codec.Decode(err, a.Data); decodeErr == nil && decoded != nil {
+				err = decoded
+				continue
+			}
+		}
+		err = &opaqueAnnotation{error: err, kind: a.Kind, data: a.Data}
+	}
+	return err
+}
+
+// correlationIDKind is the kind of annotation of the correlation ID of
+// an error chain, built into the registry of RegisterAnnotation. The
+// "correlation_id" field of the JSON object of an error and the
+// "correlation: " line of its text form are aliases for it, kept so that
+// errors serialized with them can still be parsed, and are used instead
+// of the forms of other kinds.
+const (
+	correlationIDKind       = "correlation_id"
+	correlationIDLinePrefix = "correlation: "
+)
+
+func init() {
+	RegisterAnnotation(correlationIDKind, AnnotationCodec{
+		Encode: func(err error) (json.RawMessage, bool) {
+			w, ok := err.(*withAnnotation)
+			if !ok {
+				return nil, false
+			}
+			data, _ := json.Marshal(w.value)
+			return data, true
+		},
+		Decode: func(err error, data json.RawMessage) (error, error) {
+			var id string
+			if err := json.Unmarshal(data, &id); err != nil {
+				return nil, err
+			}
+			return WithCorrelationID(err, id), nil
+		},
+	})
+}
+
+// correlationIDAnnotation returns the annotation of the correlation ID,
+// for its aliases.
+func correlationIDAnnotation(id string) annotationJSON {
+	data, _ := json.Marshal(id)
+	return annotationJSON{Kind: correlationIDKind, Data: data}
+}
+
+// writeAnnotations writes the lines of the annotations of the error
+// chain in the text form of an error (see RegisterAnnotation).
+func writeAnnotations(w io.Writer, err error) {
+	for _, a := range encodeAnnotations(err) {
+		var id string
+		if a.Kind == correlationIDKind && json.Unmarshal(a.Data, &id) == nil {
+			io.WriteString(w, "\n"+correlationIDLinePrefix+id)
+			continue
+		}
+		if byt, err := json.Marshal(a); err == nil {
+			io.WriteString(w, "\n"+annotationLinePrefix+string(byt))
+		}
+	}
+}
+
+// formatAnnotated formats an annotated error with the `%+v` verb in the
+// layout of the wrappers with frames, which write the lines of the
+// annotations of the whole chain after the frames (see writeAnnotations),
+// so that each is written once, wherever the wrappers are in the chain.
+func formatAnnotated(s fmt.State, verb rune, w, wrapped error) {
+	fmt.Fprintf(s, "%v", wrapped)
+	formatFrames(s, verb, w)
+	formatBranches(s, wrapped)
+	writeSuppressedAnnotations(s, w)
+	writeAnnotations(s, w)
+}
+
+// annotationFromLine parses the line of an annotation in the text form
+// of an error, or of the correlation ID.
+func annotationFromLine(line string) (a annotationJSON, ok bool) {
+	if id, ok := strings.CutPrefix(line, correlationIDLinePrefix); ok && id != "" {
+		return correlationIDAnnotation(id), true
+	}
+	str, ok := strings.CutPrefix(line, annotationLinePrefix)
+	if !ok || json.Unmarshal([]byte(str), &a) != nil || a.Kind == "" || len(a.Data) == 0 {
+		return annotationJSON{}, false
+	}
+	return a, true
+}
+
+// withAnnotation implements an error type annotated with its
+// correlation ID.
+type withAnnotation struct {
+	error error
+	value string
+
+	formatGuard
+}
+
+func (w *withAnnotation) Error() string { return w.error.Error() }
+
+func (w *withAnnotation) Unwrap() error { return w.error }
+
+func (w *withAnnotation) Format(s fmt.State, verb rune) {
+	if !w.enterFormat(s, verb) {
+		return
+	}
+	defer w.leaveFormat()
+
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			formatAnnotated(s, verb, w, w.error)
+			return
+		}
+		if s.Flag('#') {
+			fmt.Fprintf(s, "&errors.withAnnotation{%q}", w.error)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, safeError(w.error, verb))
+	case 'q':
+		fmt.Fprintf(s, "%q", safeError(w.error, verb))
+	default:
+		// empty
+	}
+}
+
+// opaqueAnnotation implements an error type annotated with a serialized
+// annotation of a kind that could not be decoded, so that it is
+// serialized again untouched.
+type opaqueAnnotation struct {
+	error error
+	kind  string
+	data  json.RawMessage
+
+	formatGuard
+}
+
+func (w *opaqueAnnotation) Error() string { return w.error.Error() }
+
+func (w *opaqueAnnotation) Unwrap() error { return w.error }
+
+func (w *opaqueAnnotation) Format(s fmt.State, verb rune) {
+	if !w.enterFormat(s, verb) {
+		return
+	}
+	defer w.leaveFormat()
+
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			formatAnnotated(s, verb, w, w.error)
+			return
+		}
+		if s.Flag('#') {
+			fmt.Fprintf(s, "&errors.opaqueAnnotation{%q}", w.error)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, safeError(w.error, verb))
+	case 'q':
+		fmt.Fprintf(s, "%q", safeError(w.error, verb))
+	default:
+		// empty
+	}
+}
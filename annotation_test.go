@@ -0,0 +1,351 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+func TestWithCorrelationID(t *testing.T) {
+	t.Run("no-ops", func(t *testing.T) {
+		testutils.AssertNil(t, WithCorrelationID(nil, "abc"))
+		err := New("failed")
+		testutils.AssertEqual(t, err, WithCorrelationID(err, ""))
+		_, ok := CorrelationIDFrom(err)
+		testutils.AssertFalse(t, ok)
+	})
+
+	t.Run("outermost ID", func(t *testing.T) {
+		inner := NewWithFrame("failed")
+		err := WithCorrelationID(Errorf("handling: %w", WithCorrelationID(inner, "inner")), "outer")
+		id, ok := CorrelationIDFrom(err)
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, "outer", id)
+		testutils.AssertEqual(t, "handling: failed", err.Error())
+		testutils.AssertTrue(t, Is(err, inner))
+	})
+
+	t.Run("formatting", func(t *testing.T) {
+		err := WithCorrelationID(NewWithFrames("failed", Frames{NewFrame("pkg.Fn", "pkg/fn.go", 3)}), "abc")
+		testutils.AssertEqual(t, "failed", fmt.Sprintf("%v", err))
+		testutils.AssertEqual(t, `"failed"`, fmt.Sprintf("%q", err))
+		testutils.AssertEqual(t, `&errors.withAnnotation{"failed"}`, fmt.Sprintf("%#v", err))
+		testutils.AssertEqual(t, "failed\npkg.Fn\n\tpkg/fn.go:3\ncorrelation: abc", fmt.Sprintf("%+v", err))
+	})
+
+	t.Run("wrapped formatting", func(t *testing.T) {
+		fn := Frames{NewFrame("pkg.Fn", "pkg/fn.go", 3)}
+		fw := Frames{NewFrame("pkg.W", "pkg/w.go", 9)}
+		err := WithFrames(WithCorrelationID(NewWithFrames("failed", fn), "req-1"), fw)
+		testutils.AssertEqual(t, "failed\npkg.Fn\n\tpkg/fn.go:3\npkg.W\n\tpkg/w.go:9\ncorrelation: req-1",
+			fmt.Sprintf("%+v", err))
+
+		err = WithCorrelationID(WithFrames(WithCorrelationID(New("failed"), "inner"), fw), "outer")
+		testutils.AssertEqual(t, "failed\npkg.W\n\tpkg/w.go:9\ncorrelation: outer", fmt.Sprintf("%+v", err))
+	})
+
+	t.Run("wrapped round trip", func(t *testing.T) {
+		err := WithFrame(WithCorrelationID(NewWithFrame("failed"), "req-1"))
+		parsed, parseErr := ParseErrorFromBytes([]byte(fmt.Sprintf("%+v", err)))
+		testutils.AssertNil(t, parseErr)
+		testutils.AssertEqual(t, fmt.Sprintf("%+v", err), fmt.Sprintf("%+v", parsed))
+		id, ok := CorrelationIDFrom(parsed)
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, "req-1", id)
+	})
+}
+
+func TestCorrelationIDSerialization(t *testing.T) {
+	cases := map[string]error{
+		"message": WithCorrelationID(New("failed"), "req-1"),
+		"frames": WithCorrelationID(
+			NewWithFrames("failed", Frames{NewFrame("pkg.Fn", "pkg/fn.go", 3)}), "req-1"),
+		"wrapped": Errorf("handling: %w", WithCorrelationID(NewWithFrame("failed"), "req-1")),
+		"multierror": WithCorrelationID(NewMultiError(
+			WithCorrelationID(New("a"), "req-a"), New("b")), "req-1"),
+	}
+	roundTrips := map[string]func(t *testing.T, err error) error{
+		"text": func(t *testing.T, err error) error {
+			parsed, parseErr := ParseErrorFromBytes(ErrorToBytes(err))
+			testutils.AssertNil(t, parseErr)
+			return parsed
+		},
+		"json": func(t *testing.T, err error) error {
+			byt, marshalErr := ToJSON(err)
+			testutils.AssertNil(t, marshalErr)
+			parsed, parseErr := ParseErrorFromJSON(byt)
+			testutils.AssertNil(t, parseErr)
+			return parsed
+		},
+		"gob": gobRoundTrip,
+	}
+	for name, err := range cases {
+		for format, roundTrip := range roundTrips {
+			t.Run(name+" as "+format, func(t *testing.T) {
+				parsed := roundTrip(t, err)
+				testutils.AssertEqual(t, err.Error(), parsed.Error())
+				testutils.AssertEqual(t, FramesFrom(err).Locations(), FramesFrom(parsed).Locations())
+				id, ok := CorrelationIDFrom(parsed)
+				testutils.AssertTrue(t, ok)
+				testutils.AssertEqual(t, "req-1", id)
+
+				if format != "text" { // The text form serializes a multierror as a single error.
+					errs := ErrorsFromChain(parsed)
+					testutils.AssertEqual(t, len(ErrorsFromChain(err)), len(errs))
+					if len(errs) > 1 {
+						id, _ := CorrelationIDFrom(errs[0])
+						testutils.AssertEqual(t, "req-a", id)
+					}
+				}
+			})
+		}
+	}
+
+	t.Run("text of %+v", func(t *testing.T) {
+		err := WithCorrelationID(
+			NewWithFrames("failed", Frames{NewFrame("pkg.Fn", "pkg/fn.go", 3)}), "req-1")
+		parsed, ok := ErrorFromBytes([]byte(fmt.Sprintf("%+v", err)))
+		testutils.AssertTrue(t, ok)
+		id, _ := CorrelationIDFrom(parsed)
+		testutils.AssertEqual(t, "req-1", id)
+		testutils.AssertEqual(t, fmt.Sprintf("%+v", err), fmt.Sprintf("%+v", parsed))
+	})
+
+	t.Run("text with build info", func(t *testing.T) {
+		err := &withBuildInfo{
+			error: WithCorrelationID(New("failed"), "req-1"),
+			info:  BuildInfo{Path: "acme/agent", Version: "v1.0.0"},
+		}
+		SetSerializeBuildInfo(false)
+		serializedBuildInfo.Store(&err.info)
+		defer serializedBuildInfo.Store(nil)
+
+		byt := ErrorToBytes(err)
+		testutils.AssertEqual(t, "failed\ncorrelation: req-1\nbuild: acme/agent v1.0.0", string(byt))
+		parsed, ok := ErrorFromBytes(byt)
+		testutils.AssertTrue(t, ok)
+		id, _ := CorrelationIDFrom(parsed)
+		testutils.AssertEqual(t, "req-1", id)
+		info, _ := BuildInfoFrom(parsed)
+		testutils.AssertEqual(t, err.info, info)
+	})
+
+	t.Run("json field", func(t *testing.T) {
+		byt, _ := ToJSON(WithCorrelationID(New("failed"), "req-1"))
+		testutils.AssertEqual(t, `{"message":"failed","frames":null,"correlation_id":"req-1"}`, string(byt))
+	})
+
+	t.Run("gob stream", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		testutils.AssertNil(t, Encode(gob.NewEncoder(buf), WithCorrelationID(New("a"), "req-1")))
+		decoded, decodeErr := Decode(gob.NewDecoder(buf))
+		testutils.AssertNil(t, decodeErr)
+		id, _ := CorrelationIDFrom(decoded)
+		testutils.AssertEqual(t, "req-1", id)
+	})
+}
+
+// severityError is an annotation of an error chain that is serialized
+// with RegisterAnnotation in the tests.
+type severityError struct {
+	error
+	severity string
+}
+
+func (e *severityError) Unwrap() error { return e.error }
+
+// severityCodec is a function rather than a variable, so that its
+// function literals do not shift the names of those in the package
+// initialization that other tests match.
+func severityCodec() AnnotationCodec {
+	return AnnotationCodec{
+		Encode: func(err error) (json.RawMessage, bool) {
+			s, ok := err.(*severityError)
+			if !ok {
+				return nil, false
+			}
+			data, _ := json.Marshal(s.severity)
+			return data, true
+		},
+		Decode: func(err error, data json.RawMessage) (error, error) {
+			s := &severityError{error: err}
+			return s, json.Unmarshal(data, &s.severity)
+		},
+	}
+}
+
+// registerAnnotationForTest registers the kind until the end of the
+// test, as if the test were the version of a program that knows it.
+func registerAnnotationForTest(t *testing.T, kind string, codec AnnotationCodec) {
+	t.Helper()
+	RegisterAnnotation(kind, codec)
+	t.Cleanup(func() {
+		annotationCodecs.Lock()
+		defer annotationCodecs.Unlock()
+		delete(annotationCodecs.codecs, kind)
+		for i, k := range annotationCodecs.kinds {
+			if k == kind {
+				annotationCodecs.kinds = append(annotationCodecs.kinds[:i], annotationCodecs.kinds[i+1:]...)
+				break
+			}
+		}
+	})
+}
+
+func severityFrom(err error) string {
+	var s *severityError
+	if As(err, &s) {
+		return s.severity
+	}
+	return ""
+}
+
+func TestRegisterAnnotation(t *testing.T) {
+	t.Run("panics", func(t *testing.T) {
+		for name, register := range map[string]func(){
+			"empty kind":       func() { RegisterAnnotation("", severityCodec()) },
+			"incomplete codec": func() { RegisterAnnotation("test.incomplete", AnnotationCodec{}) },
+			"duplicate":        func() { RegisterAnnotation("test.severity", severityCodec()) },
+		} {
+			t.Run(name, func(t *testing.T) {
+				registerAnnotationForTest(t, "test.severity", severityCodec())
+				defer func() { testutils.AssertNotNil(t, recover()) }()
+				register()
+			})
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		registerAnnotationForTest(t, "test.severity", severityCodec())
+		err := &severityError{error: New("failed"), severity: "high"}
+
+		byt, _ := ToJSON(err)
+		testutils.AssertEqual(t,
+			`{"message":"failed","frames":null,"annotations":[{"kind":"test.severity","data":"high"}]}`, string(byt))
+		parsed, ok := ErrorFromJSON(byt)
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, "failed", parsed.Error())
+		testutils.AssertEqual(t, "high", severityFrom(parsed))
+	})
+
+	t.Run("text", func(t *testing.T) {
+		registerAnnotationForTest(t, "test.severity", severityCodec())
+		err := WithCorrelationID(&severityError{error: NewWithFrame("failed"), severity: "high"}, "req-1")
+
+		byt := ErrorToBytes(err)
+		testutils.AssertMatch(t, "\ncorrelation: req-1\nannotation: "+
+			`\{"kind":"test.severity","data":"high"\}$`, string(byt))
+		parsed, ok := ErrorFromBytes(byt)
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, "failed", parsed.Error())
+		testutils.AssertEqual(t, "high", severityFrom(parsed))
+		id, _ := CorrelationIDFrom(parsed)
+		testutils.AssertEqual(t, "req-1", id)
+		testutils.AssertEqual(t, FramesFrom(err).Locations(), FramesFrom(parsed).Locations())
+	})
+
+	t.Run("multierror", func(t *testing.T) {
+		registerAnnotationForTest(t, "test.severity", severityCodec())
+		err := NewMultiError(&severityError{error: New("a"), severity: "low"}, New("b"))
+
+		parsed, _ := ErrorFromJSON(must(ToJSON(err)))
+		errs := ErrorsFrom(parsed)
+		testutils.AssertEqual(t, 2, len(errs))
+		testutils.AssertEqual(t, "low", severityFrom(errs[0]))
+		testutils.AssertEqual(t, "", severityFrom(errs[1]))
+	})
+}
+
+func TestAnnotationVersionSkew(t *testing.T) {
+	err := &severityError{error: NewWithFrame("failed"), severity: "high"}
+
+	// Serialized by a newer program, that knows the kind.
+	var fromJSON, fromText []byte
+	t.Run("newer writer", func(t *testing.T) {
+		registerAnnotationForTest(t, "test.severity", severityCodec())
+		fromJSON = must(ToJSON(err))
+		fromText = ErrorToBytes(err)
+	})
+
+	t.Run("older reader keeps unknown kinds", func(t *testing.T) {
+		parsedJSON, ok := ErrorFromJSON(fromJSON)
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, "", severityFrom(parsedJSON))
+		testutils.AssertEqual(t, string(fromJSON), string(must(ToJSON(parsedJSON))))
+
+		parsedText, ok := ErrorFromBytes(fromText)
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, string(fromText), string(ErrorToBytes(parsedText)))
+		testutils.AssertEqual(t, string(fromText), fmt.Sprintf("%+v", parsedText))
+		testutils.AssertEqual(t, `&errors.opaqueAnnotation{"failed"}`, fmt.Sprintf("%#v", parsedText))
+
+		// Relayed by the older program to a newer one.
+		registerAnnotationForTest(t, "test.severity", severityCodec())
+		relayed, _ := ErrorFromJSON(must(ToJSON(parsedJSON)))
+		testutils.AssertEqual(t, "high", severityFrom(relayed))
+		relayed, _ = ErrorFromBytes(ErrorToBytes(parsedText))
+		testutils.AssertEqual(t, "high", severityFrom(relayed))
+	})
+
+	t.Run("newer reader of older writer", func(t *testing.T) {
+		registerAnnotationForTest(t, "test.severity", severityCodec())
+		parsed, ok := ErrorFromJSON([]byte(`{"message":"failed","frames":null}`))
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, "", severityFrom(parsed))
+		testutils.AssertEqual(t, `{"message":"failed","frames":null}`, string(must(ToJSON(parsed))))
+	})
+
+	t.Run("undecodable data is kept", func(t *testing.T) {
+		registerAnnotationForTest(t, "test.severity", severityCodec())
+		byt := []byte(`{"message":"failed","frames":null,"annotations":[{"kind":"test.severity","data":7}]}`)
+		parsed, ok := ErrorFromJSON(byt)
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, string(byt), string(must(ToJSON(parsed))))
+	})
+}
+
+func must(byt []byte, err error) []byte {
+	if err != nil {
+		panic(err)
+	}
+	return byt
+}
+
+func TestCorrelationIDAnnotation(t *testing.T) {
+	t.Run("is registered", func(t *testing.T) {
+		defer func() { testutils.AssertNotNil(t, recover()) }()
+		RegisterAnnotation("correlation_id", severityCodec())
+	})
+
+	t.Run("text", func(t *testing.T) {
+		for _, line := range []string{
+			"correlation: req-1",
+			`annotation: {"kind":"correlation_id","data":"req-1"}`,
+		} {
+			parsed, parseErr := ParseErrorFromBytes([]byte("failed\n" + line))
+			testutils.AssertNil(t, parseErr)
+			testutils.AssertEqual(t, "failed", parsed.Error())
+			id, _ := CorrelationIDFrom(parsed)
+			testutils.AssertEqual(t, "req-1", id)
+			testutils.AssertEqual(t, "failed\ncorrelation: req-1", string(ErrorToBytes(parsed)))
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		for _, byt := range []string{
+			`{"message":"failed","correlation_id":"req-1"}`,
+			`{"message":"failed","annotations":[{"kind":"correlation_id","data":"req-1"}]}`,
+		} {
+			parsed, ok := ErrorFromJSON([]byte(byt))
+			testutils.AssertTrue(t, ok)
+			id, _ := CorrelationIDFrom(parsed)
+			testutils.AssertEqual(t, "req-1", id)
+			marshaled, _ := ToJSON(parsed)
+			testutils.AssertEqual(t, `{"message":"failed","frames":null,"correlation_id":"req-1"}`, string(marshaled))
+		}
+	})
+}
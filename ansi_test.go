@@ -0,0 +1,75 @@
+package errors
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+// colorize adds SGR color codes to the lines of the output of the "%+v"
+// verb, the way a terminal or CI log might.
+func colorize(s string) string {
+	return regexp.MustCompile(`(?m)^(\t?)(.+)$`).ReplaceAllString(s, "$1\x1b[1;31m$2\x1b[0m")
+}
+
+func TestStripANSI(t *testing.T) {
+	cases := []struct {
+		name     string
+		in       string
+		expected string
+	}{
+		{"empty", "", ""},
+		{"no escapes", "plain text", "plain text"},
+		{"SGR", "\x1b[31mred\x1b[0m", "red"},
+		{"SGR with params", "\x1b[1;38;5;208morange\x1b[m", "orange"},
+		{"cursor movement", "a\x1b[2Kb\x1b[10;20Hc", "abc"},
+		{"two-byte sequence", "a\x1bMb", "ab"},
+		{"trailing escape", "text\x1b", "text"},
+		{"unterminated sequence", "text\x1b[31", "text"},
+		{"multibyte text", "\x1b[32m文件\x1b[0m", "文件"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			testutils.AssertEqual(t, tt.expected, string(StripANSI([]byte(tt.in))))
+		})
+	}
+
+	t.Run("returns text without escapes as-is", func(t *testing.T) {
+		byt := []byte("plain text")
+		testutils.AssertTrue(t, &StripANSI(byt)[0] == &byt[0])
+	})
+}
+
+func TestParsersStripANSI(t *testing.T) {
+	ff := Frames{
+		NewFrame("github.com/secureworks/errors.Example", "/src/example.go", 10),
+		NewFrame("github.com/secureworks/errors.Other", "/src/other.go", 20),
+	}
+	err := NewWithFrames("failed to open", ff)
+	colored := colorize(fmt.Sprintf("%+v", err))
+	testutils.AssertNotEqual(t, fmt.Sprintf("%+v", err), colored)
+
+	t.Run("ErrorFromBytes", func(t *testing.T) {
+		parsed, ok := ErrorFromBytes([]byte(colored))
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, "failed to open", parsed.Error())
+		testutils.AssertEqual(t, ff.Locations(), FramesFrom(parsed).Locations())
+	})
+
+	t.Run("FramesFromBytes", func(t *testing.T) {
+		parsed, parseErr := FramesFromBytes([]byte(colorize(fmt.Sprintf("%+v", ff))))
+		testutils.AssertNil(t, parseErr)
+		testutils.AssertEqual(t, ff.Locations(), parsed.Locations())
+	})
+
+	t.Run("GoroutineStacksFromBytes", func(t *testing.T) {
+		dump := "goroutine 1 [running]:\n" + fmt.Sprintf("%+v", ff)
+		stacks, parseErr := GoroutineStacksFromBytes([]byte(colorize(dump)))
+		testutils.AssertNil(t, parseErr)
+		testutils.AssertEqual(t, 1, len(stacks))
+		testutils.AssertEqual(t, "running", stacks[0].State)
+		testutils.AssertEqual(t, ff.Locations(), stacks[0].Frames.Locations())
+	})
+}
@@ -0,0 +1,189 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WithAttempt annotates the error from an attempt of a retried operation
+// with the attempt's number (from 1) and the maximum number of attempts,
+// and prefixes its message with them, eg "attempt 2/5: connection
+// refused". Use a max of 0 (or fewer) if there is no maximum, eg
+// "attempt 2: connection refused".
+//
+//	for attempt := 1; attempt <= maxAttempts; attempt++ {
+//		if err := call(ctx); err != nil {
+//			errs = append(errs, errors.WithAttempt(err, attempt, maxAttempts))
+//			continue
+//		}
+//		return nil
+//	}
+//	return errors.CombineAttempts(errs)
+//
+// Use AttemptFrom to get the numbers. If the error is nil, WithAttempt
+// returns nil.
+func WithAttempt(err error, attempt, max int) error {
+	if err == nil {
+		return nil
+	}
+	return &withAttempt{error: err, attempts: []int{attempt}, max: max}
+}
+
+// AttemptFrom returns the attempt number and the maximum number of
+// attempts of the outermost error in the chain annotated with
+// WithAttempt. For an error that CombineAttempts grouped the identical
+// failures of several attempts in, it returns the first of them. The
+// last result is false if there is none.
+func AttemptFrom(err error) (attempt, max int, ok bool) {
+	for depth := 0; err != nil; depth++ {
+		if w, ok := err.(*withAttempt); ok {
+			return w.attempts[0], w.max, true
+		}
+		err = unwrapAt(err, depth)
+	}
+	return 0, 0, false
+}
+
+// CombineAttempts returns the errors of the attempts of a retried
+// operation as a MultiError, with each error annotated with its attempt
+// number, as with WithAttempt. The attempt number of an error is its
+// index in the slice (from 1), and the maximum number of attempts is the
+// length of the slice, unless the error was already annotated with
+// WithAttempt (anywhere in its chain, as found by AttemptFrom). Then
+// the error that was annotated is used instead, with the frames of the
+// errors wrapping the annotation, but not any message context they
+// add. Nil errors (eg, for attempts that were skipped) are left out,
+// and nil is returned if there are no errors.
+//
+// The errors of attempts that failed the same way (see Same), out of
+// the same maximum number of attempts, are grouped, keeping the first
+// of them (with its frames) and the numbers of all of them, so that:
+//
+//	errors.CombineAttempts([]error{errTimeout, errTimeout, errRefused, errTimeout})
+//
+// prints as:
+//
+//	[attempts 1,2,4/4: timeout; attempt 3/4: connection refused]
+func CombineAttempts(errs []error) error {
+	merr := &MultiError{}
+	indexes := make(map[int]*sameIndex) // By the maximum number of attempts.
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		attempts, max := []int{i + 1}, len(errs)
+		if w, above := annotatedAttempt(err); w != nil {
+			err, attempts, max = w.error, w.attempts, w.max
+			if len(above) > 0 {
+				err = WithFrames(err, above)
+			}
+		}
+
+		index, ok := indexes[max]
+		if !ok {
+			index = newSameIndex(SameOptions{})
+			indexes[max] = index
+		}
+		if j, ok := index.lookup(err, len(merr.errors)); ok {
+			w := merr.errors[j].(*withAttempt)
+			w.attempts = append(w.attempts, attempts...)
+			continue
+		}
+		merr.errors = append(merr.errors, &withAttempt{
+			error:    err,
+			attempts: append([]int(nil), attempts...),
+			max:      max,
+		})
+	}
+	return merr.AsError()
+}
+
+// annotatedAttempt returns the outermost error in the chain annotated
+// with WithAttempt, if any, and the frames of the errors wrapping it.
+func annotatedAttempt(err error) (w *withAttempt, above Frames) {
+	for depth := 0; err != nil; depth++ {
+		if w, ok := err.(*withAttempt); ok {
+			return w, above
+		}
+		if framesErr, ok := err.(framer); ok {
+			above = prependFrame(above, framesErr.Frames())
+		}
+		err = unwrapAt(err, depth)
+	}
+	return nil, nil
+}
+
+// withAttempt implements an error type annotated with the numbers of
+// the attempts of a retried operation that failed with it.
+type withAttempt struct {
+	error    error
+	attempts []int
+	max      int
+
+	formatGuard
+}
+
+func (w *withAttempt) Error() string { return w.label() + ": " + w.error.Error() }
+
+func (w *withAttempt) Unwrap() error { return w.error }
+
+// label returns the attempt numbers as they prefix the message, eg
+// "attempt 2/5" or "attempts 1-3,5/5", with consecutive numbers as a
+// range if there are more than two.
+func (w *withAttempt) label() string {
+	var b strings.Builder
+	b.WriteString("attempt")
+	if len(w.attempts) > 1 {
+		b.WriteByte('s')
+	}
+	b.WriteByte(' ')
+	for i := 0; i < len(w.attempts); i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.Itoa(w.attempts[i]))
+		j := i
+		for j+1 < len(w.attempts) && w.attempts[j+1] == w.attempts[j]+1 {
+			j++
+		}
+		if j > i+1 {
+			b.WriteString("-" + strconv.Itoa(w.attempts[j]))
+			i = j
+		}
+	}
+	if w.max > 0 {
+		b.WriteString("/" + strconv.Itoa(w.max))
+	}
+	return b.String()
+}
+
+func (w *withAttempt) Format(s fmt.State, verb rune) {
+	if !w.enterFormat(s, verb) {
+		return
+	}
+	defer w.leaveFormat()
+
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "%s: %v", w.label(), w.error)
+			formatFrames(s, verb, w)
+			formatBranches(s, w.error)
+			writeAnnotations(s, w)
+			return
+		}
+		if s.Flag('#') {
+			fmt.Fprintf(s, "&errors.withAttempt{%q}", w.Error())
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, w.label()+": "+safeError(w.error, verb))
+	case 'q':
+		fmt.Fprintf(s, "%q", w.label()+": "+safeError(w.error, verb))
+	default:
+		// empty
+	}
+}
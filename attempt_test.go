@@ -0,0 +1,104 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+func TestWithAttempt(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		testutils.AssertNil(t, WithAttempt(nil, 1, 3))
+		_, _, ok := AttemptFrom(nil)
+		testutils.AssertFalse(t, ok)
+		_, _, ok = AttemptFrom(errBasic)
+		testutils.AssertFalse(t, ok)
+	})
+
+	t.Run("records the attempt", func(t *testing.T) {
+		err := Errorf("calling: %w", WithAttempt(errSentinel, 2, 5))
+		attempt, max, ok := AttemptFrom(err)
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, 2, attempt)
+		testutils.AssertEqual(t, 5, max)
+		testutils.AssertEqual(t, "calling: attempt 2/5: sentinel err", err.Error())
+		testutils.AssertTrue(t, Is(err, errSentinel))
+	})
+
+	t.Run("without a maximum", func(t *testing.T) {
+		err := WithAttempt(errSentinel, 7, 0)
+		testutils.AssertEqual(t, "attempt 7: sentinel err", err.Error())
+		testutils.AssertEqual(t, `"attempt 7: sentinel err"`, fmt.Sprintf("%q", err))
+		testutils.AssertEqual(t, `&errors.withAttempt{"attempt 7: sentinel err"}`, fmt.Sprintf("%#v", err))
+	})
+
+	t.Run("prints frames", func(t *testing.T) {
+		err := WithAttempt(NewWithFrames("failed", Frames{NewFrame("pkg.fn", "/src/pkg/fn.go", 10)}), 1, 2)
+		testutils.AssertEqual(t, "attempt 1/2: failed\npkg.fn\n\t/src/pkg/fn.go:10", fmt.Sprintf("%+v", err))
+	})
+}
+
+func TestCombineAttempts(t *testing.T) {
+	errTimeout := NewWithFrames("timeout after 100ms", Frames{NewFrame("pkg.call", "/src/pkg/call.go", 10)})
+	errTimeoutAgain := NewWithFrames("timeout after 120ms", Frames{NewFrame("pkg.call", "/src/pkg/call.go", 10)})
+	errRefused := New("connection refused")
+
+	t.Run("no errors", func(t *testing.T) {
+		testutils.AssertNil(t, CombineAttempts(nil))
+		testutils.AssertNil(t, CombineAttempts([]error{nil, nil}))
+	})
+
+	t.Run("labels attempts", func(t *testing.T) {
+		err := CombineAttempts([]error{errRefused, nil, errTimeout})
+		testutils.AssertEqual(t, "[attempt 1/3: connection refused; attempt 3/3: timeout after 100ms]", err.Error())
+		errs := ErrorsFrom(err)
+		testutils.AssertEqual(t, 2, len(errs))
+		attempt, max, _ := AttemptFrom(errs[1])
+		testutils.AssertEqual(t, 3, attempt)
+		testutils.AssertEqual(t, 3, max)
+		testutils.AssertEqual(t, FramesFrom(errTimeout), FramesFrom(errs[1]))
+		testutils.AssertTrue(t, Is(err, errTimeout))
+	})
+
+	t.Run("groups identical attempts", func(t *testing.T) {
+		err := CombineAttempts([]error{errTimeout, errTimeoutAgain, errRefused, errTimeout, errTimeout, errTimeout})
+		testutils.AssertEqual(t,
+			"[attempts 1,2,4-6/6: timeout after 100ms; attempt 3/6: connection refused]", err.Error())
+		testutils.AssertEqual(t, `multiple errors:
+
+* error 1 of 2: attempts 1,2,4-6/6: timeout after 100ms
+pkg.call
+	/src/pkg/call.go:10
+
+* error 2 of 2: attempt 3/6: connection refused
+`, fmt.Sprintf("%+v", err))
+	})
+
+	t.Run("keeps existing attempt numbers", func(t *testing.T) {
+		err := CombineAttempts([]error{
+			WithAttempt(errRefused, 3, 0),
+			WithAttempt(errRefused, 4, 0),
+			WithAttempt(errTimeout, 6, 0),
+		})
+		testutils.AssertEqual(t, "[attempts 3,4: connection refused; attempt 6: timeout after 100ms]", err.Error())
+	})
+
+	t.Run("attempt numbers in the chain", func(t *testing.T) {
+		wrapped := WithFrame(WithAttempt(errRefused, 1, 3))
+		err := CombineAttempts([]error{wrapped, WithAttempt(errRefused, 2, 3)})
+		testutils.AssertEqual(t, "[attempts 1,2/3: connection refused]", err.Error())
+		testutils.AssertEqual(t, FramesFrom(wrapped).Locations(), FramesFrom(ErrorsFrom(err)[0]).Locations())
+	})
+
+	t.Run("groups by maximum", func(t *testing.T) {
+		err := CombineAttempts([]error{WithAttempt(errRefused, 1, 3), WithAttempt(errRefused, 1, 5)})
+		testutils.AssertEqual(t, "[attempt 1/3: connection refused; attempt 1/5: connection refused]", err.Error())
+	})
+
+	t.Run("single error", func(t *testing.T) {
+		var merr *MultiError
+		testutils.AssertTrue(t, As(CombineAttempts([]error{errRefused}), &merr))
+		testutils.AssertEqual(t, "[attempt 1/1: connection refused]", merr.Error())
+	})
+}
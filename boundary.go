@@ -0,0 +1,109 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+)
+
+// AcrossGoroutine marks the error as handed from one goroutine to
+// another, and is meant to be called just before the error is sent (eg,
+// on a channel):
+//
+//	errCh <- errors.AcrossGoroutine(err)
+//
+// Frames added to the error after it is received (eg, with WithFrame or
+// WithStackTrace) are kept apart from those of the goroutine it came
+// from: FramesFrom returns only the frames of the goroutine it came
+// from, and printing with the `%+v` verb lists the frames added after
+// it was received first, followed by those of the goroutine it came
+// from under a "received from goroutine:" line:
+//
+//	while running task: not found
+//	main.collect
+//		/path/to/main.go:40
+//	received from goroutine:
+//	main.runTask
+//		/path/to/main.go:20
+//
+// The error parsed from this text (see ErrorFromBytes) keeps the frames
+// apart the same way.
+//
+// AcrossGoroutine also records the stack of the calling goroutine, which
+// stands in for the frames of the goroutine the error came from if the
+// error has none of its own. If the error is nil, AcrossGoroutine
+// returns nil.
+func AcrossGoroutine(err error) error {
+	if err == nil {
+		return nil
+	}
+	countCreated(statsAcrossGoroutine)
+	return &acrossGoroutine{
+		error:  err,
+		frames: getStack(3),
+	}
+}
+
+// acrossGoroutine implements an error type marking where an error was
+// handed from one goroutine to another, with the stack of the goroutine
+// it came from.
+type acrossGoroutine struct {
+	error  error
+	frames frames
+
+	formatGuard
+}
+
+func (w *acrossGoroutine) Error() string { return w.error.Error() }
+
+func (w *acrossGoroutine) Unwrap() error { return w.error }
+
+func (w *acrossGoroutine) Format(s fmt.State, verb rune) {
+	if !w.enterFormat(s, verb) {
+		return
+	}
+	defer w.leaveFormat()
+
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "%v", w.error)
+			formatFrames(s, verb, w)
+			formatBranches(s, w.error)
+			writeAnnotations(s, w)
+			return
+		}
+		if s.Flag('#') {
+			fmt.Fprintf(s, "&errors.acrossGoroutine{%q}", w.error)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, safeError(w.error, verb))
+	case 'q':
+		fmt.Fprintf(s, "%q", safeError(w.error, verb))
+	default:
+		// empty
+	}
+}
+
+// receivedFromGoroutine is the line between the frames added to an error
+// after it was received from another goroutine and those of the
+// goroutine it came from, when printed with the `%+v` verb.
+const receivedFromGoroutine = "received from goroutine:"
+
+// formatFrames writes the frames of the error chain for the `%+v` verb:
+// those from FramesFrom, or if the error was handed from one goroutine
+// to another (see AcrossGoroutine), those added after it was received
+// followed by those of the goroutine it came from.
+func formatFrames(s fmt.State, verb rune, err error) {
+	ff, boundary := framesFrom(err, true)
+	if boundary == nil {
+		ff.Format(s, verb)
+		return
+	}
+	if len(ff) > 0 {
+		ff.Format(s, verb)
+		io.WriteString(s, "\n"+receivedFromGoroutine)
+	}
+	FramesFrom(boundary).Format(s, verb)
+}
@@ -0,0 +1,117 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+//go:noinline
+func sendAcrossGoroutine(fn func() error) error {
+	ch := make(chan error, 1)
+	go func() { ch <- AcrossGoroutine(fn()) }()
+	return <-ch
+}
+
+func TestAcrossGoroutine(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		testutils.AssertNil(t, AcrossGoroutine(nil))
+	})
+
+	t.Run("wraps", func(t *testing.T) {
+		err := AcrossGoroutine(errSentinel)
+		testutils.AssertTrue(t, Is(err, errSentinel))
+		testutils.AssertEqual(t, "sentinel err", fmt.Sprintf("%v", err))
+		testutils.AssertEqual(t, `"sentinel err"`, fmt.Sprintf("%q", err))
+		testutils.AssertEqual(t, `&errors.acrossGoroutine{"sentinel err"}`, fmt.Sprintf("%#v", err))
+	})
+}
+
+func TestFramesFrom_acrossGoroutine(t *testing.T) {
+	t.Run("frames are those of the sender", func(t *testing.T) {
+		received := sendAcrossGoroutine(func() error { return NewWithFrame("failed") })
+		err := WithFrame(Errorf("received: %w", WithFrame(received)))
+
+		ff := FramesFrom(err)
+		testutils.AssertEqual(t, 1, len(ff))
+		testutils.AssertMatch(t, `TestFramesFrom_acrossGoroutine\.func1\.1$`, LocationOf(ff[0]).Function)
+		origin, _ := Origin(err)
+		testutils.AssertEqual(t, ff[0], origin)
+	})
+
+	t.Run("stack traces after receiving are ignored", func(t *testing.T) {
+		received := sendAcrossGoroutine(func() error { return NewWithFrame("failed") })
+		err := WithStackTrace(received)
+
+		testutils.AssertEqual(t, 1, len(FramesFrom(err)))
+		origin, _ := Origin(err)
+		testutils.AssertMatch(t, `TestFramesFrom_acrossGoroutine\.func2\.1$`, LocationOf(origin).Function)
+	})
+
+	t.Run("stack trace of the sender", func(t *testing.T) {
+		received := sendAcrossGoroutine(func() error { return NewWithStackTrace("failed") })
+		ff := FramesFrom(WithFrame(received))
+		testutils.AssertMatch(t, `TestFramesFrom_acrossGoroutine\.func3\.1$`, LocationOf(ff[0]).Function)
+		testutils.AssertMatch(t, `sendAcrossGoroutine\.func1$`, LocationOf(ff[1]).Function)
+	})
+
+	t.Run("stack of the sender without frames", func(t *testing.T) {
+		received := sendAcrossGoroutine(func() error { return errSentinel })
+		err := WithFrame(received)
+
+		ff := FramesFrom(err)
+		testutils.AssertMatch(t, `sendAcrossGoroutine\.func1$`, LocationOf(ff[0]).Function)
+		origin, _ := Origin(err)
+		testutils.AssertEqual(t, ff[0], origin)
+	})
+
+	t.Run("the first sender wins", func(t *testing.T) {
+		received := sendAcrossGoroutine(func() error {
+			return WithFrame(sendAcrossGoroutine(func() error { return NewWithFrame("failed") }))
+		})
+		ff := FramesFrom(WithFrame(received))
+		testutils.AssertEqual(t, 1, len(ff))
+		testutils.AssertMatch(t, `TestFramesFrom_acrossGoroutine\.func5\.1\.1$`, LocationOf(ff[0]).Function)
+	})
+}
+
+func TestFormat_acrossGoroutine(t *testing.T) {
+	sent := NewWithFrames("failed", Frames{NewFrame("pkg.send", "pkg/send.go", 3)})
+
+	t.Run("frames after receiving come first", func(t *testing.T) {
+		err := WithFrames(fmt.Errorf("received: %w", AcrossGoroutine(sent)), Frames{NewFrame("pkg.receive", "pkg/receive.go", 7)})
+		testutils.AssertEqual(t,
+			"received: failed\n"+
+				"pkg.receive\n\tpkg/receive.go:7\n"+
+				"received from goroutine:\n"+
+				"pkg.send\n\tpkg/send.go:3",
+			fmt.Sprintf("%+v", err))
+	})
+
+	t.Run("without frames after receiving", func(t *testing.T) {
+		err := AcrossGoroutine(sent)
+		testutils.AssertEqual(t, "failed\npkg.send\n\tpkg/send.go:3", fmt.Sprintf("%+v", err))
+	})
+
+	t.Run("in a multierror", func(t *testing.T) {
+		err := NewMultiError(WithFrames(AcrossGoroutine(sent), Frames{NewFrame("pkg.receive", "pkg/receive.go", 7)}))
+		testutils.AssertEqual(t,
+			"failed\n"+
+				"pkg.receive\n\tpkg/receive.go:7\n"+
+				"received from goroutine:\n"+
+				"pkg.send\n\tpkg/send.go:3",
+			fmt.Sprintf("%+v", err.ErrorOrNil()))
+	})
+}
+
+func TestErrorFromBytes_acrossGoroutine(t *testing.T) {
+	sent := NewWithFrames("failed", Frames{NewFrame("pkg.send", "pkg/send.go", 3)})
+	err := WithFrames(fmt.Errorf("received: %w", AcrossGoroutine(sent)), Frames{NewFrame("pkg.receive", "pkg/receive.go", 7)})
+
+	parsed, parseErr := ParseErrorFromBytes([]byte(fmt.Sprintf("%+v", err)))
+	testutils.AssertNil(t, parseErr)
+	testutils.AssertEqual(t, err.Error(), parsed.Error())
+	testutils.AssertEqual(t, fmt.Sprintf("%+v", err), fmt.Sprintf("%+v", parsed))
+	testutils.AssertEqual(t, FramesFrom(err).Locations(), FramesFrom(parsed).Locations())
+}
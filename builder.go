@@ -0,0 +1,84 @@
+package errors
+
+import "fmt"
+
+// Builder composes context onto an error in the order it is read,
+// instead of nesting wrapper calls inside-out:
+//
+//	err = errors.Build(err).Msgf("syncing tenant %d", id).Frame().Err()
+//	// ... the same as:
+//	// err = errors.WithFrame(fmt.Errorf("syncing tenant %d: %w", id, err))
+//
+// Each method is a thin wrapper over the equivalent constructor in this
+// package, and is applied when it is called, so the semantics (and
+// allocations) are identical to nesting those constructors. Builder is
+// a small value type: each method returns a new Builder.
+//
+// Building on a nil error is the same as creating a new error with the
+// first message given, so that:
+//
+//	err := errors.Build(nil).Msg("new err").Frame().Err()
+//	// ... the same as:
+//	// err := errors.NewWithFrame("new err")
+//
+// If no message is given for a nil error, Err returns nil.
+type Builder struct {
+	err error
+}
+
+// Build returns a Builder that composes context onto the given error.
+func Build(err error) Builder {
+	return Builder{err: err}
+}
+
+// Msg adds message context to the error, like:
+//
+//	fmt.Errorf("msg: %w", err)
+//
+// If the error is nil, a new error is created with the message, like
+// New.
+func (b Builder) Msg(msg string) Builder {
+	if b.err == nil {
+		return Builder{err: New(msg)}
+	}
+	return Builder{err: fmt.Errorf("%s: %w", msg, b.err)}
+}
+
+// Msgf adds formatted message context to the error. See Msg.
+func (b Builder) Msgf(format string, values ...interface{}) Builder {
+	return b.Msg(fmt.Sprintf(format, values...))
+}
+
+// Frame adds a call stack frame to the error for the caller, like
+// WithFrame.
+func (b Builder) Frame() Builder {
+	return Builder{err: WithFrameAt(b.err, 1)}
+}
+
+// FrameAt adds a call stack frame to the error for the caller, skipping
+// the given number of callers, like WithFrameAt.
+func (b Builder) FrameAt(skipCallers int) Builder {
+	return Builder{err: WithFrameAt(b.err, skipCallers+1)}
+}
+
+// Frames adds a list of frames to the error, like WithFrames.
+func (b Builder) Frames(ff Frames) Builder {
+	return Builder{err: WithFrames(b.err, ff)}
+}
+
+// StackTrace adds a stack trace starting at the caller to the error,
+// like WithStackTrace.
+func (b Builder) StackTrace() Builder {
+	if b.err == nil {
+		return b
+	}
+	return Builder{err: &withStackTrace{
+		error:  b.err,
+		frames: getStack(3),
+	}}
+}
+
+// Err returns the composed error.
+func (b Builder) Err() error {
+	return b.err
+}
@@ -0,0 +1,61 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+func TestBuilder(t *testing.T) {
+	t.Run("nil with no message is nil", func(t *testing.T) {
+		testutils.AssertNil(t, Build(nil).Err())
+		testutils.AssertNil(t, Build(nil).Frame().StackTrace().Err())
+	})
+
+	t.Run("nil with message creates an error", func(t *testing.T) {
+		err := Build(nil).Msg("new err").Err()
+		testutils.AssertEqual(t, "new err", err.Error())
+		testutils.AssertNil(t, Unwrap(err))
+	})
+
+	t.Run("adds message context in order", func(t *testing.T) {
+		err := Build(errSentinel).Msgf("inner %d", 1).Msg("outer").Err()
+		testutils.AssertEqual(t, "outer: inner 1: sentinel err", err.Error())
+		testutils.AssertTrue(t, Is(err, errSentinel))
+	})
+
+	t.Run("adds frames for the caller", func(t *testing.T) {
+		err := Build(errSentinel).Frame().Msg("wrap").Frame().Err()
+		testutils.AssertLinesMatch(t, err, "%+v", []string{
+			"^wrap: sentinel err$",
+			`^github\.com/secureworks/errors\.TestBuilder\.func4$`,
+			`^\t.+/builder_test\.go:\d+$`,
+			`^github\.com/secureworks/errors\.TestBuilder\.func4$`,
+			`^\t.+/builder_test\.go:\d+$`,
+		})
+	})
+
+	t.Run("adds frames for a caller", func(t *testing.T) {
+		err := func() error {
+			return Build(errSentinel).FrameAt(1).Err()
+		}()
+		function, _, _ := FramesFrom(err)[0].Location()
+		testutils.AssertEqual(t, "github.com/secureworks/errors.TestBuilder.func5", function)
+	})
+
+	t.Run("adds a stack trace for the caller", func(t *testing.T) {
+		err := Build(nil).Msg("new err").StackTrace().Err()
+		_, ok := err.(stackTracer)
+		testutils.AssertTrue(t, ok)
+		function, _, _ := FramesFrom(err)[0].Location()
+		testutils.AssertEqual(t, "github.com/secureworks/errors.TestBuilder.func6", function)
+	})
+
+	t.Run("is the same as the constructors", func(t *testing.T) {
+		ff := Frames{NewFrame("example", "example.go", 10)}
+		testutils.AssertEqual(t,
+			fmt.Sprintf("%+v", WithFrames(fmt.Errorf("wrap: %w", errSentinel), ff)),
+			fmt.Sprintf("%+v", Build(errSentinel).Msg("wrap").Frames(ff).Err()))
+	})
+}
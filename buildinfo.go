@@ -0,0 +1,159 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// BuildInfo identifies the build of the binary that serialized an error,
+// so that its frames can be matched to the right version of the source
+// code. See SetSerializeBuildInfo.
+type BuildInfo struct {
+	Path     string `json:"path"`               // The main module path.
+	Version  string `json:"version,omitempty"`  // The main module version.
+	Revision string `json:"revision,omitempty"` // The VCS revision.
+	Modified bool   `json:"modified,omitempty"` // Whether the VCS tree had local changes.
+}
+
+// String returns the build info in the form it is serialized as text,
+// eg: "acme/agent v1.4.2 (abc1234)" or "acme/agent (abc1234, modified)".
+func (b BuildInfo) String() string {
+	var sb strings.Builder
+	sb.WriteString(b.Path)
+	if b.Version != "" {
+		sb.WriteString(" " + b.Version)
+	}
+	if b.Revision != "" {
+		sb.WriteString(" (" + b.Revision)
+		if b.Modified {
+			sb.WriteString(", modified")
+		}
+		sb.WriteString(")")
+	}
+	return sb.String()
+}
+
+// buildInfoPrefix starts the line with the build info in the text form
+// of a serialized error.
+const buildInfoPrefix = "build: "
+
+// buildInfoFromString parses the build info from its String form.
+func buildInfoFromString(str string) (b BuildInfo, ok bool) {
+	if open := strings.LastIndex(str, " ("); open >= 0 && strings.HasSuffix(str, ")") {
+		b.Revision = str[open+2 : len(str)-1]
+		b.Revision, b.Modified = strings.CutSuffix(b.Revision, ", modified")
+		str = str[:open]
+	}
+	b.Path, b.Version, _ = strings.Cut(str, " ")
+	if b.Path == "" || strings.Contains(b.Version, " ") {
+		return BuildInfo{}, false
+	}
+	return b, true
+}
+
+var (
+	buildInfoOnce       sync.Once
+	buildInfoDetected   BuildInfo
+	serializedBuildInfo atomic.Pointer[BuildInfo]
+)
+
+// SetSerializeBuildInfo sets whether the build info of the binary is
+// included when errors are serialized with ErrorToBytes or ToJSON, for
+// the whole program. The build info is read once, with
+// debug.ReadBuildInfo. It is included as a final line of text:
+//
+//	build: acme/agent v1.4.2 (abc1234)
+//
+// or as a "build" object in JSON:
+//
+//	{"message":"...","frames":[...],"build":{"path":"acme/agent","version":"v1.4.2","revision":"abc1234"}}
+//
+// If the build info is not available, nothing is included. Use
+// BuildInfoFrom to get the build info from an error parsed with
+// ErrorFromBytes or ErrorFromJSON.
+func SetSerializeBuildInfo(include bool) {
+	if !include {
+		serializedBuildInfo.Store(nil)
+		return
+	}
+	buildInfoOnce.Do(func() {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		buildInfoDetected.Path = info.Main.Path
+		if info.Main.Version != "(devel)" { // No version.
+			buildInfoDetected.Version = info.Main.Version
+		}
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				buildInfoDetected.Revision = setting.Value
+			case "vcs.modified":
+				buildInfoDetected.Modified = setting.Value == "true"
+			}
+		}
+	})
+	if buildInfoDetected.Path == "" {
+		serializedBuildInfo.Store(nil)
+		return
+	}
+	info := buildInfoDetected
+	serializedBuildInfo.Store(&info)
+}
+
+// BuildInfoFrom returns the build info of the binary that serialized
+// the error, if it was parsed with ErrorFromBytes or ErrorFromJSON and
+// the build info was included (see SetSerializeBuildInfo).
+func BuildInfoFrom(err error) (info BuildInfo, ok bool) {
+	for depth := 0; err != nil; depth++ {
+		if w, ok := err.(*withBuildInfo); ok {
+			return w.info, true
+		}
+		err = unwrapAt(err, depth)
+	}
+	return BuildInfo{}, false
+}
+
+// withBuildInfo implements an error type annotated with the build info
+// of the binary that serialized it.
+type withBuildInfo struct {
+	error error
+	info  BuildInfo
+
+	formatGuard
+}
+
+func (w *withBuildInfo) Error() string { return w.error.Error() }
+
+func (w *withBuildInfo) Unwrap() error { return w.error }
+
+func (w *withBuildInfo) Format(s fmt.State, verb rune) {
+	if !w.enterFormat(s, verb) {
+		return
+	}
+	defer w.leaveFormat()
+
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "%+v", w.error)
+			return
+		}
+		if s.Flag('#') {
+			fmt.Fprintf(s, "&errors.withBuildInfo{%q}", w.error)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, safeError(w.error, verb))
+	case 'q':
+		fmt.Fprintf(s, "%q", safeError(w.error, verb))
+	default:
+		// empty
+	}
+}
@@ -0,0 +1,114 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+// withSerializedBuildInfo includes the build info in serialized errors
+// for the rest of the test.
+func withSerializedBuildInfo(t *testing.T, info BuildInfo) {
+	t.Helper()
+	serializedBuildInfo.Store(&info)
+	t.Cleanup(func() { SetSerializeBuildInfo(false) })
+}
+
+func TestBuildInfo_String(t *testing.T) {
+	cases := []struct {
+		info     BuildInfo
+		expected string
+	}{
+		{BuildInfo{Path: "acme/agent", Version: "v1.4.2", Revision: "abc1234"}, "acme/agent v1.4.2 (abc1234)"},
+		{BuildInfo{Path: "acme/agent", Revision: "abc1234", Modified: true}, "acme/agent (abc1234, modified)"},
+		{BuildInfo{Path: "acme/agent"}, "acme/agent"},
+	}
+	for _, tt := range cases {
+		testutils.AssertEqual(t, tt.expected, tt.info.String())
+		parsed, ok := buildInfoFromString(tt.expected)
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, tt.info, parsed)
+	}
+
+	for _, str := range []string{"", " v1", "acme/agent v1 extra"} {
+		_, ok := buildInfoFromString(str)
+		testutils.AssertFalse(t, ok, str)
+	}
+}
+
+func TestSetSerializeBuildInfo(t *testing.T) {
+	info := BuildInfo{Path: "acme/agent", Version: "v1.4.2", Revision: "abc1234", Modified: true}
+	ff := Frames{NewFrame("github.com/secureworks/errors.Example", "/src/example.go", 10)}
+	errs := []error{New("failed"), NewWithFrames("failed", ff), NewWithFrames("a\nb", ff)}
+
+	t.Run("not included by default", func(t *testing.T) {
+		err := NewWithFrames("failed", ff)
+		testutils.AssertEqual(t, fmt.Sprintf("%+v", err), string(ErrorToBytes(err)))
+		byt, _ := ToJSON(err)
+		testutils.AssertEqual(t, `{"message":"failed","frames":[{"function":"github.com/secureworks/errors.Example","file":"/src/example.go","line":10}]}`, string(byt))
+
+		parsed, _ := ErrorFromBytes(ErrorToBytes(err))
+		_, ok := BuildInfoFrom(parsed)
+		testutils.AssertFalse(t, ok)
+	})
+
+	t.Run("text", func(t *testing.T) {
+		withSerializedBuildInfo(t, info)
+		testutils.AssertEqual(t,
+			"failed\ngithub.com/secureworks/errors.Example\n\t/src/example.go:10\nbuild: acme/agent v1.4.2 (abc1234, modified)",
+			string(ErrorToBytes(errs[1])))
+		for _, err := range errs {
+			parsed, ok := ErrorFromBytes(ErrorToBytes(err))
+			testutils.AssertTrue(t, ok)
+			testutils.AssertEqual(t, err.Error(), parsed.Error())
+			testutils.AssertEqual(t, fmt.Sprintf("%+v", err), fmt.Sprintf("%+v", parsed))
+			parsedInfo, ok := BuildInfoFrom(parsed)
+			testutils.AssertTrue(t, ok)
+			testutils.AssertEqual(t, info, parsedInfo)
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		withSerializedBuildInfo(t, info)
+		byt, _ := ToJSON(errs[0])
+		testutils.AssertEqual(t, `{"message":"failed","frames":null,"build":{"path":"acme/agent","version":"v1.4.2","revision":"abc1234","modified":true}}`, string(byt))
+		for _, err := range append(errs, NewMultiError(New("a"), New("b"))) {
+			byt, _ := ToJSON(err)
+			parsed, ok := ErrorFromJSON(byt)
+			testutils.AssertTrue(t, ok)
+			testutils.AssertEqual(t, fmt.Sprintf("%+v", err), fmt.Sprintf("%+v", parsed))
+			parsedInfo, ok := BuildInfoFrom(parsed)
+			testutils.AssertTrue(t, ok)
+			testutils.AssertEqual(t, info, parsedInfo)
+		}
+	})
+
+	t.Run("a message like build info is kept", func(t *testing.T) {
+		parsed, ok := ErrorFromBytes([]byte("build: acme/agent v1.4.2"))
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, "build: acme/agent v1.4.2", parsed.Error())
+		_, ok = BuildInfoFrom(parsed)
+		testutils.AssertFalse(t, ok)
+	})
+
+	t.Run("off", func(t *testing.T) {
+		withSerializedBuildInfo(t, info)
+		SetSerializeBuildInfo(false)
+		testutils.AssertEqual(t, "failed", string(ErrorToBytes(errs[0])))
+	})
+
+	t.Run("from the binary", func(t *testing.T) {
+		SetSerializeBuildInfo(true)
+		t.Cleanup(func() { SetSerializeBuildInfo(false) })
+		// Test binaries may not have a main module.
+		if buildInfoDetected.Path == "" {
+			testutils.AssertEqual(t, "failed", string(ErrorToBytes(errs[0])))
+			return
+		}
+		parsed, _ := ErrorFromBytes(ErrorToBytes(errs[0]))
+		parsedInfo, ok := BuildInfoFrom(parsed)
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, buildInfoDetected, parsedInfo)
+	})
+}
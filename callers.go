@@ -1,6 +1,14 @@
 package errors
 
-import "github.com/secureworks/errors/internal/runtime"
+import (
+	stdruntime "runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/secureworks/errors/runtimeutil"
+)
 
 // Caller returns a Frame that describes the proximate frame on the
 // caller's stack.
@@ -14,6 +22,15 @@ func CallerAt(skipCallers int) Frame {
 	return getFrame(skipCallers + 3)
 }
 
+// CallerFileLine returns the file and line of the proximate frame on
+// the caller's stack. It is cheaper than Caller when the function name
+// is not needed (eg, for metrics or sampled logging), since only the
+// file and line are resolved.
+func CallerFileLine() (file string, line int) {
+	fr := getFileLine(3)
+	return fr.getFile(), fr.getLine()
+}
+
 // CallStack returns all the Frames that describe the caller's stack.
 func CallStack() Frames {
 	st := getStack(3)
@@ -58,23 +75,136 @@ func CallStackAtMost(skipCallers int, maxFrames int) Frames {
 	return ff
 }
 
-// getFrame translates a runtime.Frame item returned from the internal
-// runtime utilities into a frame.
+// CallStackInModule returns the Frames that describe the caller's stack
+// within the main module: once the stack leaves the main module (into a
+// framework or the runtime, say) collection stops, after keeping one
+// frame to show where control entered the module. Functions in package
+// main are always in the main module.
+//
+// The main module is read from the build info of the binary, and may be
+// overridden with SetMainModule. If it cannot be determined, the entire
+// stack is returned, the same as CallStack.
+func CallStackInModule() Frames {
+	st := getStackInModule(3)
+	ff := make(Frames, len(st))
+	for i, fr := range st {
+		ff[i] = fr
+	}
+	return ff
+}
+
+var (
+	mainModuleOverride atomic.Pointer[string]
+	mainModuleOnce     sync.Once
+	mainModuleDetected string
+)
+
+// SetMainModule sets the module path used by CallStackInModule and
+// WithStackTraceInModule for the whole program, instead of the main
+// module from the build info. Setting an empty path restores the
+// default.
+func SetMainModule(path string) {
+	if path == "" {
+		mainModuleOverride.Store(nil)
+		return
+	}
+	mainModuleOverride.Store(&path)
+}
+
+// mainModule returns the path of the main module, or an empty string if
+// it is unknown.
+func mainModule() string {
+	if path := mainModuleOverride.Load(); path != nil {
+		return *path
+	}
+	mainModuleOnce.Do(func() {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		mainModuleDetected = info.Main.Path
+		if mainModuleDetected == "" {
+			// Test binaries may not record the main module: fall back to the
+			// path of the package being tested.
+			mainModuleDetected = strings.TrimSuffix(info.Path, ".test")
+		}
+	})
+	return mainModuleDetected
+}
+
+// inModule reports whether the fully-qualified function name is in a
+// package in the module, including external test packages.
+func inModule(function string, module string) bool {
+	if strings.HasPrefix(function, "main.") {
+		return true
+	}
+	if !strings.HasPrefix(function, module) {
+		return false
+	}
+	rest := strings.TrimPrefix(function[len(module):], "_test")
+	return strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "/")
+}
+
+// getFrame translates a runtime.Frame item returned from runtimeutil
+// into a frame. Unlike runtimeutil, the skipCallers of getFrame and the
+// helpers below count the helper itself as 1.
 //
 //go:noinline
 func getFrame(skipCallers int) *frame {
-	return &frame{pc: runtime.GetFrame(skipCallers).PC}
+	fr := &frame{pc: runtimeutil.GetFrame(skipCallers - 1).PC}
+	countFramesCaptured(1)
+	return fr
+}
+
+// getFileLine is the same as getFrame, but resolves only the file and
+// line of the frame, immediately: its function is "unknown". Since the
+// frame is never expanded afterwards, it is safe for concurrent access.
+//
+//go:noinline
+func getFileLine(skipCallers int) *frame {
+	_, file, line, _ := stdruntime.Caller(skipCallers - 1)
+	countFramesCaptured(1)
+	return &frame{file: file, line: line}
 }
 
-// getStack translates runtime.Frame items returned from the internal
-// runtime utilities into frames.
+// getStack translates runtime.Frame items returned from runtimeutil into
+// frames.
 //
 //go:noinline
 func getStack(skipCallers int) frames {
-	st := runtime.GetStack(skipCallers)
+	if internStacks.Load() {
+		return getInternedStack(skipCallers + 1)
+	}
+	st := runtimeutil.GetStack(skipCallers - 1)
+	ff := make([]*frame, len(st))
+	for i, fr := range st {
+		ff[i] = &frame{pc: fr.PC}
+	}
+	countFramesCaptured(len(ff))
+	return ff
+}
+
+// getStackInModule is the same as getStack, except that it stops after
+// the first frame that leaves the main module.
+//
+//go:noinline
+func getStackInModule(skipCallers int) frames {
+	module := mainModule()
+	var entered bool
+	st := runtimeutil.GetStackUntil(skipCallers-1, func(fr stdruntime.Frame) bool {
+		if module == "" {
+			return false
+		}
+		if inModule(fr.Function, module) {
+			entered = true
+			return false
+		}
+		return entered
+	})
 	ff := make([]*frame, len(st))
 	for i, fr := range st {
 		ff[i] = &frame{pc: fr.PC}
 	}
+	countFramesCaptured(len(ff))
 	return ff
 }
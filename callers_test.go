@@ -374,3 +374,76 @@ var (
 		file: `.+\/testing\/testing\.go`,
 	}
 )
+
+func TestCallStackInModule(t *testing.T) {
+	t.Run("detects the main module", func(t *testing.T) {
+		testutils.AssertEqual(t, "github.com/secureworks/errors", mainModule())
+	})
+
+	t.Run("stops after leaving the module", func(t *testing.T) {
+		ff := CallStackInModule()
+		testutils.AssertEqual(t, 2, len(ff))
+		fn, _, _ := ff[0].Location()
+		testutils.AssertEqual(t, "github.com/secureworks/errors.TestCallStackInModule.func2", fn)
+		fn, _, _ = ff[1].Location()
+		testutils.AssertEqual(t, "testing.tRunner", fn)
+	})
+
+	t.Run("returns the stack when never in the module", func(t *testing.T) {
+		SetMainModule("example.com/other")
+		defer SetMainModule("")
+		testutils.AssertEqual(t, len(CallStack()), len(CallStackInModule()))
+	})
+
+	t.Run("can be overridden", func(t *testing.T) {
+		SetMainModule("testing")
+		defer SetMainModule("")
+		testutils.AssertEqual(t, "testing", mainModule())
+		ff := CallStackInModule()
+		fn, _, _ := ff[len(ff)-1].Location()
+		testutils.AssertEqual(t, "testing.tRunner", fn)
+
+		SetMainModule("")
+		testutils.AssertEqual(t, "github.com/secureworks/errors", mainModule())
+	})
+
+	t.Run("adds a stack trace to an error", func(t *testing.T) {
+		testutils.AssertNil(t, WithStackTraceInModule(nil))
+		err := WithStackTraceInModule(errSentinel)
+		_, ok := err.(stackTracer)
+		testutils.AssertTrue(t, ok)
+		ff := FramesFrom(err)
+		testutils.AssertEqual(t, 2, len(ff))
+		fn, _, _ := ff[0].Location()
+		testutils.AssertEqual(t, "github.com/secureworks/errors.TestCallStackInModule.func5", fn)
+	})
+}
+
+func Test_inModule(t *testing.T) {
+	cases := []struct {
+		function string
+		expected bool
+	}{
+		{"example.com/app.Run", true},
+		{"example.com/app.(*Server).Run.func1", true},
+		{"example.com/app/internal/db.Query", true},
+		{"example.com/app_test.TestRun", true},
+		{"example.com/application.Run", false},
+		{"example.com/other.Run", false},
+		{"net/http.HandlerFunc.ServeHTTP", false},
+		{"main.main", true},
+		{"runtime.main", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.function, func(t *testing.T) {
+			testutils.AssertEqual(t, tc.expected, inModule(tc.function, "example.com/app"))
+		})
+	}
+}
+
+func TestCallerFileLine(t *testing.T) {
+	file, line := CallerFileLine()
+	_, expectedFile, expectedLine := Caller().Location()
+	testutils.AssertEqual(t, expectedFile, file)
+	testutils.AssertEqual(t, expectedLine-1, line)
+}
@@ -0,0 +1,182 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// CancelKind is why an operation was cancelled, as returned by
+// CancellationFrom.
+type CancelKind int
+
+const (
+	// CancelDeadline is an operation that timed out: its context passed
+	// its deadline, or it failed with a timeout (see IsTimeout).
+	CancelDeadline CancelKind = iota + 1
+
+	// CancelExplicit is an operation whose context was cancelled (eg, by
+	// calling its CancelFunc).
+	CancelExplicit
+
+	// CancelParent is an operation that was cancelled because a parent
+	// or outer context was (see CanceledByParent).
+	CancelParent
+)
+
+// String returns the kind as a word, eg "deadline".
+func (k CancelKind) String() string {
+	switch k {
+	case CancelDeadline:
+		return "deadline"
+	case CancelExplicit:
+		return "explicit"
+	case CancelParent:
+		return "parent"
+	default:
+		return fmt.Sprintf("CancelKind(%d)", int(k))
+	}
+}
+
+// ErrCanceledByParent is matched by errors that were annotated with
+// CanceledByParent. It may also be given as the cause of cancelling a
+// context (see context.WithCancelCause), so that an error returned with
+// context.Cause is found by CancellationFrom to be a CancelParent.
+var ErrCanceledByParent = New("cancelled by parent context")
+
+// CanceledByParent annotates the error from an operation that failed
+// because a parent or outer context was cancelled, rather than its own,
+// eg a task of a group whose context was cancelled by the caller:
+//
+//	if err := task(ctx); err != nil && parent.Err() != nil {
+//		return errors.CanceledByParent(err)
+//	}
+//
+// Its message is prefixed with "cancelled by parent context: ", and it
+// matches ErrCanceledByParent with Is, as well as the error it wraps. If
+// the error is nil, CanceledByParent returns nil.
+func CanceledByParent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &canceledByParent{error: err}
+}
+
+// IsTimeout reports whether the error, or any error in its chain
+// (including the errors of multierrors), is a timeout: the error of a
+// context that passed its deadline (context.DeadlineExceeded, which
+// os.ErrDeadlineExceeded is also for I/O deadlines), any error with a
+// Timeout method that returns true (eg, a net.Error), or an error
+// annotated with WithDeadlineInfo after the deadline passed.
+//
+// Note that IsTimeout only finds errors in the chain: a context
+// cancelled with a cause (see context.WithDeadlineCause) is only found
+// if the cause is a timeout and is returned, eg with context.Cause.
+func IsTimeout(err error) bool {
+	return anyInTree(err, 0, isTimeout)
+}
+
+// IsCanceled reports whether the error, or any error in its chain, is
+// from an operation that was cancelled, rather than timing out: it
+// matches context.Canceled or ErrCanceledByParent with Is.
+func IsCanceled(err error) bool {
+	return Is(err, context.Canceled) || Is(err, ErrCanceledByParent)
+}
+
+// CancellationFrom returns why the operation that failed with the error
+// was cancelled, if it was. An error annotated with CanceledByParent (or
+// that otherwise matches ErrCanceledByParent) is a CancelParent, even if
+// the parent context timed out. Otherwise a timeout (see IsTimeout) is a
+// CancelDeadline, and an error that matches context.Canceled is a
+// CancelExplicit. The last result is false if the error is none of
+// them, in which case the kind is 0.
+func CancellationFrom(err error) (kind CancelKind, ok bool) {
+	switch {
+	case Is(err, ErrCanceledByParent):
+		return CancelParent, true
+	case IsTimeout(err):
+		return CancelDeadline, true
+	case Is(err, context.Canceled):
+		return CancelExplicit, true
+	default:
+		return 0, false
+	}
+}
+
+func isTimeout(err error) bool {
+	if err == context.DeadlineExceeded {
+		return true
+	}
+	if w, ok := err.(*withDeadlineInfo); ok {
+		return w.info.Done && w.info.Remaining <= 0
+	}
+	t, ok := err.(interface{ Timeout() bool })
+	return ok && t.Timeout()
+}
+
+// anyInTree reports whether the match function returns true for the
+// error or any error in its chain, descending into the errors of
+// multierrors, given the number of Unwrap steps to the error.
+func anyInTree(err error, depth int, match func(error) bool) bool {
+	for ; err != nil; err, depth = unwrapAt(err, depth), depth+1 {
+		if match(err) {
+			return true
+		}
+		if merr, ok := err.(multierror); ok {
+			for _, err := range unwrapMulti(merr) {
+				if anyInTree(err, depth+1, match) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return false
+}
+
+// canceledByParent implements an error type annotated as cancelled by a
+// parent context.
+type canceledByParent struct {
+	error error
+
+	formatGuard
+}
+
+func (w *canceledByParent) Error() string {
+	return ErrCanceledByParent.Error() + ": " + w.error.Error()
+}
+
+func (w *canceledByParent) Unwrap() error { return w.error }
+
+// Is matches ErrCanceledByParent.
+func (w *canceledByParent) Is(target error) bool { return target == ErrCanceledByParent }
+
+func (w *canceledByParent) Format(s fmt.State, verb rune) {
+	if !w.enterFormat(s, verb) {
+		return
+	}
+	defer w.leaveFormat()
+
+	prefix := ErrCanceledByParent.Error() + ": "
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "%s%v", prefix, w.error)
+			formatFrames(s, verb, w)
+			formatBranches(s, w.error)
+			writeAnnotations(s, w)
+			return
+		}
+		if s.Flag('#') {
+			fmt.Fprintf(s, "&errors.canceledByParent{%q}", w.Error())
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, prefix+safeError(w.error, verb))
+	case 'q':
+		fmt.Fprintf(s, "%q", prefix+safeError(w.error, verb))
+	default:
+		// empty
+	}
+}
@@ -0,0 +1,142 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+// timeoutError is like a net.Error.
+type timeoutError struct{ timeout bool }
+
+func (e timeoutError) Error() string   { return "i/o failed" }
+func (e timeoutError) Timeout() bool   { return e.timeout }
+func (e timeoutError) Temporary() bool { return false }
+
+func TestIsTimeout(t *testing.T) {
+	t.Run("no timeout", func(t *testing.T) {
+		testutils.AssertFalse(t, IsTimeout(nil))
+		testutils.AssertFalse(t, IsTimeout(New("failed")))
+		testutils.AssertFalse(t, IsTimeout(context.Canceled))
+		testutils.AssertFalse(t, IsTimeout(timeoutError{timeout: false}))
+		testutils.AssertFalse(t, IsTimeout(Join(New("failed"), context.Canceled)))
+	})
+
+	t.Run("context", func(t *testing.T) {
+		testutils.AssertTrue(t, IsTimeout(context.DeadlineExceeded))
+		testutils.AssertTrue(t, IsTimeout(
+			fmt.Errorf("calling: %w", WithFrame(context.DeadlineExceeded)),
+		))
+	})
+
+	t.Run("net and os", func(t *testing.T) {
+		testutils.AssertTrue(t, IsTimeout(timeoutError{timeout: true}))
+		testutils.AssertTrue(t, IsTimeout(os.ErrDeadlineExceeded))
+		testutils.AssertTrue(t, IsTimeout(
+			&os.PathError{Op: "read", Path: "/dev/null", Err: os.ErrDeadlineExceeded},
+		))
+		testutils.AssertTrue(t, IsTimeout(Errorf("reading: %w", timeoutError{timeout: true})))
+	})
+
+	t.Run("multierror", func(t *testing.T) {
+		testutils.AssertTrue(t, IsTimeout(Join(New("failed"), context.DeadlineExceeded)))
+		testutils.AssertTrue(t, IsTimeout(
+			fmt.Errorf("calls: %w", Join(New("failed"), Join(timeoutError{timeout: true}))),
+		))
+		testutils.AssertTrue(t, IsTimeout(fmt.Errorf("%w; %w", New("failed"), context.DeadlineExceeded)))
+	})
+
+	t.Run("deadline info", func(t *testing.T) {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+		err := Errorf("calling: %w", WithDeadlineInfo(ctx, New("call failed")))
+		testutils.AssertTrue(t, IsTimeout(err))
+
+		ctx, cancel = context.WithTimeout(context.Background(), time.Hour)
+		cancel()
+		testutils.AssertFalse(t, IsTimeout(WithDeadlineInfo(ctx, New("call failed"))))
+	})
+}
+
+func TestIsCanceled(t *testing.T) {
+	testutils.AssertFalse(t, IsCanceled(nil))
+	testutils.AssertFalse(t, IsCanceled(New("failed")))
+	testutils.AssertFalse(t, IsCanceled(context.DeadlineExceeded))
+
+	testutils.AssertTrue(t, IsCanceled(context.Canceled))
+	testutils.AssertTrue(t, IsCanceled(Errorf("calling: %w", WithFrame(context.Canceled))))
+	testutils.AssertTrue(t, IsCanceled(Join(New("failed"), context.Canceled)))
+	testutils.AssertTrue(t, IsCanceled(CanceledByParent(New("failed"))))
+}
+
+func TestCancellationFrom(t *testing.T) {
+	tcs := []struct {
+		name string
+		err  error
+		kind CancelKind
+		ok   bool
+	}{
+		{"nil", nil, 0, false},
+		{"other", New("failed"), 0, false},
+		{"deadline", context.DeadlineExceeded, CancelDeadline, true},
+		{"wrapped deadline", Errorf("calling: %w", context.DeadlineExceeded), CancelDeadline, true},
+		{"net timeout", timeoutError{timeout: true}, CancelDeadline, true},
+		{"explicit", context.Canceled, CancelExplicit, true},
+		{"wrapped explicit", Errorf("calling: %w", WithFrame(context.Canceled)), CancelExplicit, true},
+		{"multierror", Join(New("failed"), context.Canceled), CancelExplicit, true},
+		{"parent", CanceledByParent(context.Canceled), CancelParent, true},
+		{"parent deadline", CanceledByParent(context.DeadlineExceeded), CancelParent, true},
+		{"wrapped parent", Errorf("task: %w", CanceledByParent(New("failed"))), CancelParent, true},
+		{"parent in multierror", Join(New("failed"), CanceledByParent(New("failed"))), CancelParent, true},
+		{"timeout and cancel", Join(context.Canceled, context.DeadlineExceeded), CancelDeadline, true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			kind, ok := CancellationFrom(tc.err)
+			testutils.AssertEqual(t, tc.kind, kind)
+			testutils.AssertEqual(t, tc.ok, ok)
+		})
+	}
+
+	t.Run("cause", func(t *testing.T) {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(ErrCanceledByParent)
+		kind, ok := CancellationFrom(context.Cause(ctx))
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, CancelParent, kind)
+
+		kind, ok = CancellationFrom(ctx.Err())
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, CancelExplicit, kind)
+	})
+}
+
+func TestCanceledByParent(t *testing.T) {
+	testutils.AssertNil(t, CanceledByParent(nil))
+
+	inner := fmt.Errorf("watcher: %w", context.Canceled)
+	err := CanceledByParent(inner)
+	testutils.AssertEqual(t, "cancelled by parent context: watcher: context canceled", err.Error())
+	testutils.AssertEqual(t, inner, Unwrap(err))
+	testutils.AssertTrue(t, Is(err, ErrCanceledByParent))
+	testutils.AssertTrue(t, Is(err, context.Canceled))
+	testutils.AssertFalse(t, Is(inner, ErrCanceledByParent))
+
+	testutils.AssertEqual(t, "cancelled by parent context: watcher: context canceled", fmt.Sprintf("%v", err))
+	testutils.AssertEqual(t, `"cancelled by parent context: watcher: context canceled"`, fmt.Sprintf("%q", err))
+	testutils.AssertEqual(t,
+		`&errors.canceledByParent{"cancelled by parent context: watcher: context canceled"}`,
+		fmt.Sprintf("%#v", err),
+	)
+}
+
+func TestCancelKindString(t *testing.T) {
+	testutils.AssertEqual(t, "deadline", CancelDeadline.String())
+	testutils.AssertEqual(t, "explicit", CancelExplicit.String())
+	testutils.AssertEqual(t, "parent", CancelParent.String())
+	testutils.AssertEqual(t, "CancelKind(0)", CancelKind(0).String())
+}
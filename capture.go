@@ -0,0 +1,147 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+)
+
+// CaptureConfig configures how much call stack information the
+// context-aware constructors (WithStackTraceCtx, WithFrameCtx, ErrorfCtx
+// etc) capture, for the requests or tasks with the context. See
+// ContextWithCaptureConfig.
+//
+// The zero value captures nothing: errors are still created and
+// wrapped, but without frames.
+type CaptureConfig struct {
+	// Stacks is whether stack traces are captured by WithStackTraceCtx
+	// and NewWithStackTraceCtx. If Stacks is true, Frames is implied.
+	Stacks bool
+
+	// Frames is whether frames for the caller are captured by
+	// WithFrameCtx, NewWithFrameCtx and ErrorfCtx. They are also
+	// captured in place of stack traces if Stacks is false.
+	Frames bool
+
+	// MaxDepth is the maximum number of frames in a stack trace. Zero or
+	// less is no limit.
+	MaxDepth int
+}
+
+// defaultCaptureConfig is the package default: the context-aware
+// constructors behave the same as the functions without a context.
+var defaultCaptureConfig = CaptureConfig{Stacks: true, Frames: true}
+
+type captureConfigKey struct{}
+
+// ContextWithCaptureConfig returns a copy of the context with the
+// CaptureConfig, so that errors created with the context-aware
+// constructors have as much (or as little) call stack information as
+// suits the work being done with it. For example, for a debug request:
+//
+//	ctx = errors.ContextWithCaptureConfig(ctx, errors.CaptureConfig{Stacks: true, MaxDepth: 16})
+//
+// or, for bulk ingestion where errors are expected and counted:
+//
+//	ctx = errors.ContextWithCaptureConfig(ctx, errors.CaptureConfig{})
+//
+// If the context has no CaptureConfig, the context-aware constructors
+// behave the same as the functions without a context.
+func ContextWithCaptureConfig(ctx context.Context, config CaptureConfig) context.Context {
+	return context.WithValue(ctx, captureConfigKey{}, config)
+}
+
+// CaptureConfigFrom returns the CaptureConfig of the context, if it has
+// one.
+func CaptureConfigFrom(ctx context.Context) (config CaptureConfig, ok bool) {
+	if ctx == nil {
+		return CaptureConfig{}, false
+	}
+	config, ok = ctx.Value(captureConfigKey{}).(CaptureConfig)
+	return
+}
+
+// captureConfig returns the CaptureConfig of the context, or the package
+// default.
+func captureConfig(ctx context.Context) CaptureConfig {
+	if config, ok := CaptureConfigFrom(ctx); ok {
+		return config
+	}
+	return defaultCaptureConfig
+}
+
+// NewWithStackTraceCtx returns a new error annotated with a stack trace,
+// like NewWithStackTrace, or with less, as configured by the context.
+func NewWithStackTraceCtx(ctx context.Context, msg string) error {
+	return withStackTraceCtx(ctx, New(msg))
+}
+
+// WithStackTraceCtx adds a stack trace to the error by wrapping it, like
+// WithStackTrace, or adds less, as configured by the context.
+func WithStackTraceCtx(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	return withStackTraceCtx(ctx, err)
+}
+
+//go:noinline
+func withStackTraceCtx(ctx context.Context, err error) error {
+	config := captureConfig(ctx)
+	switch {
+	case config.Stacks:
+		st := getStack(4)
+		if config.MaxDepth > 0 && len(st) > config.MaxDepth {
+			st = st[:config.MaxDepth]
+		}
+		return &withStackTrace{
+			error:  err,
+			frames: st,
+		}
+	case config.Frames:
+		return &withFrames{
+			error:  err,
+			frames: frames{getFrame(4)},
+		}
+	default:
+		return err
+	}
+}
+
+// NewWithFrameCtx returns a new error annotated with a call stack frame,
+// like NewWithFrame, unless frames are not captured for the context.
+func NewWithFrameCtx(ctx context.Context, msg string) error {
+	if !captureFrames(ctx) {
+		return New(msg)
+	}
+	return &withFrames{
+		error:  New(msg),
+		frames: frames{getFrame(3)},
+	}
+}
+
+// WithFrameCtx adds a call stack frame to the error by wrapping it, like
+// WithFrame, unless frames are not captured for the context.
+func WithFrameCtx(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if !captureFrames(ctx) {
+		return err
+	}
+	return WithFrameAt(err, 1)
+}
+
+// ErrorfCtx is the same as Errorf, unless frames are not captured for
+// the context, in which case it is the same as fmt.Errorf.
+func ErrorfCtx(ctx context.Context, format string, values ...interface{}) error {
+	if !captureFrames(ctx) {
+		return fmt.Errorf(format, values...)
+	}
+	return errorf(format, values, false, 0)
+}
+
+// captureFrames reports whether frames are captured for the context.
+func captureFrames(ctx context.Context) bool {
+	config := captureConfig(ctx)
+	return config.Stacks || config.Frames
+}
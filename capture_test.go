@@ -0,0 +1,70 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+func TestCaptureConfig(t *testing.T) {
+	var (
+		defaults = context.Background()
+		nothing  = ContextWithCaptureConfig(context.Background(), CaptureConfig{})
+		frames   = ContextWithCaptureConfig(context.Background(), CaptureConfig{Frames: true})
+		stacks   = ContextWithCaptureConfig(context.Background(), CaptureConfig{Stacks: true, MaxDepth: 2})
+	)
+	errBase := New("failed")
+
+	t.Run("CaptureConfigFrom", func(t *testing.T) {
+		_, ok := CaptureConfigFrom(defaults)
+		testutils.AssertFalse(t, ok)
+		config, ok := CaptureConfigFrom(stacks)
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, CaptureConfig{Stacks: true, MaxDepth: 2}, config)
+	})
+
+	t.Run("WithStackTraceCtx", func(t *testing.T) {
+		err := WithStackTraceCtx(defaults, errBase)
+		testutils.AssertEqual(t, len(FramesFrom(WithStackTrace(errBase))), len(FramesFrom(err)))
+		testutils.AssertMatch(t, `\.TestCaptureConfig\.func\d+$`, LocationOf(FramesFrom(err)[0]).Function)
+
+		err = WithStackTraceCtx(stacks, errBase)
+		testutils.AssertEqual(t, 2, len(FramesFrom(err)))
+		testutils.AssertMatch(t, `\.TestCaptureConfig\.func\d+$`, LocationOf(FramesFrom(err)[0]).Function)
+		testutils.AssertEqual(t, 2, len(FramesFrom(NewWithStackTraceCtx(stacks, "failed"))))
+
+		err = WithStackTraceCtx(frames, errBase)
+		testutils.AssertEqual(t, 1, len(FramesFrom(err)))
+		testutils.AssertMatch(t, `\.TestCaptureConfig\.func\d+$`, LocationOf(FramesFrom(err)[0]).Function)
+
+		testutils.AssertEqual(t, errBase, WithStackTraceCtx(nothing, errBase))
+		testutils.AssertEqual(t, 0, len(FramesFrom(NewWithStackTraceCtx(nothing, "failed"))))
+		testutils.AssertNil(t, WithStackTraceCtx(stacks, nil))
+	})
+
+	t.Run("WithFrameCtx", func(t *testing.T) {
+		for _, ctx := range []context.Context{defaults, frames, stacks} {
+			err := WithFrameCtx(ctx, errBase)
+			testutils.AssertEqual(t, 1, len(FramesFrom(err)))
+			testutils.AssertMatch(t, `\.TestCaptureConfig\.func\d+$`, LocationOf(FramesFrom(err)[0]).Function)
+			testutils.AssertEqual(t, 1, len(FramesFrom(NewWithFrameCtx(ctx, "failed"))))
+		}
+		testutils.AssertEqual(t, errBase, WithFrameCtx(nothing, errBase))
+		testutils.AssertEqual(t, 0, len(FramesFrom(NewWithFrameCtx(nothing, "failed"))))
+		testutils.AssertNil(t, WithFrameCtx(frames, nil))
+	})
+
+	t.Run("ErrorfCtx", func(t *testing.T) {
+		err := ErrorfCtx(frames, "wrapped: %w", errBase)
+		testutils.AssertEqual(t, "wrapped: failed", err.Error())
+		testutils.AssertEqual(t, 1, len(FramesFrom(err)))
+		testutils.AssertMatch(t, `\.TestCaptureConfig\.func\d+$`, LocationOf(FramesFrom(err)[0]).Function)
+		testutils.AssertTrue(t, Is(err, errBase))
+
+		err = ErrorfCtx(nothing, "wrapped: %w", errBase)
+		testutils.AssertEqual(t, fmt.Errorf("wrapped: %w", errBase), err)
+		testutils.AssertEqual(t, 0, len(FramesFrom(err)))
+	})
+}
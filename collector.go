@@ -0,0 +1,143 @@
+package errors
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// maxCollectedLine is the maximum size of a line collected by a
+// WriterCollector. The rest of a longer line is dropped.
+const maxCollectedLine = 64 * 1024
+
+// WriterCollector is an io.Writer that collects the errors written to
+// it as text, one per line, so that code that reports errors by writing
+// them (eg, to a log or os.Stderr) can have them handled as errors
+// without being changed:
+//
+//	collector := errors.NewWriterCollector()
+//	legacy.Run(input, collector) // Writes "line 3: bad input\n" etc.
+//	collector.Close()
+//	return collector.Err()
+//
+// Each line written is the message of an error. An error printed with
+// the `%+v` verb is collected as a single error with its frames: the
+// lines after a message that are frames (a function name, followed by
+// a line starting with a tab with its file and line) are parsed with
+// the message, like with ErrorFromBytes. Other lines that start with a
+// tab continue the error before them, and empty lines are skipped.
+//
+// Since whether a line is the function of a frame is only known once
+// the line after it is written, an error is only collected once two
+// more lines are written, an empty line is written, or Close is called.
+// A line may be written in parts across calls to Write: it is collected
+// once it ends with a newline (or Close is called). Lines longer than
+// 64KiB are truncated. A WriterCollector is safe for concurrent use, but
+// the writes of concurrent writers are interleaved in the order they
+// are made, so each should write whole lines (or errors) with a single
+// call to Write.
+type WriterCollector struct {
+	mu      sync.Mutex
+	partial []byte   // A line without its newline yet.
+	block   [][]byte // The lines of the error being collected.
+	pending []byte   // A line after block that is either a function or a message.
+	merr    SafeMultiError
+}
+
+var _ io.WriteCloser = (*WriterCollector)(nil)
+
+// NewWriterCollector returns an empty WriterCollector.
+func NewWriterCollector() *WriterCollector {
+	return new(WriterCollector)
+}
+
+// Write collects the errors of the lines written (see WriterCollector).
+// It never fails.
+func (c *WriterCollector) Write(p []byte) (n int, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n = len(p)
+	for len(p) > 0 {
+		line, rest, found := bytes.Cut(p, []byte("\n"))
+		if room := maxCollectedLine - len(c.partial); room > 0 {
+			if len(line) > room {
+				line = line[:room]
+			}
+			c.partial = append(c.partial, line...)
+		}
+		if !found {
+			break
+		}
+		c.collectLine(bytes.TrimSuffix(c.partial, []byte("\r")))
+		c.partial = nil
+		p = rest
+	}
+	return n, nil
+}
+
+// Close collects the last error written, and any line written without
+// a newline at the end. It never fails. Lines written after Close are
+// collected as if nothing had been written before.
+func (c *WriterCollector) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.partial) > 0 {
+		c.collectLine(c.partial)
+		c.partial = nil
+	}
+	c.flush()
+	return nil
+}
+
+// Err returns the errors collected so far, like ErrorOrNil: nil if no
+// errors were collected, the error itself if only one was, or else a
+// MultiError. Call it after Close, to include the last error.
+func (c *WriterCollector) Err() error {
+	return c.merr.ErrorOrNil()
+}
+
+// collectLine adds a line to the error being collected, or collects
+// that error and starts another with the line.
+func (c *WriterCollector) collectLine(line []byte) {
+	switch {
+	case len(bytes.TrimSpace(line)) == 0:
+		c.flush()
+	case line[0] == '\t':
+		if c.pending != nil { // The pending line was a function.
+			c.block = append(c.block, c.pending)
+			c.pending = nil
+		}
+		c.block = append(c.block, line)
+	case len(c.block) == 0:
+		c.block = append(c.block, line)
+	case c.pending == nil:
+		c.pending = line
+	default: // The pending line was a message.
+		c.appendBlock()
+		c.block, c.pending = append(c.block, c.pending), line
+	}
+}
+
+// flush collects the error being collected, and the pending line as
+// another.
+func (c *WriterCollector) flush() {
+	c.appendBlock()
+	if c.pending != nil {
+		c.block, c.pending = append(c.block, c.pending), nil
+		c.appendBlock()
+	}
+}
+
+// appendBlock collects the error being collected, if any.
+func (c *WriterCollector) appendBlock() {
+	if len(c.block) == 0 {
+		return
+	}
+	text := bytes.Join(c.block, []byte("\n"))
+	c.block = c.block[:0]
+	err, _ := ParseErrorFromBytes(text)
+	if err == nil { // Eg, "<nil>".
+		err = New(string(text))
+	}
+	c.merr.Append(err)
+}
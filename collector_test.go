@@ -0,0 +1,97 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+func TestWriterCollector(t *testing.T) {
+	t.Run("one error per line", func(t *testing.T) {
+		c := NewWriterCollector()
+		fmt.Fprintln(c, "line 3: bad input")
+		testutils.AssertNil(t, c.Err())
+		fmt.Fprintln(c, "line 7: bad input")
+		testutils.AssertNil(t, c.Err()) // It may be the function of a frame.
+		fmt.Fprintln(c)
+		fmt.Fprint(c, "line 9: bad input")
+		// The empty line ends the error before it.
+		testutils.AssertEqual(t, "[line 3: bad input; line 7: bad input]", c.Err().Error())
+
+		testutils.AssertNil(t, c.Close())
+		testutils.AssertEqual(t, "[line 3: bad input; line 7: bad input; line 9: bad input]", c.Err().Error())
+	})
+
+	t.Run("nothing written", func(t *testing.T) {
+		c := NewWriterCollector()
+		testutils.AssertNil(t, c.Close())
+		testutils.AssertNil(t, c.Err())
+	})
+
+	t.Run("errors with frames", func(t *testing.T) {
+		framed := WithFrames(New("failed"), Frames{
+			NewFrame("pkg.Fn", "/src/pkg/fn.go", 3),
+			NewFrame("main.main", "/src/main.go", 10),
+		})
+		c := NewWriterCollector()
+		fmt.Fprintf(c, "%+v\n", framed)
+		fmt.Fprintln(c, "plain")
+		fmt.Fprintf(c, "%+v\n", framed)
+		c.Close()
+
+		errs := ErrorsFrom(c.Err())
+		testutils.AssertEqual(t, 3, len(errs))
+		testutils.AssertEqual(t, "failed", errs[0].Error())
+		testutils.AssertEqual(t, FramesFrom(framed).Locations(), FramesFrom(errs[0]).Locations())
+		testutils.AssertEqual(t, "plain", errs[1].Error())
+		testutils.AssertEqual(t, 0, len(FramesFrom(errs[1])))
+		testutils.AssertEqual(t, FramesFrom(framed).Locations(), FramesFrom(errs[2]).Locations())
+	})
+
+	t.Run("partial writes", func(t *testing.T) {
+		c := NewWriterCollector()
+		text := "failed\npkg.Fn\n\t/src/pkg/fn.go:3\r\nnext\n"
+		for i := range text {
+			n, err := c.Write([]byte(text[i : i+1]))
+			testutils.AssertEqual(t, 1, n)
+			testutils.AssertNil(t, err)
+		}
+		c.Close()
+		errs := ErrorsFrom(c.Err())
+		testutils.AssertEqual(t, 2, len(errs))
+		testutils.AssertEqual(t, 1, len(FramesFrom(errs[0])))
+		testutils.AssertEqual(t, "next", errs[1].Error())
+	})
+
+	t.Run("huge lines", func(t *testing.T) {
+		c := NewWriterCollector()
+		huge := strings.Repeat("x", maxCollectedLine*2)
+		fmt.Fprintln(c, huge)
+		c.Write([]byte(huge))
+		c.Write([]byte(huge + "\nafter\n"))
+		c.Close()
+		errs := ErrorsFrom(c.Err())
+		testutils.AssertEqual(t, 3, len(errs))
+		testutils.AssertEqual(t, maxCollectedLine, len(errs[0].Error()))
+		testutils.AssertEqual(t, maxCollectedLine, len(errs[1].Error()))
+		testutils.AssertEqual(t, "after", errs[2].Error())
+	})
+
+	t.Run("concurrent writers", func(t *testing.T) {
+		c := NewWriterCollector()
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				fmt.Fprintf(c, "worker %d failed\n", i)
+			}(i)
+		}
+		wg.Wait()
+		c.Close()
+		testutils.AssertEqual(t, 50, len(ErrorsFrom(c.Err())))
+	})
+}
@@ -0,0 +1,364 @@
+// Package compat provides the v0.1 API of the
+// github.com/secureworks/errors package, so that code written against it
+// keeps compiling, and behaving as it did, while migrating to newer
+// versions of the package:
+//
+//	import errors "github.com/secureworks/errors/compat"
+//
+// Every function and type here has the name and signature it had in
+// v0.1. Most are those of the current package, since they behave as they
+// did in v0.1; the rest are adapters over the current package that keep
+// the v0.1 behavior where it has changed since:
+//
+//   - New is the standard library's errors.New: it takes a single string,
+//     which is never treated as a format.
+//   - WithFrame, WithFrameAt, NewWithFrame and NewWithFrameAt always add a
+//     frame, even if the error already has one for the same call site (or
+//     the limit set with errors.SetMaxAnnotations was reached).
+//   - The errors created here (by Errorf, the With* and NewWith*
+//     functions, Opaque and ErrorFromBytes) print with the `%+v` verb in
+//     the v0.1 layout: the message followed by the frames of the chain,
+//     without the lines the current package adds for annotations,
+//     multierrors in the chain and suppressed frames.
+//   - ErrorFromBytes returns the parse error, and false, if the frames in
+//     the text are malformed, and parses everything after the first line
+//     as frames (ie, it does not parse multierrors).
+//
+// Errors created with one package work with the other: the types (eg,
+// MultiError, Frames) are the same, and the errors created here have the
+// Frames and StackTrace methods that the current package (eg, FramesFrom)
+// reads frames from. Settings that change the behavior of the errors
+// package for the whole program (eg, errors.SetMultiErrorFormat) also
+// apply to this package.
+//
+// The tests of this package are copied from the v0.1 tests of the errors
+// package, so a change to the errors package that breaks one of the v0.1
+// behaviors they cover fails here, and needs an adapter here.
+//
+// The version of the errors package used is the one selected for the
+// main module (this module requires a minimum version of it).
+package compat
+
+import (
+	"bytes"
+	stderrors "errors"
+	"fmt"
+	"io"
+
+	"github.com/secureworks/errors"
+)
+
+// Frame is errors.Frame.
+type Frame = errors.Frame
+
+// Frames is errors.Frames.
+type Frames = errors.Frames
+
+// MultiError is errors.MultiError. Its v0.1 methods are Error, Unwrap,
+// Errors, ErrorOrNil and Format.
+type MultiError = errors.MultiError
+
+// ErrorResulter is errors.ErrorResulter.
+type ErrorResulter = errors.ErrorResulter
+
+// The functions of the v0.1 API that behave as they did in v0.1. They
+// are variables, rather than functions wrapping the ones from the errors
+// package, so that calling them does not add a frame to the stack:
+// frames captured by the errors package are those of the caller, as they
+// were in v0.1. Their types are given to check that the signatures have
+// not changed.
+var (
+	// New is the standard library's errors.New: it takes a single string,
+	// not a format.
+	New func(text string) error = stderrors.New
+	// Unwrap is errors.Unwrap.
+	Unwrap func(err error) error = errors.Unwrap
+	// Is is errors.Is.
+	Is func(err, target error) bool = errors.Is
+	// As is errors.As.
+	As func(err error, target interface{}) bool = errors.As
+	// Join is errors.Join.
+	Join func(errs ...error) error = errors.Join
+
+	// FramesFrom is errors.FramesFrom.
+	FramesFrom func(err error) Frames = errors.FramesFrom
+	// WithMessage is errors.WithMessage.
+	WithMessage func(err error, msg string) error = errors.WithMessage
+
+	// NewFrame is errors.NewFrame.
+	NewFrame func(function string, file string, line int) Frame = errors.NewFrame
+	// FrameFromPC is errors.FrameFromPC.
+	FrameFromPC func(pc uintptr) Frame = errors.FrameFromPC
+	// PCFromFrame is errors.PCFromFrame.
+	PCFromFrame func(v interface{}) uintptr = errors.PCFromFrame
+	// FramesFromBytes is errors.FramesFromBytes.
+	FramesFromBytes func(byt []byte) (Frames, error) = errors.FramesFromBytes
+	// FramesFromJSON is errors.FramesFromJSON.
+	FramesFromJSON func(byt []byte) (Frames, error) = errors.FramesFromJSON
+	// Caller is errors.Caller.
+	Caller func() Frame = errors.Caller
+	// CallerAt is errors.CallerAt.
+	CallerAt func(skipCallers int) Frame = errors.CallerAt
+	// CallStack is errors.CallStack.
+	CallStack func() Frames = errors.CallStack
+	// CallStackAt is errors.CallStackAt.
+	CallStackAt func(skipCallers int) Frames = errors.CallStackAt
+	// CallStackAtMost is errors.CallStackAtMost.
+	CallStackAtMost func(skipCallers int, maxFrames int) Frames = errors.CallStackAtMost
+
+	// NewMultiError is errors.NewMultiError.
+	NewMultiError func(errs ...error) *MultiError = errors.NewMultiError
+	// ErrorsFrom is errors.ErrorsFrom.
+	ErrorsFrom func(err error) []error = errors.ErrorsFrom
+	// Append is errors.Append.
+	Append func(errs ...error) error = errors.Append
+	// AppendInto is errors.AppendInto.
+	AppendInto func(receivingErr *error, appendingErr error) bool = errors.AppendInto
+	// AppendResult is errors.AppendResult.
+	AppendResult func(receivingErr *error, resulterFn ErrorResulter) = errors.AppendResult
+)
+
+// Stack trace error wrapper.
+
+// withStackTrace implements an error type annotated with a list of
+// frames as a full stack trace.
+type withStackTrace struct {
+	error  error
+	frames Frames
+}
+
+var _ interface { // Assert interface implementation.
+	error
+	StackTrace() []uintptr
+	Frames() Frames
+	Unwrap() error
+	fmt.Formatter
+} = (*withStackTrace)(nil)
+
+// NewWithStackTrace returns a new error annotated with a stack trace.
+func NewWithStackTrace(msg string) error {
+	return &withStackTrace{
+		error:  New(msg),
+		frames: errors.CallStackAt(1),
+	}
+}
+
+// WithStackTrace adds a stack trace to the error by wrapping it.
+func WithStackTrace(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &withStackTrace{
+		error:  err,
+		frames: errors.CallStackAt(1),
+	}
+}
+
+func (w *withStackTrace) Error() string { return w.error.Error() }
+
+func (w *withStackTrace) Unwrap() error { return w.error }
+
+// StackTrace returns the call stack frames associated with this error
+// in the form of program counters.
+func (w *withStackTrace) StackTrace() []uintptr {
+	pcs := make([]uintptr, 0, len(w.frames))
+	for _, fr := range w.frames {
+		if pc := errors.PCFromFrame(fr); pc != 0 {
+			pcs = append(pcs, pc)
+		}
+	}
+	return pcs
+}
+
+// Frames returns the call stack frames associated with this error.
+func (w *withStackTrace) Frames() Frames {
+	return w.frames
+}
+
+func (w *withStackTrace) Format(s fmt.State, verb rune) {
+	formatWithFrames(s, verb, w, w.error, "withStackTrace")
+}
+
+// Caller frame error wrapper.
+
+// withFrames implements an error type annotated with list of Frames.
+type withFrames struct {
+	error  error
+	frames Frames
+}
+
+var _ interface { // Assert interface implementation.
+	error
+	Frames() Frames
+	Unwrap() error
+	fmt.Formatter
+} = (*withFrames)(nil)
+
+// NewWithFrame returns a new error annotated with a call stack frame.
+func NewWithFrame(msg string) error {
+	return NewWithFrameAt(msg, 1)
+}
+
+// WithFrame adds a call stack frame to the error by wrapping it.
+func WithFrame(err error) error {
+	return WithFrameAt(err, 1)
+}
+
+// NewWithFrameAt returns a new error annotated with a call stack frame.
+// The second param allows you to tune how many callers to skip (in case
+// this is called in a helper you want to ignore, for example).
+func NewWithFrameAt(msg string, skipCallers int) error {
+	return &withFrames{
+		error:  New(msg),
+		frames: Frames{errors.CallerAt(1 + skipCallers)},
+	}
+}
+
+// WithFrameAt adds a call stack frame to the error by wrapping it. The
+// second param allows you to tune how many callers to skip (in case
+// this is called in a helper you want to ignore, for example).
+//
+// Unlike errors.WithFrameAt, the frame is added even if the error was
+// already annotated with the same frame.
+func WithFrameAt(err error, skipCallers int) error {
+	if err == nil {
+		return nil
+	}
+	return &withFrames{
+		error:  err,
+		frames: Frames{errors.CallerAt(1 + skipCallers)},
+	}
+}
+
+// NewWithFrames returns a new error annotated with a list of frames.
+func NewWithFrames(msg string, ff Frames) error {
+	return WithFrames(New(msg), ff)
+}
+
+// WithFrames adds a list of frames to the error by wrapping it.
+func WithFrames(err error, ff Frames) error {
+	if err == nil {
+		return nil
+	}
+	return &withFrames{
+		error:  err,
+		frames: append(Frames(nil), ff...),
+	}
+}
+
+func (w *withFrames) Error() string { return w.error.Error() }
+
+func (w *withFrames) Unwrap() error { return w.error }
+
+// Frames returns the call stack frames associated with this error.
+func (w *withFrames) Frames() Frames {
+	return w.frames
+}
+
+func (w *withFrames) Format(s fmt.State, verb rune) {
+	formatWithFrames(s, verb, w, w.error, "withFrames")
+}
+
+// formatWithFrames formats an error annotated with frames in the v0.1
+// layout: with the `%+v` verb, the message of the wrapped error is
+// followed by the frames of the whole chain, and nothing else.
+func formatWithFrames(s fmt.State, verb rune, w, wrapped error, typeName string) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			// NOTE: removes '+' from wrapped error formatters, to stop recursive
+			// calls to FramesFrom.
+			fmt.Fprintf(s, "%v", wrapped)
+			errors.FramesFrom(w).Format(s, verb)
+			return
+		}
+		if s.Flag('#') {
+			fmt.Fprintf(s, "&errors.%s{%q}", typeName, wrapped)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, w.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", w.Error())
+	default:
+		// empty
+	}
+}
+
+// Errorf is errors.Errorf, except that the error it returns (when the
+// format has at most one `%w` verb) prints in the v0.1 layout.
+func Errorf(format string, values ...interface{}) error {
+	err := errors.ErrorfAt(1, format, values...)
+	if framesErr, ok := err.(interface{ Frames() Frames }); ok {
+		return &withFrames{
+			error:  errors.Unwrap(err),
+			frames: framesErr.Frames(),
+		}
+	}
+	return err
+}
+
+// Error masking.
+
+// Mask returns an error with the same message context as err, but that
+// does not match err and can't be unwrapped. As and Is will return
+// false for all meaningful values.
+func Mask(err error) error {
+	if err == nil {
+		return nil
+	}
+	return New(err.Error())
+}
+
+// Opaque returns an error with the same message context as err, but
+// that does not match err. As and Is will return false for all
+// meaningful values.
+//
+// If err is a chain with Frames, then those are retained as wrappers
+// around the opaque error, so that the error does not lose any
+// information. Otherwise, err cannot be unwrapped.
+func Opaque(err error) error {
+	if err == nil {
+		return nil
+	}
+	newErr := Mask(err)
+	if ff := errors.FramesFrom(err); len(ff) > 0 {
+		newErr = WithFrames(newErr, ff)
+	}
+	return newErr
+}
+
+// Error deserialization.
+
+// ErrorFromBytes parses a stack trace or stack dump provided as bytes
+// into an error. The format of the text is expected to match the output
+// of printing with a formatter using the `%+v` verb. When an error is
+// successfully parsed the second result is true; otherwise it is false.
+// If you receive an error and the second result is false, it is the
+// error from parsing the frames.
+//
+// This only supports single errors with or without a stack trace or
+// appended frames: the first line is the message, and the rest are
+// frames.
+func ErrorFromBytes(byt []byte) (err error, ok bool) {
+	trimbyt := bytes.TrimRight(byt, "\n")
+	if len(trimbyt) == 0 || bytes.Equal(trimbyt, []byte("nil")) || bytes.Equal(trimbyt, []byte("<nil>")) {
+		return nil, false
+	}
+
+	n := bytes.IndexByte(byt, '\n')
+	if n == -1 {
+		return New(string(byt)), true
+	}
+
+	err = New(string(byt[:n]))
+	ff, parseErr := errors.FramesFromBytes(byt[n+1:])
+	if parseErr != nil {
+		return parseErr, false
+	}
+	if len(ff) > 0 {
+		err = WithFrames(err, ff)
+	}
+	return err, true
+}
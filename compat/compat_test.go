@@ -0,0 +1,91 @@
+package compat_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/secureworks/errors"
+	"github.com/secureworks/errors/compat"
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+func TestNew(t *testing.T) {
+	err := compat.New("100%d done")
+	testutils.AssertEqual(t, "100%d done", err.Error())
+	testutils.AssertEqual(t, "100%d done", fmt.Sprintf("%+v", err))
+}
+
+func TestMultiErrorErrors(t *testing.T) {
+	errBasic := compat.New("new err")
+	merr := compat.NewMultiError(errBasic, compat.New("other err"))
+
+	errs := merr.Errors()
+	testutils.AssertEqual(t, 2, len(errs))
+	testutils.AssertEqual(t, errBasic, errs[0])
+
+	errs[0] = nil
+	testutils.AssertEqual(t, errBasic, merr.Errors()[0])
+}
+
+// Errors and types are shared with the errors package.
+func TestInterop(t *testing.T) {
+	merr := compat.NewMultiError(errors.New("a"), compat.New("b"))
+	var target *errors.MultiError
+	testutils.AssertTrue(t, errors.As(compat.WithMessage(merr, "wrapped"), &target))
+	testutils.AssertEqual(t, merr, target)
+
+	err := compat.NewWithFrame("err")
+	testutils.AssertEqual(t, errors.FramesFrom(err), compat.FramesFrom(err))
+	function, _, _ := compat.FramesFrom(err)[0].Location()
+	testutils.AssertEqual(t, "github.com/secureworks/errors/compat_test.TestInterop", function)
+}
+
+// The adapters keep the v0.1 behaviors that have changed in the errors
+// package.
+func TestAdapters(t *testing.T) {
+	t.Run("WithFrame adds a frame for the same call site", func(t *testing.T) {
+		var err error = compat.New("err")
+		for i := 0; i < 2; i++ {
+			err = compat.WithFrame(err)
+		}
+		testutils.AssertEqual(t, 2, len(compat.FramesFrom(err)))
+	})
+
+	t.Run("WithFrame ignores the annotation limit", func(t *testing.T) {
+		errors.SetMaxAnnotations(1)
+		defer errors.SetMaxAnnotations(0)
+
+		err := compat.WithFrame(compat.NewWithFrame("err"))
+		testutils.AssertEqual(t, 2, len(compat.FramesFrom(err)))
+		testutils.AssertEqual(t, 0, errors.SuppressedAnnotations(err))
+	})
+
+	t.Run("%+v has only the message and frames", func(t *testing.T) {
+		joined := errors.Join(errors.New("a"), errors.NewWithFrame("b"))
+		for _, err := range []error{
+			compat.WithFrame(joined),
+			compat.WithStackTrace(errors.WithCorrelationID(compat.New("err"), "abc")),
+			compat.Errorf("wrapped: %w", joined),
+		} {
+			lines := strings.Split(fmt.Sprintf("%+v", err), "\n")
+			testutils.AssertEqual(t, err.Error(), lines[0])
+			testutils.AssertEqual(t, 2*len(compat.FramesFrom(err)), len(lines)-1)
+		}
+	})
+
+	t.Run("ErrorFromBytes fails on malformed frames", func(t *testing.T) {
+		err, ok := compat.ErrorFromBytes([]byte("err\nnot a frame\n"))
+		testutils.AssertFalse(t, ok)
+		_, parseErr := errors.FramesFromBytes([]byte("not a frame\n"))
+		testutils.AssertEqual(t, parseErr.Error(), err.Error())
+	})
+
+	t.Run("errors interoperate with the errors package", func(t *testing.T) {
+		err := compat.WithStackTrace(compat.New("err"))
+		testutils.AssertEqual(t, compat.FramesFrom(err), errors.FramesFrom(err))
+		parsed, ok := errors.ErrorFromBytes(errors.ErrorToBytes(err))
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, len(errors.FramesFrom(err)), len(errors.FramesFrom(parsed)))
+	})
+}
@@ -0,0 +1,7 @@
+module github.com/secureworks/errors/compat
+
+go 1.20
+
+require github.com/secureworks/errors v0.2.1-0.20261015182823-2e706f4cf2f1
+
+replace github.com/secureworks/errors => ../
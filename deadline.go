@@ -0,0 +1,134 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// DeadlineInfo is how much of the deadline of a context remained when
+// an error was annotated with WithDeadlineInfo.
+type DeadlineInfo struct {
+	// Remaining is the time left until the deadline, which is negative
+	// if it had passed.
+	Remaining time.Duration
+
+	// Done is whether the context was done (eg, cancelled, or past its
+	// deadline).
+	Done bool
+}
+
+// String returns the deadline info as it is printed with an error, eg
+// "120ms remaining" or "exceeded by 5ms (done)".
+func (d DeadlineInfo) String() string {
+	var str string
+	if d.Remaining > 0 {
+		str = roundDuration(d.Remaining).String() + " remaining"
+	} else {
+		str = "exceeded by " + roundDuration(-d.Remaining).String()
+	}
+	if d.Done {
+		str += " (done)"
+	}
+	return str
+}
+
+// roundDuration rounds a duration to be readable: to milliseconds,
+// unless it is shorter.
+func roundDuration(d time.Duration) time.Duration {
+	if d >= time.Millisecond {
+		return d.Round(time.Millisecond)
+	}
+	return d.Round(time.Microsecond)
+}
+
+// WithDeadlineInfo annotates the error with how much of the deadline of
+// the context remains, and whether the context is already done, so that
+// an error from a downstream call shows whether the call was too slow
+// or failed on its own:
+//
+//	if err := client.Call(ctx, req); err != nil {
+//		return errors.WithDeadlineInfo(ctx, err)
+//	}
+//
+// Use DeadlineInfoFrom to get the info. The error prints it after its
+// message when printed with the `%+v` verb, eg:
+//
+//	call failed
+//	deadline: 120ms remaining
+//	...
+//
+// If the error is nil, WithDeadlineInfo returns nil, and if the context
+// is nil or has no deadline, it returns the error as is.
+func WithDeadlineInfo(ctx context.Context, err error) error {
+	if err == nil || ctx == nil {
+		return err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return err
+	}
+	return &withDeadlineInfo{
+		error: err,
+		info: DeadlineInfo{
+			Remaining: time.Until(deadline),
+			Done:      ctx.Err() != nil,
+		},
+	}
+}
+
+// DeadlineInfoFrom returns the deadline info of the outermost error in
+// the chain annotated with WithDeadlineInfo. The second result is false
+// if there is none.
+func DeadlineInfoFrom(err error) (info DeadlineInfo, ok bool) {
+	for depth := 0; err != nil; depth++ {
+		if w, ok := err.(*withDeadlineInfo); ok {
+			return w.info, true
+		}
+		err = unwrapAt(err, depth)
+	}
+	return DeadlineInfo{}, false
+}
+
+// withDeadlineInfo implements an error type annotated with how much of
+// the deadline of a context remained.
+type withDeadlineInfo struct {
+	error error
+	info  DeadlineInfo
+
+	formatGuard
+}
+
+func (w *withDeadlineInfo) Error() string { return w.error.Error() }
+
+func (w *withDeadlineInfo) Unwrap() error { return w.error }
+
+func (w *withDeadlineInfo) Format(s fmt.State, verb rune) {
+	if !w.enterFormat(s, verb) {
+		return
+	}
+	defer w.leaveFormat()
+
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "%v\ndeadline: %s", w.error, w.info)
+			formatFrames(s, verb, w)
+			formatBranches(s, w.error)
+			writeAnnotations(s, w)
+			return
+		}
+		if s.Flag('#') {
+			fmt.Fprintf(s, "&errors.withDeadlineInfo{%q}", w.error)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, safeError(w.error, verb))
+	case 'q':
+		fmt.Fprintf(s, "%q", safeError(w.error, verb))
+	default:
+		// empty
+	}
+}
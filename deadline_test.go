@@ -0,0 +1,73 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+func TestWithDeadlineInfo(t *testing.T) {
+	t.Run("no-ops", func(t *testing.T) {
+		testutils.AssertNil(t, WithDeadlineInfo(context.Background(), nil))
+
+		err := New("failed")
+		testutils.AssertEqual(t, err, WithDeadlineInfo(context.Background(), err))
+		testutils.AssertEqual(t, err, WithDeadlineInfo(nil, err)) //nolint:staticcheck
+		_, ok := DeadlineInfoFrom(err)
+		testutils.AssertFalse(t, ok)
+	})
+
+	t.Run("records the remaining time", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+		inner := NewWithFrame("call failed")
+		err := Errorf("calling: %w", WithDeadlineInfo(ctx, inner))
+
+		info, ok := DeadlineInfoFrom(err)
+		testutils.AssertTrue(t, ok)
+		testutils.AssertTrue(t, info.Remaining > 59*time.Minute && info.Remaining <= time.Hour)
+		testutils.AssertFalse(t, info.Done)
+		testutils.AssertEqual(t, "calling: call failed", err.Error())
+		testutils.AssertTrue(t, Is(err, inner))
+	})
+
+	t.Run("records a done context", func(t *testing.T) {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+		info, ok := DeadlineInfoFrom(WithDeadlineInfo(ctx, New("failed")))
+		testutils.AssertTrue(t, ok)
+		testutils.AssertTrue(t, info.Remaining < 0)
+		testutils.AssertTrue(t, info.Done)
+	})
+
+	t.Run("formatting", func(t *testing.T) {
+		err := &withDeadlineInfo{
+			error: NewWithFrames("call failed", Frames{NewFrame("pkg.Call", "pkg/call.go", 12)}),
+			info:  DeadlineInfo{Remaining: 120*time.Millisecond + 400*time.Microsecond},
+		}
+		testutils.AssertEqual(t, "call failed", fmt.Sprintf("%v", err))
+		testutils.AssertEqual(t, `"call failed"`, fmt.Sprintf("%q", err))
+		testutils.AssertEqual(t, `&errors.withDeadlineInfo{"call failed"}`, fmt.Sprintf("%#v", err))
+		testutils.AssertEqual(t,
+			"call failed\ndeadline: 120ms remaining\npkg.Call\n\tpkg/call.go:12",
+			fmt.Sprintf("%+v", err))
+	})
+}
+
+func TestDeadlineInfoString(t *testing.T) {
+	for _, tt := range []struct {
+		info     DeadlineInfo
+		expected string
+	}{
+		{DeadlineInfo{Remaining: 120 * time.Millisecond}, "120ms remaining"},
+		{DeadlineInfo{Remaining: 1500 * time.Microsecond}, "2ms remaining"},
+		{DeadlineInfo{Remaining: 250 * time.Microsecond}, "250µs remaining"},
+		{DeadlineInfo{Remaining: -5 * time.Millisecond, Done: true}, "exceeded by 5ms (done)"},
+		{DeadlineInfo{Remaining: time.Second, Done: true}, "1s remaining (done)"},
+	} {
+		testutils.AssertEqual(t, tt.expected, tt.info.String())
+	}
+}
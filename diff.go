@@ -0,0 +1,252 @@
+package errors
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DiffOptions configures how DiffWith compares errors.
+type DiffOptions struct {
+	// Lines compares the line numbers of frames, which change whenever
+	// the code moves.
+	Lines bool
+
+	// Files compares the file paths of frames (after any source mappings,
+	// see AddSourceMapping), which differ between build environments.
+	Files bool
+
+	// IgnoreOrder compares the errors of a multierror regardless of their
+	// order, eg for errors collected concurrently.
+	IgnoreOrder bool
+}
+
+// Difference describes where two errors compared by Diff differ.
+type Difference struct {
+	// Path locates the difference in the error tree, eg
+	// "links[1].frames[0].function" for the function of the first frame
+	// of the second link (see Links), or "errors[2].links[0].message" in
+	// the third error of a multierror. The path of a nil error compared
+	// to a non-nil one is "error".
+	Path string
+
+	// A and B describe the differing values of each error.
+	A, B string
+}
+
+// String returns the difference as text, eg:
+//
+//	links[0].message: "reading config" != "loading config"
+func (d Difference) String() string {
+	return d.Path + ": " + d.A + " != " + d.B
+}
+
+// Diff compares two errors structurally, returning their differences,
+// or nil if they are equivalent. It is the same as DiffWith with the
+// zero DiffOptions: the lines and files of frames and the order of
+// errors in a multierror are not compared.
+//
+// Diff is meant for tests and for reviewing regressions, where
+// comparing the text printed with the `%+v` verb is noisy.
+func Diff(a, b error) []Difference {
+	return DiffWith(a, b, DiffOptions{})
+}
+
+// DiffWith compares two errors structurally, returning their
+// differences, or nil if they are equivalent.
+//
+// The errors are compared by their links (see Links): the message
+// context of each layer of the chain and the functions of its frames,
+// and the files and lines of its frames if configured by the options.
+// The errors of a multierror in the chain are compared in the same way,
+// in order unless configured by the options, as are the frames
+// annotated directly above it. The types of the errors are not
+// compared.
+func DiffWith(a, b error, opts DiffOptions) []Difference {
+	switch {
+	case a == nil && b == nil:
+		return nil
+	case a == nil || b == nil:
+		return []Difference{{Path: "error", A: diffMessage(a), B: diffMessage(b)}}
+	}
+	var diffs []Difference
+	diffNodes(&diffs, "", newDiffNode(a), newDiffNode(b), opts)
+	return diffs
+}
+
+func diffMessage(err error) string {
+	if err == nil {
+		return "<nil>"
+	}
+	return strconv.Quote(safeError(err, 'v'))
+}
+
+// diffNode is an error tree normalized for comparison: the links of an
+// error chain, and the errors of a multierror at its end.
+type diffNode struct {
+	links    []diffLink
+	multi    bool
+	frames   Frames // Annotated directly above the multierror.
+	branches []*diffNode
+}
+
+type diffLink struct {
+	message string
+	frames  Frames
+}
+
+// newDiffNode normalizes the error chain in the same way as Links.
+func newDiffNode(err error) *diffNode {
+	node := new(diffNode)
+	var ff Frames
+	for depth := 0; err != nil; err, depth = unwrapAt(err, depth), depth+1 {
+		if framesErr, ok := err.(framer); ok {
+			ff = prependFrame(ff, framesErr.Frames())
+		}
+
+		if merr, ok := err.(multierror); ok {
+			if mm, ok := err.(*MultiError); ok && mm.message != "" {
+				node.links = append(node.links, diffLink{message: mm.message, frames: ff})
+				ff = nil
+			}
+			node.multi = true
+			node.frames = ff
+			for _, err := range unwrapMulti(merr) {
+				if err != nil {
+					node.branches = append(node.branches, newDiffNode(err))
+				}
+			}
+			return node
+		}
+
+		msg, ok := messageContext(err, unwrapAt(err, depth))
+		if !ok {
+			continue // Only adds frames.
+		}
+		node.links = append(node.links, diffLink{message: msg, frames: ff})
+		ff = nil
+	}
+	return node
+}
+
+func diffNodes(diffs *[]Difference, path string, a, b *diffNode, opts DiffOptions) {
+	diffCount(diffs, path+"links", len(a.links), len(b.links))
+	for i := 0; i < len(a.links) && i < len(b.links); i++ {
+		linkPath := fmt.Sprintf("%slinks[%d].", path, i)
+		if a.links[i].message != b.links[i].message {
+			*diffs = append(*diffs, Difference{
+				Path: linkPath + "message",
+				A:    strconv.Quote(a.links[i].message),
+				B:    strconv.Quote(b.links[i].message),
+			})
+		}
+		diffFrames(diffs, linkPath+"frames", a.links[i].frames, b.links[i].frames, opts)
+	}
+
+	if a.multi != b.multi {
+		*diffs = append(*diffs, Difference{Path: path + "errors", A: describeMulti(a), B: describeMulti(b)})
+		return
+	}
+	if !a.multi {
+		return
+	}
+	diffFrames(diffs, path+"frames", a.frames, b.frames, opts)
+	diffCount(diffs, path+"errors", len(a.branches), len(b.branches))
+	aBranches, bBranches := a.branches, b.branches
+	if opts.IgnoreOrder {
+		aBranches, bBranches = sortDiffNodes(aBranches, opts), sortDiffNodes(bBranches, opts)
+	}
+	for i := 0; i < len(aBranches) && i < len(bBranches); i++ {
+		diffNodes(diffs, fmt.Sprintf("%serrors[%d].", path, i), aBranches[i], bBranches[i], opts)
+	}
+}
+
+func describeMulti(node *diffNode) string {
+	if node.multi {
+		return "multierror"
+	}
+	return "no multierror"
+}
+
+func diffCount(diffs *[]Difference, path string, a, b int) {
+	if a != b {
+		*diffs = append(*diffs, Difference{
+			Path: path,
+			A:    strconv.Itoa(a),
+			B:    strconv.Itoa(b),
+		})
+	}
+}
+
+func diffFrames(diffs *[]Difference, path string, a, b Frames, opts DiffOptions) {
+	diffCount(diffs, path, len(a), len(b))
+	for i := 0; i < len(a) && i < len(b); i++ {
+		la, lb := diffLocation(a[i]), diffLocation(b[i])
+		framePath := fmt.Sprintf("%s[%d].", path, i)
+		if la.Function != lb.Function {
+			*diffs = append(*diffs, Difference{Path: framePath + "function", A: la.Function, B: lb.Function})
+		}
+		if opts.Files && la.File != lb.File {
+			*diffs = append(*diffs, Difference{Path: framePath + "file", A: la.File, B: lb.File})
+		}
+		if opts.Lines && la.Line != lb.Line {
+			*diffs = append(*diffs, Difference{
+				Path: framePath + "line",
+				A:    strconv.Itoa(la.Line),
+				B:    strconv.Itoa(lb.Line),
+			})
+		}
+	}
+}
+
+func diffLocation(fr Frame) Location {
+	loc := LocationOf(fr)
+	loc.File = mapSource(loc.File)
+	return loc
+}
+
+// sortDiffNodes returns the nodes sorted by the text of what is compared
+// with the options, so that equivalent multierrors are compared in the
+// same order.
+func sortDiffNodes(nodes []*diffNode, opts DiffOptions) []*diffNode {
+	keys := make(map[*diffNode]string, len(nodes))
+	for _, node := range nodes {
+		b := new(strings.Builder)
+		writeDiffKey(b, node, opts)
+		keys[node] = b.String()
+	}
+	sorted := append([]*diffNode(nil), nodes...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return keys[sorted[i]] < keys[sorted[j]]
+	})
+	return sorted
+}
+
+func writeDiffKey(b *strings.Builder, node *diffNode, opts DiffOptions) {
+	writeFrames := func(ff Frames) {
+		for _, fr := range ff {
+			loc := diffLocation(fr)
+			b.WriteString(loc.Function)
+			if opts.Files {
+				b.WriteString(" " + loc.File)
+			}
+			if opts.Lines {
+				fmt.Fprintf(b, ":%d", loc.Line)
+			}
+			b.WriteString("\n")
+		}
+	}
+	for _, link := range node.links {
+		b.WriteString(strconv.Quote(link.message) + "\n")
+		writeFrames(link.frames)
+	}
+	if node.multi {
+		writeFrames(node.frames)
+		b.WriteString("[\n")
+		for _, branch := range sortDiffNodes(node.branches, opts) {
+			writeDiffKey(b, branch, opts)
+		}
+		b.WriteString("]\n")
+	}
+}
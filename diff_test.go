@@ -0,0 +1,91 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+func TestDiff(t *testing.T) {
+	frame := func(function, file string, line int) Frames {
+		return Frames{NewFrame(function, file, line)}
+	}
+	chain := func(rootMsg string, root, wrapper Frames) error {
+		err := NewWithFrames(rootMsg, root)
+		return WithFrames(fmt.Errorf("reading config: %w", err), wrapper)
+	}
+	fOpen := frame("pkg.open", "/src/pkg/open.go", 10)
+	fRun := frame("pkg.Run", "/src/pkg/run.go", 20)
+
+	t.Run("nil", func(t *testing.T) {
+		testutils.AssertNil(t, Diff(nil, nil))
+		testutils.AssertEqual(t, []Difference{{Path: "error", A: "<nil>", B: `"failed"`}}, Diff(nil, New("failed")))
+		testutils.AssertEqual(t, []Difference{{Path: "error", A: `"failed"`, B: "<nil>"}}, Diff(New("failed"), nil))
+	})
+
+	t.Run("equivalent", func(t *testing.T) {
+		a := chain("not found", fOpen, fRun)
+		b := chain("not found", frame("pkg.open", "/build/pkg/open.go", 12), frame("pkg.Run", "/src/pkg/run.go", 21))
+		testutils.AssertNil(t, Diff(a, b))
+
+		// Wrappers that only add frames, and the types of the errors, do not
+		// matter.
+		b = WithFrames(fmt.Errorf("reading config: %w", WithFrames(New("not found"), fOpen)), fRun)
+		testutils.AssertNil(t, Diff(a, b))
+	})
+
+	t.Run("messages", func(t *testing.T) {
+		diffs := Diff(chain("not found", fOpen, fRun), chain("denied", fOpen, fRun))
+		testutils.AssertEqual(t, []Difference{{Path: "links[1].message", A: `"not found"`, B: `"denied"`}}, diffs)
+		testutils.AssertEqual(t, `links[1].message: "not found" != "denied"`, diffs[0].String())
+
+		diffs = Diff(chain("not found", fOpen, fRun), NewWithFrames("not found", fOpen))
+		testutils.AssertEqual(t, []Difference{
+			{Path: "links", A: "2", B: "1"},
+			{Path: "links[0].message", A: `"reading config"`, B: `"not found"`},
+			{Path: "links[0].frames[0].function", A: "pkg.Run", B: "pkg.open"},
+		}, diffs)
+	})
+
+	t.Run("frames", func(t *testing.T) {
+		a := chain("not found", fOpen, fRun)
+		b := chain("not found", frame("pkg.Open", "/build/pkg/open.go", 12), nil)
+		testutils.AssertEqual(t, []Difference{
+			{Path: "links[0].frames", A: "1", B: "0"},
+			{Path: "links[1].frames[0].function", A: "pkg.open", B: "pkg.Open"},
+		}, Diff(a, b))
+
+		b = chain("not found", frame("pkg.open", "/build/pkg/open.go", 12), fRun)
+		testutils.AssertEqual(t, []Difference{
+			{Path: "links[1].frames[0].file", A: "/src/pkg/open.go", B: "/build/pkg/open.go"},
+			{Path: "links[1].frames[0].line", A: "10", B: "12"},
+		}, DiffWith(a, b, DiffOptions{Files: true, Lines: true}))
+		testutils.AssertEqual(t, []Difference{
+			{Path: "links[1].frames[0].line", A: "10", B: "12"},
+		}, DiffWith(a, b, DiffOptions{Lines: true}))
+	})
+
+	t.Run("multierrors", func(t *testing.T) {
+		a := WithFrames(NewMultiError(NewWithFrames("a", fOpen), New("b")), fRun)
+		b := WithFrames(NewMultiError(New("b"), NewWithFrames("a", fOpen), New("c")), nil)
+		testutils.AssertEqual(t, []Difference{
+			{Path: "frames", A: "1", B: "0"},
+			{Path: "errors", A: "2", B: "3"},
+			{Path: "errors[0].links[0].message", A: `"a"`, B: `"b"`},
+			{Path: "errors[0].links[0].frames", A: "1", B: "0"},
+			{Path: "errors[1].links[0].message", A: `"b"`, B: `"a"`},
+			{Path: "errors[1].links[0].frames", A: "0", B: "1"},
+		}, Diff(a, b))
+
+		a = NewMultiErrorMsg("failed", NewWithFrames("a", fOpen), New("b"))
+		b = NewMultiErrorMsg("failed", New("b"), NewWithFrames("a", fOpen))
+		testutils.AssertEqual(t, 4, len(Diff(a, b)))
+		testutils.AssertNil(t, DiffWith(a, b, DiffOptions{IgnoreOrder: true}))
+
+		testutils.AssertEqual(t, []Difference{
+			{Path: "links", A: "1", B: "0"},
+			{Path: "errors", A: "no multierror", B: "multierror"},
+		}, Diff(New("a"), NewMultiError(New("a"))))
+	})
+}
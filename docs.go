@@ -145,6 +145,19 @@
 // function will panic if you are not wrapping an error with the "%w"
 // verb.
 //
+// To append a frame for the line of a defer statement to whatever error
+// a function returns, use errors.AnnotateOnReturn with a named result:
+//
+//	func doSomething() (err error) {
+//		defer errors.AnnotateOnReturn(&err)()
+//		// ...
+//	}
+//
+// When an error needs several annotations, errors.Build composes them
+// in the order they are read, instead of nesting wrappers inside-out:
+//
+//	err = errors.Build(err).Msgf("syncing tenant %d", id).Frame().Err()
+//
 // # Multierrors
 //
 // Wrapping errors is useful enough, but there are instances when we
@@ -179,6 +192,14 @@
 //		fmt.Printf("%+v\n", merr.Unwrap())
 //	}
 //
+// When each error belongs to a key, such as a field being validated,
+// use errors.NamedErrors to keep the label with the error:
+//
+//	ne := new(errors.NamedErrors)
+//	ne.Set("email", validateEmail(form.Email))
+//	ne.Set("name", validateName(form.Name))
+//	return ne.ErrorOrNil() // => "[email: invalid address; name: required]"
+//
 // # Retrieving error information
 //
 // The additional types of context this package's wrappers add: call
@@ -208,17 +229,47 @@
 //	len(frames)
 //	// 0
 //
-// errors.ErrorsFrom returns a slice of errors, unwrapping the first
-// multierror found in an error chain and returning the results. If none
-// is found, the slice of errors contains the given error, or is nil if
-// the error is nil:
+// errors.ErrorsFrom returns a slice of errors, unwrapping the given
+// error if it is a multierror. errors.ErrorsFromChain does the same for
+// the first multierror found in an error chain. If none is found, the
+// slice of errors contains the given error, or is nil if the error is
+// nil:
 //
 //	merr := errors.NewMultiError(errors.New("err"), errors.New("err"))
 //	err := errors.WithStackTrace(merr)
-//	errs := errors.ErrorsFrom(err)
-//	len(errs)
+//	len(errors.ErrorsFrom(err))
+//	// 1
+//	len(errors.ErrorsFromChain(err))
 //	// 2
 //
+// errors.ErrorsFromAll goes further, flattening every multierror in the
+// error tree, including multierrors nested in the errors of others, so
+// that none is returned.
+//
+// Code that walks error chains with the single-error Unwrap stops at a
+// MultiError: MultiError.Sequential returns a view of it that such code
+// can walk through each of its errors in turn.
+//
+// # Wrapped multierrors
+//
+// A multierror wrapped in an error chain behaves as follows, for each
+// wrapper:
+//
+//	                    Is/As   ErrorsFrom  ErrorsFromChain  FramesFrom    FramesFromAll  %+v
+//	WithFrame, Errorf   member  wrapper     members          wrapper's     per member     frames, then members
+//	WithStackTrace      member  wrapper     members          stack trace   per member     stack trace, then members
+//	WithMessage         member  wrapper     members          none          per member     message only
+//	fmt.Errorf          member  wrapper     members          none          per member     message only
+//	Mask, Opaque        no      wrapper     wrapper          none          one, none      message only
+//
+// Is and As match the errors of the multierror through any wrapper that
+// can be unwrapped. FramesFrom does not traverse a multierror, so it
+// returns only the frames of the wrappers above it: FramesFromAll
+// returns the frames of each member with those of the wrappers above
+// it. When a wrapper with frames is printed with the `%+v` verb, the
+// errors of the multierror are printed after its frames if any of them
+// have frames, in the layout of a MultiError.
+//
 // # Masking Errors
 //
 // Because this errors package allows us to add a fair amount of
@@ -278,6 +329,17 @@
 //
 // This not an exhaustive list, see the tests for more.
 //
+// Multierrors from other packages, such as those returned by the
+// standard library's errors.Join, do not print their errors' frames.
+// When one of these is wrapped by an error from this package that is
+// printed with %+v, its errors are printed after the wrapper's frames
+// in the same layout as a MultiError, if any of them have frames.
+//
+// An error that is formatted again while it is being formatted, eg
+// because its message includes a value whose String method formats the
+// error itself, is printed as "%!v(CYCLE)" (for the verb) the second
+// time, instead of recursing until the stack overflows.
+//
 // # Unexported interfaces
 //
 // Following the precedent of other errors packages, this package is
@@ -0,0 +1,26 @@
+// Package errctx provides utilities for collecting errors on a
+// context.Context.
+//
+// Some code paths, like middleware chains or template rendering, can't
+// cleanly return errors to the code that should handle them. Instead,
+// the errors can be "parked" on the request context with Append and
+// collected at the top of the call chain with From:
+//
+//	ctx, _ := errctx.With(ctx)
+//	// ... deep in the call chain:
+//	errctx.Append(ctx, err)
+//	// ... back at the top:
+//	if err := errctx.From(ctx); err != nil {
+//		// ...
+//	}
+//
+// Appending to a context without a Collector is a no-op, so code that
+// appends errors does not need to know if anything is collecting them.
+//
+// # Collector
+//
+// Collector is the accumulator installed on the context by With. It is
+// safe for concurrent use, so the context may be shared by goroutines.
+// Errors appended after From (or Collector.Err) is called are still
+// collected, and are returned by later calls.
+package errctx
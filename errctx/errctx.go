@@ -0,0 +1,74 @@
+package errctx
+
+import (
+	"context"
+	"sync"
+
+	"github.com/secureworks/errors"
+)
+
+// collectorKey is the context key for the Collector.
+type collectorKey struct{}
+
+// Collector accumulates errors appended to a context. It is safe for
+// concurrent use.
+type Collector struct {
+	mu  sync.Mutex
+	err error
+}
+
+// With returns a copy of the context with a new Collector installed,
+// along with the Collector.
+func With(ctx context.Context) (context.Context, *Collector) {
+	c := new(Collector)
+	return context.WithValue(ctx, collectorKey{}, c), c
+}
+
+// CollectorFrom returns the Collector installed on the context, or nil
+// if there is none.
+func CollectorFrom(ctx context.Context) *Collector {
+	c, _ := ctx.Value(collectorKey{}).(*Collector)
+	return c
+}
+
+// Append appends the error to the Collector installed on the context,
+// and returns whether the error was collected. If there is no Collector
+// installed, or the error is nil, it does nothing and returns false.
+func Append(ctx context.Context, err error) bool {
+	c := CollectorFrom(ctx)
+	if c == nil {
+		return false
+	}
+	return c.Append(err)
+}
+
+// From returns the errors collected on the context, using the same
+// rules as errors.MultiError.ErrorOrNil: it is nil if there are no
+// errors (or no Collector), the error itself if there is one, and an
+// *errors.MultiError otherwise.
+func From(ctx context.Context) error {
+	c := CollectorFrom(ctx)
+	if c == nil {
+		return nil
+	}
+	return c.Err()
+}
+
+// Append appends the error to the Collector and returns whether the
+// error was non-nil.
+func (c *Collector) Append(err error) bool {
+	if err == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return errors.AppendInto(&c.err, err)
+}
+
+// Err returns the errors collected, using the same rules as
+// errors.MultiError.ErrorOrNil.
+func (c *Collector) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return errors.NewMultiError(c.err).ErrorOrNil()
+}
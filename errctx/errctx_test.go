@@ -0,0 +1,69 @@
+package errctx
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/secureworks/errors"
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+func TestCollector(t *testing.T) {
+	err1 := errors.New("new err: 1")
+	err2 := errors.New("new err: 2")
+
+	t.Run("no-op without a collector", func(t *testing.T) {
+		ctx := context.Background()
+		testutils.AssertFalse(t, Append(ctx, err1))
+		testutils.AssertNil(t, From(ctx))
+		testutils.AssertNil(t, CollectorFrom(ctx))
+	})
+
+	t.Run("collects errors", func(t *testing.T) {
+		ctx, c := With(context.Background())
+		testutils.AssertTrue(t, c == CollectorFrom(ctx))
+		testutils.AssertNil(t, From(ctx))
+
+		testutils.AssertFalse(t, Append(ctx, nil))
+		testutils.AssertTrue(t, Append(ctx, err1))
+		testutils.AssertEqual(t, err1, From(ctx))
+
+		testutils.AssertTrue(t, Append(ctx, err2))
+		testutils.AssertEqual(t, []error{err1, err2}, errors.ErrorsFrom(From(ctx)))
+		testutils.AssertEqual(t, From(ctx), c.Err())
+	})
+
+	t.Run("collects errors after From", func(t *testing.T) {
+		ctx, _ := With(context.Background())
+		Append(ctx, err1)
+		first := From(ctx)
+		Append(ctx, err2)
+
+		testutils.AssertEqual(t, err1, first)
+		testutils.AssertEqual(t, 2, len(errors.ErrorsFrom(From(ctx))))
+	})
+
+	t.Run("collects errors from child contexts", func(t *testing.T) {
+		ctx, _ := With(context.Background())
+		child, cancel := context.WithCancel(ctx)
+		defer cancel()
+		Append(child, err1)
+		testutils.AssertEqual(t, err1, From(ctx))
+	})
+
+	t.Run("is safe for concurrent use", func(t *testing.T) {
+		ctx, _ := With(context.Background())
+		var wg sync.WaitGroup
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				Append(ctx, fmt.Errorf("err %d", i))
+			}(i)
+		}
+		wg.Wait()
+		testutils.AssertEqual(t, 100, len(errors.ErrorsFrom(From(ctx))))
+	})
+}
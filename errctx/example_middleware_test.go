@@ -0,0 +1,53 @@
+package errctx_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/secureworks/errors"
+	"github.com/secureworks/errors/errctx"
+)
+
+// collectErrors is middleware that installs a Collector on each request
+// context and handles any errors collected once the request is served.
+func collectErrors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, _ := errctx.With(r.Context())
+		next.ServeHTTP(w, r.WithContext(ctx))
+
+		if err := errctx.From(ctx); err != nil {
+			for _, err := range errors.ErrorsFrom(err) {
+				fmt.Println("collected:", err)
+			}
+		}
+	})
+}
+
+// renderWidget cannot return an error to its caller, so it parks the
+// error on the context instead.
+func renderWidget(r *http.Request, name string) string {
+	if name == "broken" {
+		errctx.Append(r.Context(), errors.New("cannot render widget: "+name))
+		return ""
+	}
+	return "<" + name + ">"
+}
+
+// Errors that can't be returned up the call chain can be collected on
+// the request context by middleware and handled in one place.
+func Example_middleware() {
+	handler := collectErrors(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, name := range []string{"header", "broken", "footer"} {
+			fmt.Fprint(w, renderWidget(r, name))
+		}
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	fmt.Println("response:", rec.Body.String())
+
+	// Output:
+	// collected: cannot render widget: broken
+	// response: <header><footer>
+}
@@ -14,8 +14,14 @@ package errors
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"reflect"
+	stdruntime "runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
 )
 
 // Stack trace error wrapper.
@@ -25,6 +31,8 @@ import (
 type withStackTrace struct {
 	error  error
 	frames frames
+
+	formatGuard
 }
 
 var _ interface { // Assert interface implementation.
@@ -37,6 +45,7 @@ var _ interface { // Assert interface implementation.
 
 // NewWithStackTrace returns a new error annotated with a stack trace.
 func NewWithStackTrace(msg string) error {
+	countCreated(statsNewWithStackTrace)
 	return &withStackTrace{
 		error:  New(msg),
 		frames: getStack(3),
@@ -48,12 +57,27 @@ func WithStackTrace(err error) error {
 	if err == nil {
 		return nil
 	}
+	countCreated(statsWithStackTrace)
 	return &withStackTrace{
 		error:  err,
 		frames: getStack(3),
 	}
 }
 
+// WithStackTraceInModule adds a stack trace to the error by wrapping it,
+// like WithStackTrace, but only collects the stack within the main
+// module, like CallStackInModule.
+func WithStackTraceInModule(err error) error {
+	if err == nil {
+		return nil
+	}
+	countCreated(statsWithStackTrace)
+	return &withStackTrace{
+		error:  err,
+		frames: getStackInModule(3),
+	}
+}
+
 func (w *withStackTrace) Error() string { return w.error.Error() }
 
 func (w *withStackTrace) Unwrap() error { return w.error }
@@ -71,6 +95,9 @@ func (w *withStackTrace) Unwrap() error { return w.error }
 // packages or APIs that expect stack traces to be represented with
 // uintptrs: pPrefer the Frames method for general interoperability
 // across this package.
+//
+// Synthetic frames have no program counter and are not included, so
+// the result may be shorter than the result of Frames.
 func (w *withStackTrace) StackTrace() []uintptr {
 	return w.frames.StackTrace()
 }
@@ -85,6 +112,11 @@ func (w *withStackTrace) Frames() Frames {
 }
 
 func (w *withStackTrace) Format(s fmt.State, verb rune) {
+	if !w.enterFormat(s, verb) {
+		return
+	}
+	defer w.leaveFormat()
+
 	switch verb {
 	case 'v':
 		if s.Flag('+') {
@@ -92,7 +124,9 @@ func (w *withStackTrace) Format(s fmt.State, verb rune) {
 			// calls to FramesFrom. May have unintended consequences for errors from
 			// outside libraries. Don't mix and match.
 			fmt.Fprintf(s, "%v", w.error)
-			FramesFrom(w).Format(s, verb)
+			formatFrames(s, verb, w)
+			formatBranches(s, w.error)
+			writeAnnotations(s, w)
 			return
 		}
 		if s.Flag('#') {
@@ -101,9 +135,9 @@ func (w *withStackTrace) Format(s fmt.State, verb rune) {
 		}
 		fallthrough
 	case 's':
-		io.WriteString(s, w.Error())
+		io.WriteString(s, safeError(w.error, verb))
 	case 'q':
-		fmt.Fprintf(s, "%q", w.Error())
+		fmt.Fprintf(s, "%q", safeError(w.error, verb))
 	default:
 		// empty
 	}
@@ -115,6 +149,12 @@ func (w *withStackTrace) Format(s fmt.State, verb rune) {
 type withFrames struct {
 	error  error
 	frames frames
+
+	// suppressed counts the frames not added to the error chain since
+	// the annotation budget was reached (see SetMaxAnnotations).
+	suppressed int
+
+	formatGuard
 }
 
 var _ interface { // Assert interface implementation.
@@ -138,6 +178,7 @@ func WithFrame(err error) error {
 // The second param allows you to tune how many callers to skip (in case
 // this is called in a helper you want to ignore, for example).
 func NewWithFrameAt(msg string, skipCallers int) error {
+	countCreated(statsNewWithFrame)
 	return &withFrames{
 		error:  New(msg),
 		frames: frames{getFrame(3 + skipCallers)},
@@ -147,16 +188,73 @@ func NewWithFrameAt(msg string, skipCallers int) error {
 // WithFrameAt adds a call stack frame to the error by wrapping it. The
 // second param allows you to tune how many callers to skip (in case
 // this is called in a helper you want to ignore, for example).
+//
+// If the error was already annotated with the same frame (eg, with
+// `errors.WithFrame(errors.Errorf(...))`), it is returned unchanged.
+// Frames are the same if they are on the same line of the same
+// function, so an error wrapped by a function that calls itself
+// recursively from one line gets a single frame for those calls.
 func WithFrameAt(err error, skipCallers int) error {
 	if err == nil {
 		return nil
 	}
+	if annotationBudgetSpent(err) {
+		return withSuppressedAnnotation(err)
+	}
+	fr := getFrame(3 + skipCallers)
+	if w, ok := err.(*withFrames); ok && len(w.frames) == 1 && sameLocation(w.frames[0], fr) {
+		return err
+	}
+	countCreated(statsWithFrame)
 	return &withFrames{
 		error:  err,
-		frames: frames{getFrame(3 + skipCallers)},
+		frames: frames{fr},
+	}
+}
+
+// WithFileLine adds the file and line of the caller to the error by
+// wrapping it, the same as WithFrame, except that the function of the
+// frame is "unknown". The file and line are resolved when the error is
+// created, which avoids looking up the function when the frame is
+// formatted: use it on hot paths where only file:line is needed (eg,
+// for metrics or sampled logging).
+func WithFileLine(err error) error {
+	if err == nil {
+		return nil
+	}
+	countCreated(statsWithFileLine)
+	return &withFrames{
+		error:  err,
+		frames: frames{getFileLine(3)},
 	}
 }
 
+// sameLocation reports whether the frames are on the same line of the
+// same function: frames for different calls on one line have different
+// program counters. Frames with program counters are compared by the
+// entry of their function, and only if that is the same by their line,
+// so that frames at different call sites are told apart without
+// resolving their locations. So the frames of recursive calls made from
+// the same line are the same.
+func sameLocation(a, b *frame) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.pc == 0 || b.pc == 0 { // Synthetic frames have only their location.
+		return LocationOf(a) == LocationOf(b)
+	}
+	if a.pc == b.pc {
+		return true
+	}
+	fnA, fnB := stdruntime.FuncForPC(a.pc), stdruntime.FuncForPC(b.pc)
+	if fnA == nil || fnB == nil || fnA.Entry() != fnB.Entry() {
+		return false
+	}
+	_, lineA := fnA.FileLine(a.pc)
+	_, lineB := fnB.FileLine(b.pc)
+	return lineA == lineB
+}
+
 // NewWithFrames returns a new error annotated with a list of frames.
 func NewWithFrames(msg string, ff Frames) error {
 	return WithFrames(New(msg), ff)
@@ -176,12 +274,44 @@ func WithFrames(err error, ff Frames) error {
 		}
 		fframes[i] = newFrameFrom(fr)
 	}
+	countCreated(statsWithFrames)
 	return &withFrames{
 		error:  err,
 		frames: fframes,
 	}
 }
 
+// AnnotateOnReturn returns a function that, when deferred, adds a call
+// stack frame for the defer statement to the error returned by the
+// function that deferred it, if it is non-nil:
+//
+//	func doSomething() (err error) {
+//		defer errors.AnnotateOnReturn(&err)()
+//		// ...
+//	}
+//
+// The frame is captured when AnnotateOnReturn is called, rather than
+// when the returned function runs: calling Caller inside a deferred
+// function returns the frame where the function that deferred it
+// returned (or its closing brace), since the runtime does not record
+// where a defer statement is. The error must be a named result.
+func AnnotateOnReturn(err *error) func() {
+	if err == nil {
+		panic(NewWithStackTrace(
+			"errors.AnnotateOnReturn used incorrectly: error pointer must not be nil"))
+	}
+	fr := getFrame(3)
+	return func() {
+		if *err != nil {
+			countCreated(statsAnnotateOnReturn)
+			*err = &withFrames{
+				error:  *err,
+				frames: frames{fr},
+			}
+		}
+	}
+}
+
 func (w *withFrames) Error() string { return w.error.Error() }
 
 func (w *withFrames) Unwrap() error { return w.error }
@@ -196,6 +326,11 @@ func (w *withFrames) Frames() Frames {
 }
 
 func (w *withFrames) Format(s fmt.State, verb rune) {
+	if !w.enterFormat(s, verb) {
+		return
+	}
+	defer w.leaveFormat()
+
 	switch verb {
 	case 'v':
 		if s.Flag('+') {
@@ -203,7 +338,10 @@ func (w *withFrames) Format(s fmt.State, verb rune) {
 			// calls to FramesFrom. May have unintended consequences for errors from
 			// outside libraries. Don't mix and match.
 			fmt.Fprintf(s, "%v", w.error)
-			FramesFrom(w).Format(s, verb)
+			formatFrames(s, verb, w)
+			formatBranches(s, w.error)
+			writeSuppressedAnnotations(s, w)
+			writeAnnotations(s, w)
 			return
 		}
 		if s.Flag('#') {
@@ -212,14 +350,77 @@ func (w *withFrames) Format(s fmt.State, verb rune) {
 		}
 		fallthrough
 	case 's':
-		io.WriteString(s, w.Error())
+		io.WriteString(s, safeError(w.error, verb))
 	case 'q':
-		fmt.Fprintf(s, "%q", w.Error())
+		fmt.Fprintf(s, "%q", safeError(w.error, verb))
 	default:
 		// empty
 	}
 }
 
+// Formatting of multierrors from other packages.
+
+// formatBranches writes the errors of a multierror in the chain in the
+// layout of a MultiError printed with the `%+v` verb, if any of them
+// have frames. Since FramesFrom does not traverse a multierror, they
+// would not be printed otherwise. A multierror that formats itself but
+// is not a MultiError is left alone, since its layout is unknown.
+func formatBranches(s fmt.State, err error) {
+	for depth := 0; err != nil; err, depth = unwrapAt(err, depth), depth+1 {
+		merr, ok := err.(multierror)
+		if !ok {
+			continue
+		}
+		grouped, ok := err.(*MultiError)
+		if !ok {
+			if _, ok := err.(fmt.Formatter); ok {
+				return
+			}
+			grouped = groupBranches(merr)
+		}
+		for _, ff := range FramesFromAll(err) {
+			if len(ff) > 0 {
+				fmt.Fprintf(s, "\n\n%+v", grouped)
+				return
+			}
+		}
+		return
+	}
+}
+
+// groupBranches returns a MultiError of the errors of a multierror that
+// does not format itself, and likewise for any such multierrors in it,
+// so that the whole tree is printed.
+func groupBranches(merr multierror) *MultiError {
+	errs := append([]error(nil), merr.Unwrap()...)
+	for i, err := range errs {
+		if merr, ok := err.(multierror); ok {
+			if _, ok := err.(fmt.Formatter); !ok {
+				errs[i] = groupBranches(merr)
+			}
+		}
+	}
+	return NewMultiErrorGrouped(errs...)
+}
+
+// Safe formatting.
+
+// safeError returns the result of calling Error on err. If Error
+// panics, it returns a placeholder instead, the same way the fmt
+// package does, so that formatting an error never panics.
+func safeError(err error, verb rune) (msg string) {
+	defer func() {
+		if r := recover(); r != nil {
+			if v := reflect.ValueOf(err); v.Kind() == reflect.Pointer && v.IsNil() {
+				msg = "<nil>"
+				return
+			}
+			msg = fmt.Sprintf("%%!%c(PANIC=Error method: %v)", verb, r)
+		}
+	}()
+	return err.Error()
+}
+
 // Helpers to extract data from the error interface.
 
 // FramesFrom extracts all the Frames annotated across an error chain in
@@ -234,32 +435,295 @@ func (w *withFrames) Format(s fmt.State, verb rune) {
 // Therefore, try not to mix the WithFrame and WithStackTrace patterns
 // in a single error chain.
 //
+// A stack trace that does not include any program counters (ie, it is
+// entirely synthetic) is not treated as a stack trace.
+//
+// The stack traces of errors from github.com/pkg/errors (eg, from its
+// WithStack) are found too, the same as those from WithStackTrace: see
+// FramesFromPkgErrors.
+//
+// If the error was handed from one goroutine to another with
+// AcrossGoroutine, only the frames of the goroutine it came from are
+// returned: those added after it was received are ignored.
+//
 // FramesFrom will not traverse a multierror, since there is no sensible
-// way to structure the returned frames.
+// way to structure the returned frames: use FramesFromAll to get the
+// frames for each error in a multierror.
 func FramesFrom(err error) (ff Frames) {
+	ff, _ = framesFrom(err, false)
+	return
+}
+
+// framesFrom returns the frames of the error chain, as FramesFrom does.
+// If the error was handed from one goroutine to another (see
+// AcrossGoroutine), the frames added after it was received are dropped
+// in favor of those of the goroutine it came from, unless stop is true:
+// then the frames added after it was received are returned, with the
+// error where it was handed over.
+func framesFrom(err error, stop bool) (ff Frames, boundary *acrossGoroutine) {
 	var traceFound bool
-	for err != nil {
+	for depth := 0; err != nil; depth++ {
+		err = sequentialMember(err)
+		if b, ok := err.(*acrossGoroutine); ok {
+			if stop {
+				return ff, b
+			}
+			ff, traceFound, boundary = nil, false, b
+		}
 		var errHasTrace bool
-		traceErr, ok := err.(stackTracer)
-		if ok {
+		var trace []uintptr
+		if trace = stackTraceOf(err); len(trace) > 0 {
 			traceFound = true
 			errHasTrace = true
 		}
 		if framesErr, ok := err.(framer); ok {
 			if traceFound && !errHasTrace { // Ignore frames after trace.
 			} else if errHasTrace {
-				ff = framesFromPCs(traceErr.StackTrace()) // Set, not append, traces.
+				ff = framesErr.Frames() // Set, not append, traces.
 			} else {
 				ff = prependFrame(ff, framesErr.Frames()) // Prepend frames.
 			}
 		} else if errHasTrace { // Set, not append, traces.
-			ff = framesFromPCs(traceErr.StackTrace())
+			ff = framesFromPCs(trace)
 		}
-		err = Unwrap(err)
+		err = unwrapAt(err, depth)
+	}
+	if boundary != nil && len(ff) == 0 {
+		ff = boundary.frames.Frames()
 	}
 	return
 }
 
+// Origin returns the frame where an error was created or first
+// annotated: the first frame that FramesFrom would return, using the
+// same precedence rules, but without building the entire list of
+// Frames. If the error chain has no frames, the second result is false.
+func Origin(err error) (origin Frame, ok bool) {
+	var traceFound bool
+	var boundary *acrossGoroutine
+	for depth := 0; err != nil; depth++ {
+		err = sequentialMember(err)
+		if b, ok := err.(*acrossGoroutine); ok {
+			origin, traceFound, boundary = nil, false, b
+		}
+		trace := stackTraceOf(err)
+		framesErr, isFramer := err.(framer)
+		switch {
+		case len(trace) > 0:
+			traceFound = true
+			origin = FrameFromPC(trace[0])
+			if isFramer {
+				if ff := framesErr.Frames(); len(ff) > 0 {
+					origin = ff[0]
+				}
+			}
+		case isFramer && !traceFound:
+			if ff := framesErr.Frames(); len(ff) > 0 {
+				origin = ff[0]
+			}
+		}
+		err = unwrapAt(err, depth)
+	}
+	if origin == nil && boundary != nil {
+		if ff := boundary.frames.Frames(); len(ff) > 0 {
+			origin = ff[0]
+		}
+	}
+	return origin, origin != nil
+}
+
+// OriginString returns the frame from Origin formatted for logging, as
+// the function name qualified by its package, and the file base name
+// and line number, eg:
+//
+//	errors.OriginString(err) // => "pkg.Function(file.go:42)"
+//
+// If the error chain has no frames, it returns an empty string.
+func OriginString(err error) string {
+	fr, ok := Origin(err)
+	if !ok {
+		return ""
+	}
+	function, file, line := fr.Location()
+	function = function[strings.LastIndexByte(function, '/')+1:]
+	return fmt.Sprintf("%s(%s:%s)", function, baseName(mapSource(file)), renderLine(line))
+}
+
+// HasFrames reports whether any error in the chain has frames, ie if
+// FramesFrom would return any, without building the list of Frames.
+//
+// Like FramesFrom, HasFrames does not traverse a multierror.
+func HasFrames(err error) bool {
+	for depth := 0; err != nil; depth++ {
+		err = sequentialMember(err)
+		if len(stackTraceOf(err)) > 0 {
+			return true
+		}
+		if framesErr, ok := err.(framer); ok && len(framesErr.Frames()) > 0 {
+			return true
+		}
+		err = unwrapAt(err, depth)
+	}
+	return false
+}
+
+// Depth returns the number of times Unwrap can be called on the error
+// before reaching the root of the chain: an error that does not wrap
+// another error (or a nil error) has a depth of 0.
+//
+// A multierror is counted as a single level: Depth does not traverse
+// it. To protect against cyclic error chains, Depth stops counting at
+// the maximum set with SetMaxUnwrapDepth (1024 by default).
+func Depth(err error) (depth int) {
+	for err != nil {
+		err = unwrapAt(err, depth)
+		if err == nil || err == errUnwrapDepthExceeded {
+			break
+		}
+		depth++
+	}
+	return
+}
+
+// Unwrap depth guard.
+
+// defaultMaxUnwrapDepth is the most Unwrap steps followed in an error
+// chain, unless it is changed with SetMaxUnwrapDepth.
+const defaultMaxUnwrapDepth = 1024
+
+var maxUnwrapDepth atomic.Int64
+
+// errUnwrapDepthExceeded ends an error chain that is deeper than the
+// maximum set with SetMaxUnwrapDepth.
+var errUnwrapDepthExceeded = New("unwrap depth exceeded")
+
+// SetMaxUnwrapDepth sets the maximum number of times Unwrap is called
+// when following an error chain, for the whole program, in the
+// functions of this package that do so: FramesFrom, FramesFromAll,
+// Origin, HasFrames, Depth, Measure, Clip, Links and ReferencesFrom. The
+// default of 0 (or fewer) means a maximum of 1024.
+//
+// This protects against cyclic error chains (eg, an error whose Unwrap
+// method returns itself), which would otherwise never end. A chain
+// deeper than the maximum is treated as if it ended with an error with
+// the message "unwrap depth exceeded". Raise the maximum if you have
+// legitimately deeper chains.
+//
+// Is and As call the standard library, and are not protected.
+func SetMaxUnwrapDepth(n int) {
+	maxUnwrapDepth.Store(int64(n))
+}
+
+// unwrapAt calls Unwrap on an error found after the given number of
+// Unwrap steps in its chain, returning errUnwrapDepthExceeded instead
+// if that is the maximum set with SetMaxUnwrapDepth.
+func unwrapAt(err error, depth int) error {
+	max := maxUnwrapDepth.Load()
+	if max <= 0 {
+		max = defaultMaxUnwrapDepth
+	}
+	if int64(depth) >= max {
+		if err == errUnwrapDepthExceeded {
+			return nil
+		}
+		return errUnwrapDepthExceeded
+	}
+	return Unwrap(err)
+}
+
+// Annotation budget.
+
+var maxAnnotations atomic.Int64
+
+// SetMaxAnnotations sets the maximum number of frames that WithFrame,
+// WithFrameAt and Errorf (with at most one `%w` verb) add to an error
+// chain, for the whole program. The default of 0 (or fewer) means there
+// is no maximum.
+//
+// This protects against errors that are wrapped again and again, eg in
+// a retry loop, which would otherwise grow a frame for every attempt.
+// Once an error chain has the maximum number of frames, wrapping it
+// counts the frame that was not added instead, and the error printed
+// with the `%+v` verb ends its frames with:
+//
+//	(+37 additional wrap frames suppressed)
+//
+// Use SuppressedAnnotations to get the count, which ErrorToBytes
+// serializes with the error. Errorf still adds its message to the error.
+func SetMaxAnnotations(n int) {
+	maxAnnotations.Store(int64(n))
+}
+
+// SuppressedAnnotations returns the number of frames that were not
+// added to the error chain, since it had the maximum number set with
+// SetMaxAnnotations. It returns 0 if the error is nil or none were
+// suppressed.
+func SuppressedAnnotations(err error) int {
+	for depth := 0; err != nil; err, depth = unwrapAt(err, depth), depth+1 {
+		if w, ok := err.(*withFrames); ok && w.suppressed > 0 {
+			return w.suppressed
+		}
+		if _, ok := err.(multierror); ok {
+			break
+		}
+	}
+	return 0
+}
+
+// writeSuppressedAnnotations writes the line with the count of frames
+// suppressed from the error chain, if any.
+func writeSuppressedAnnotations(w io.Writer, err error) {
+	if n := SuppressedAnnotations(err); n > 0 {
+		fmt.Fprintf(w, "\n(+%d additional wrap frames suppressed)", n)
+	}
+}
+
+// suppressedAnnotationsFromLine parses the line written by
+// writeSuppressedAnnotations.
+func suppressedAnnotationsFromLine(line string) (n int, ok bool) {
+	str, ok := strings.CutPrefix(line, "(+")
+	if !ok {
+		return 0, false
+	}
+	if str, ok = strings.CutSuffix(str, " additional wrap frames suppressed)"); !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(str)
+	return n, err == nil && n > 0
+}
+
+// annotationBudgetSpent reports whether the error chain, up to any
+// multierror, has the maximum number of frames set with
+// SetMaxAnnotations, counting each wrapper that added them once.
+func annotationBudgetSpent(err error) bool {
+	max := maxAnnotations.Load()
+	if max <= 0 {
+		return false
+	}
+	var n int64
+	for depth := 0; err != nil; err, depth = unwrapAt(err, depth), depth+1 {
+		if w, ok := err.(*withFrames); ok && len(w.frames) > 0 {
+			if n++; n >= max {
+				return true
+			}
+		}
+		if _, ok := err.(multierror); ok {
+			break
+		}
+	}
+	return false
+}
+
+// withSuppressedAnnotation counts a frame that was not added to the
+// error chain, on a wrapper without frames. If the error is already such
+// a wrapper it is replaced, rather than wrapped again.
+func withSuppressedAnnotation(err error) error {
+	if w, ok := err.(*withFrames); ok && len(w.frames) == 0 && w.suppressed > 0 {
+		return &withFrames{error: w.error, suppressed: w.suppressed + 1}
+	}
+	return &withFrames{error: err, suppressed: SuppressedAnnotations(err) + 1}
+}
+
 func prependFrame(slice Frames, frames Frames) Frames {
 	slice = append(slice, frames...)
 	copy(slice[len(frames):], slice)
@@ -276,6 +740,8 @@ func prependFrame(slice Frames, frames Frames) Frames {
 type withMessage struct {
 	error   error
 	message string
+
+	formatGuard
 }
 
 var _ interface { // Assert interface implementation.
@@ -302,6 +768,11 @@ func (w *withMessage) Error() string { return w.message }
 func (w *withMessage) Unwrap() error { return w.error }
 
 func (w *withMessage) Format(s fmt.State, verb rune) {
+	if !w.enterFormat(s, verb) {
+		return
+	}
+	defer w.leaveFormat()
+
 	switch verb {
 	case 'v':
 		if s.Flag('#') {
@@ -356,32 +827,541 @@ func Opaque(err error) error {
 // into an error. The format of the text is expected to match the output
 // of printing with a formatter using the `%+v` verb. When an error is
 // successfully parsed the second result is true; otherwise it is false.
-// If you receive an error and the second result is false, well congrats
-// you got an error.
 //
-// Currently, this only supports single errors with or without a stack
-// trace or appended frames.
+// If the frames in the text are malformed, the error is still returned
+// with its message context and any frames that could be parsed, but the
+// second result is false. Use ParseErrorFromBytes to find out what went
+// wrong while parsing.
 //
-// TODO(PH): ensure ErrorFromBytes works with: multierror.
+// This supports single errors with or without a stack trace or
+// appended frames (including messages quoted by ErrorToBytes), and
+// MultiErrors (including groups of errors, as created by
+// NewMultiErrorGrouped), which are returned as a *MultiError. A final
+// line with build info (see SetSerializeBuildInfo) is available from
+// the error with BuildInfoFrom, and a line with a correlation ID before
+// it from CorrelationIDFrom. To parse text written by a logger with a
+// prefix before the message, see ParseErrorFromBytesWith.
 func ErrorFromBytes(byt []byte) (err error, ok bool) {
+	err, parseErr := ParseErrorFromBytes(byt)
+	return err, err != nil && parseErr == nil
+}
+
+// ErrorToBytes serializes an error as text that ErrorFromBytes parses
+// back into an error with the same message and frames: this is the
+// output of printing the error with the `%+v` verb, except that the
+// message is quoted (as with strconv.Quote) if it would not otherwise
+// be parsed as the same message, eg if it has a newline, a tab, a
+// quote, or if it is empty. A nil error is serialized as "<nil>".
+//
+// ErrorFromBytes(ErrorToBytes(err)) always succeeds. The error parsed
+// has the message of the original error (see Error), its frames (see
+// FramesFrom) and its correlation ID (see WithCorrelationID), but none
+// of its types: a multierror is serialized as a single error.
+func ErrorToBytes(err error) []byte {
+	if err == nil {
+		return []byte("<nil>")
+	}
+	var buf bytes.Buffer
+	msg := safeError(err, 'v')
+	if messageNeedsQuote(msg) {
+		msg = strconv.Quote(msg)
+	}
+	buf.WriteString(msg)
+	if ff := FramesFrom(err); len(ff) > 0 {
+		fmt.Fprintf(&buf, "%+v", ff)
+	}
+	writeSuppressedAnnotations(&buf, err)
+	writeAnnotations(&buf, err)
+	if info := serializedBuildInfo.Load(); info != nil {
+		buf.WriteString("\n" + buildInfoPrefix + info.String())
+	}
+	return buf.Bytes()
+}
+
+// ToJSON marshals an error as a JSON object with its message (see
+// Error) and frames (see FramesFrom, including the rules for choosing
+// the deepest stack trace):
+//
+//	{"message":"failed: not found","frames":[{"function":"...","file":"...","line":10}]}
+//
+// If the error chain has a multierror, the object also has its errors
+// (if any), each marshaled the same way, and the frames are those of
+// the errors above the multierror:
+//
+//	{"message":"[a; b]","frames":null,"errors":[{"message":"a","frames":null},{"message":"b","frames":null}]}
+//
+// The object of an error with a correlation ID (see WithCorrelationID)
+// has it as "correlation_id", and any annotations registered with
+// RegisterAnnotation as "annotations". Empty frames are marshaled as set with
+// SetJSONEmptyFrames. A nil error is marshaled as `null`.
+func ToJSON(err error) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	var v *errorJSON
+	if err != nil {
+		v = newErrorJSON(err)
+		v.Build = serializedBuildInfo.Load()
+	}
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// errorJSON is the JSON representation of an error.
+type errorJSON struct {
+	Message string       `json:"message"`
+	Frames  Frames       `json:"frames"`
+	Errors  []*errorJSON `json:"errors,omitempty"`
+	Build   *BuildInfo   `json:"build,omitempty"`
+
+	CorrelationID string           `json:"correlation_id,omitempty"`
+	Annotations   []annotationJSON `json:"annotations,omitempty"`
+}
+
+func newErrorJSON(err error) *errorJSON {
+	v := &errorJSON{
+		Message: safeError(err, 'v'),
+		Frames:  FramesFrom(err),
+	}
+	for _, a := range encodeAnnotations(err) {
+		if a.Kind == correlationIDKind && json.Unmarshal(a.Data, &v.CorrelationID) == nil {
+			continue
+		}
+		v.Annotations = append(v.Annotations, a)
+	}
+	for depth := 0; err != nil; err, depth = unwrapAt(err, depth), depth+1 {
+		if merr, ok := err.(multierror); ok {
+			errs := unwrapMulti(merr)
+			v.Errors = make([]*errorJSON, 0, len(errs))
+			for _, err := range errs {
+				if err != nil {
+					v.Errors = append(v.Errors, newErrorJSON(err))
+				}
+			}
+			break
+		}
+	}
+	return v
+}
+
+var errMalformedErrorJSON = New("malformed error: message is required")
+
+// ErrorFromJSON parses an error provided as JSON-encoded bytes, in the
+// form marshaled by ToJSON, into an error. When an error is
+// successfully parsed the second result is true; otherwise it is false.
+// Use ParseErrorFromJSON to find out what went wrong while parsing.
+//
+// The JSON object must have a "message", and may have "frames" (in the
+// form parsed by FramesFromJSON), "errors" (an array of objects in this
+// form, parsed into a MultiError), "cause" (an object in this form,
+// parsed into the error it wraps), "correlation_id" (see
+// WithCorrelationID) or "annotations" (see RegisterAnnotation):
+//
+//	{"message":"reading config: not found","frames":null,"cause":{"message":"not found","frames":[...]}}
+//
+// The frames of an object with a cause are only those added by its own
+// layer of the error chain. JSON `null` is parsed as a nil error.
+func ErrorFromJSON(byt []byte) (err error, ok bool) {
+	err, parseErr := ParseErrorFromJSON(byt)
+	return err, err != nil && parseErr == nil
+}
+
+// ParseErrorFromJSON is the same as ErrorFromJSON, but returns any
+// problem found while parsing as the second result. Unlike
+// ParseErrorFromBytes, the first result is nil if the JSON cannot be
+// parsed.
+func ParseErrorFromJSON(byt []byte) (err error, parseErr error) {
+	if _, err := limitParseBytes(byt); err != nil {
+		return nil, err
+	}
+	var v *errorFromJSON
+	if err := json.Unmarshal(byt, &v); err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, nil
+	}
+	err, parseErr = v.error()
+	countParsed(err)
+	if err != nil && v.Build != nil {
+		err = &withBuildInfo{error: err, info: *v.Build}
+	}
+	return err, parseErr
+}
+
+// errorFromJSON is the JSON representation of an error, as parsed by
+// ErrorFromJSON.
+type errorFromJSON struct {
+	Message *string          `json:"message"`
+	Frames  json.RawMessage  `json:"frames"`
+	Errors  []*errorFromJSON `json:"errors"`
+	Cause   *errorFromJSON   `json:"cause"`
+	Build   *BuildInfo       `json:"build"`
+
+	CorrelationID string           `json:"correlation_id"`
+	Annotations   []annotationJSON `json:"annotations"`
+}
+
+func (v *errorFromJSON) error() (error, error) {
+	if v.Message == nil {
+		return nil, errMalformedErrorJSON
+	}
+	msg := *v.Message
+
+	var err error
+	switch {
+	case v.Errors != nil:
+		errs := make([]error, 0, len(v.Errors))
+		for _, member := range v.Errors {
+			if member == nil {
+				continue
+			}
+			memberErr, parseErr := member.error()
+			if parseErr != nil {
+				return nil, parseErr
+			}
+			errs = append(errs, memberErr)
+		}
+		err = multiErrorWithMessage(msg, errs)
+	case v.Cause != nil:
+		cause, parseErr := v.Cause.error()
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		err = causeWithMessage(msg, cause)
+	default:
+		err = New(msg)
+	}
+
+	if len(v.Frames) > 0 {
+		ff, parseErr := FramesFromJSON(v.Frames)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		if len(ff) > 0 {
+			err = WithFrames(err, ff)
+		}
+	}
+	annotations := v.Annotations
+	if v.CorrelationID != "" {
+		annotations = append([]annotationJSON{correlationIDAnnotation(v.CorrelationID)}, annotations...)
+	}
+	return decodeAnnotations(err, annotations), nil
+}
+
+// multiErrorWithMessage returns a MultiError of the errors with the
+// message of a parsed error: as the message of the MultiError if it
+// prefixes the list of errors, or else by wrapping it.
+func multiErrorWithMessage(msg string, errs []error) error {
+	merr := NewMultiErrorGrouped(errs...)
+	list := merr.Error()
+	if msg == list {
+		return merr
+	}
+	if prefix, ok := strings.CutSuffix(msg, ": "+list); ok {
+		merr.message = prefix
+		return merr
+	}
+	return WithMessage(merr, msg)
+}
+
+// causeWithMessage returns the cause of a parsed error with its
+// message: the cause itself if the message is the same, or else the
+// cause wrapped with the message (with fmt.Errorf if the message ends
+// with the message of the cause, as if it was wrapped with %w).
+func causeWithMessage(msg string, cause error) error {
+	causeMsg := cause.Error()
+	if msg == causeMsg {
+		return cause
+	}
+	if prefix, ok := strings.CutSuffix(msg, ": "+causeMsg); ok {
+		return fmt.Errorf("%s: %w", prefix, cause)
+	}
+	return WithMessage(cause, msg)
+}
+
+// messageNeedsQuote reports whether the message would not be parsed
+// as itself by ErrorFromBytes.
+func messageNeedsQuote(msg string) bool {
+	switch msg {
+	case "", "nil", "<nil>", "empty errors: []":
+		return true
+	}
+	quoted := strconv.Quote(msg)
+	return quoted[1:len(quoted)-1] != msg
+}
+
+// messageFromBytes returns the message of an error from the first line
+// of its text, unquoting it if it was quoted by ErrorToBytes.
+func messageFromBytes(byt []byte) string {
+	if len(byt) > 1 && byt[0] == '"' && byt[len(byt)-1] == '"' {
+		if msg, err := strconv.Unquote(string(byt)); err == nil {
+			return msg
+		}
+	}
+	return string(byt)
+}
+
+// ParseOptions configures how ParseErrorFromBytesWith parses an error.
+type ParseOptions struct {
+	// LinePreprocessor, if set, is called with the first line of the
+	// text (the line with the message) and returns the line to parse
+	// instead, eg to remove the prefix a logger wrote before the error.
+	// See StripLogPrefix. The lines of the frames are never changed.
+	LinePreprocessor func(line []byte) []byte
+}
+
+// ParseErrorFromBytes is the same as ErrorFromBytes, but returns any
+// problem found while parsing as the second result. The first result
+// is the error reconstructed from the text, as complete as possible,
+// and is nil only if the text is empty or represents a nil error.
+func ParseErrorFromBytes(byt []byte) (err error, parseErr error) {
+	return ParseErrorFromBytesWith(byt, ParseOptions{})
+}
+
+// ParseErrorFromBytesWith is the same as ParseErrorFromBytes, but
+// parses the text as configured by the options. Eg, to parse errors
+// printed by a logger that starts lines with a timestamp and level:
+//
+//	err, parseErr := errors.ParseErrorFromBytesWith(byt, errors.ParseOptions{
+//		LinePreprocessor: errors.StripLogPrefix,
+//	})
+func ParseErrorFromBytesWith(byt []byte, opts ParseOptions) (err error, parseErr error) {
+	byt, limitErr := limitParseBytes(byt)
+	defer func() {
+		if limitErr != nil && err != nil {
+			parseErr = limitErr
+		}
+	}()
+	byt = StripANSI(byt)
+	if opts.LinePreprocessor != nil {
+		line, rest, found := bytes.Cut(byt, []byte("\n"))
+		line = opts.LinePreprocessor(line)
+		byt = make([]byte, 0, len(line)+1+len(rest))
+		byt = append(byt, line...)
+		if found {
+			byt = append(append(byt, '\n'), rest...)
+		}
+	}
+
 	trimbyt := bytes.TrimRight(byt, "\n")
 	if len(trimbyt) == 0 || bytes.Equal(trimbyt, []byte("nil")) || bytes.Equal(trimbyt, []byte("<nil>")) {
-		return nil, false
+		return nil, nil
+	}
+
+	// Take the lines with the build info and annotations off the end.
+	var (
+		info        *BuildInfo
+		annotations []annotationJSON
+		suppressed  int
+		panicValue  bool
+	)
+	for rest := trimbyt; ; {
+		n := bytes.LastIndexByte(rest, '\n')
+		if n < 0 {
+			break
+		}
+		line := string(rest[n+1:])
+		if str, ok := strings.CutPrefix(line, buildInfoPrefix); ok && info == nil && annotations == nil {
+			if parsed, ok := buildInfoFromString(str); ok {
+				info = &parsed
+				rest, byt = rest[:n], rest[:n]
+				continue
+			}
+		}
+		if a, ok := annotationFromLine(line); ok {
+			annotations = append([]annotationJSON{a}, annotations...)
+			rest, byt = rest[:n], rest[:n]
+			continue
+		}
+		if count, ok := suppressedAnnotationsFromLine(line); ok && suppressed == 0 {
+			suppressed = count
+			rest, byt = rest[:n], rest[:n]
+			continue
+		}
+		if strings.HasPrefix(line, panicValuePrefix) && !panicValue {
+			// The panic value is printed with `%#v`, which cannot be parsed back.
+			panicValue = true
+			rest, byt = rest[:n], rest[:n]
+			continue
+		}
+		break
+	}
+
+	err, parseErr = errorFromBytes(byt)
+	countParsed(err)
+	if err != nil && suppressed > 0 {
+		err = &withFrames{error: err, suppressed: suppressed}
+	}
+	err = decodeAnnotations(err, annotations)
+	if info != nil {
+		err = &withBuildInfo{error: err, info: *info}
+	}
+	return err, parseErr
+}
+
+// logLevels are the levels that loggers commonly write before a
+// message, for StripLogPrefix.
+var logLevels = []string{
+	"TRACE", "DEBUG", "INFO", "NOTICE", "WARN", "WARNING",
+	"ERR", "ERROR", "CRIT", "CRITICAL", "FATAL", "PANIC",
+}
+
+// StripLogPrefix returns the line without the timestamps and levels
+// that loggers commonly write at the start of it, for use as the
+// LinePreprocessor of ParseOptions. Eg, each of these lines:
+//
+//	2024-05-02T10:11:12Z ERROR wrap: err
+//	2024/05/02 10:11:12 [error] wrap: err
+//	10:11:12.123 ERR: wrap: err
+//
+// is returned as "wrap: err". Words are removed from the start of the
+// line while they look like a date or time (starting with a date such
+// as 2024-05-02 or 2024/05/02, or a time such as 10:11:12, and made of
+// digits and the separators of a date or time) or a level
+// (optionally followed by a colon). A level is only removed if it is in
+// brackets, in uppercase, or follows a date or time, so that a message
+// such as "error: file not found" is kept. This is a heuristic: if the
+// whole line would be removed it is returned as-is.
+func StripLogPrefix(line []byte) []byte {
+	rest := line
+	afterTimestamp := false
+	for {
+		word, after, found := bytes.Cut(rest, []byte(" "))
+		if isLogTimestamp(word) {
+			afterTimestamp = true
+		} else if !isLogLevel(word, afterTimestamp) {
+			break
+		}
+		if !found {
+			return line
+		}
+		rest = bytes.TrimLeft(after, " ")
+	}
+	if len(rest) == 0 {
+		return line
+	}
+	return rest
+}
+
+func isLogTimestamp(word []byte) bool {
+	if !hasDigitsShape(word, "dddd-dd-dd") && !hasDigitsShape(word, "dddd/dd/dd") && !hasDigitsShape(word, "dd:dd:dd") {
+		return false
+	}
+	if word[len(word)-1] == ':' { // Eg, "2024-05-02: quota exceeded".
+		return false
+	}
+	for _, c := range word {
+		switch {
+		case isDigit(c), c == '.', c == ',', c == 'T', c == 'Z', c == '+', c == '-', c == '/', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// hasDigitsShape reports whether the word starts with the shape, where
+// each 'd' is a digit and any other byte is itself.
+func hasDigitsShape(word []byte, shape string) bool {
+	if len(word) < len(shape) {
+		return false
+	}
+	for i := 0; i < len(shape); i++ {
+		if shape[i] == 'd' && !isDigit(word[i]) || shape[i] != 'd' && word[i] != shape[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// isLogLevel reports whether the word is a level. Unless it is in
+// brackets, it must be in uppercase or, if anyCase, follow a timestamp.
+func isLogLevel(word []byte, anyCase bool) bool {
+	if len(word) > 2 && word[0] == '[' && word[len(word)-1] == ']' {
+		word, anyCase = word[1:len(word)-1], true
+	} else {
+		word = bytes.TrimSuffix(word, []byte(":"))
+	}
+	for _, level := range logLevels {
+		if string(word) == level || (anyCase && strings.EqualFold(string(word), level)) {
+			return true
+		}
+	}
+	return false
+}
+
+// errorFromBytes parses the text of a non-nil error, without its build
+// info.
+func errorFromBytes(byt []byte) (err error, parseErr error) {
+	if merr, ok, merrParseErr := multiErrorFromBytes(byt); ok {
+		return merr, merrParseErr
 	}
 
-	ok = true
 	n := bytes.IndexByte(byt, '\n')
 	if n == -1 {
-		return New(string(byt)), true
+		return New(messageFromBytes(byt)), nil
+	}
+
+	msg, rest := messageFromBytes(byt[:n]), byt[n+1:]
+	err = New(msg)
+
+	// The errors of a multierror in the chain follow the frames of the
+	// error after a blank line (see formatBranches).
+	byt, merr, branchesErr := branchesFromBytes(rest)
+	if merr != nil {
+		err = causeWithMessage(msg, merr)
 	}
 
-	err = New(string(byt[:n]))
-	stack, actualErr := FramesFromBytes(byt[n+1:])
-	if actualErr != nil {
-		return actualErr, false
+	// The frames of the goroutine an error came from follow those added
+	// after it was received (see formatFrames).
+	if received, origin, ok := bytes.Cut(byt, []byte("\n"+receivedFromGoroutine+"\n")); ok {
+		err, parseErr = withFramesFromBytes(err, origin)
+		err, byt = &acrossGoroutine{error: err}, received
 	}
+
+	err, framesErr := withFramesFromBytes(err, byt)
+	if parseErr == nil {
+		parseErr = framesErr
+	}
+	if parseErr == nil {
+		parseErr = branchesErr
+	}
+	return err, parseErr
+}
+
+// withFramesFromBytes returns the error wrapped with the frames parsed
+// from the text, if any.
+func withFramesFromBytes(err error, byt []byte) (error, error) {
+	stack, parseErr := framesFromBytes(byt)
 	if len(stack) > 0 {
-		err = WithFrames(err, stack)
+		ff := make(Frames, len(stack))
+		for i, fr := range stack {
+			ff[i] = fr
+		}
+		err = WithFrames(err, ff)
 	}
-	return
+	return err, parseErr
+}
+
+// branchesFromBytes splits the text after the message of an error into
+// its frames and the errors of a multierror in its chain, printed after
+// a blank line in the layout of a MultiError. The MultiError is nil if
+// there is none.
+func branchesFromBytes(byt []byte) (frames []byte, merr *MultiError, parseErr error) {
+	for i := 0; i < len(byt); {
+		n := bytes.IndexByte(byt[i:], '\n')
+		if n == -1 {
+			break
+		}
+		if n == 0 {
+			if merr, ok, parseErr := multiErrorFromBytes(byt[i+1:]); ok {
+				return bytes.TrimSuffix(byt[:i], []byte("\n")), merr, parseErr
+			}
+		}
+		i += n + 1
+	}
+	return byt, nil, nil
 }
@@ -692,3 +692,787 @@ func TestErrorFromBytes(t *testing.T) {
 		)
 	})
 }
+
+func TestStackTraceSyntheticFrames(t *testing.T) {
+	real := getFrame(2)
+	synthetic := NewFrame("example.com/pkg.Function", "/src/file.go", 10).(*frame)
+
+	t.Run("skips frames without program counters", func(t *testing.T) {
+		err := &withStackTrace{
+			error:  New("err"),
+			frames: frames{synthetic, real, synthetic},
+		}
+		testutils.AssertEqual(t, []uintptr{real.PC()}, err.StackTrace())
+		testutils.AssertEqual(t, 3, len(err.Frames()))
+	})
+
+	t.Run("when mixed: trace wins and keeps synthetic frames", func(t *testing.T) {
+		err := WithFrame(&withStackTrace{
+			error:  New("err"),
+			frames: frames{synthetic, real},
+		})
+		ff := FramesFrom(err)
+		testutils.AssertEqual(t, 2, len(ff))
+		testutils.AssertEqual(t, Frame(synthetic), ff[0])
+	})
+
+	t.Run("when all synthetic: appended frames are aggregated", func(t *testing.T) {
+		err := WithFrame(&withStackTrace{
+			error:  New("err"),
+			frames: frames{synthetic},
+		})
+		ff := FramesFrom(err)
+		testutils.AssertEqual(t, 2, len(ff))
+		testutils.AssertEqual(t, Frame(synthetic), ff[0])
+		testutils.AssertTrue(t, PCFromFrame(ff[1]) != 0)
+	})
+}
+
+func TestErrorFromBytes_malformed(t *testing.T) {
+	byt := []byte("err\n" +
+		"github.com/secureworks/errors.Example\n" +
+		"\t/src/example.go:10\n" +
+		"github.com/secureworks/errors.Example\n" +
+		"\t/src/example.go:not_a_number\n")
+
+	t.Run("returns the reconstructed error", func(t *testing.T) {
+		err, ok := ErrorFromBytes(byt)
+		testutils.AssertFalse(t, ok)
+		testutils.AssertNotNil(t, err)
+		testutils.AssertEqual(t, "err", err.Error())
+		testutils.AssertEqual(t, 1, len(FramesFrom(err)))
+	})
+
+	t.Run("exposes the parse failure", func(t *testing.T) {
+		err, parseErr := ParseErrorFromBytes(byt)
+		testutils.AssertEqual(t, "err", err.Error())
+		testutils.AssertTrue(t, errors.Is(parseErr, errMalformedFrame))
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		err, parseErr := ParseErrorFromBytes([]byte("<nil>\n"))
+		testutils.AssertNil(t, err)
+		testutils.AssertNil(t, parseErr)
+
+		err, ok := ErrorFromBytes([]byte("<nil>\n"))
+		testutils.AssertNil(t, err)
+		testutils.AssertFalse(t, ok)
+	})
+}
+
+func TestOrigin(t *testing.T) {
+	t.Run("when none: returns false", func(t *testing.T) {
+		fr, ok := Origin(newErrorCaller())
+		testutils.AssertFalse(t, ok)
+		testutils.AssertNil(t, fr)
+		testutils.AssertEqual(t, "", OriginString(newErrorCaller()))
+		testutils.AssertEqual(t, "", OriginString(nil))
+	})
+
+	cases := []struct {
+		name string
+		err  error
+	}{
+		{name: "when only frames", err: framesChainError()},
+		{name: "when only traces", err: stackChainError()},
+		{name: "when both", err: framesAndStackChainError()},
+		{name: "when synthetic", err: WithFrame(NewWithFrames("err", Frames{
+			NewFrame("example.com/pkg.Function", "/src/file.go", 10),
+			NewFrame("example.com/pkg.Caller", "/src/file.go", 20),
+		}))},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name+": returns the first of FramesFrom", func(t *testing.T) {
+			fr, ok := Origin(tt.err)
+			testutils.AssertTrue(t, ok)
+			testutils.AssertEqual(t,
+				fmt.Sprintf("%+v", FramesFrom(tt.err)[0]),
+				fmt.Sprintf("%+v", fr))
+		})
+	}
+
+	t.Run("formats for logging", func(t *testing.T) {
+		err := WithFrames(New("err"), Frames{NewFrame("example.com/pkg.Function", "/src/file.go", 10)})
+		testutils.AssertEqual(t, "pkg.Function(file.go:10)", OriginString(err))
+		testutils.AssertMatch(t,
+			`^errors\.withFrameCaller\(errors_test\.go:91\)$`, OriginString(framesChainError()))
+	})
+}
+
+type cyclicError struct{ next error }
+
+func (c *cyclicError) Error() string { return "cyclic" }
+func (c *cyclicError) Unwrap() error { return c.next }
+
+func TestHasFrames(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		expect bool
+	}{
+		{name: "nil", err: nil, expect: false},
+		{name: "none", err: fmt.Errorf("wrap: %w", newErrorCaller()), expect: false},
+		{name: "empty frames", err: NewWithFrames("err", Frames{}), expect: false},
+		{name: "frames", err: framesChainError(), expect: true},
+		{name: "traces", err: stackChainError(), expect: true},
+		{name: "wrapped frames", err: fmt.Errorf("wrap: %w", NewWithFrame("err")), expect: true},
+		{name: "multierror", err: NewMultiError(NewWithFrame("err"), NewWithFrame("err")), expect: false},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			testutils.AssertEqual(t, tt.expect, HasFrames(tt.err))
+			testutils.AssertEqual(t, len(FramesFrom(tt.err)) > 0, HasFrames(tt.err))
+		})
+	}
+}
+
+func TestDepth(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		expect int
+	}{
+		{name: "nil", err: nil, expect: 0},
+		{name: "root", err: newErrorCaller(), expect: 0},
+		{name: "wrapped", err: fmt.Errorf("wrap: %w", newErrorCaller()), expect: 1},
+		{name: "frames chain", err: framesChainError(), expect: 5},
+		{name: "multierror", err: WithFrame(NewMultiError(New("1"), fmt.Errorf("2: %w", New("2")))), expect: 1},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			testutils.AssertEqual(t, tt.expect, Depth(tt.err))
+		})
+	}
+
+	t.Run("cyclic", func(t *testing.T) {
+		err := &cyclicError{}
+		err.next = err
+		testutils.AssertEqual(t, defaultMaxUnwrapDepth, Depth(err))
+	})
+}
+
+func TestSetMaxUnwrapDepth(t *testing.T) {
+	cyclic := &cyclicError{}
+	cyclic.next = cyclic
+	err := fmt.Errorf("wrap: %w", WithFrame(cyclic))
+
+	t.Run("cyclic chains end", func(t *testing.T) {
+		testutils.AssertEqual(t, 1, len(FramesFrom(err)))
+		testutils.AssertEqual(t, 1, len(FramesFromAll(err)))
+		testutils.AssertTrue(t, HasFrames(err))
+		_, ok := Origin(err)
+		testutils.AssertTrue(t, ok)
+		testutils.AssertNil(t, ReferencesFrom(err))
+		testutils.AssertMatch(t, `^wrap: cyclic\n`, fmt.Sprintf("%+v", WithFrame(err)))
+		testutils.AssertMatch(t, `^multiple errors:`, fmt.Sprintf("%+v", NewMultiError(err, cyclic)))
+
+		links := Links(err)
+		testutils.AssertEqual(t, "wrap", links[0].Error())
+		testutils.AssertEqual(t, "unwrap depth exceeded", links[len(links)-1].Error())
+	})
+
+	t.Run("adjusts the maximum", func(t *testing.T) {
+		SetMaxUnwrapDepth(10)
+		t.Cleanup(func() { SetMaxUnwrapDepth(0) })
+		testutils.AssertEqual(t, 10, Depth(cyclic))
+		testutils.AssertEqual(t, 5, Depth(framesChainError()))
+		testutils.AssertEqual(t, 2, len(Links(cyclic)))
+
+		SetMaxUnwrapDepth(3)
+		testutils.AssertEqual(t, 3, Depth(framesChainError()))
+	})
+}
+
+func TestErrorToBytes(t *testing.T) {
+	ff := Frames{
+		NewFrame("github.com/secureworks/errors.Example", "/src/example.go", 10),
+		NewFrame("github.com/secureworks/errors.Other", "/src/path with\ttab/\"other\".go", 20),
+	}
+	cases := []struct {
+		name string
+		msg  string
+	}{
+		{"plain", "failed to open: no such file"},
+		{"newlines", "line 1\nline 2\n"},
+		{"tabs", "col 1\tcol 2"},
+		{"quotes", `"quoted" message`},
+		{"quoted", `"message"`},
+		{"backslashes", `C:\temp\new`},
+		{"control characters", "bell\a and \x1b[31mcolor"},
+		{"unicode", "文件 not found"},
+		{"empty", ""},
+		{"nil", "<nil>"},
+		{"multierror header", "empty errors: []"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, err := range []error{
+				New(tt.msg),
+				NewWithFrames(tt.msg, ff),
+				WithStackTrace(New(tt.msg)),
+			} {
+				parsed, ok := ErrorFromBytes(ErrorToBytes(err))
+				testutils.AssertTrue(t, ok)
+				testutils.AssertEqual(t, tt.msg, parsed.Error())
+				testutils.AssertEqual(t, FramesFrom(err).Locations(), FramesFrom(parsed).Locations())
+				testutils.AssertEqual(t, string(ErrorToBytes(err)), string(ErrorToBytes(parsed)))
+			}
+		})
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		testutils.AssertEqual(t, "<nil>", string(ErrorToBytes(nil)))
+		parsed, _ := ErrorFromBytes(ErrorToBytes(nil))
+		testutils.AssertNil(t, parsed)
+	})
+
+	t.Run("matches %+v for plain messages", func(t *testing.T) {
+		err := NewWithFrames("failed: no such file", ff)
+		testutils.AssertEqual(t, fmt.Sprintf("%+v", err), string(ErrorToBytes(err)))
+	})
+
+	t.Run("multierror", func(t *testing.T) {
+		err := WithFrames(NewMultiError(New("a\nb"), New("c")), ff)
+		parsed, ok := ErrorFromBytes(ErrorToBytes(err))
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, "[a\nb; c]", parsed.Error())
+		testutils.AssertEqual(t, 2, len(FramesFrom(parsed)))
+	})
+}
+
+// annotatedOnReturn returns the error after it is annotated with the
+// frame of the defer statement, along with that frame.
+func annotatedOnReturn(fail error) (err error, deferLine Location) {
+	deferLine = LocationOf(Caller())
+	deferLine.Line += 2
+	defer AnnotateOnReturn(&err)()
+	return fail, deferLine
+}
+
+// annotatedOnReturnLater defers the function returned by
+// AnnotateOnReturn some lines after calling it.
+func annotatedOnReturnLater(fail error) (err error, callLine Location) {
+	callLine = LocationOf(Caller())
+	callLine.Line += 2
+	annotate := AnnotateOnReturn(&err)
+	var deferred func()
+	deferred = annotate
+	defer deferred()
+	return fail, callLine
+}
+
+func TestAnnotateOnReturn(t *testing.T) {
+	t.Run("panics if the pointer is nil", func(t *testing.T) {
+		err := func() (err error) {
+			defer func() {
+				err = recover().(error)
+			}()
+			_ = AnnotateOnReturn(nil)
+			return
+		}()
+		testutils.AssertEqual(t,
+			`errors.AnnotateOnReturn used incorrectly: error pointer must not be nil`,
+			err.Error())
+	})
+
+	t.Run("ignores nil", func(t *testing.T) {
+		err, _ := annotatedOnReturn(nil)
+		testutils.AssertNil(t, err)
+	})
+
+	t.Run("frame is the defer statement", func(t *testing.T) {
+		err, deferLine := annotatedOnReturn(errSentinel)
+		testutils.AssertTrue(t, Is(err, errSentinel))
+		testutils.AssertEqual(t, "sentinel err", err.Error())
+		ff := FramesFrom(err)
+		testutils.AssertEqual(t, 1, len(ff))
+		testutils.AssertEqual(t, deferLine, LocationOf(ff[0]))
+	})
+
+	t.Run("frame is the call when deferred later", func(t *testing.T) {
+		err, callLine := annotatedOnReturnLater(errSentinel)
+		ff := FramesFrom(err)
+		testutils.AssertEqual(t, 1, len(ff))
+		testutils.AssertEqual(t, callLine, LocationOf(ff[0]))
+	})
+
+	t.Run("adds to existing frames", func(t *testing.T) {
+		err, deferLine := annotatedOnReturn(NewWithFrame("failed"))
+		ff := FramesFrom(err)
+		testutils.AssertEqual(t, 2, len(ff))
+		testutils.AssertEqual(t, deferLine, LocationOf(ff[1]))
+	})
+}
+
+func TestToJSON(t *testing.T) {
+	ff := Frames{NewFrame("github.com/secureworks/errors.Example", "/src/example.go", 10)}
+	cases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"nil", nil, `null`},
+		{"plain", New("failed"), `{"message":"failed","frames":null}`},
+		{"frames", NewWithFrames("failed: \"x\" & <y>", ff), `{"message":"failed: \"x\" & <y>","frames":[{"function":"github.com/secureworks/errors.Example","file":"/src/example.go","line":10}]}`},
+		{"wrapped", fmt.Errorf("outer: %w", NewWithFrames("failed", ff)), `{"message":"outer: failed","frames":[{"function":"github.com/secureworks/errors.Example","file":"/src/example.go","line":10}]}`},
+		{"empty multierror", NewMultiError(), `{"message":"[]","frames":null}`},
+		{"multierror", WithFrames(NewMultiError(New("a"), NewWithFrames("b", ff)), ff),
+			`{"message":"[a; b]","frames":[{"function":"github.com/secureworks/errors.Example","file":"/src/example.go","line":10}],"errors":[` +
+				`{"message":"a","frames":null},` +
+				`{"message":"b","frames":[{"function":"github.com/secureworks/errors.Example","file":"/src/example.go","line":10}]}]}`},
+		{"grouped", fmt.Errorf("failed: %w", NewMultiErrorGrouped(New("a"), NewMultiError(New("b"), New("c")))),
+			`{"message":"failed: [a; [b; c]]","frames":null,"errors":[` +
+				`{"message":"a","frames":null},` +
+				`{"message":"[b; c]","frames":null,"errors":[{"message":"b","frames":null},{"message":"c","frames":null}]}]}`},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			byt, err := ToJSON(tt.err)
+			testutils.AssertNil(t, err)
+			testutils.AssertEqual(t, tt.expected, string(byt))
+		})
+	}
+
+	t.Run("uses the deepest stack trace", func(t *testing.T) {
+		err := WithFrame(WithStackTrace(New("failed")))
+		byt, jsonErr := ToJSON(err)
+		testutils.AssertNil(t, jsonErr)
+		frBytes, _ := FramesFrom(err).MarshalJSON()
+		testutils.AssertEqual(t, `{"message":"failed","frames":`+string(frBytes)+`}`, string(byt))
+	})
+
+	t.Run("frames round-trip", func(t *testing.T) {
+		err := NewWithFrames("failed", ff)
+		byt, _ := ToJSON(err)
+		frBytes := byt[len(`{"message":"failed","frames":`) : len(byt)-1]
+		parsed, parseErr := FramesFromJSON(frBytes)
+		testutils.AssertNil(t, parseErr)
+		testutils.AssertEqual(t, ff.Locations(), parsed.Locations())
+	})
+}
+
+func TestErrorFromJSON(t *testing.T) {
+	ff := Frames{
+		NewFrame("github.com/secureworks/errors.Example", "/src/example.go", 10),
+		NewFrame("github.com/secureworks/errors.Other", "/src/other.go", 20),
+	}
+
+	t.Run("round-trips ToJSON", func(t *testing.T) {
+		errs := []error{
+			New("failed"),
+			NewWithFrames("failed: \"x\"\n", ff),
+			NewMultiError(New("a"), NewWithFrames("b", ff)),
+			NewMultiErrorMsg("2 failed", New("a"), New("b")),
+			WithFrames(NewMultiErrorGrouped(New("a"), NewMultiError(New("b"), NewWithFrames("c", ff))), ff),
+		}
+		for _, err := range errs {
+			byt, _ := ToJSON(err)
+			parsed, ok := ErrorFromJSON(byt)
+			testutils.AssertTrue(t, ok)
+			testutils.AssertEqual(t, fmt.Sprintf("%+v", err), fmt.Sprintf("%+v", parsed))
+			testutils.AssertEqual(t, len(ErrorsFrom(err)), len(ErrorsFrom(parsed)))
+			reparsed, _ := ToJSON(parsed)
+			testutils.AssertEqual(t, string(byt), string(reparsed))
+		}
+	})
+
+	t.Run("rebuilds chains", func(t *testing.T) {
+		parsed, ok := ErrorFromJSON([]byte(`{
+			"message": "reading config: masked",
+			"frames": [{"function":"github.com/secureworks/errors.Outer","file":"/src/outer.go","line":1}],
+			"cause": {
+				"message": "masked",
+				"cause": {
+					"message": "not found",
+					"frames": [{"function":"github.com/secureworks/errors.Example","file":"/src/example.go","line":10}]
+				}
+			}
+		}`))
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, "reading config: masked", parsed.Error())
+		testutils.AssertEqual(t, "reading config: masked: not found", FullMessage(parsed))
+		testutils.AssertEqual(t, "[example.go:10 outer.go:1]", fmt.Sprintf("%s", FramesFrom(parsed)))
+
+		links := Links(parsed)
+		testutils.AssertEqual(t, 3, len(links))
+		testutils.AssertEqual(t, "not found", links[2].Error())
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		parsed, parseErr := ParseErrorFromJSON([]byte(`null`))
+		testutils.AssertNil(t, parsed)
+		testutils.AssertNil(t, parseErr)
+		_, ok := ErrorFromJSON([]byte(`null`))
+		testutils.AssertFalse(t, ok)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		for _, byt := range []string{
+			``,
+			`{"message":`,
+			`"failed"`,
+			`{}`,
+			`{"message":1}`,
+			`{"message":"failed","frames":{}}`,
+			`{"message":"failed","frames":[{"function":"f","file":"f.go","line":-1}]}`,
+			`{"message":"failed","errors":[{}]}`,
+			`{"message":"failed","cause":{"frames":null}}`,
+		} {
+			parsed, parseErr := ParseErrorFromJSON([]byte(byt))
+			testutils.AssertNil(t, parsed, byt)
+			testutils.AssertNotNil(t, parseErr, byt)
+			_, ok := ErrorFromJSON([]byte(byt))
+			testutils.AssertFalse(t, ok)
+		}
+	})
+}
+
+// helperErrorf is the pattern that motivates WithFrame skipping
+// duplicate frames: the frame from Errorf is the same as the frame
+// from WithFrame.
+func helperErrorf(err error) error {
+	return WithFrame(Errorf("helper: %w", err))
+}
+
+func TestWithFrame_sameLocation(t *testing.T) {
+	t.Run("skips the same frame", func(t *testing.T) {
+		err := helperErrorf(New("failed"))
+		testutils.AssertEqual(t, 1, len(FramesFrom(err)))
+		testutils.AssertMatch(t, `\.helperErrorf$`, LocationOf(FramesFrom(err)[0]).Function)
+		testutils.AssertEqual(t, "helper: failed", err.Error())
+	})
+
+	t.Run("returns the error unchanged", func(t *testing.T) {
+		withFrame := func(err error) error { return WithFrame(err) }
+		err := withFrame(New("failed"))
+		testutils.AssertTrue(t, err == withFrame(err))
+		testutils.AssertFalse(t, err == WithFrame(err))
+	})
+
+	t.Run("keeps frames from different lines", func(t *testing.T) {
+		err := Errorf("helper: %w", New("failed"))
+		err = WithFrame(err)
+		testutils.AssertEqual(t, 2, len(FramesFrom(err)))
+		locs := FramesFrom(err).Locations()
+		testutils.AssertEqual(t, locs[0].Line+1, locs[1].Line)
+	})
+
+	t.Run("keeps frames from wrappers with more frames", func(t *testing.T) {
+		err := WithFrame(NewWithFrames("failed", Frames{NewFrame("main.main", "main.go", 1), NewFrame("main.main", "main.go", 2)}))
+		testutils.AssertEqual(t, 3, len(FramesFrom(err)))
+	})
+
+	t.Run("compares synthetic frames by location", func(t *testing.T) {
+		withFrame := func(err error) error { return WithFrame(err) }
+		loc := LocationOf(FramesFrom(withFrame(New("failed")))[0])
+		err := NewWithFrames("failed", Frames{NewFrame(loc.Function, loc.File, loc.Line)})
+		testutils.AssertTrue(t, err == withFrame(err))
+	})
+}
+
+func BenchmarkWithFrame(b *testing.B) {
+	b.Run("new frame", func(b *testing.B) {
+		err := New("failed")
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = WithFrame(err)
+		}
+	})
+
+	b.Run("helper pattern", func(b *testing.B) {
+		err := New("failed")
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = helperErrorf(err)
+		}
+	})
+}
+
+func TestWithFileLine(t *testing.T) {
+	testutils.AssertNil(t, WithFileLine(nil))
+
+	errBase := New("failed")
+	err := WithFileLine(errBase)
+	testutils.AssertTrue(t, Is(err, errBase))
+	testutils.AssertEqual(t, "failed", err.Error())
+
+	ff := FramesFrom(err)
+	testutils.AssertEqual(t, 1, len(ff))
+	testutils.AssertEqual(t, uintptr(0), ff[0].(programCounter).PC())
+	loc := LocationOf(ff[0])
+	testutils.AssertEqual(t, "unknown", loc.Function)
+	testutils.AssertEqual(t, LocationOf(FramesFrom(WithFrame(errBase))[0]).File, loc.File)
+	testutils.AssertNotEqual(t, 0, loc.Line)
+
+	// Formatted and marshaled the same as a synthetic frame.
+	synthetic := NewFrame("", loc.File, loc.Line)
+	testutils.AssertMatch(t, `^failed\nunknown\n\t\S+errors_test.go:\d+$`, fmt.Sprintf("%+v", err))
+	testutils.AssertEqual(t, fmt.Sprintf("%+v", synthetic), fmt.Sprintf("%+v", ff[0]))
+	testutils.AssertEqual(t, fmt.Sprintf("%s", synthetic), fmt.Sprintf("%s", ff[0]))
+	testutils.AssertEqual(t, fmt.Sprintf("%n", synthetic), fmt.Sprintf("%n", ff[0]))
+	byt, jsonErr := ff.MarshalJSON()
+	testutils.AssertNil(t, jsonErr)
+	expected, _ := Frames{synthetic}.MarshalJSON()
+	testutils.AssertEqual(t, string(expected), string(byt))
+	parsed, parseErr := FrameFromString(fmt.Sprintf("%+v", ff[0]))
+	testutils.AssertNil(t, parseErr)
+	testutils.AssertEqual(t, loc, LocationOf(parsed))
+}
+
+// BenchmarkWithFileLine compares WithFileLine with WithFrame, for
+// capturing a frame and for formatting it as file:line afterwards.
+func BenchmarkWithFileLine(b *testing.B) {
+	wrappers := []struct {
+		name string
+		wrap func(error) error
+	}{
+		{"WithFrame", WithFrame},
+		{"WithFileLine", WithFileLine},
+	}
+	for _, w := range wrappers {
+		b.Run(w.name+"/capture", func(b *testing.B) {
+			err := New("failed")
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = w.wrap(err)
+			}
+		})
+		b.Run(w.name+"/capture and format", func(b *testing.B) {
+			err := New("failed")
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = fmt.Sprintf("%s", FramesFrom(w.wrap(err))[0])
+			}
+		})
+	}
+}
+
+func TestParseErrorFromBytesWith(t *testing.T) {
+	text := "2024-05-02T10:11:12Z ERROR wrap: err\n" +
+		"pkg.Fn\n" +
+		"\t/home/user/pkg/fn.go:3\n"
+
+	t.Run("default is unchanged", func(t *testing.T) {
+		err, parseErr := ParseErrorFromBytesWith([]byte(text), ParseOptions{})
+		testutils.AssertNil(t, parseErr)
+		testutils.AssertEqual(t, "2024-05-02T10:11:12Z ERROR wrap: err", err.Error())
+	})
+
+	t.Run("strips the prefix", func(t *testing.T) {
+		byt := []byte(text)
+		err, parseErr := ParseErrorFromBytesWith(byt, ParseOptions{LinePreprocessor: StripLogPrefix})
+		testutils.AssertNil(t, parseErr)
+		testutils.AssertEqual(t, "wrap: err", err.Error())
+		testutils.AssertEqual(t, "pkg.Fn", LocationOf(FramesFrom(err)[0]).Function)
+		testutils.AssertEqual(t, text, string(byt))
+	})
+
+	t.Run("frames are not preprocessed", func(t *testing.T) {
+		calls := 0
+		err, parseErr := ParseErrorFromBytesWith([]byte(text), ParseOptions{
+			LinePreprocessor: func(line []byte) []byte {
+				calls++
+				return []byte("replaced")
+			},
+		})
+		testutils.AssertNil(t, parseErr)
+		testutils.AssertEqual(t, 1, calls)
+		testutils.AssertEqual(t, "replaced", err.Error())
+		testutils.AssertEqual(t, 1, len(FramesFrom(err)))
+	})
+
+	t.Run("single line", func(t *testing.T) {
+		err, _ := ParseErrorFromBytesWith([]byte("[warn] wrap: err"), ParseOptions{LinePreprocessor: StripLogPrefix})
+		testutils.AssertEqual(t, "wrap: err", err.Error())
+	})
+}
+
+func TestStripLogPrefix(t *testing.T) {
+	cases := map[string]string{
+		"2024-05-02T10:11:12Z ERROR wrap: err":          "wrap: err",
+		"2024-05-02T10:11:12.123+02:00 error wrap: err": "wrap: err",
+		"2024/05/02 10:11:12 [error] wrap: err":         "wrap: err",
+		"10:11:12.123 ERR: wrap: err":                   "wrap: err",
+		"2024-05-02 10:11:12,123  WARNING  wrap: err":   "wrap: err",
+		"wrap: err":         "wrap: err",
+		"error: wrap: err":  "error: wrap: err",
+		"404 not found":     "404 not found",
+		"3 errors occurred": "3 errors occurred",
+		"ERROR":             "ERROR",
+		"2024-05-02 ERROR":  "2024-05-02 ERROR",
+		"":                  "",
+	}
+	for line, expected := range cases {
+		testutils.AssertEqual(t, expected, string(StripLogPrefix([]byte(line))))
+	}
+}
+
+func TestSetMaxAnnotations(t *testing.T) {
+	SetMaxAnnotations(3)
+	defer SetMaxAnnotations(0)
+
+	retry := func(attempts int, wrap func(error, int) error) error {
+		err := New("connection refused")
+		for i := 1; i <= attempts; i++ {
+			err = wrap(err, i)
+		}
+		return err
+	}
+
+	t.Run("retry loop", func(t *testing.T) {
+		err := retry(40, func(err error, i int) error { return WithFrame(fmt.Errorf("attempt %d: %w", i, err)) })
+		testutils.AssertEqual(t, 3, len(FramesFrom(err)))
+		testutils.AssertEqual(t, 37, SuppressedAnnotations(err))
+		testutils.AssertTrue(t, bytes.HasSuffix([]byte(fmt.Sprintf("%+v", err)),
+			[]byte("\n(+37 additional wrap frames suppressed)")))
+		testutils.AssertTrue(t, Is(err, err.(*withFrames).error))
+	})
+
+	t.Run("retry loop with Errorf", func(t *testing.T) {
+		err := retry(5, func(err error, i int) error { return Errorf("attempt %d: %w", i, err) })
+		testutils.AssertEqual(t, 3, len(FramesFrom(err)))
+		testutils.AssertEqual(t, 2, SuppressedAnnotations(err))
+		testutils.AssertEqual(t,
+			"attempt 5: attempt 4: attempt 3: attempt 2: attempt 1: connection refused", err.Error())
+	})
+
+	t.Run("consecutive wrappers", func(t *testing.T) {
+		err := WithFrame(New("err"))
+		err = WithFrame(err)
+		err = WithFrame(err)
+		err = WithFrame(err)
+		err = WithFrame(err)
+		testutils.AssertEqual(t, 3, len(FramesFrom(err)))
+		testutils.AssertEqual(t, 2, SuppressedAnnotations(err))
+		testutils.AssertEqual(t, 4, Depth(err))
+	})
+
+	t.Run("under budget", func(t *testing.T) {
+		err := retry(3, func(err error, i int) error { return WithFrame(fmt.Errorf("attempt %d: %w", i, err)) })
+		testutils.AssertEqual(t, 3, len(FramesFrom(err)))
+		testutils.AssertEqual(t, 0, SuppressedAnnotations(err))
+		testutils.AssertFalse(t, bytes.Contains([]byte(fmt.Sprintf("%+v", err)), []byte("suppressed")))
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		SetMaxAnnotations(0)
+		defer SetMaxAnnotations(3)
+		err := retry(40, func(err error, i int) error { return WithFrame(fmt.Errorf("attempt %d: %w", i, err)) })
+		testutils.AssertEqual(t, 40, len(FramesFrom(err)))
+		testutils.AssertEqual(t, 0, SuppressedAnnotations(err))
+	})
+
+	t.Run("serialization", func(t *testing.T) {
+		err := retry(5, func(err error, i int) error { return Errorf("attempt %d: %w", i, err) })
+		for _, byt := range [][]byte{ErrorToBytes(err), []byte(fmt.Sprintf("%+v", err))} {
+			parsed, parseErr := ParseErrorFromBytes(byt)
+			testutils.AssertNil(t, parseErr)
+			testutils.AssertEqual(t, err.Error(), parsed.Error())
+			testutils.AssertEqual(t, 3, len(FramesFrom(parsed)))
+			testutils.AssertEqual(t, 2, SuppressedAnnotations(parsed))
+		}
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		testutils.AssertNil(t, WithFrame(nil))
+		testutils.AssertEqual(t, 0, SuppressedAnnotations(nil))
+	})
+}
+
+func TestStripLogPrefix_levels(t *testing.T) {
+	cases := map[string]string{
+		"ERROR: file not found":              "file not found",
+		"[Error] file not found":             "file not found",
+		"10:11:12 Error: file not found":     "file not found",
+		"Error: file not found":              "Error: file not found",
+		"warning: disk almost full: /var":    "warning: disk almost full: /var",
+		"panic: runtime error: out of range": "panic: runtime error: out of range",
+	}
+	for line, expected := range cases {
+		testutils.AssertEqual(t, expected, string(StripLogPrefix([]byte(line))))
+	}
+}
+
+type annotatedOnReturnRepo struct{ fail error }
+
+// save defers the function returned by AnnotateOnReturn in a method.
+func (r *annotatedOnReturnRepo) save() (err error, deferLine Location) {
+	deferLine = LocationOf(Caller())
+	deferLine.Line += 2
+	defer AnnotateOnReturn(&err)()
+	return r.fail, deferLine
+}
+
+func TestAnnotateOnReturn_methodValues(t *testing.T) {
+	repo := &annotatedOnReturnRepo{fail: errSentinel}
+
+	t.Run("method", func(t *testing.T) {
+		err, deferLine := repo.save()
+		ff := FramesFrom(err)
+		testutils.AssertEqual(t, 1, len(ff))
+		testutils.AssertEqual(t, deferLine, LocationOf(ff[0]))
+		testutils.AssertEqual(t, "github.com/secureworks/errors.(*annotatedOnReturnRepo).save", deferLine.Function)
+	})
+
+	t.Run("method value", func(t *testing.T) {
+		save := repo.save
+		err, deferLine := save()
+		ff := FramesFrom(err)
+		testutils.AssertEqual(t, 1, len(ff))
+		testutils.AssertEqual(t, deferLine, LocationOf(ff[0]))
+	})
+
+	t.Run("deferred method value", func(t *testing.T) {
+		var (
+			err       error
+			deferLine Location
+		)
+		func() {
+			defer func() { err, deferLine = repo.save() }()
+		}()
+		ff := FramesFrom(err)
+		testutils.AssertEqual(t, 1, len(ff))
+		testutils.AssertEqual(t, deferLine, LocationOf(ff[0]))
+	})
+}
+
+func TestStripLogPrefix_timestamps(t *testing.T) {
+	cases := map[string]string{
+		"500: internal error":                      "500: internal error",
+		"1/3 tasks failed":                         "1/3 tasks failed",
+		"10.0.0.1:8080 connection refused":         "10.0.0.1:8080 connection refused",
+		"2024-05-02: quota exceeded":               "2024-05-02: quota exceeded",
+		"12:30 meeting canceled":                   "12:30 meeting canceled",
+		"2024-05-02 10:11:12 ERROR wrap: err":      "wrap: err",
+		"2024/05/02T10:11:12.123456Z INFO started": "started",
+	}
+	for line, expected := range cases {
+		testutils.AssertEqual(t, expected, string(StripLogPrefix([]byte(line))), line)
+	}
+}
+
+// wrapRecursively wraps the error with a frame at each of n recursive
+// calls, all made from the same line.
+func wrapRecursively(err error, n int) error {
+	if n == 0 {
+		return err
+	}
+	return WithFrame(wrapRecursively(err, n-1))
+}
+
+func TestWithFrame_sameLocationCost(t *testing.T) {
+	t.Run("frames at other call sites are not resolved", func(t *testing.T) {
+		inner := WithFrame(New("failed"))
+		err := WithFrame(inner)
+		testutils.AssertEqual(t, 2, len(FramesFrom(err)))
+		fr := inner.(*withFrames).frames[0]
+		testutils.AssertEqual(t, "", fr.function)
+		testutils.AssertEqual(t, "", fr.file)
+	})
+
+	t.Run("recursive calls from one line", func(t *testing.T) {
+		err := wrapRecursively(New("failed"), 3)
+		testutils.AssertEqual(t, 1, len(FramesFrom(err)))
+		testutils.AssertMatch(t, `\.wrapRecursively$`, LocationOf(FramesFrom(err)[0]).Function)
+	})
+}
@@ -0,0 +1,212 @@
+// Package errorsanalyzer provides static analyzers that report misuse
+// of the github.com/secureworks/errors package which would otherwise
+// only show up at runtime (or in production logs).
+//
+// The analyzers are built on golang.org/x/tools/go/analysis, so they can
+// be run with `go vet` using the errorsvet command in this module:
+//
+//	$ go install github.com/secureworks/errors/errorsanalyzer/cmd/errorsvet@latest
+//	$ go vet -vettool=$(which errorsvet) ./...
+//
+// The following analyzers are provided:
+//
+//	errorfwrap    – errors.Errorf called without a %w verb
+//	appendinto    – errors.AppendInto called with a possibly-nil receiving pointer
+//	multierrornil – a *errors.MultiError compared against nil
+//	fmterrorf     – fmt.Errorf with a %w verb in a file importing this package
+package errorsanalyzer
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// errorsPath is the import path of the package being checked for.
+const errorsPath = "github.com/secureworks/errors"
+
+// Analyzers are all the analyzers in this package.
+var Analyzers = []*analysis.Analyzer{
+	ErrorfWrap,
+	AppendInto,
+	MultiErrorNil,
+	FmtErrorf,
+}
+
+// ErrorfWrap reports calls to errors.Errorf that do not wrap an error
+// with the %w verb: use errors.NewWithFrame instead.
+var ErrorfWrap = &analysis.Analyzer{
+	Name:     "errorfwrap",
+	Doc:      "report calls to errors.Errorf that do not wrap an error with %w",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runErrorfWrap,
+}
+
+// AppendInto reports calls to errors.AppendInto where the receiving
+// pointer is not the address of a variable, since AppendInto panics if
+// it is nil.
+var AppendInto = &analysis.Analyzer{
+	Name:     "appendinto",
+	Doc:      "report calls to errors.AppendInto with a possibly-nil receiving pointer",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runAppendInto,
+}
+
+// MultiErrorNil reports comparisons of a *errors.MultiError against
+// nil: a MultiError is almost never nil, even when it has no errors, so
+// use ErrorOrNil instead.
+var MultiErrorNil = &analysis.Analyzer{
+	Name:     "multierrornil",
+	Doc:      "report comparisons of a *errors.MultiError against nil",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runMultiErrorNil,
+}
+
+// FmtErrorf reports calls to fmt.Errorf that wrap an error with %w in
+// files that import this package, where errors.Errorf would also have
+// added a frame.
+var FmtErrorf = &analysis.Analyzer{
+	Name:     "fmterrorf",
+	Doc:      "report calls to fmt.Errorf with %w where errors.Errorf would add a frame",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runFmtErrorf,
+}
+
+func runErrorfWrap(pass *analysis.Pass) (interface{}, error) {
+	eachCall(pass, func(call *ast.CallExpr, fn *types.Func) {
+		if !isFunc(fn, errorsPath, "Errorf") || len(call.Args) == 0 {
+			return
+		}
+		if format, ok := constantString(pass, call.Args[0]); ok && !strings.Contains(format, "%w") {
+			pass.Reportf(call.Pos(), "errors.Errorf called without a %%w verb: use errors.NewWithFrame")
+		}
+	})
+	return nil, nil
+}
+
+func runAppendInto(pass *analysis.Pass) (interface{}, error) {
+	eachCall(pass, func(call *ast.CallExpr, fn *types.Func) {
+		if !isFunc(fn, errorsPath, "AppendInto") || len(call.Args) == 0 {
+			return
+		}
+		switch arg := ast.Unparen(call.Args[0]).(type) {
+		case *ast.UnaryExpr:
+			if arg.Op == token.AND {
+				return
+			}
+		case *ast.CallExpr:
+			if id, ok := ast.Unparen(arg.Fun).(*ast.Ident); ok && id.Name == "new" {
+				if _, ok := pass.TypesInfo.Uses[id].(*types.Builtin); ok {
+					return
+				}
+			}
+		}
+		pass.Reportf(call.Args[0].Pos(),
+			"errors.AppendInto receiving pointer may be nil: pass the address of an error variable")
+	})
+	return nil, nil
+}
+
+func runMultiErrorNil(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	inspect.Preorder([]ast.Node{(*ast.BinaryExpr)(nil)}, func(n ast.Node) {
+		expr := n.(*ast.BinaryExpr)
+		if expr.Op != token.EQL && expr.Op != token.NEQ {
+			return
+		}
+		if (isNil(pass, expr.Y) && isMultiError(pass, expr.X)) ||
+			(isNil(pass, expr.X) && isMultiError(pass, expr.Y)) {
+			pass.Reportf(expr.Pos(),
+				"*errors.MultiError compared against nil: use ErrorOrNil or check the length of Unwrap")
+		}
+	})
+	return nil, nil
+}
+
+func runFmtErrorf(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	inspect.WithStack([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		call := n.(*ast.CallExpr)
+		fn, _ := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if !isFunc(fn, "fmt", "Errorf") || len(call.Args) == 0 {
+			return true
+		}
+		if file, ok := stack[0].(*ast.File); !ok || !importsErrors(file) {
+			return true
+		}
+		if format, ok := constantString(pass, call.Args[0]); ok && strings.Contains(format, "%w") {
+			pass.Reportf(call.Pos(), "fmt.Errorf wraps an error without a frame: use errors.Errorf")
+		}
+		return true
+	})
+	return nil, nil
+}
+
+// eachCall calls fn for every call to a function or method in the
+// package being analyzed.
+func eachCall(pass *analysis.Pass, fn func(*ast.CallExpr, *types.Func)) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	inspect.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		if callee, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func); ok {
+			fn(call, callee)
+		}
+	})
+}
+
+// isFunc reports whether fn is the package-level function name in the
+// package with the given import path.
+func isFunc(fn *types.Func, path, name string) bool {
+	return fn != nil && fn.Pkg() != nil && fn.Pkg().Path() == path && fn.Name() == name &&
+		fn.Type().(*types.Signature).Recv() == nil
+}
+
+// isMultiError reports whether the expression's type is
+// *errors.MultiError.
+func isMultiError(pass *analysis.Pass, expr ast.Expr) bool {
+	ptr, ok := pass.TypesInfo.TypeOf(expr).(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == errorsPath && obj.Name() == "MultiError"
+}
+
+// isNil reports whether the expression is the predeclared nil.
+func isNil(pass *analysis.Pass, expr ast.Expr) bool {
+	tv, ok := pass.TypesInfo.Types[expr]
+	return ok && tv.IsNil()
+}
+
+// constantString returns the value of a constant string expression.
+func constantString(pass *analysis.Pass, expr ast.Expr) (string, bool) {
+	tv, ok := pass.TypesInfo.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
+
+// importsErrors reports whether the file imports this package.
+func importsErrors(file *ast.File) bool {
+	for _, spec := range file.Imports {
+		if strings.Trim(spec.Path.Value, `"`) == errorsPath {
+			return true
+		}
+	}
+	return false
+}
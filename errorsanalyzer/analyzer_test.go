@@ -0,0 +1,25 @@
+package errorsanalyzer_test
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"github.com/secureworks/errors/errorsanalyzer"
+)
+
+func TestErrorfWrap(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), errorsanalyzer.ErrorfWrap, "errorfwrap")
+}
+
+func TestAppendInto(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), errorsanalyzer.AppendInto, "appendinto")
+}
+
+func TestMultiErrorNil(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), errorsanalyzer.MultiErrorNil, "multierrornil")
+}
+
+func TestFmtErrorf(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), errorsanalyzer.FmtErrorf, "fmterrorf")
+}
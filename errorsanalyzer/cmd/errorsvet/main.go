@@ -0,0 +1,14 @@
+// Command errorsvet runs the errorsanalyzer analyzers as a vet tool:
+//
+//	$ go vet -vettool=$(which errorsvet) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/unitchecker"
+
+	"github.com/secureworks/errors/errorsanalyzer"
+)
+
+func main() {
+	unitchecker.Main(errorsanalyzer.Analyzers...)
+}
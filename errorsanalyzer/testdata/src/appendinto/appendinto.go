@@ -0,0 +1,15 @@
+package appendinto
+
+import "github.com/secureworks/errors"
+
+type holder struct{ err error }
+
+func run(err error, ptr *error, h *holder) {
+	var merr error
+	errors.AppendInto(&merr, err)
+	errors.AppendInto(&h.err, err)
+	errors.AppendInto(new(error), err)
+
+	errors.AppendInto(nil, err) // want `errors.AppendInto receiving pointer may be nil`
+	errors.AppendInto(ptr, err) // want `errors.AppendInto receiving pointer may be nil`
+}
@@ -0,0 +1,12 @@
+package errorfwrap
+
+import "github.com/secureworks/errors"
+
+func run(err error, format string) {
+	_ = errors.Errorf("context: %w", err)
+	_ = errors.Errorf("context: %v: %w", 1, err)
+	_ = errors.Errorf(format, err) // Not a constant: ignored.
+
+	_ = errors.Errorf("context: %v", err) // want `errors.Errorf called without a %w verb`
+	_ = errors.Errorf("context")          // want `errors.Errorf called without a %w verb`
+}
@@ -0,0 +1,14 @@
+package fmterrorf
+
+import (
+	"fmt"
+
+	"github.com/secureworks/errors"
+)
+
+func run(err error) {
+	_ = errors.Errorf("context: %w", err)
+	_ = fmt.Errorf("context: %v", err)
+
+	_ = fmt.Errorf("context: %w", err) // want `fmt.Errorf wraps an error without a frame`
+}
@@ -0,0 +1,8 @@
+package fmterrorf
+
+import "fmt"
+
+// This file does not import the errors package, so it is ignored.
+func other(err error) {
+	_ = fmt.Errorf("context: %w", err)
+}
@@ -0,0 +1,16 @@
+// Package errors is a stub of github.com/secureworks/errors for tests.
+package errors
+
+func Errorf(format string, values ...interface{}) error { return nil }
+
+func AppendInto(receivingErr *error, appendingErr error) bool { return false }
+
+type MultiError struct{ errors []error }
+
+func (merr *MultiError) Error() string { return "" }
+
+func (merr *MultiError) ErrorOrNil() error { return nil }
+
+func (merr *MultiError) Unwrap() []error { return merr.errors }
+
+func NewMultiError(errs ...error) *MultiError { return new(MultiError) }
@@ -0,0 +1,21 @@
+package multierrornil
+
+import "github.com/secureworks/errors"
+
+func run(err error) bool {
+	merr := errors.NewMultiError(err)
+	if merr.ErrorOrNil() != nil {
+		return true
+	}
+	if len(merr.Unwrap()) > 0 {
+		return true
+	}
+	if err != nil {
+		return true
+	}
+
+	if merr != nil { // want `\*errors.MultiError compared against nil`
+		return true
+	}
+	return nil == errors.NewMultiError(err) // want `\*errors.MultiError compared against nil`
+}
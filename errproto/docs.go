@@ -0,0 +1,26 @@
+// Package errproto converts errors from the github.com/secureworks/errors
+// package to and from a protobuf message, for a stable wire schema when
+// sending errors with their frames between services:
+//
+//	byt, _ := proto.Marshal(errproto.ToProto(err))
+//	// ... on the other end:
+//	msg := new(errproto.Error)
+//	if err := proto.Unmarshal(byt, msg); err != nil {
+//		// ...
+//	}
+//	err := errproto.FromProto(msg)
+//
+// The schema is in errors.proto, and the Error and Frame messages are
+// generated from it with protoc-gen-go. This package is a module of its
+// own, so that the errors package does not depend on a protobuf
+// runtime.
+//
+// An error is converted with its message, the frames of each error in
+// the chain that errors.FramesFrom uses, its correlation ID (see
+// errors.WithCorrelationID), and the errors of a multierror in the
+// chain, so that the error from FromProto has the same messages,
+// frames (see errors.FramesFrom and errors.FramesFromAll) and
+// multierrors (see errors.ErrorsFromChain), and prints the same with
+// the `%+v` verb. The types of the errors are not preserved, and the
+// frames are synthetic (see errors.NewFrame).
+package errproto
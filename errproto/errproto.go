@@ -0,0 +1,97 @@
+package errproto
+
+import (
+	"github.com/secureworks/errors"
+)
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative errors.proto
+
+// ToProto converts the error to an Error message, or returns nil if the
+// error is nil. The message has the data of the error chain that
+// errors.Encode writes (see errors.DataFrom), with the causes of the
+// outermost error listed in order.
+func ToProto(err error) *Error {
+	return chainToProto(errors.DataFrom(err))
+}
+
+// chainToProto converts the data of an error chain to an Error message.
+func chainToProto(data *errors.ErrorData) *Error {
+	if data == nil {
+		return nil
+	}
+	v := dataToProto(data)
+	for cause := data.Cause; cause != nil; cause = cause.Cause {
+		v.Causes = append(v.Causes, dataToProto(cause))
+	}
+	v.CorrelationId = data.CorrelationID
+	return v
+}
+
+// dataToProto converts an error in a chain to an Error message, without
+// its causes.
+func dataToProto(data *errors.ErrorData) *Error {
+	v := &Error{
+		Message: data.Message,
+		Framed:  data.Framed,
+		Multi:   data.Multi,
+	}
+	for _, loc := range data.Frames {
+		v.Frames = append(v.Frames, &Frame{Function: loc.Function, File: loc.File, Line: int64(loc.Line)})
+	}
+	for _, member := range data.Errors {
+		if member != nil {
+			v.Members = append(v.Members, chainToProto(member))
+		}
+	}
+	return v
+}
+
+// FromProto converts an Error message back to an error, or returns nil
+// if it is nil.
+//
+// The error has the same messages, frames and multierrors as the error
+// that was converted, but not the same types: each error in the chain
+// is converted as an error created by the errors package, so errors.Is
+// and errors.As do not find sentinel errors or the error types of other
+// packages. Frames are synthetic (see errors.NewFrame).
+func FromProto(v *Error) error {
+	return protoToChain(v).Err()
+}
+
+// protoToChain converts an Error message to the data of an error chain.
+func protoToChain(v *Error) *errors.ErrorData {
+	if v == nil {
+		return nil
+	}
+	data := protoToData(v)
+	last := data
+	for _, cause := range v.GetCauses() {
+		if cause != nil {
+			last.Cause = protoToData(cause)
+			last = last.Cause
+		}
+	}
+	data.CorrelationID = v.GetCorrelationId()
+	return data
+}
+
+// protoToData converts an Error message to the data of an error in a
+// chain, without its causes.
+func protoToData(v *Error) *errors.ErrorData {
+	data := &errors.ErrorData{
+		Message: v.GetMessage(),
+		Framed:  v.GetFramed(),
+		Multi:   v.GetMulti() || len(v.GetMembers()) > 0,
+	}
+	for _, fr := range v.GetFrames() {
+		data.Frames = append(data.Frames, errors.Location{
+			Function: fr.GetFunction(), File: fr.GetFile(), Line: int(fr.GetLine()),
+		})
+	}
+	for _, member := range v.GetMembers() {
+		if member != nil {
+			data.Errors = append(data.Errors, protoToChain(member))
+		}
+	}
+	return data
+}
@@ -0,0 +1,190 @@
+package errproto
+
+import (
+	"fmt"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/secureworks/errors"
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+func protoRoundTrip(t *testing.T, err error) error {
+	t.Helper()
+	byt, marshalErr := proto.Marshal(ToProto(err))
+	testutils.AssertNil(t, marshalErr)
+	v := new(Error)
+	testutils.AssertNil(t, proto.Unmarshal(byt, v))
+	return FromProto(v)
+}
+
+// chainLength counts the errors in the chain down to any multierror.
+func chainLength(err error) (n int) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		n++
+	}
+	return
+}
+
+//go:noinline
+func withFrameCaller(fn func() error) error { return errors.WithFrame(fn()) }
+
+//go:noinline
+func wrapCaller(msg string, fn func() error) error { return fmt.Errorf("%s: %w", msg, fn()) }
+
+// framesChainError is the same as the chain of the same name in the
+// tests of the errors package: F - O - F - O - F - Ø.
+func framesChainError() error {
+	return withFrameCaller(func() error {
+		return wrapCaller("1", func() error {
+			return withFrameCaller(func() error {
+				return wrapCaller("2", func() error {
+					return withFrameCaller(func() error { return errors.New("new err") })
+				})
+			})
+		})
+	})
+}
+
+func TestRoundTrip(t *testing.T) {
+	errSentinel := errors.New("not found")
+	cases := map[string]error{
+		"new":          errors.New("failed"),
+		"frame":        errors.NewWithFrame("failed"),
+		"stack trace":  errors.NewWithStackTrace("failed"),
+		"frames chain": framesChainError(),
+		"errorf":       errors.Errorf("reading config: %w", errors.WithFrame(errSentinel)),
+		"message":      errors.WithMessage(errors.WithFrame(errSentinel), "config missing"),
+		"after trace":  errors.WithFrame(errors.Errorf("wrapped: %w", errors.WithStackTrace(errSentinel))),
+		"multierror": errors.NewMultiError(
+			errors.NewWithFrame("a"), errors.New("b"), errors.Errorf("c: %w", errSentinel)),
+		"message list": errors.WithFrame(
+			errors.NewMultiErrorMsg("failed", errors.New("a"), errors.NewWithFrame("b"))),
+		"nested multierror": errors.Errorf("outer: %w", errors.NewMultiErrorGrouped(
+			errors.New("a"), errors.NewMultiErrorGrouped(errors.NewWithFrame("b"), errors.New("c")))),
+		"multiple wrapped": errors.Errorf("%w and %w", errors.New("a"), errors.New("b")),
+	}
+	for name, err := range cases {
+		t.Run(name, func(t *testing.T) {
+			decoded := protoRoundTrip(t, err)
+			testutils.AssertEqual(t, err.Error(), decoded.Error())
+			testutils.AssertEqual(t, errors.FramesFrom(err).Locations(), errors.FramesFrom(decoded).Locations())
+			testutils.AssertEqual(t, fmt.Sprintf("%+v", err), fmt.Sprintf("%+v", decoded))
+			testutils.AssertEqual(t, chainLength(err), chainLength(decoded))
+
+			all, decodedAll := errors.FramesFromAll(err), errors.FramesFromAll(decoded)
+			testutils.AssertEqual(t, len(all), len(decodedAll))
+			for i := range all {
+				testutils.AssertEqual(t, all[i].Locations(), decodedAll[i].Locations())
+			}
+			testutils.AssertEqual(t, len(errors.ErrorsFrom(err)), len(errors.ErrorsFrom(decoded)))
+			testutils.AssertEqual(t, len(errors.ErrorsFromChain(err)), len(errors.ErrorsFromChain(decoded)))
+		})
+	}
+
+	t.Run("frames chain has its frames", func(t *testing.T) {
+		decoded := protoRoundTrip(t, framesChainError())
+		ff := errors.FramesFrom(decoded)
+		testutils.AssertEqual(t, 3, len(ff))
+		for _, fr := range ff {
+			testutils.AssertMatch(t, `errproto\.withFrameCaller$`, errors.LocationOf(fr).Function)
+		}
+	})
+
+	t.Run("sentinel errors are not preserved", func(t *testing.T) {
+		decoded := protoRoundTrip(t, errors.Errorf("reading config: %w", errSentinel))
+		testutils.AssertFalse(t, errors.Is(decoded, errSentinel))
+	})
+
+	t.Run("correlation IDs", func(t *testing.T) {
+		err := errors.WithCorrelationID(errors.NewMultiError(
+			errors.WithCorrelationID(errors.New("a"), "req-a"), errors.New("b")), "req-1")
+		decoded := protoRoundTrip(t, err)
+		testutils.AssertEqual(t, err.Error(), decoded.Error())
+		id, _ := errors.CorrelationIDFrom(decoded)
+		testutils.AssertEqual(t, "req-1", id)
+		id, _ = errors.CorrelationIDFrom(errors.ErrorsFromChain(decoded)[0])
+		testutils.AssertEqual(t, "req-a", id)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		testutils.AssertNil(t, ToProto(nil))
+		testutils.AssertNil(t, FromProto(nil))
+	})
+}
+
+func TestToProto(t *testing.T) {
+	t.Run("chain", func(t *testing.T) {
+		v := ToProto(errors.WithMessage(errors.NewWithFrame("failed"), "context"))
+		testutils.AssertEqual(t, "context", v.Message)
+		testutils.AssertEqual(t, 0, len(v.Frames))
+		testutils.AssertEqual(t, 2, len(v.Causes))
+		testutils.AssertTrue(t, v.Causes[0].Framed)
+		testutils.AssertEqual(t, 1, len(v.Causes[0].Frames))
+		testutils.AssertMatch(t, `errproto\.TestToProto\.func1$`, v.Causes[0].Frames[0].Function)
+		testutils.AssertEqual(t, "failed", v.Causes[1].Message)
+	})
+
+	t.Run("frames after a stack trace", func(t *testing.T) {
+		v := ToProto(errors.WithFrame(errors.NewWithStackTrace("failed")))
+		testutils.AssertTrue(t, v.Framed)
+		testutils.AssertEqual(t, 0, len(v.Frames))
+		testutils.AssertNotEqual(t, 0, len(v.Causes[0].Frames))
+	})
+
+	t.Run("multierror", func(t *testing.T) {
+		v := ToProto(errors.NewMultiError(errors.New("a"), errors.New("b")))
+		testutils.AssertTrue(t, v.Multi)
+		testutils.AssertEqual(t, 0, len(v.Causes))
+		testutils.AssertEqual(t, 2, len(v.Members))
+		testutils.AssertEqual(t, "b", v.Members[1].Message)
+	})
+}
+
+func TestSchema(t *testing.T) {
+	v := &Error{
+		Message: "a",
+		Frames:  []*Frame{{Function: "f", File: "g", Line: 300}},
+		Causes:  []*Error{{Message: "b"}},
+		Members: []*Error{{}},
+		Framed:  true,
+		Multi:   true,
+	}
+	byt, err := proto.MarshalOptions{Deterministic: true}.Marshal(v)
+	testutils.AssertNil(t, err)
+	testutils.AssertEqual(t, []byte{
+		0x0a, 1, 'a', // message
+		0x12, 9, 0x0a, 1, 'f', 0x12, 1, 'g', 0x18, 0xac, 0x02, // frames
+		0x1a, 3, 0x0a, 1, 'b', // causes
+		0x22, 0, // members
+		0x28, 1, // framed
+		0x38, 1, // multi
+	}, byt)
+}
+
+func TestMaxUnwrapDepth(t *testing.T) {
+	errors.SetMaxUnwrapDepth(2)
+	t.Cleanup(func() { errors.SetMaxUnwrapDepth(0) })
+	err := framesChainError()
+	v := ToProto(err)
+	testutils.AssertTrue(t, len(v.Causes) < chainLength(err)-1)
+}
+
+func ExampleToProto() {
+	err := errors.Errorf("reading config: %w", errors.NewWithFrame("file not found"))
+	byt, _ := proto.Marshal(ToProto(err))
+
+	// On the other end:
+	v := new(Error)
+	if err := proto.Unmarshal(byt, v); err != nil {
+		panic(err)
+	}
+	decoded := FromProto(v)
+	fmt.Println(decoded)
+	fmt.Println(len(errors.FramesFrom(decoded)))
+
+	// Output:
+	// reading config: file not found
+	// 2
+}
@@ -0,0 +1,10 @@
+module github.com/secureworks/errors/errproto
+
+go 1.20
+
+require (
+	github.com/secureworks/errors v0.2.1-0.20261015182823-2e706f4cf2f1
+	google.golang.org/protobuf v1.34.2
+)
+
+replace github.com/secureworks/errors => ../
@@ -0,0 +1,35 @@
+// Package errtest provides utilities for testing code that formats
+// errors with frames.
+//
+// Golden tests of formatted errors (eg, with the `%+v` verb) break
+// whenever the code under test moves by a line, since the frames
+// include line numbers. FreezeLines renders the line numbers of all
+// frames as the FrozenLine placeholder for the duration of a test, so
+// that the output only changes if the frames do:
+//
+//	func TestHandler(t *testing.T) {
+//		errtest.FreezeLines(t)
+//		got := fmt.Sprintf("%+v", handle(req))
+//		// got: "failed\n" +
+//		//	"github.com/acme/svc.handle\n" +
+//		//	"\t/src/acme/svc/handler.go:NN"
+//	}
+//
+// Use FreezeLinesFor where there is no testing.TB, eg in examples.
+//
+// Where the exact output does not matter, AssertEquivalent compares the
+// structure of errors instead (see errors.Diff), ignoring the lines and
+// files of their frames:
+//
+//	errtest.AssertEquivalent(t, want, handle(req))
+//
+// # Parallel tests
+//
+// The line renderer is set for the whole program (see
+// errors.SetLineRenderer), so line numbers are frozen while any test
+// that froze them is running, including for tests running in parallel
+// with it. It is safe to freeze line numbers in parallel tests: they
+// stay frozen until the last of the tests finishes. Tests that check
+// exact line numbers should not run in parallel with tests that freeze
+// them.
+package errtest
@@ -0,0 +1,74 @@
+package errtest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/secureworks/errors"
+)
+
+// FrozenLine is the placeholder that frozen line numbers are rendered
+// as.
+const FrozenLine = "NN"
+
+var (
+	mu       sync.Mutex
+	frozen   int
+	previous func(line int) string
+)
+
+// FreezeLines renders the line numbers of all frames as FrozenLine
+// until the test (and its subtests) finish, then restores the previous
+// renderer.
+func FreezeLines(tb testing.TB) {
+	tb.Helper()
+	freeze()
+	tb.Cleanup(unfreeze)
+}
+
+// FreezeLinesFor renders the line numbers of all frames as FrozenLine
+// while the function runs, then restores the previous renderer.
+func FreezeLinesFor(fn func()) {
+	freeze()
+	defer unfreeze()
+	fn()
+}
+
+func freeze() {
+	mu.Lock()
+	defer mu.Unlock()
+	if frozen == 0 {
+		previous = errors.SetLineRenderer(frozenLine)
+	}
+	frozen++
+}
+
+func unfreeze() {
+	mu.Lock()
+	defer mu.Unlock()
+	frozen--
+	if frozen == 0 {
+		errors.SetLineRenderer(previous)
+		previous = nil
+	}
+}
+
+func frozenLine(int) string { return FrozenLine }
+
+// AssertEquivalent fails the test if the errors are not equivalent, as
+// compared by errors.Diff, reporting their differences: the lines and
+// files of their frames do not matter. Use errors.DiffWith to compare
+// them otherwise.
+func AssertEquivalent(tb testing.TB, want, got error) {
+	tb.Helper()
+	diffs := errors.Diff(want, got)
+	if len(diffs) == 0 {
+		return
+	}
+	lines := make([]string, len(diffs))
+	for i, diff := range diffs {
+		lines[i] = "\t" + diff.String()
+	}
+	tb.Errorf("errors are not equivalent (want != got):\n%s", strings.Join(lines, "\n"))
+}
@@ -0,0 +1,89 @@
+package errtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/secureworks/errors"
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+func TestFreezeLines(t *testing.T) {
+	err := errors.NewWithFrames("failed", errors.Frames{errors.NewFrame("pkg.Fn", "/src/pkg/file.go", 42)})
+
+	t.Run("freezes lines for the test", func(t *testing.T) {
+		t.Run("frozen", func(t *testing.T) {
+			FreezeLines(t)
+			testutils.AssertEqual(t, "failed\npkg.Fn\n\t/src/pkg/file.go:NN", fmt.Sprintf("%+v", err))
+			testutils.AssertEqual(t, "[file.go:NN]", fmt.Sprintf("%s", errors.FramesFrom(err)))
+			testutils.AssertEqual(t, "pkg.Fn(file.go:NN)", errors.OriginString(err))
+		})
+		testutils.AssertEqual(t, "failed\npkg.Fn\n\t/src/pkg/file.go:42", fmt.Sprintf("%+v", err))
+	})
+
+	t.Run("FreezeLinesFor", func(t *testing.T) {
+		var out string
+		FreezeLinesFor(func() { out = fmt.Sprintf("%v", errors.FramesFrom(err)) })
+		testutils.AssertEqual(t, "[/src/pkg/file.go:NN]", out)
+		testutils.AssertEqual(t, "[/src/pkg/file.go:42]", fmt.Sprintf("%v", errors.FramesFrom(err)))
+	})
+
+	t.Run("restores the previous renderer", func(t *testing.T) {
+		errors.SetLineRenderer(func(line int) string { return fmt.Sprintf("L%d", line) })
+		defer errors.SetLineRenderer(nil)
+
+		FreezeLinesFor(func() {
+			testutils.AssertEqual(t, "[file.go:NN]", fmt.Sprintf("%s", errors.FramesFrom(err)))
+		})
+		testutils.AssertEqual(t, "[file.go:L42]", fmt.Sprintf("%s", errors.FramesFrom(err)))
+	})
+
+	t.Run("parallel tests", func(t *testing.T) {
+		// The group returns after its parallel subtests, and their
+		// cleanups, have finished.
+		t.Run("group", func(t *testing.T) {
+			for i := 0; i < 8; i++ {
+				t.Run(fmt.Sprint(i), func(t *testing.T) {
+					t.Parallel()
+					FreezeLines(t)
+					testutils.AssertEqual(t, "[file.go:NN]", fmt.Sprintf("%s", errors.FramesFrom(err)))
+				})
+			}
+		})
+		testutils.AssertEqual(t, "[file.go:42]", fmt.Sprintf("%s", errors.FramesFrom(err)))
+	})
+}
+
+// recordingTB records the failures of an assertion.
+type recordingTB struct {
+	testing.TB
+	failures []string
+}
+
+func (tb *recordingTB) Errorf(format string, args ...interface{}) {
+	tb.failures = append(tb.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertEquivalent(t *testing.T) {
+	frame := func(function string, line int) errors.Frames {
+		return errors.Frames{errors.NewFrame(function, "/src/pkg/file.go", line)}
+	}
+
+	t.Run("equivalent", func(t *testing.T) {
+		tb := &recordingTB{TB: t}
+		want := errors.WithFrames(fmt.Errorf("reading: %w", errors.NewWithFrames("failed", frame("pkg.read", 10))), frame("pkg.Run", 20))
+		got := errors.WithFrames(fmt.Errorf("reading: %w", errors.NewWithFrames("failed", frame("pkg.read", 11))), frame("pkg.Run", 24))
+		AssertEquivalent(tb, want, got)
+		testutils.AssertEqual(t, 0, len(tb.failures))
+	})
+
+	t.Run("not equivalent", func(t *testing.T) {
+		tb := &recordingTB{TB: t}
+		want := errors.NewWithFrames("failed", frame("pkg.read", 10))
+		got := errors.NewWithFrames("failure", frame("pkg.load", 10))
+		AssertEquivalent(tb, want, got)
+		testutils.AssertEqual(t, []string{"errors are not equivalent (want != got):\n" +
+			"\tlinks[0].message: \"failed\" != \"failure\"\n" +
+			"\tlinks[0].frames[0].function: pkg.read != pkg.load"}, tb.failures)
+	})
+}
@@ -0,0 +1,98 @@
+package errors
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+// pathString generates random path-like strings that are heavy in the
+// characters (and character sequences) that must be escaped.
+type pathString string
+
+var pathAlphabet = []string{
+	`\`, `\\`, `\t`, `\n`, `\"`, "\t", "\n", `"`, "t", "n", "/", ".", "_", "C", "文件", "č",
+}
+
+func (pathString) Generate(rand *rand.Rand, size int) reflect.Value {
+	var b strings.Builder
+	b.WriteString("/")
+	for i := 0; i < rand.Intn(size+1); i++ {
+		b.WriteString(pathAlphabet[rand.Intn(len(pathAlphabet))])
+	}
+	return reflect.ValueOf(pathString(b.String()))
+}
+
+func TestEscape(t *testing.T) {
+	cases := []struct {
+		raw     string
+		escaped string
+	}{
+		{raw: ``, escaped: ``},
+		{raw: `plain`, escaped: `plain`},
+		{raw: `C:\temp\new\thing.go`, escaped: `C:\\temp\\new\\thing.go`},
+		{raw: "tab\tnewline\n", escaped: `tab\tnewline\n`},
+		{raw: `\t`, escaped: `\\t`},
+		{raw: `"quoted"`, escaped: `\"quoted\"`},
+	}
+	for _, tt := range cases {
+		t.Run(fmt.Sprintf("%q", tt.raw), func(t *testing.T) {
+			testutils.AssertEqual(t, tt.escaped, escape(tt.raw))
+			testutils.AssertEqual(t, tt.raw, unescape(tt.escaped))
+		})
+	}
+
+	t.Run("keeps unknown escape sequences", func(t *testing.T) {
+		testutils.AssertEqual(t, `C:\Users\x`, unescape(`C:\Users\x`))
+		testutils.AssertEqual(t, `trailing\`, unescape(`trailing\`))
+	})
+}
+
+func TestEscapeRoundTrip(t *testing.T) {
+	t.Run("unescape is the inverse of escape", func(t *testing.T) {
+		f := func(str pathString) bool {
+			return unescape(escape(string(str))) == string(str)
+		}
+		if err := quick.Check(f, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		f := func(function, file pathString) bool {
+			fr := NewFrame(string(function), string(file), 10)
+			ff, err := FramesFromBytes([]byte(fmt.Sprintf("%+v", Frames{fr})))
+			if err != nil || len(ff) != 1 {
+				return false
+			}
+			gotFunction, gotFile, gotLine := ff[0].Location()
+			return gotFunction == string(function) && gotFile == string(file) && gotLine == 10
+		}
+		if err := quick.Check(f, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		f := func(function, file pathString) bool {
+			byt, err := Frames{NewFrame(string(function), string(file), 10)}.MarshalJSON()
+			if err != nil {
+				return false
+			}
+			ff, err := FramesFromJSON(byt)
+			if err != nil || len(ff) != 1 {
+				return false
+			}
+			gotFunction, gotFile, gotLine := ff[0].Location()
+			return gotFunction == string(function) && gotFile == string(file) && gotLine == 10
+		}
+		if err := quick.Check(f, nil); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -29,6 +29,9 @@ func runSomeTask(n int) error {
 //
 // We can also coalesce errors into an errors.MultiError and handle it
 // using single error idioms, which is useful for managing subtasks.
+// Marking errors with errors.AcrossGoroutine before sending them keeps
+// the frames of the go routine they came from apart from those added
+// where they are received.
 func Example_debugTasks() {
 	var wg sync.WaitGroup
 
@@ -40,7 +43,7 @@ func Example_debugTasks() {
 			defer wg.Done()
 			err := errors.WithFrame(runSomeTask(i))
 			if err != nil {
-				errCh <- err
+				errCh <- errors.AcrossGoroutine(err)
 			}
 		}()
 	}
@@ -52,7 +55,7 @@ func Example_debugTasks() {
 
 	var merr error
 	for err := range errCh {
-		errors.AppendInto(&merr, err)
+		errors.AppendInto(&merr, errors.WithFrame(err))
 	}
 
 	if merr != nil {
@@ -62,18 +65,24 @@ func Example_debugTasks() {
 	// Output: multiple errors:
 	//
 	// * error 1 of 2: while running some task (0): err from wrapper type
+	// github.com/secureworks/errors_test.Example_debugTasks
+	// 	/home/testuser/pkgs/errors/example_debug_tasks_test.go:NN
+	// received from goroutine:
 	// github.com/secureworks/errors_test.(*wrapperType).ReturnError
-	// 	/home/testuser/pkgs/errors/example_debug_tasks_test.go:0
+	// 	/home/testuser/pkgs/errors/example_debug_tasks_test.go:NN
 	// github.com/secureworks/errors_test.runSomeTask
-	// 	/home/testuser/pkgs/errors/example_debug_tasks_test.go:0
+	// 	/home/testuser/pkgs/errors/example_debug_tasks_test.go:NN
 	// github.com/secureworks/errors_test.Example_debugTasks.func1
-	// 	/home/testuser/pkgs/errors/example_debug_tasks_test.go:0
+	// 	/home/testuser/pkgs/errors/example_debug_tasks_test.go:NN
 	//
 	// * error 2 of 2: while running some task (2): err from wrapper type
+	// github.com/secureworks/errors_test.Example_debugTasks
+	// 	/home/testuser/pkgs/errors/example_debug_tasks_test.go:NN
+	// received from goroutine:
 	// github.com/secureworks/errors_test.(*wrapperType).ReturnError
-	// 	/home/testuser/pkgs/errors/example_debug_tasks_test.go:0
+	// 	/home/testuser/pkgs/errors/example_debug_tasks_test.go:NN
 	// github.com/secureworks/errors_test.runSomeTask
-	// 	/home/testuser/pkgs/errors/example_debug_tasks_test.go:0
+	// 	/home/testuser/pkgs/errors/example_debug_tasks_test.go:NN
 	// github.com/secureworks/errors_test.Example_debugTasks.func1
-	// 	/home/testuser/pkgs/errors/example_debug_tasks_test.go:0
+	// 	/home/testuser/pkgs/errors/example_debug_tasks_test.go:NN
 }
@@ -52,7 +52,12 @@ func Example_streamErrors() {
 	scanner := bufio.NewScanner(r)
 	scanner.Split(tokenizer)
 	for scanner.Scan() {
-		err, _ := errors.ErrorFromBytes(scanner.Bytes())
+		// If the text is malformed we still get the error with as much
+		// context as could be parsed, so we only log the parse failure.
+		err, parseErr := errors.ParseErrorFromBytes(scanner.Bytes())
+		if parseErr != nil {
+			pprintf("\nPARSE FAILED: %v\n", parseErr)
+		}
 		pprintf("\nREAD IN ERROR: %+v\n", err)
 	}
 
@@ -60,25 +65,25 @@ func Example_streamErrors() {
 	//
 	// READ IN ERROR: outer context: inner context: err w frames
 	// github.com/secureworks/errors_test.Example_streamErrors
-	// 	/home/testuser/pkgs/errors/example_stream_errors_test.go:0
+	// 	/home/testuser/pkgs/errors/example_stream_errors_test.go:NN
 	// github.com/secureworks/errors_test.Example_streamErrors
-	// 	/home/testuser/pkgs/errors/example_stream_errors_test.go:0
+	// 	/home/testuser/pkgs/errors/example_stream_errors_test.go:NN
 	// github.com/secureworks/errors_test.Example_streamErrors.func1
-	// 	/home/testuser/pkgs/errors/example_stream_errors_test.go:0
+	// 	/home/testuser/pkgs/errors/example_stream_errors_test.go:NN
 	//
 	// READ IN ERROR: basic err
 	//
 	// READ IN ERROR: err w stack
 	// github.com/secureworks/errors_test.Example_streamErrors
-	// 	/home/testuser/pkgs/errors/example_stream_errors_test.go:0
+	// 	/home/testuser/pkgs/errors/example_stream_errors_test.go:NN
 	// testing.runExample
-	// 	/go/src/testing/run_example.go:0
+	// 	/go/src/testing/run_example.go:NN
 	// testing.runExamples
-	// 	/go/src/testing/example.go:0
+	// 	/go/src/testing/example.go:NN
 	// testing.(*M).Run
-	// 	/go/src/testing/testing.go:0
+	// 	/go/src/testing/testing.go:NN
 	// main.main
-	// 	_testmain.go:0
+	// 	_testmain.go:NN
 	// runtime.main
-	// 	/go/src/runtime/proc.go:0
+	// 	/go/src/runtime/proc.go:NN
 }
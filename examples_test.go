@@ -8,35 +8,37 @@ import (
 	"strings"
 
 	"github.com/secureworks/errors"
+	"github.com/secureworks/errors/errtest"
 )
 
 var sharedPath = "/home/testuser/pkgs/errors/"
 var matchInternalPath = regexp.MustCompile(`((/.+)+)/src/`)
 var matchPackagePath = regexp.MustCompile(`((/.+)+)/errors/`)
-var matchLineNumbers = regexp.MustCompile(`:[0-9]+`)
 
 // pprint allows these tests to pass in any environment by grepping
-// filepaths in the output, and to ease matching by removing line
-// numbers from the call stacks.
+// filepaths in the output, and to ease matching by freezing line
+// numbers in the call stacks.
 func pprint(v ...interface{}) {
-	entries := strings.Split(fmt.Sprint(v...), " ")
+	var out string
+	errtest.FreezeLinesFor(func() { out = fmt.Sprint(v...) })
+	entries := strings.Split(out, " ")
 	for i := range entries {
 		entries[i] = matchInternalPath.ReplaceAllString(entries[i], "/go/src/")
 		entries[i] = matchPackagePath.ReplaceAllString(entries[i], sharedPath)
-		entries[i] = matchLineNumbers.ReplaceAllString(entries[i], ":0")
 	}
 	fmt.Print(strings.Join(entries, " "))
 }
 
 // pprintf allows these tests to pass in any environment by grepping
-// filepaths in the output, and to ease matching by removing line
-// numbers from the call stacks.
+// filepaths in the output, and to ease matching by freezing line
+// numbers in the call stacks.
 func pprintf(format string, v ...interface{}) {
-	entries := strings.Split(fmt.Sprintf(format, v...), " ")
+	var out string
+	errtest.FreezeLinesFor(func() { out = fmt.Sprintf(format, v...) })
+	entries := strings.Split(out, " ")
 	for i := range entries {
 		entries[i] = matchInternalPath.ReplaceAllString(entries[i], "/go/src/")
 		entries[i] = matchPackagePath.ReplaceAllString(entries[i], sharedPath)
-		entries[i] = matchLineNumbers.ReplaceAllString(entries[i], ":0")
 	}
 	fmt.Print(strings.Join(entries, " "))
 }
@@ -45,7 +47,7 @@ func ExampleCaller() {
 	fr := errors.Caller()
 	pprint(fr)
 
-	// Output: /home/testuser/pkgs/errors/examples_test.go:0
+	// Output: /home/testuser/pkgs/errors/examples_test.go:NN
 }
 
 // The underlying type generated here implements the unexported
@@ -86,14 +88,14 @@ func ExampleFrame_printf() {
 	pprintf("%%+v: %+v\n", fr)
 
 	// Output:
-	// %s:  examples_test.go:0
-	// %q:  "examples_test.go:0"
+	// %s:  examples_test.go:NN
+	// %q:  "examples_test.go:NN"
 	// %n:  ExampleFrame_printf
-	// %d:  77
-	// %v:  /home/testuser/pkgs/errors/examples_test.go:0
-	// %#v: errors.Frame("/home/testuser/pkgs/errors/examples_test.go:0")
+	// %d:  NN
+	// %v:  /home/testuser/pkgs/errors/examples_test.go:NN
+	// %#v: errors.Frame("/home/testuser/pkgs/errors/examples_test.go:NN")
 	// %+v: github.com/secureworks/errors_test.ExampleFrame_printf
-	// 	/home/testuser/pkgs/errors/examples_test.go:0
+	// 	/home/testuser/pkgs/errors/examples_test.go:NN
 }
 
 func ExampleNewFrame() {
@@ -101,7 +103,7 @@ func ExampleNewFrame() {
 	pprintf("%+v", fr)
 
 	// Output: fn.name
-	// 	file.go:0
+	// 	file.go:NN
 }
 
 func ExampleFrameFromPC() {
@@ -110,7 +112,7 @@ func ExampleFrameFromPC() {
 	pprintf("%+v", fr)
 
 	// Output: github.com/secureworks/errors_test.ExampleFrameFromPC
-	// 	/home/testuser/pkgs/errors/examples_test.go:0
+	// 	/home/testuser/pkgs/errors/examples_test.go:NN
 }
 
 func ExamplePCFromFrame_runtimePC() {
@@ -149,7 +151,7 @@ func ExampleFrames() {
 	stack := errors.CallStack()
 	pprint(stack)
 
-	// Output: [/home/testuser/pkgs/errors/examples_test.go:0 /go/src/testing/run_example.go:0 /go/src/testing/example.go:0 /go/src/testing/testing.go:0 _testmain.go:0 /go/src/runtime/proc.go:0]
+	// Output: [/home/testuser/pkgs/errors/examples_test.go:NN /go/src/testing/run_example.go:NN /go/src/testing/example.go:NN /go/src/testing/testing.go:NN _testmain.go:NN /go/src/runtime/proc.go:NN]
 }
 
 func ExampleFrames_printf() {
@@ -160,17 +162,17 @@ func ExampleFrames_printf() {
 
 	// Output:
 	// github.com/secureworks/errors_test.ExampleFrames_printf
-	// 	/home/testuser/pkgs/errors/examples_test.go:0
+	// 	/home/testuser/pkgs/errors/examples_test.go:NN
 	// testing.runExample
-	// 	/go/src/testing/run_example.go:0
+	// 	/go/src/testing/run_example.go:NN
 	// testing.runExamples
-	// 	/go/src/testing/example.go:0
+	// 	/go/src/testing/example.go:NN
 	// testing.(*M).Run
-	// 	/go/src/testing/testing.go:0
+	// 	/go/src/testing/testing.go:NN
 	// main.main
-	// 	_testmain.go:0
+	// 	_testmain.go:NN
 	// runtime.main
-	// 	/go/src/runtime/proc.go:0
+	// 	/go/src/runtime/proc.go:NN
 }
 
 // The underlying types generated by errors implement json.Marshaler.
@@ -190,7 +192,7 @@ func ExampleFrames_jsonMarshal() {
 	//     {
 	//         "function": "github.com/secureworks/errors_test.ExampleFrames_jsonMarshal",
 	//         "file": "/home/testuser/pkgs/errors/examples_test.go",
-	//         "line": 180
+	//         "line": 182
 	//     }
 	// ]
 }
@@ -239,11 +241,11 @@ func ExampleFramesFromJSON() {
 
 	// Output:
 	// github.com/secureworks/errors_test.FnName
-	// 	/home/testuser/pkgs/errors/examples_test.go:0
+	// 	/home/testuser/pkgs/errors/examples_test.go:NN
 	// github.com/secureworks/errors_test.FnWrapper
-	// 	/home/testuser/pkgs/errors/examples_test.go:0
+	// 	/home/testuser/pkgs/errors/examples_test.go:NN
 	// runtime.main
-	// 	/go/src/runtime/proc.go:0
+	// 	/go/src/runtime/proc.go:NN
 }
 
 func ExampleNew() {
@@ -259,7 +261,7 @@ func ExampleNewWithFrame() {
 
 	// Output: err message
 	// github.com/secureworks/errors_test.ExampleNewWithFrame
-	// 	/home/testuser/pkgs/errors/examples_test.go:0
+	// 	/home/testuser/pkgs/errors/examples_test.go:NN
 }
 
 func ExampleNewWithFrameAt() {
@@ -268,7 +270,7 @@ func ExampleNewWithFrameAt() {
 
 	// Output: err message
 	// testing.runExample
-	// 	/go/src/testing/run_example.go:0
+	// 	/go/src/testing/run_example.go:NN
 }
 
 func ExampleNewWithFrames() {
@@ -278,9 +280,9 @@ func ExampleNewWithFrames() {
 
 	// Output: err message
 	// github.com/secureworks/errors_test.ExampleNewWithFrames
-	// 	/home/testuser/pkgs/errors/examples_test.go:0
+	// 	/home/testuser/pkgs/errors/examples_test.go:NN
 	// testing.runExample
-	// 	/go/src/testing/run_example.go:0
+	// 	/go/src/testing/run_example.go:NN
 }
 
 func ExampleNewWithStackTrace() {
@@ -289,17 +291,17 @@ func ExampleNewWithStackTrace() {
 
 	// Output: err message
 	// github.com/secureworks/errors_test.ExampleNewWithStackTrace
-	// 	/home/testuser/pkgs/errors/examples_test.go:0
+	// 	/home/testuser/pkgs/errors/examples_test.go:NN
 	// testing.runExample
-	// 	/go/src/testing/run_example.go:0
+	// 	/go/src/testing/run_example.go:NN
 	// testing.runExamples
-	// 	/go/src/testing/example.go:0
+	// 	/go/src/testing/example.go:NN
 	// testing.(*M).Run
-	// 	/go/src/testing/testing.go:0
+	// 	/go/src/testing/testing.go:NN
 	// main.main
-	// 	_testmain.go:0
+	// 	_testmain.go:NN
 	// runtime.main
-	// 	/go/src/runtime/proc.go:0
+	// 	/go/src/runtime/proc.go:NN
 }
 
 func ExampleWithFrame() {
@@ -309,7 +311,7 @@ func ExampleWithFrame() {
 
 	// Output: err message
 	// github.com/secureworks/errors_test.ExampleWithFrame
-	// 	/home/testuser/pkgs/errors/examples_test.go:0
+	// 	/home/testuser/pkgs/errors/examples_test.go:NN
 }
 
 func ExampleWithFrameAt() {
@@ -319,7 +321,7 @@ func ExampleWithFrameAt() {
 
 	// Output: err message
 	// testing.runExample
-	// 	/go/src/testing/run_example.go:0
+	// 	/go/src/testing/run_example.go:NN
 }
 
 func ExampleWithFrames() {
@@ -330,9 +332,9 @@ func ExampleWithFrames() {
 
 	// Output: err message
 	// github.com/secureworks/errors_test.ExampleWithFrames
-	// 	/home/testuser/pkgs/errors/examples_test.go:0
+	// 	/home/testuser/pkgs/errors/examples_test.go:NN
 	// testing.runExample
-	// 	/go/src/testing/run_example.go:0
+	// 	/go/src/testing/run_example.go:NN
 }
 
 func ExampleWithStackTrace() {
@@ -342,17 +344,17 @@ func ExampleWithStackTrace() {
 
 	// Output: err message
 	// github.com/secureworks/errors_test.ExampleWithStackTrace
-	// 	/home/testuser/pkgs/errors/examples_test.go:0
+	// 	/home/testuser/pkgs/errors/examples_test.go:NN
 	// testing.runExample
-	// 	/go/src/testing/run_example.go:0
+	// 	/go/src/testing/run_example.go:NN
 	// testing.runExamples
-	// 	/go/src/testing/example.go:0
+	// 	/go/src/testing/example.go:NN
 	// testing.(*M).Run
-	// 	/go/src/testing/testing.go:0
+	// 	/go/src/testing/testing.go:NN
 	// main.main
-	// 	_testmain.go:0
+	// 	_testmain.go:NN
 	// runtime.main
-	// 	/go/src/runtime/proc.go:0
+	// 	/go/src/runtime/proc.go:NN
 }
 
 func ExampleErrorf() {
@@ -362,7 +364,7 @@ func ExampleErrorf() {
 
 	// Output: outer context: err message
 	// github.com/secureworks/errors_test.ExampleErrorf
-	// 	/home/testuser/pkgs/errors/examples_test.go:0
+	// 	/home/testuser/pkgs/errors/examples_test.go:NN
 }
 
 func ExampleErrorf_appendingDebuggingContext() {
@@ -373,9 +375,9 @@ func ExampleErrorf_appendingDebuggingContext() {
 
 	// Output: outermost context: context: err message
 	// github.com/secureworks/errors_test.ExampleErrorf_appendingDebuggingContext
-	// 	/home/testuser/pkgs/errors/examples_test.go:0
+	// 	/home/testuser/pkgs/errors/examples_test.go:NN
 	// github.com/secureworks/errors_test.ExampleErrorf_appendingDebuggingContext
-	// 	/home/testuser/pkgs/errors/examples_test.go:0
+	// 	/home/testuser/pkgs/errors/examples_test.go:NN
 }
 
 func ExampleFramesFrom_appendedFrames() {
@@ -387,9 +389,9 @@ func ExampleFramesFrom_appendedFrames() {
 
 	// Output:
 	// github.com/secureworks/errors_test.ExampleFramesFrom_appendedFrames
-	// 	/home/testuser/pkgs/errors/examples_test.go:0
+	// 	/home/testuser/pkgs/errors/examples_test.go:NN
 	// github.com/secureworks/errors_test.ExampleFramesFrom_appendedFrames
-	// 	/home/testuser/pkgs/errors/examples_test.go:0
+	// 	/home/testuser/pkgs/errors/examples_test.go:NN
 
 }
 
@@ -402,17 +404,17 @@ func ExampleFramesFrom_stackTrace() {
 
 	// Output:
 	// github.com/secureworks/errors_test.ExampleFramesFrom_stackTrace
-	// 	/home/testuser/pkgs/errors/examples_test.go:0
+	// 	/home/testuser/pkgs/errors/examples_test.go:NN
 	// testing.runExample
-	// 	/go/src/testing/run_example.go:0
+	// 	/go/src/testing/run_example.go:NN
 	// testing.runExamples
-	// 	/go/src/testing/example.go:0
+	// 	/go/src/testing/example.go:NN
 	// testing.(*M).Run
-	// 	/go/src/testing/testing.go:0
+	// 	/go/src/testing/testing.go:NN
 	// main.main
-	// 	_testmain.go:0
+	// 	_testmain.go:NN
 	// runtime.main
-	// 	/go/src/runtime/proc.go:0
+	// 	/go/src/runtime/proc.go:NN
 }
 
 func ExampleWithMessage() {
@@ -469,9 +471,9 @@ func ExampleOpaque() {
 
 	// Output: outermost context: context: err message
 	// github.com/secureworks/errors_test.ExampleOpaque
-	// 	/home/testuser/pkgs/errors/examples_test.go:0
+	// 	/home/testuser/pkgs/errors/examples_test.go:NN
 	// github.com/secureworks/errors_test.ExampleOpaque
-	// 	/home/testuser/pkgs/errors/examples_test.go:0
+	// 	/home/testuser/pkgs/errors/examples_test.go:NN
 }
 
 func ExampleNewMultiError() {
@@ -649,25 +651,47 @@ func ExampleMultiError_printf() {
 	//
 	// * error 2 of 3: err2
 	// github.com/secureworks/errors_test.ExampleMultiError_printf
-	// 	/home/testuser/pkgs/errors/examples_test.go:0
+	// 	/home/testuser/pkgs/errors/examples_test.go:NN
 	//
 	// * error 3 of 3: err3
 	// github.com/secureworks/errors_test.ExampleMultiError_printf
-	// 	/home/testuser/pkgs/errors/examples_test.go:0
+	// 	/home/testuser/pkgs/errors/examples_test.go:NN
 	// testing.runExample
-	// 	/go/src/testing/run_example.go:0
+	// 	/go/src/testing/run_example.go:NN
 	// testing.runExamples
-	// 	/go/src/testing/example.go:0
+	// 	/go/src/testing/example.go:NN
 	// testing.(*M).Run
-	// 	/go/src/testing/testing.go:0
+	// 	/go/src/testing/testing.go:NN
 	// main.main
-	// 	_testmain.go:0
+	// 	_testmain.go:NN
 	// runtime.main
-	// 	/go/src/runtime/proc.go:0
+	// 	/go/src/runtime/proc.go:NN
 	//
 	// 3. context: [err1; err2; err3]
 	// github.com/secureworks/errors_test.ExampleMultiError_printf
-	// 	/home/testuser/pkgs/errors/examples_test.go:0
+	// 	/home/testuser/pkgs/errors/examples_test.go:NN
+	//
+	// multiple errors:
+	//
+	// * error 1 of 3: err1
+	//
+	// * error 2 of 3: err2
+	// github.com/secureworks/errors_test.ExampleMultiError_printf
+	// 	/home/testuser/pkgs/errors/examples_test.go:NN
+	//
+	// * error 3 of 3: err3
+	// github.com/secureworks/errors_test.ExampleMultiError_printf
+	// 	/home/testuser/pkgs/errors/examples_test.go:NN
+	// testing.runExample
+	// 	/go/src/testing/run_example.go:NN
+	// testing.runExamples
+	// 	/go/src/testing/example.go:NN
+	// testing.(*M).Run
+	// 	/go/src/testing/testing.go:NN
+	// main.main
+	// 	_testmain.go:NN
+	// runtime.main
+	// 	/go/src/runtime/proc.go:NN
 }
 
 func ExampleErrorsFrom() {
@@ -826,3 +850,79 @@ type testCloser struct{ err error }
 func (t testCloser) Close() error {
 	return t.err
 }
+
+func ExampleMultiError_AtLeast() {
+	errReplicaDown := errors.New("replica down")
+	write := func(replica int) error {
+		if replica == 2 {
+			return fmt.Errorf("replica %d: %w", replica, errReplicaDown)
+		}
+		return nil
+	}
+
+	// Write to 3 replicas, and succeed if at least 2 of them work.
+	merr := errors.NewMultiError(write(1), write(2), write(3))
+	if merr.AtLeast(2, errReplicaDown) {
+		fmt.Println("write failed:", merr.Error())
+	} else {
+		fmt.Println("write succeeded despite:", merr.Error())
+	}
+
+	// Output: write succeeded despite: [replica 2: replica down]
+}
+
+func ExampleAppendResultf() {
+	errFn := func() (err error) {
+		input := &testErrCloser{}
+		defer errors.AppendResultf(&err, input.Close, "closing %s", "input.txt")
+		output := &testCloser{}
+		defer errors.AppendResultf(&err, output.Close, "closing %s", "output.txt")
+
+		return errors.New("some error we got")
+	}
+
+	noErrFn := func() (err error) {
+		closer := &testCloser{}
+		defer errors.AppendResultf(&err, closer.Close, "closing %s", "input.txt")
+
+		return
+	}
+
+	fmt.Println()
+
+	err := errFn()
+	if err != nil {
+		pprintf("%+v", err)
+	}
+
+	err = noErrFn()
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Println("noErrFn returned nil")
+	}
+
+	// Output:
+	// multiple errors:
+	//
+	// * error 1 of 2: some error we got
+	//
+	// * error 2 of 2: closing input.txt: and a closer error to boot!
+	// github.com/secureworks/errors_test.ExampleAppendResultf.func1
+	// 	/home/testuser/pkgs/errors/examples_test.go:NN
+	// noErrFn returned nil
+}
+
+func ExampleStats() {
+	errors.EnableStats(true)
+	defer errors.EnableStats(false)
+	defer errors.ResetStats()
+
+	errTimeout := errors.New("timeout")
+	for i := 1; i <= 3; i++ {
+		_ = errors.Errorf("attempt %d: %w", i, errTimeout)
+	}
+	fmt.Println(errors.Stats().Created)
+
+	// Output: map[Errorf:3]
+}
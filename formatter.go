@@ -9,6 +9,7 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"io"
 	"regexp"
 	"strconv"
 	"strings"
@@ -25,12 +26,53 @@ import (
 //
 // Similar to fmt.Errorf, this function supports multiple `%w` verbs to
 // generate a multierror: each wrapped error will have a frame attached
-// to it.
+// to it. Use ErrorfAt to attach the frame of a caller further up the
+// stack, from a helper.
 func Errorf(format string, values ...interface{}) error {
+	return errorf(format, values, false, 0)
+}
+
+// ErrorfAt is the same as Errorf, but the frame the error (and each
+// error wrapped with the `%w` verb) is annotated with is that of a
+// caller further up the stack: skipCallers tunes how many callers to
+// skip, in case this is called in a helper you want to ignore, eg:
+//
+//	func Internalf(format string, values ...interface{}) error {
+//		return errors.ErrorfAt(1, "internal error: "+format, values...)
+//	}
+//
+// Errorf is the same as ErrorfAt(0, ...). A negative skipCallers is
+// treated as 0.
+func ErrorfAt(skipCallers int, format string, values ...interface{}) error {
+	if skipCallers < 0 {
+		skipCallers = 0
+	}
+	return errorf(format, values, false, skipCallers)
+}
+
+// ErrorfAll is the same as Errorf, except that errors formatted with the
+// %v or %s verbs are also annotated with a frame for the caller:
+//
+//	err := errors.ErrorfAll("outer: %w: context from other: %v", primary, secondary)
+//
+// Those errors are still only used for context, and do not join the
+// error chain, so errors.Is and errors.As work the same as for Errorf.
+// Use ReferencesFrom to retrieve them (with their frames) from the
+// result.
+func ErrorfAll(format string, values ...interface{}) error {
+	return errorf(format, values, true, 0)
+}
+
+// errorf implements Errorf and ErrorfAll, optionally annotating errors
+// that are formatted but not wrapped, with the frame of the caller after
+// skipping the given number of callers.
+func errorf(format string, values []interface{}, references bool, skipCallers int) error {
 	verbs, err := parseFormatString(format, len(values))
 	if err != nil {
 		return errors.New(`%!e(errors.Errorf=failed: ` + err.Error() + `)`)
 	}
+	fr := getFrame(4 + skipCallers)
+	countCreated(statsErrorf)
 
 	var numWrapped int
 	for _, v := range verbs {
@@ -40,14 +82,47 @@ func Errorf(format string, values ...interface{}) error {
 		numWrapped++
 	}
 
+	// Annotate errors formatted with %v or %s, unless they are also wrapped
+	// by another verb. These do not join the chain, so are tracked to be
+	// retrieved with ReferencesFrom.
+	var refs []error
+	if references {
+		wrappedIdx := make(map[int]bool)
+		for _, v := range verbs {
+			if v.letter == 'w' {
+				wrappedIdx[v.idx] = true
+			}
+		}
+		values = append([]interface{}(nil), values...)
+		for _, v := range verbs {
+			if (v.letter != 'v' && v.letter != 's') || wrappedIdx[v.idx] {
+				continue
+			}
+			if refErr, ok := values[v.idx].(error); ok && refErr != nil {
+				ref := &withFrames{
+					error:  refErr,
+					frames: frames{fr},
+				}
+				values[v.idx] = ref
+				refs = append(refs, ref)
+			}
+		}
+	}
+
 	// Allow no %w verbs, and when 0 or 1, then we wrap the created error
 	// with a frame. This allows the received error to handle %+v formatting
 	// correctly.
 	if numWrapped <= 1 {
-		return &withFrames{
-			error:  fmt.Errorf(format, values...),
-			frames: frames{getFrame(3)},
+		if wrapped := wrappedValue(verbs, values); annotationBudgetSpent(wrapped) {
+			return withReferencesTo(&withFrames{
+				error:      fmt.Errorf(format, values...),
+				suppressed: SuppressedAnnotations(wrapped) + 1,
+			}, refs)
 		}
+		return withReferencesTo(&withFrames{
+			error:  fmt.Errorf(format, values...),
+			frames: frames{fr},
+		}, refs)
 	}
 
 	// Interpose and wrap errors with framer if the associated verb is `%w`,
@@ -59,15 +134,113 @@ func Errorf(format string, values ...interface{}) error {
 		}
 		if wrappedErr, ok := values[v.idx].(error); ok {
 			if wrappedErr != nil {
-				numWrapped++
 				values[v.idx] = &withFrames{
 					error:  wrappedErr,
-					frames: frames{getFrame(3)},
+					frames: frames{fr},
 				}
 			}
 		}
 	}
-	return fmt.Errorf(format, values...)
+	return withReferencesTo(fmt.Errorf(format, values...), refs)
+}
+
+// ReferencesFrom returns the errors that were formatted into an error
+// by ErrorfAll without being wrapped, each annotated with the frame of
+// the ErrorfAll call. It returns nil if there are none.
+func ReferencesFrom(err error) []error {
+	for depth := 0; err != nil; depth++ {
+		if w, ok := err.(interface{ references() []error }); ok {
+			return w.references()
+		}
+		err = unwrapAt(err, depth)
+	}
+	return nil
+}
+
+// withReferences implements an error type that keeps the errors that
+// were formatted into its message, for ErrorfAll.
+type withReferences struct {
+	error error
+	refs  []error
+
+	formatGuard
+}
+
+var _ interface { // Assert interface implementation.
+	error
+	Unwrap() error
+	fmt.Formatter
+} = (*withReferences)(nil)
+
+// wrappedValue returns the error formatted with the `%w` verb, if any.
+func wrappedValue(verbs []fmtVerb, values []interface{}) error {
+	for _, v := range verbs {
+		if v.letter == 'w' {
+			err, _ := values[v.idx].(error)
+			return err
+		}
+	}
+	return nil
+}
+
+// withReferencesTo wraps the error if there are any references. If the
+// error is a multierror, the wrapper is one too.
+func withReferencesTo(err error, refs []error) error {
+	if len(refs) == 0 {
+		return err
+	}
+	if _, ok := err.(multierror); ok {
+		return &withMultiReferences{withReferences{error: err, refs: refs}}
+	}
+	return &withReferences{error: err, refs: refs}
+}
+
+func (w *withReferences) Error() string { return w.error.Error() }
+
+func (w *withReferences) Unwrap() error { return w.error }
+
+func (w *withReferences) references() []error { return w.refs }
+
+func (w *withReferences) Format(s fmt.State, verb rune) {
+	if !w.enterFormat(s, verb) {
+		return
+	}
+	defer w.leaveFormat()
+
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "%+v", w.error)
+			return
+		}
+		if s.Flag('#') {
+			fmt.Fprintf(s, "&errors.withReferences{%q}", w.error)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, safeError(w.error, verb))
+	case 'q':
+		fmt.Fprintf(s, "%q", safeError(w.error, verb))
+	default:
+		// empty
+	}
+}
+
+// withMultiReferences is a withReferences wrapping a multierror, such as
+// the result of formatting multiple %w verbs.
+type withMultiReferences struct {
+	withReferences
+}
+
+var _ interface { // Assert interface implementation.
+	error
+	multierror
+	fmt.Formatter
+} = (*withMultiReferences)(nil)
+
+func (w *withMultiReferences) Unwrap() []error {
+	return w.error.(multierror).Unwrap()
 }
 
 type fmtVerb struct {
@@ -302,3 +302,107 @@ func Test_parseFormatString(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorfAll(t *testing.T) {
+	t.Run("is the same as Errorf without references", func(t *testing.T) {
+		err := ErrorfAll("wrap: %w: %d", errSentinel, 10)
+		testutils.AssertEqual(t, "wrap: sentinel err: 10", err.Error())
+		testutils.AssertNil(t, ReferencesFrom(err))
+		_, ok := err.(*withFrames)
+		testutils.AssertTrue(t, ok)
+	})
+
+	t.Run("annotates referenced errors", func(t *testing.T) {
+		errSignal := errors.New("signal")
+		ctxErr := errors.New("just context")
+		err := ErrorfAll("outer: %w: context from other: %v", errSignal, ctxErr)
+
+		testutils.AssertEqual(t, "outer: signal: context from other: just context", err.Error())
+		testutils.AssertTrue(t, Is(err, errSignal))
+		testutils.AssertFalse(t, Is(err, ctxErr))
+		testutils.AssertEqual(t, 1, len(FramesFrom(err)))
+
+		refs := ReferencesFrom(Errorf("wrap: %w", err))
+		testutils.AssertEqual(t, 1, len(refs))
+		testutils.AssertTrue(t, Is(refs[0], ctxErr))
+		testutils.AssertLinesMatch(t, FramesFrom(refs[0]), "%+v", []string{
+			"",
+			`^github.com/secureworks/errors\.TestErrorfAll\.func2$`,
+			"^\t.+/formatter_test\\.go:\\d+$",
+		})
+		testutils.AssertEqual(t, FramesFrom(err)[0], FramesFrom(refs[0])[0])
+	})
+
+	t.Run("annotates referenced errors with multiple wrapped", func(t *testing.T) {
+		errSignal := errors.New("signal")
+		ctxErr := errors.New("just context")
+		err := ErrorfAll("outer: %w: %s: %w", errSignal, ctxErr, errBasic)
+
+		testutils.AssertEqual(t, "outer: signal: just context: new err", err.Error())
+		testutils.AssertTrue(t, Is(err, errSignal))
+		testutils.AssertTrue(t, Is(err, errBasic))
+		testutils.AssertFalse(t, Is(err, ctxErr))
+		testutils.AssertEqual(t, 2, len(ErrorsFrom(err)))
+
+		refs := ReferencesFrom(err)
+		testutils.AssertEqual(t, 1, len(refs))
+		testutils.AssertEqual(t, ctxErr, Unwrap(refs[0]))
+	})
+
+	t.Run("does not annotate wrapped errors twice", func(t *testing.T) {
+		err := ErrorfAll("outer: %[1]w (%[1]v)", errSentinel)
+		testutils.AssertEqual(t, "outer: sentinel err (sentinel err)", err.Error())
+		testutils.AssertNil(t, ReferencesFrom(err))
+	})
+
+	t.Run("does not change the values", func(t *testing.T) {
+		values := []interface{}{errSentinel}
+		_ = ErrorfAll("outer: %v", values...)
+		testutils.AssertEqual(t, errSentinel, values[0])
+	})
+
+	t.Run("formats", func(t *testing.T) {
+		err := ErrorfAll("outer: %v", errSentinel)
+		testutils.AssertEqual(t, "outer: sentinel err", fmt.Sprintf("%s", err))
+		testutils.AssertEqual(t, `"outer: sentinel err"`, fmt.Sprintf("%q", err))
+		testutils.AssertEqual(t, `&errors.withReferences{"outer: sentinel err"}`, fmt.Sprintf("%#v", err))
+		testutils.AssertLinesMatch(t, err, "%+v", []string{
+			"^outer: sentinel err$",
+			`^github.com/secureworks/errors\.TestErrorfAll\.func6$`,
+			"^\t.+/formatter_test\\.go:\\d+$",
+		})
+	})
+}
+
+//go:noinline
+func internalf(format string, values ...interface{}) error {
+	return ErrorfAt(1, "internal error: "+format, values...)
+}
+
+func TestErrorfAt(t *testing.T) {
+	t.Run("frames of the helper's caller", func(t *testing.T) {
+		err, caller := internalf("reading: %w", errSentinel), LocationOf(Caller())
+		testutils.AssertEqual(t, "internal error: reading: sentinel err", err.Error())
+		testutils.AssertTrue(t, Is(err, errSentinel))
+		ff := FramesFrom(err)
+		testutils.AssertEqual(t, 1, len(ff))
+		testutils.AssertEqual(t, caller, LocationOf(ff[0]))
+	})
+
+	t.Run("multiple wrapped errors", func(t *testing.T) {
+		err, caller := internalf("%w and %w", errSentinel, errors.New("other")), LocationOf(Caller())
+		for _, err := range ErrorsFrom(err) {
+			testutils.AssertEqual(t, caller, LocationOf(FramesFrom(err)[0]))
+		}
+	})
+
+	t.Run("zero is Errorf", func(t *testing.T) {
+		a, b := ErrorfAt(0, "a: %w", errSentinel), Errorf("b: %w", errSentinel)
+		testutils.AssertEqual(t, LocationOf(FramesFrom(a)[0]), LocationOf(FramesFrom(b)[0]))
+	})
+
+	t.Run("clamps negative skips", func(t *testing.T) {
+		a, b := ErrorfAt(-3, "a: %w", errSentinel), Errorf("b: %w", errSentinel)
+		testutils.AssertEqual(t, LocationOf(FramesFrom(a)[0]), LocationOf(FramesFrom(b)[0]))
+	})
+}
@@ -15,14 +15,16 @@ package errors
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"path/filepath"
+	"reflect"
 	stdruntime "runtime"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
-	"github.com/secureworks/errors/internal/runtime"
+	"github.com/secureworks/errors/runtimeutil"
 )
 
 // Frame defines an interface for accessing and displaying stack frame
@@ -41,6 +43,8 @@ import (
 //	        and a full file name and line number on a second line
 //	"%#v" – a Golang representation with the type (`errors.Frame`)
 //
+// File paths may be rewritten when formatted with AddSourceMapping.
+//
 // Marshaling a frame as text uses the `%+v` format.
 // Marshaling as JSON returns an object with location data:
 //
@@ -88,6 +92,7 @@ var _ interface { // Assert interface implementation.
 	programCounter
 	fmt.Formatter
 	json.Marshaler
+	json.Unmarshaler
 } = (*frame)(nil)
 
 // PC returns the Frame's local frame program counter.
@@ -111,20 +116,16 @@ func (f *frame) Format(s fmt.State, verb rune) {
 	var appendD = func(line int) {
 		if line > 0 {
 			io.WriteString(s, ":")
-			io.WriteString(s, strconv.Itoa(line))
+			io.WriteString(s, renderLine(line))
 		}
 	}
 	var formatS = func(file string, line int) {
-		io.WriteString(s, escaper.Replace(filepath.Base(file)))
+		io.WriteString(s, escape(baseName(file)))
 		appendD(line)
 	}
 
-	// FIXME(PH): does not handle Windows paths correctly, which means
-	//   it's likely that we can't ensure Windows stack traces are formatted
-	//   correctly, and we definitely can't deserialize Windows stack traces
-	//   on a non-Windows system.
-
 	function, file, line := f.Location()
+	file = mapSource(file)
 	switch verb {
 	case 's':
 		formatS(file, line)
@@ -133,24 +134,24 @@ func (f *frame) Format(s fmt.State, verb rune) {
 		formatS(file, line)
 		io.WriteString(s, `"`)
 	case 'd':
-		io.WriteString(s, strconv.Itoa(line))
+		io.WriteString(s, renderLine(line))
 	case 'n':
-		io.WriteString(s, escaper.Replace(runtime.FuncName(function)))
+		io.WriteString(s, escape(runtimeutil.FuncName(function)))
 	case 'v':
 		switch {
 		case s.Flag('+'):
-			io.WriteString(s, escaper.Replace(function))
+			io.WriteString(s, escape(function))
 			io.WriteString(s, "\n\t")
-			io.WriteString(s, escaper.Replace(file))
+			io.WriteString(s, escape(file))
 			io.WriteString(s, ":")
-			io.WriteString(s, strconv.Itoa(line))
+			io.WriteString(s, renderLine(line))
 		case s.Flag('#'):
 			io.WriteString(s, "errors.Frame(\"")
-			io.WriteString(s, escaper.Replace(file))
+			io.WriteString(s, escape(file))
 			appendD(line)
 			io.WriteString(s, "\")")
 		default:
-			io.WriteString(s, escaper.Replace(file))
+			io.WriteString(s, escape(file))
 			appendD(line)
 		}
 	}
@@ -160,17 +161,164 @@ func (f *frame) Format(s fmt.State, verb rune) {
 // into JSON for serialization.
 func (f frame) MarshalJSON() ([]byte, error) {
 	function, file, line := f.Location()
-	str := fmt.Sprintf(`{"function":%q,"file":%q,"line":%d}`,
-		escaper.Replace(function), escaper.Replace(file), line)
-	return []byte(str), nil
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(frameJSON{
+		Function: escape(function),
+		File:     escape(mapSource(file)),
+		Line:     line,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// UnmarshalJSON sets the location of an empty frame (eg, one allocated
+// by json.Unmarshal) from the JSON object marshaled for it. The frame
+// is synthetic afterwards.
+//
+// A frame that is not empty is never changed, since frames are shared
+// by the errors they were added to, and interned stack traces (see
+// InternStacks) are shared by many errors: an error is returned
+// instead. Use FrameFromJSON, or unmarshal into Frames, which allocate
+// new frames.
+func (f *frame) UnmarshalJSON(byt []byte) error {
+	if *f != (frame{}) {
+		return errUnmarshalSharedFrame
+	}
+	if _, err := limitParseBytes(byt); err != nil {
+		return err
+	}
+	var raw frameJSON
+	if err := json.Unmarshal(byt, &raw); err != nil {
+		return err
+	}
+	fr, err := frameFromJSON(raw)
+	if err != nil {
+		return err
+	}
+	*f = *fr
+	return nil
+}
+
+// frameJSON is the JSON representation of a frame.
+type frameJSON struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// escapeChars are the characters that will keep a stack trace from
+// being parsable / deserializable, mapped to the character that follows
+// the backslash when they are escaped.
+var escapeChars = map[byte]byte{'\\': '\\', '\t': 't', '\n': 'n', '"': '"'}
+
+// unescapeChars is the inverse of escapeChars.
+var unescapeChars = map[byte]byte{'\\': '\\', 't': '\t', 'n': '\n', '"': '"'}
+
+// escape escapes the characters that will keep a stack trace from being
+// parsable / deserializable in a single pass, so that unescape is its
+// exact inverse.
+func escape(str string) string {
+	if !strings.ContainsAny(str, "\\\t\n\"") {
+		return str
+	}
+	var b strings.Builder
+	b.Grow(len(str) + 8)
+	for i := 0; i < len(str); i++ {
+		if c, ok := escapeChars[str[i]]; ok {
+			b.WriteByte('\\')
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte(str[i])
+	}
+	return b.String()
+}
+
+// unescape unescapes characters on deserialization in a single pass.
+//
+// For compatibility with text that was not escaped (eg, it was written
+// by hand or by another tool), a backslash that does not begin a known
+// escape sequence is kept as-is.
+func unescape(str string) string {
+	if strings.IndexByte(str, '\\') < 0 {
+		return str
+	}
+	var b strings.Builder
+	b.Grow(len(str))
+	for i := 0; i < len(str); i++ {
+		if str[i] == '\\' && i+1 < len(str) {
+			if c, ok := unescapeChars[str[i+1]]; ok {
+				b.WriteByte(c)
+				i++
+				continue
+			}
+		}
+		b.WriteByte(str[i])
+	}
+	return b.String()
+}
+
+// StripANSI returns the text with any ANSI escape sequences (such as
+// the color codes in terminal output or CI logs) removed. The parsers
+// in this package (FramesFromBytes, ErrorFromBytes, et al) strip these
+// automatically.
+//
+// Control sequences (ESC [ ... final byte) and two-byte escape
+// sequences are removed. If the text has no escape sequences it is
+// returned as-is, otherwise a new slice is returned.
+func StripANSI(byt []byte) []byte {
+	const esc = 0x1b
+	if bytes.IndexByte(byt, esc) < 0 {
+		return byt
+	}
+	stripped := make([]byte, 0, len(byt))
+	for i := 0; i < len(byt); i++ {
+		if byt[i] != esc {
+			stripped = append(stripped, byt[i])
+			continue
+		}
+		if i+1 >= len(byt) {
+			break // Drop a trailing ESC.
+		}
+		if byt[i+1] != '[' {
+			i++ // Two-byte sequence.
+			continue
+		}
+		// Control sequence: skip parameter and intermediate bytes up to and
+		// including the final byte.
+		i += 2
+		for i < len(byt) && (byt[i] < 0x40 || byt[i] > 0x7e) {
+			i++
+		}
+	}
+	return stripped
 }
 
-// escaper escapes some characters that will keep a stack trace from
-// being parsable / deserializable.
-var escaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, `"`, `\"`)
+// baseName returns the last element of a file path, like filepath.Base,
+// but the same on any system: backslashes are separators too if the
+// path is a Windows path (see isWindowsPath), so that traces captured
+// on Windows are formatted the same way everywhere.
+func baseName(file string) string {
+	if isWindowsPath(file) {
+		return file[strings.LastIndexAny(file, `/\`)+1:]
+	}
+	return file[strings.LastIndexByte(file, '/')+1:]
+}
 
-// unescaper unescapes characters on deserialization.
-var unescaper = strings.NewReplacer(`\t`, "\t", `\n`, "\n", `\"`, `"`, `\\`, `\`)
+// isWindowsPath reports whether the file path is a Windows path: it
+// starts with a drive letter or is a UNC path, or it is relative and
+// has backslashes but no forward slashes. Otherwise a backslash is
+// taken to be part of a file or directory name.
+func isWindowsPath(file string) bool {
+	if len(file) >= 3 && isDriveColon([]byte(file[:3]), 1) || strings.HasPrefix(file, `\\`) {
+		return true
+	}
+	return !strings.Contains(file, "/") && strings.Contains(file, `\`)
+}
 
 // getFunction gets the frame's full caller function name. Prioritizes
 // synthetic values if available, otherwise expands the pc using runtime
@@ -242,10 +390,45 @@ func FrameFromPC(pc uintptr) Frame {
 	return frameFromPC(pc)
 }
 
+// FrameFromString parses the text printed for a Frame with the `%+v`
+// verb into a synthetic Frame (see NewFrame): the function name, a
+// newline and a tab, and then the file path and line number, eg:
+//
+//	github.com/secureworks/errors.Example
+//		/src/example.go:10
+//
+// The text printed for an empty Frame ("unknown" function and file,
+// and line 0) is parsed as an empty Frame.
+func FrameFromString(str string) (Frame, error) {
+	rawFrames, err := framesFromBytes([]byte(str))
+	if err != nil {
+		return nil, err
+	}
+	if len(rawFrames) != 1 {
+		return nil, fmt.Errorf("%w: %q: must be a single frame", errMalformedFrame, str)
+	}
+	fr := rawFrames[0]
+	if fr.function == "unknown" && fr.file == "unknown" && fr.line == 0 {
+		return &frame{}, nil
+	}
+	return fr, nil
+}
+
+// FrameFromJSON parses the JSON object marshaled for a Frame into a new
+// synthetic Frame (see NewFrame).
+func FrameFromJSON(byt []byte) (Frame, error) {
+	fr := new(frame)
+	if err := fr.UnmarshalJSON(byt); err != nil {
+		return nil, err
+	}
+	return fr, nil
+}
+
 // PCFromFrame extracts the frame location program counter (pc) from
 // either this package's Frame implementation (using an unexported
-// interface), a raw uintptr (for identity), or runtime.Frame. Does not
-// distinguish between an empty or nil frame, an unsupported frame
+// interface), a raw uintptr (for identity), runtime.Frame, or a
+// github.com/pkg/errors Frame (without importing that package). Does
+// not distinguish between an empty or nil frame, an unsupported frame
 // implementation, or some other error: all return 0.
 func PCFromFrame(v interface{}) uintptr {
 	if v == nil {
@@ -259,8 +442,73 @@ func PCFromFrame(v interface{}) uintptr {
 	case programCounter:
 		return fr.PC()
 	default:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Uintptr && rv.Type().PkgPath() == "github.com/pkg/errors" {
+			return pcFromReturnAddress(uintptr(rv.Uint()))
+		}
+		return 0
+	}
+}
+
+// FramesFromPkgErrors converts a stack trace from a
+// github.com/pkg/errors error (the result of its StackTrace method) into
+// Frames, without importing that package:
+//
+//	if st, ok := err.(interface{ StackTrace() pkgerrors.StackTrace }); ok {
+//		ff := errors.FramesFromPkgErrors(st.StackTrace())
+//	}
+//
+// Any slice of uintptr-based values is accepted. Each value is taken to
+// be a return address, as collected by runtime.Callers, which is the
+// convention used by pkg/errors: the Frames resolve to the same
+// locations that pkg/errors prints. A nil or unsupported value returns
+// nil.
+func FramesFromPkgErrors(st interface{}) Frames {
+	rv := reflect.ValueOf(st)
+	if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() != reflect.Uintptr || rv.Len() == 0 {
+		return nil
+	}
+	ff := make(Frames, rv.Len())
+	for i := range ff {
+		ff[i] = frameFromPC(pcFromReturnAddress(uintptr(rv.Index(i).Uint())))
+	}
+	return ff
+}
+
+// pcFromReturnAddress returns the pc of the call instruction for a
+// return address.
+func pcFromReturnAddress(addr uintptr) uintptr {
+	if addr == 0 {
 		return 0
 	}
+	return addr - 1
+}
+
+// Location is the location described by a Frame, as a comparable value
+// that can be used as a map key:
+//
+//	counts := make(map[errors.Location]int)
+//	if origin, ok := errors.Origin(err); ok {
+//		counts[errors.LocationOf(origin)]++
+//	}
+//
+// A Frame created from a program counter and a synthetic Frame created
+// with NewFrame for the same function, file and line have equal
+// Locations. Source mappings (see AddSourceMapping) are not applied.
+type Location struct {
+	Function string
+	File     string
+	Line     int
+}
+
+// LocationOf returns the Location of the Frame. A nil Frame has the
+// zero Location.
+func LocationOf(f Frame) Location {
+	if f == nil {
+		return Location{}
+	}
+	function, file, line := f.Location()
+	return Location{Function: function, File: file, Line: line}
 }
 
 // frameFromPC creates a frame struct from a program counter.
@@ -287,6 +535,8 @@ var _ interface { // Assert interface implementation.
 	json.Marshaler
 } = (Frames)(nil)
 
+var _ json.Unmarshaler = (*Frames)(nil)
+
 func (ff Frames) Format(s fmt.State, verb rune) {
 	switch verb {
 	case 's':
@@ -309,8 +559,52 @@ func (ff Frames) Format(s fmt.State, verb rune) {
 	}
 }
 
+// Locations returns the Location of each Frame. See LocationOf.
+func (ff Frames) Locations() []Location {
+	if ff == nil {
+		return nil
+	}
+	locations := make([]Location, len(ff))
+	for i, f := range ff {
+		locations[i] = LocationOf(f)
+	}
+	return locations
+}
+
+// FramesDiff returns the frames in outer that are not in inner, in
+// order, matched by their locations (see LocationOf). It is meant for
+// an outer stack trace captured for an error that already has one, to
+// find the frames new to the outer capture: the frames at the base of
+// both stacks (their common suffix, since frames are ordered from the
+// innermost call) are dropped, and then any remaining frames of outer
+// that are in the rest of inner. Identical stacks have no difference,
+// and disjoint stacks leave all of outer.
+func FramesDiff(outer, inner Frames) Frames {
+	o, i := outer.Locations(), inner.Locations()
+	for len(o) > 0 && len(i) > 0 && o[len(o)-1] == i[len(i)-1] {
+		o, i = o[:len(o)-1], i[:len(i)-1]
+	}
+	seen := make(map[Location]bool, len(i))
+	for _, loc := range i {
+		seen[loc] = true
+	}
+	var diff Frames
+	for n, loc := range o {
+		if !seen[loc] {
+			diff = append(diff, outer[n])
+		}
+	}
+	return diff
+}
+
+// MarshalJSON marshals the Frames as a JSON array of frame objects. By
+// default, empty Frames are marshaled as `null`: use SetJSONEmptyFrames
+// to marshal them as `[]` instead.
 func (ff Frames) MarshalJSON() ([]byte, error) {
 	if len(ff) == 0 {
+		if JSONEmptyFrames(jsonEmptyFrames.Load()) == EmptyAsArray {
+			return []byte("[]"), nil
+		}
 		return []byte("null"), nil
 	}
 
@@ -338,6 +632,86 @@ func (ff Frames) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// UnmarshalJSON unmarshals a JSON array of frame objects, as marshaled
+// by MarshalJSON, into synthetic Frames (see NewFrame). This lets
+// json.Unmarshal populate Frames, eg as a field of a struct:
+//
+//	var payload struct {
+//		Message string        `json:"message"`
+//		Frames  errors.Frames `json:"frames"`
+//	}
+//	err := json.Unmarshal(byt, &payload)
+//
+// JSON `null` and `[]` are unmarshaled as nil Frames.
+func (ff *Frames) UnmarshalJSON(byt []byte) error {
+	rawFrames, err := framesFromJSON(byt)
+	if err != nil {
+		return err
+	}
+	if len(rawFrames) == 0 {
+		*ff = nil
+		return nil
+	}
+	frames := make(Frames, len(rawFrames))
+	for i, fr := range rawFrames {
+		frames[i] = fr
+	}
+	*ff = frames
+	return nil
+}
+
+// JSONEmptyFrames defines how empty Frames are marshaled as JSON.
+type JSONEmptyFrames int32
+
+const (
+	// EmptyAsNull marshals empty Frames as `null`. This is the default.
+	EmptyAsNull JSONEmptyFrames = iota
+
+	// EmptyAsArray marshals empty Frames as `[]`.
+	EmptyAsArray
+)
+
+var jsonEmptyFrames atomic.Int32
+
+// SetJSONEmptyFrames sets how empty Frames are marshaled as JSON for
+// the whole program. The default is EmptyAsNull, which marshals empty
+// Frames as `null`. FramesFromJSON accepts either.
+func SetJSONEmptyFrames(mode JSONEmptyFrames) {
+	jsonEmptyFrames.Store(int32(mode))
+}
+
+var lineRenderer atomic.Pointer[func(line int) string]
+
+// SetLineRenderer sets how the line numbers of frames are formatted
+// with the fmt verbs (see Frame) for the whole program, returning the
+// previous renderer. A nil renderer restores the default, which formats
+// line numbers as decimal numbers. Line numbers marshaled as JSON are
+// not affected.
+//
+// This is intended for tests with golden output, to render line numbers
+// as a placeholder so that the output does not change whenever the code
+// under test moves: see the errtest package, which does this for the
+// duration of a test. Text formatted with line numbers that are not
+// decimal numbers cannot be parsed by FramesFromBytes.
+func SetLineRenderer(render func(line int) string) (previous func(line int) string) {
+	var ptr *func(line int) string
+	if render != nil {
+		ptr = &render
+	}
+	if ptr = lineRenderer.Swap(ptr); ptr != nil {
+		previous = *ptr
+	}
+	return previous
+}
+
+// renderLine formats a line number as set with SetLineRenderer.
+func renderLine(line int) string {
+	if render := lineRenderer.Load(); render != nil {
+		return (*render)(line)
+	}
+	return strconv.Itoa(line)
+}
+
 // formatSlice wraps a list of formatted frames with brackets.
 func (ff Frames) formatSlice(s fmt.State, verb rune, delimiters [2]string) {
 	io.WriteString(s, delimiters[0])
@@ -353,6 +727,10 @@ func (ff Frames) formatSlice(s fmt.State, verb rune, delimiters [2]string) {
 // FramesFromBytes parses a stack trace or stack dump provided as bytes
 // into a stack of Frames. The format of the text is expected to match
 // the output of printing with a formatter using the `%+v` verb.
+//
+// Goroutine headers in dump-style text are skipped, so all the frames in
+// a goroutine dump are returned together: use GoroutineStacksFromBytes
+// to keep them apart.
 func FramesFromBytes(byt []byte) (Frames, error) {
 	rawFrames, err := framesFromBytes(byt)
 	if err != nil {
@@ -367,19 +745,13 @@ func FramesFromBytes(byt []byte) (Frames, error) {
 }
 
 // FramesFromJSON parses a stack trace or stack dump provided as
-// JSON-encoded bytes into a stack of Frames. json. Unmarshal does not
-// work because it is meant to marshal into pre-allocated items, where
-// Frames are defined only as interfaces.
+// JSON-encoded bytes into a stack of Frames. It is the same as
+// json.Unmarshal with a *Frames (see Frames.UnmarshalJSON).
 func FramesFromJSON(byt []byte) (Frames, error) {
-	rawFrames, err := framesFromJSON(byt)
-	if err != nil {
+	var ff Frames
+	if err := ff.UnmarshalJSON(byt); err != nil {
 		return nil, err
 	}
-
-	ff := make([]Frame, len(rawFrames))
-	for i, fr := range rawFrames {
-		ff[i] = fr
-	}
 	return ff, nil
 }
 
@@ -427,11 +799,14 @@ func (ff frames) Frames() Frames {
 }
 
 // StackTrace implements the stackTracer interface, returning a slice of
-// program counters.
+// program counters. Synthetic frames do not have a program counter, so
+// they are skipped: the result may be shorter than the list of frames.
 func (ff frames) StackTrace() []uintptr {
-	st := make([]uintptr, len(ff))
-	for i, f := range ff {
-		st[i] = PCFromFrame(f)
+	st := make([]uintptr, 0, len(ff))
+	for _, f := range ff {
+		if pc := PCFromFrame(f); pc != 0 {
+			st = append(st, pc)
+		}
 	}
 	return st
 }
@@ -442,6 +817,42 @@ func (ff frames) MarshalJSON() ([]byte, error) {
 
 var errIncompleteFrame = New("incomplete frame data")
 var errMalformedFrame = New("missing frame data: function name must come first")
+var errParseTooLarge = New("input too large to parse")
+var errUnmarshalSharedFrame = New("cannot unmarshal into a frame that is not empty: frames may be shared")
+
+var maxParseBytes atomic.Int64
+
+// SetMaxParseBytes sets the maximum size of the input, in bytes, that
+// is parsed by FramesFromBytes, FramesFromJSON, ErrorFromBytes and
+// ParseErrorFromBytes for the whole program. Use it when parsing
+// untrusted input. The default of 0 (or fewer) means there is no limit.
+//
+// FramesFromBytes and FramesFromJSON return an error for larger input,
+// while ErrorFromBytes and ParseErrorFromBytes parse as much of it as
+// the limit allows.
+func SetMaxParseBytes(n int) {
+	maxParseBytes.Store(int64(n))
+}
+
+// limitParseBytes truncates the input to the maximum size set with
+// SetMaxParseBytes, returning an error if it was truncated.
+func limitParseBytes(byt []byte) ([]byte, error) {
+	max := maxParseBytes.Load()
+	if max <= 0 || int64(len(byt)) <= max {
+		return byt, nil
+	}
+	return byt[:max], fmt.Errorf("%w: %d bytes is more than the limit of %d", errParseTooLarge, len(byt), max)
+}
+
+// isDriveColon reports whether the colon at the index of the file path
+// follows a Windows drive letter at the start of the path.
+func isDriveColon(file []byte, colonIdx int) bool {
+	if colonIdx != 1 || len(file) < 3 || (file[2] != '\\' && file[2] != '/') {
+		return false
+	}
+	c := file[0]
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
 
 // framesFromBytes is the underlying text (stack trace dump) parser for
 // creating synthetic frames. Expects the text to be formatted as if it
@@ -454,13 +865,22 @@ var errMalformedFrame = New("missing frame data: function name must come first")
 // context prepended directly to the stack (it may not contain any
 // newlines: *only one line allowed*).
 func framesFromBytes(byt []byte) (rawFrames []*frame, err error) {
-	byt = bytes.TrimSpace(byt)
+	if _, err = limitParseBytes(byt); err != nil {
+		return
+	}
+	byt = bytes.TrimSpace(StripANSI(byt))
 
 	// Handle empty text.
 	if len(byt) == 0 {
 		return
 	}
 
+	// Skip goroutine headers, and the blank lines between goroutines, in
+	// dump-style text.
+	if bytes.Contains(byt, []byte("goroutine ")) {
+		byt = skipGoroutineHeaders(byt)
+	}
+
 	// Check for prepended message context.
 	firstNL := bytes.IndexByte(byt, '\n')
 	firstNT := bytes.Index(byt, []byte("\n\t"))
@@ -476,9 +896,19 @@ func framesFromBytes(byt []byte) (rawFrames []*frame, err error) {
 		// second line to split on: if exists, split off the line number.
 		function := bytes.TrimSpace(lines[index])
 		file := bytes.TrimSpace(lines[index+1])
-		colonIdx := bytes.IndexByte(file, ':')
-		if colonIdx > 0 {
-			line, err = strconv.ParseInt(string(file[colonIdx+1:]), 10, 64)
+		// Split on the last colon, unless it is the colon after a Windows
+		// drive letter (eg, "C:\main.go" without a line number).
+		colonIdx := bytes.LastIndexByte(file, ':')
+		if colonIdx > 0 && !isDriveColon(file, colonIdx) {
+			lineNum := file[colonIdx+1:]
+			// Drop the program counter offset the runtime adds in dumps.
+			if spaceIdx := bytes.Index(lineNum, []byte(" +0x")); spaceIdx > 0 {
+				lineNum = lineNum[:spaceIdx]
+			}
+			line, err = strconv.ParseInt(string(lineNum), 10, 32)
+			if err == nil && line < 0 {
+				err = errors.New("must not be negative")
+			}
 			if err != nil {
 				err = fmt.Errorf(
 					"%w: %q: unparsable line number: %s",
@@ -492,20 +922,35 @@ func framesFromBytes(byt []byte) (rawFrames []*frame, err error) {
 		}
 		// Add the frame to the list and advance the index.
 		rawFrames = append(rawFrames, &frame{
-			function: unescaper.Replace(string(function)),
-			file:     unescaper.Replace(string(file)),
+			function: unescape(string(function)),
+			file:     unescape(string(file)),
 			line:     int(line),
 		})
 		index += 2
 	}
 
 	// If lines don't line up, send incomplete error with frames.
-	if index < len(lines) {
+	if err == nil && index < len(lines) {
 		err = fmt.Errorf("%w: %q", errIncompleteFrame, lines[index])
 	}
 	return
 }
 
+// skipGoroutineHeaders removes goroutine header lines from the text,
+// along with any blank lines.
+func skipGoroutineHeaders(byt []byte) []byte {
+	lines := bytes.Split(byt, []byte{'\n'})
+	kept := lines[:0]
+	for _, line := range lines {
+		trimmed := bytes.TrimSpace(line)
+		if len(trimmed) == 0 || isGoroutineHeader(trimmed) {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return bytes.Join(kept, []byte{'\n'})
+}
+
 // framesFromJSON is the underlying JSON parser for creating synthetic
 // frames from JSON.
 func framesFromJSON(byt []byte) ([]*frame, error) {
@@ -513,29 +958,40 @@ func framesFromJSON(byt []byte) ([]*frame, error) {
 		return nil, nil
 	}
 
-	type frameUnmarshaler struct {
-		Function string `json:"function"`
-		File     string `json:"file"`
-		Line     int    `json:"line"`
+	if _, err := limitParseBytes(byt); err != nil {
+		return nil, err
 	}
 
-	var rawFrames []frameUnmarshaler
+	var rawFrames []frameJSON
 	err := json.Unmarshal(byt, &rawFrames)
 	if err != nil {
 		return nil, err
 	}
+	if len(rawFrames) == 0 { // Same as null.
+		return nil, nil
+	}
 
 	frames := make([]*frame, len(rawFrames))
 	for i, fr := range rawFrames {
-		frames[i] = NewFrame(
-			unescaper.Replace(fr.Function),
-			unescaper.Replace(fr.File),
-			fr.Line,
-		).(*frame)
+		if frames[i], err = frameFromJSON(fr); err != nil {
+			return nil, err
+		}
 	}
 	return frames, nil
 }
 
+// frameFromJSON creates a synthetic frame from its JSON representation.
+func frameFromJSON(fr frameJSON) (*frame, error) {
+	if fr.Line < 0 {
+		return nil, fmt.Errorf("%w: line number must not be negative: %d", errMalformedFrame, fr.Line)
+	}
+	return &frame{
+		function: unescape(fr.Function),
+		file:     unescape(fr.File),
+		line:     fr.Line,
+	}, nil
+}
+
 // framesFromPCs turns a stack trace of program counters into Frames.
 func framesFromPCs(pcs []uintptr) Frames {
 	ff := make(Frames, len(pcs))
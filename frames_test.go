@@ -6,8 +6,8 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/secureworks/errors/internal/runtime"
 	"github.com/secureworks/errors/internal/testutils"
+	"github.com/secureworks/errors/runtimeutil"
 )
 
 // Callers to build up a call stack in tests.
@@ -50,7 +50,7 @@ var (
 
 //go:noinline
 func testGetStack() Frames {
-	st := runtime.GetStack(2) // Skip runtime.GetStack.
+	st := runtimeutil.GetStack(1) // Skip testGetStack.
 	ff := make([]Frame, len(st))
 	for i, fr := range st {
 		ff[i] = FrameFromPC(fr.PC)
@@ -60,7 +60,7 @@ func testGetStack() Frames {
 
 //go:noinline
 func testGetFrame() Frame {
-	return FrameFromPC(runtime.GetFrame(2).PC) // Skip runtime.GetFrame and errors_test.getFrame.
+	return FrameFromPC(runtimeutil.GetFrame(1).PC) // Skip testGetFrame.
 }
 
 func TestFrame(t *testing.T) {
@@ -237,7 +237,7 @@ func TestPCFromFrame(t *testing.T) {
 	testutils.AssertEqual(t, rtimeFramePC, PCFromFrame(rtimeFrame))
 
 	// Get a frame from the std lib runtime.
-	fr := runtime.GetFrame(1)
+	fr := runtimeutil.GetFrame(0)
 	testutils.AssertTrue(t, fr.PC > 0)
 	testutils.AssertEqual(t, fr.PC, PCFromFrame(fr))
 
@@ -355,6 +355,21 @@ func TestFramesMarshalJSON(t *testing.T) {
 		testutils.AssertNil(t, err)
 		testutils.AssertEqual(t, "null", string(byt))
 	})
+
+	t.Run("when empty as array", func(t *testing.T) {
+		SetJSONEmptyFrames(EmptyAsArray)
+		defer SetJSONEmptyFrames(EmptyAsNull)
+
+		byt, err := json.Marshal((Frames)(nil))
+		testutils.AssertNil(t, err)
+		testutils.AssertEqual(t, "[]", string(byt))
+
+		byt, err = json.Marshal(struct {
+			Frames Frames `json:"frames"`
+		}{})
+		testutils.AssertNil(t, err)
+		testutils.AssertEqual(t, `{"frames":[]}`, string(byt))
+	})
 }
 
 func TestFramesFromBytes(t *testing.T) {
@@ -490,6 +505,12 @@ func TestFramesFromJSON(t *testing.T) {
 		testutils.AssertNil(t, err)
 		testutils.AssertEqual(t, 0, len(ff))
 	})
+
+	t.Run("when empty array", func(t *testing.T) {
+		ff, err := FramesFromJSON([]byte("[]"))
+		testutils.AssertNil(t, err)
+		testutils.AssertEqual(t, 0, len(ff))
+	})
 }
 
 func TestFrameEscapes(t *testing.T) {
@@ -534,3 +555,275 @@ func TestFrameEscapes(t *testing.T) {
 		testutils.AssertEqual(t, 10, line)
 	})
 }
+
+func TestSetMaxParseBytes(t *testing.T) {
+	SetMaxParseBytes(32)
+	defer SetMaxParseBytes(0)
+
+	text := []byte("err\ngithub.com/secureworks/errors.Example\n\t/src/example.go:10\n")
+
+	t.Run("FramesFromBytes", func(t *testing.T) {
+		ff, err := FramesFromBytes(text[4:])
+		testutils.AssertNil(t, ff)
+		testutils.AssertTrue(t, Is(err, errParseTooLarge))
+	})
+
+	t.Run("FramesFromJSON", func(t *testing.T) {
+		ff, err := FramesFromJSON([]byte(`[{"function":"example","file":"example.go","line":10}]`))
+		testutils.AssertNil(t, ff)
+		testutils.AssertTrue(t, Is(err, errParseTooLarge))
+	})
+
+	t.Run("ParseErrorFromBytes", func(t *testing.T) {
+		err, parseErr := ParseErrorFromBytes(text)
+		testutils.AssertEqual(t, "err", err.Error())
+		testutils.AssertTrue(t, Is(parseErr, errParseTooLarge))
+	})
+}
+
+func TestFramesParseLineNumbers(t *testing.T) {
+	cases := []string{"-1", "99999999999999999999", "2147483648"}
+	for _, line := range cases {
+		t.Run(line, func(t *testing.T) {
+			_, err := FramesFromBytes([]byte("example\n\texample.go:" + line))
+			testutils.AssertTrue(t, Is(err, errMalformedFrame))
+		})
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		_, err := FramesFromJSON([]byte(`[{"function":"example","file":"example.go","line":-1}]`))
+		testutils.AssertTrue(t, Is(err, errMalformedFrame))
+	})
+}
+
+func TestLocationOf(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		testutils.AssertEqual(t, Location{}, LocationOf(nil))
+	})
+
+	t.Run("synthetic", func(t *testing.T) {
+		loc := LocationOf(NewFrame("example.Fn", "/src/example.go", 10))
+		testutils.AssertEqual(t, Location{Function: "example.Fn", File: "/src/example.go", Line: 10}, loc)
+	})
+
+	t.Run("same for program counter and synthetic frames", func(t *testing.T) {
+		pcFrame := Caller()
+		function, file, line := pcFrame.Location()
+		synthetic := NewFrame(function, file, line)
+
+		counts := make(map[Location]int)
+		counts[LocationOf(pcFrame)]++
+		counts[LocationOf(synthetic)]++
+		counts[LocationOf(FrameFromPC(PCFromFrame(pcFrame)))]++
+		testutils.AssertEqual(t, 1, len(counts))
+		testutils.AssertEqual(t, 3, counts[LocationOf(pcFrame)])
+	})
+
+	t.Run("ignores source mappings", func(t *testing.T) {
+		AddSourceMapping("/src/", "/mapped/")
+		defer ResetSourceMappings()
+		testutils.AssertEqual(t, "/src/example.go", LocationOf(NewFrame("example.Fn", "/src/example.go", 10)).File)
+	})
+}
+
+func TestFramesLocations(t *testing.T) {
+	testutils.AssertNil(t, Frames(nil).Locations())
+	ff := Frames{
+		NewFrame("example.Fn", "/src/example.go", 10),
+		NewFrame("example.Other", "/src/other.go", 20),
+	}
+	testutils.AssertEqual(t, []Location{
+		{Function: "example.Fn", File: "/src/example.go", Line: 10},
+		{Function: "example.Other", File: "/src/other.go", Line: 20},
+	}, ff.Locations())
+}
+
+func TestFrames_UnmarshalJSON(t *testing.T) {
+	ff := Frames{
+		NewFrame("github.com/secureworks/errors.Example", "/src/example.go", 10),
+		NewFrame("github.com/secureworks/errors.\"Other\"", "/src/path\twith tab.go", 20),
+	}
+	byt, err := json.Marshal(struct {
+		Message string `json:"message"`
+		Frames  Frames `json:"frames"`
+	}{"failed", ff})
+	testutils.AssertNil(t, err)
+
+	t.Run("as a field", func(t *testing.T) {
+		var payload struct {
+			Message string `json:"message"`
+			Frames  Frames `json:"frames"`
+		}
+		testutils.AssertNil(t, json.Unmarshal(byt, &payload))
+		testutils.AssertEqual(t, "failed", payload.Message)
+		testutils.AssertEqual(t, ff.Locations(), payload.Frames.Locations())
+
+		expected, _ := FramesFromJSON([]byte(`[{"function":"github.com/secureworks/errors.Example","file":"/src/example.go","line":10},{"function":"github.com/secureworks/errors.\\\"Other\\\"","file":"/src/path\\twith tab.go","line":20}]`))
+		testutils.AssertEqual(t, expected, payload.Frames)
+	})
+
+	t.Run("null and empty", func(t *testing.T) {
+		for _, raw := range []string{`{"frames":null}`, `{"frames":[]}`} {
+			payload := struct {
+				Frames Frames `json:"frames"`
+			}{Frames: ff}
+			testutils.AssertNil(t, json.Unmarshal([]byte(raw), &payload))
+			testutils.AssertEqual(t, 0, len(payload.Frames))
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		var payload struct {
+			Frames Frames `json:"frames"`
+		}
+		testutils.AssertNotNil(t, json.Unmarshal([]byte(`{"frames":{}}`), &payload))
+		testutils.AssertNotNil(t, json.Unmarshal([]byte(`{"frames":[{"function":"f","file":"f.go","line":-1}]}`), &payload))
+	})
+}
+
+func TestFrameFromString(t *testing.T) {
+	cases := []Frame{
+		NewFrame("github.com/secureworks/errors.Example", "/src/example.go", 10),
+		NewFrame("github.com/secureworks/errors.(*T).\"Quoted\"", "/src/path\twith\\tab\nand newline.go", 20),
+		NewFrame("main.main", "main.go", 0),
+		Caller(),
+	}
+	for _, fr := range cases {
+		parsed, err := FrameFromString(fmt.Sprintf("%+v", fr))
+		testutils.AssertNil(t, err)
+		testutils.AssertEqual(t, LocationOf(fr), LocationOf(parsed))
+		testutils.AssertEqual(t, fmt.Sprintf("%+v", fr), fmt.Sprintf("%+v", parsed))
+	}
+
+	t.Run("empty frame", func(t *testing.T) {
+		for _, str := range []string{"unknown\n\tunknown:0", "unknown\n\tunknown"} {
+			parsed, err := FrameFromString(str)
+			testutils.AssertNil(t, err)
+			testutils.AssertEqual(t, Location{Function: "unknown", File: "unknown"}, LocationOf(parsed))
+			testutils.AssertEqual(t, &frame{}, parsed)
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		for _, str := range []string{"", "main.main", "main.main\n\tmain.go:x", "a\n\ta.go:1\nb\n\tb.go:2"} {
+			_, err := FrameFromString(str)
+			testutils.AssertNotNil(t, err, str)
+		}
+	})
+}
+
+func TestFrameFromJSON(t *testing.T) {
+	fr := NewFrame("github.com/secureworks/errors.\"Example\"", "/src/path\twith tab.go", 10)
+	byt, err := json.Marshal(fr)
+	testutils.AssertNil(t, err)
+
+	parsed, err := FrameFromJSON(byt)
+	testutils.AssertNil(t, err)
+	testutils.AssertEqual(t, LocationOf(fr), LocationOf(parsed))
+
+	t.Run("json.Unmarshal", func(t *testing.T) {
+		target := new(frame)
+		testutils.AssertNil(t, json.Unmarshal(byt, target))
+		testutils.AssertEqual(t, LocationOf(fr), LocationOf(target))
+		testutils.AssertEqual(t, uintptr(0), PCFromFrame(target))
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		for _, str := range []string{``, `[]`, `{"line":"1"}`, `{"function":"f","file":"f.go","line":-1}`} {
+			_, err := FrameFromJSON([]byte(str))
+			testutils.AssertNotNil(t, err, str)
+		}
+	})
+}
+
+func TestFrames_windowsPaths(t *testing.T) {
+	paths := []struct {
+		name string
+		file string
+		base string
+	}{
+		{"drive", `C:\Users\me\code\main.go`, "main.go"},
+		{"drive with forward slashes", "C:/Users/me/code/main.go", "main.go"},
+		{"mixed separators", `C:\Users\me/code\sub/main.go`, "main.go"},
+		{"UNC", `\\server\share\code\main.go`, "main.go"},
+		{"relative", `code\main.go`, "main.go"},
+	}
+	for _, tt := range paths {
+		t.Run(tt.name, func(t *testing.T) {
+			fr := NewFrame("main.main", tt.file, 42)
+			testutils.AssertEqual(t, tt.base+":42", fmt.Sprintf("%s", fr))
+
+			ff, err := FramesFromBytes([]byte(fmt.Sprintf("%+v", Frames{fr, fr})))
+			testutils.AssertNil(t, err)
+			testutils.AssertEqual(t, 2, len(ff))
+			testutils.AssertEqual(t, LocationOf(fr), LocationOf(ff[0]))
+
+			parsed, ok := ErrorFromBytes([]byte(fmt.Sprintf("%+v", NewWithFrames("failed", Frames{fr}))))
+			testutils.AssertTrue(t, ok)
+			testutils.AssertEqual(t, LocationOf(fr), LocationOf(FramesFrom(parsed)[0]))
+
+			// Without a line number.
+			ff, err = FramesFromBytes([]byte(fmt.Sprintf("main.main\n\t%s", escape(tt.file))))
+			testutils.AssertNil(t, err)
+			testutils.AssertEqual(t, Location{Function: "main.main", File: tt.file}, LocationOf(ff[0]))
+		})
+	}
+
+	t.Run("unescaped text", func(t *testing.T) {
+		ff, err := FramesFromBytes([]byte("main.main\n\tC:\\Users\\me\\code\\main.go:42 +0x1d\nmain.run\n\tD:/src/run.go:7"))
+		testutils.AssertNil(t, err)
+		testutils.AssertEqual(t, []Location{
+			{Function: "main.main", File: `C:\Users\me\code\main.go`, Line: 42},
+			{Function: "main.run", File: "D:/src/run.go", Line: 7},
+		}, ff.Locations())
+	})
+
+	t.Run("malformed line numbers", func(t *testing.T) {
+		for _, file := range []string{`C:\main.go:x`, `C:\main.go:-1`, "main.go:42:x"} {
+			_, err := FramesFromBytes([]byte("main.main\n\t" + file))
+			testutils.AssertNotNil(t, err, file)
+		}
+	})
+}
+
+func TestFramesDiff(t *testing.T) {
+	fr := func(function string, line int) Frame {
+		return NewFrame("example."+function, "example.go", line)
+	}
+	var (
+		main   = fr("main", 1)
+		serve  = fr("serve", 2)
+		handle = fr("handle", 3)
+		read   = fr("read", 4)
+		worker = fr("worker", 5)
+		task   = fr("task", 6)
+	)
+
+	cases := []struct {
+		name         string
+		outer, inner Frames
+		want         Frames
+	}{
+		{"identical", Frames{handle, serve, main}, Frames{handle, serve, main}, nil},
+		{"disjoint", Frames{task, worker}, Frames{handle, serve, main}, Frames{task, worker}},
+		{"common suffix", Frames{read, handle, serve, main}, Frames{handle, serve, main}, Frames{read}},
+		{"goroutine hop", Frames{task, worker, serve, main}, Frames{read, handle, serve, main}, Frames{task, worker}},
+		{"overlap outside suffix", Frames{read, handle, worker}, Frames{handle, main}, Frames{read, worker}},
+		{"empty outer", nil, Frames{main}, nil},
+		{"empty inner", Frames{serve, main}, nil, Frames{serve, main}},
+		{"resolved locations", Frames{fr("read", 4), fr("serve", 2), fr("main", 1)}, Frames{serve, main}, Frames{read}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			testutils.AssertEqual(t, tt.want.Locations(), FramesDiff(tt.outer, tt.inner).Locations())
+		})
+	}
+
+	t.Run("runtime frames", func(t *testing.T) {
+		inner := CallStack()
+		outer := func() Frames { return CallStack() }()
+		diff := FramesDiff(outer, inner)
+		testutils.AssertEqual(t, 2, len(diff))
+		testutils.AssertEqual(t, outer[:2].Locations(), diff.Locations())
+	})
+}
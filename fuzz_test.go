@@ -0,0 +1,114 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+var fuzzSeeds = []string{
+	"",
+	"err",
+	"<nil>",
+	"err\ngithub.com/secureworks/errors.Example\n\t/src/example.go:10\n",
+	"github.com/secureworks/errors.Example\n\t/src/example.go:10\ngithub.com/secureworks/errors.Example\n\t/src/example.go:20",
+	"err\ngithub.com/secureworks/errors.Example\n\t/src/example.go:99999999999999999999999",
+	"err\ngithub.com/secureworks/errors.Example\n\t/src/example.go:-1",
+	"err\n\\\\\\t\\n\\\"\\\n\t\\:\n",
+	"err\n\xff\xfe\n\t\xff:1",
+	"\x1b[31merr\x1b[0m\n\x1b[1mgithub.com/secureworks/errors.Example\x1b[0m\n\t\x1b[2m/src/example.go:10\x1b[0m\n\x1b[",
+	"multiple errors:\n\n* error 1 of 2: a\ngithub.com/secureworks/errors.Example\n\t/src/example.go:10\n\n* error 2 of 2: b: c\n",
+	"empty errors: []",
+	"goroutine 1 [running]:\nmain.main()\n\t/src/main.go:10 +0x1d\n\ngoroutine 2 [select]:\nmain.f\n\t/src/main.go:20",
+	`[{"function":"github.com/secureworks/errors.Example","file":"/src/example.go","line":10}]`,
+	`[{"function":"\\\\t\\t","file":"\u0001","line":-10}]`,
+	`null`,
+	`[]`,
+}
+
+func FuzzFramesFromBytes(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, byt []byte) {
+		ff, err := FramesFromBytes(byt)
+		if err != nil {
+			return
+		}
+		for _, fr := range ff {
+			if _, _, line := fr.Location(); line < 0 {
+				t.Fatalf("negative line number: %d", line)
+			}
+		}
+
+		// Whatever we parse we must be able to parse again.
+		if _, err := FramesFromBytes([]byte(fmt.Sprintf("%+v", ff))); err != nil {
+			t.Fatalf("cannot parse formatted frames: %v", err)
+		}
+	})
+}
+
+func FuzzFramesFromJSON(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, byt []byte) {
+		ff, err := FramesFromJSON(byt)
+		if err != nil {
+			return
+		}
+
+		// Whatever we parse we must be able to marshal and parse again, with
+		// the same results.
+		marshaled, err := json.Marshal(ff)
+		if err != nil {
+			t.Fatalf("cannot marshal parsed frames: %v", err)
+		}
+		reparsed, err := FramesFromJSON(marshaled)
+		if err != nil {
+			t.Fatalf("cannot parse marshaled frames: %v: %s", err, marshaled)
+		}
+		if len(ff) != len(reparsed) {
+			t.Fatalf("expected %d frames, got %d", len(ff), len(reparsed))
+		}
+		for i := range ff {
+			function, file, line := ff[i].Location()
+			rfunction, rfile, rline := reparsed[i].Location()
+			if function != rfunction || file != rfile || line != rline {
+				t.Fatalf("frame %d does not round-trip: %+v != %+v", i, ff[i], reparsed[i])
+			}
+		}
+	})
+}
+
+func FuzzErrorFromBytes(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, byt []byte) {
+		err, parseErr := ParseErrorFromBytes(byt)
+		if err == nil {
+			if parseErr != nil {
+				t.Fatalf("parse error without an error: %v", parseErr)
+			}
+			return
+		}
+		_ = fmt.Sprintf("%+v", err)
+	})
+}
+
+func FuzzErrorToBytes(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, msg string) {
+		err := NewWithFrames(msg, Frames{NewFrame("github.com/secureworks/errors.Example", "/src/example.go", 10)})
+		parsed, ok := ErrorFromBytes(ErrorToBytes(err))
+		if !ok {
+			t.Fatalf("does not parse: %q", ErrorToBytes(err))
+		}
+		if parsed.Error() != msg {
+			t.Fatalf("message does not round-trip: %q != %q", parsed.Error(), msg)
+		}
+	})
+}
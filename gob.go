@@ -0,0 +1,190 @@
+package errors
+
+import (
+	"encoding/gob"
+	"strings"
+)
+
+// Encode writes the error to the gob encoder, in a form that Decode
+// reads back as an error with the same chain: each error in the chain
+// is written with its message and the locations of its frames, and the
+// errors of a multierror are written the same way. The correlation ID
+// (see WithCorrelationID) is included, as is the build info if set with
+// SetSerializeBuildInfo. A nil error is written as
+// nil.
+//
+// The errors in this package are not registered with gob, since their
+// fields are unexported: use Encode and Decode to send errors with
+// their frames between processes with gob.
+func Encode(enc *gob.Encoder, err error) error {
+	var v errorGobValue
+	if err != nil {
+		v.Error = DataFrom(err)
+		v.Build = serializedBuildInfo.Load()
+	}
+	return enc.Encode(&v)
+}
+
+// Decode reads an error written by Encode from the gob decoder. The
+// second result is any error from the decoder.
+//
+// The decoded error has the same messages, frames (see FramesFrom) and
+// multierrors as the error that was encoded, but not the same types:
+// each error in the chain is decoded as an error created by this
+// package, so errors.Is and errors.As do not find sentinel errors or
+// the error types of other packages. Frames are synthetic (see
+// NewFrame). If the build info was included, use BuildInfoFrom to get
+// it.
+func Decode(dec *gob.Decoder) (error, error) {
+	var v errorGobValue
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if v.Error == nil {
+		return nil, nil
+	}
+	err := v.Error.Err()
+	countParsed(err)
+	if v.Build != nil {
+		err = &withBuildInfo{error: err, info: *v.Build}
+	}
+	return err, nil
+}
+
+// errorGobValue is the gob representation of an error written by
+// Encode: gob cannot encode a nil pointer, so it is wrapped.
+type errorGobValue struct {
+	Error *ErrorData
+	Build *BuildInfo
+}
+
+// ErrorData is an error chain in the form that Encode writes it: each
+// error in the chain with its message and the locations of its frames,
+// and the errors of a multierror the same way. Use it to serialize
+// errors in other encodings (eg, protobuf): DataFrom returns the data of
+// an error, and ErrorData.Err the error it describes.
+type ErrorData struct {
+	Message string
+	Framed  bool         // Whether this error has frames, even if unused.
+	Frames  []Location   // The frames of this error that FramesFrom uses.
+	Multi   bool         // Whether this is a multierror of Errors.
+	Errors  []*ErrorData // The errors of a multierror.
+	Cause   *ErrorData   // The error this error wraps.
+
+	CorrelationID string // Of the chain, on its outermost error.
+}
+
+// DataFrom returns the data of the error chain, down to any multierror
+// (see SetMaxUnwrapDepth), or nil if the error is nil.
+func DataFrom(err error) *ErrorData {
+	if err == nil {
+		return nil
+	}
+
+	// Collect the chain down to any multierror, and find the deepest
+	// stack trace: if there is one, it is the only frames that FramesFrom
+	// uses.
+	var chain []error
+	trace := -1
+	for depth := 0; err != nil; err, depth = unwrapAt(err, depth), depth+1 {
+		if len(stackTraceOf(err)) > 0 {
+			trace = len(chain)
+		}
+		chain = append(chain, err)
+		if _, ok := err.(multierror); ok {
+			break
+		}
+	}
+
+	var root, v *ErrorData
+	for i, err := range chain {
+		next := &ErrorData{Message: safeError(err, 'v')}
+		if ff, ok := layerFrames(err); ok {
+			next.Framed = true
+			if trace < 0 || trace == i {
+				next.Frames = ff.Locations()
+			}
+		}
+		if merr, ok := err.(multierror); ok {
+			next.Multi = true
+			for _, member := range unwrapMulti(merr) {
+				if member != nil {
+					next.Errors = append(next.Errors, DataFrom(member))
+				}
+			}
+		}
+		if root == nil {
+			root = next
+		} else {
+			v.Cause = next
+		}
+		v = next
+	}
+	root.CorrelationID, _ = CorrelationIDFrom(chain[0])
+	return root
+}
+
+// layerFrames returns the frames of this error in the chain, not those
+// of the errors it wraps, and whether it has frames.
+func layerFrames(err error) (Frames, bool) {
+	if framesErr, ok := err.(framer); ok {
+		return framesErr.Frames(), true
+	}
+	if traceErr, ok := err.(stackTracer); ok {
+		return framesFromPCs(traceErr.StackTrace()), true
+	}
+	if trace := stackTraceOf(err); len(trace) > 0 {
+		return framesFromPCs(trace), true
+	}
+	return nil, false
+}
+
+// Err returns the error described by the data, or nil if it is nil. The
+// error has the same messages, frames and multierrors as the error the
+// data was taken from, but not the same types (see Decode).
+func (v *ErrorData) Err() error {
+	if v == nil {
+		return nil
+	}
+	var err error
+	switch {
+	case v.Multi:
+		errs := make([]error, 0, len(v.Errors))
+		for _, member := range v.Errors {
+			if member != nil {
+				errs = append(errs, member.Err())
+			}
+		}
+		list := NewMultiErrorGrouped(errs...).Error()
+		if v.Message == list || strings.HasSuffix(v.Message, ": "+list) {
+			err = multiErrorWithMessage(v.Message, errs)
+		} else {
+			// Eg, the result of fmt.Errorf with more than one %w verb.
+			err = &joinedErrors{message: v.Message, errors: errs}
+		}
+	case v.Cause != nil:
+		err = causeWithMessage(v.Message, v.Cause.Err())
+	default:
+		err = New(v.Message)
+	}
+	if v.Framed {
+		ff := make(Frames, len(v.Frames))
+		for i, loc := range v.Frames {
+			ff[i] = NewFrame(loc.Function, loc.File, loc.Line)
+		}
+		err = WithFrames(err, ff)
+	}
+	return WithCorrelationID(err, v.CorrelationID)
+}
+
+// joinedErrors implements a multierror with a message that is not the
+// list of its errors, for a decoded multierror that is not a
+// MultiError.
+type joinedErrors struct {
+	message string
+	errors  []error
+}
+
+func (e *joinedErrors) Error() string { return e.message }
+
+func (e *joinedErrors) Unwrap() []error { return e.errors }
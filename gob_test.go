@@ -0,0 +1,107 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"testing"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+func gobRoundTrip(t *testing.T, err error) error {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	testutils.AssertNil(t, Encode(gob.NewEncoder(buf), err))
+	decoded, decodeErr := Decode(gob.NewDecoder(buf))
+	testutils.AssertNil(t, decodeErr)
+	return decoded
+}
+
+// chainLength counts the errors in the chain down to any multierror.
+func chainLength(err error) (n int) {
+	for ; err != nil; err = Unwrap(err) {
+		n++
+	}
+	return
+}
+
+func TestEncodeDecode(t *testing.T) {
+	errSentinel := New("not found")
+	cases := map[string]error{
+		"new":          New("failed"),
+		"frame":        NewWithFrame("failed"),
+		"stack trace":  NewWithStackTrace("failed"),
+		"errorf":       Errorf("reading config: %w", WithFrame(errSentinel)),
+		"message":      WithMessage(WithFrame(errSentinel), "config missing"),
+		"after trace":  WithFrame(Errorf("wrapped: %w", WithStackTrace(errSentinel))),
+		"stdlib":       fmt.Errorf("wrapped: %w", NewWithFrame("failed")),
+		"multierror":   NewMultiError(NewWithFrame("a"), New("b"), Errorf("c: %w", errSentinel)),
+		"message list": WithFrame(NewMultiErrorMsg("failed", New("a"), NewWithFrame("b"))),
+		"nested multierror": Errorf("outer: %w", NewMultiErrorGrouped(
+			New("a"), NewMultiErrorGrouped(NewWithFrame("b"), New("c")))),
+		"multiple wrapped": Errorf("%w and %w", New("a"), New("b")),
+	}
+	for name, err := range cases {
+		t.Run(name, func(t *testing.T) {
+			decoded := gobRoundTrip(t, err)
+			testutils.AssertEqual(t, err.Error(), decoded.Error())
+			testutils.AssertEqual(t, FramesFrom(err).Locations(), FramesFrom(decoded).Locations())
+			testutils.AssertEqual(t, fmt.Sprintf("%+v", err), fmt.Sprintf("%+v", decoded))
+			testutils.AssertEqual(t, chainLength(err), chainLength(decoded))
+
+			all, decodedAll := FramesFromAll(err), FramesFromAll(decoded)
+			testutils.AssertEqual(t, len(all), len(decodedAll))
+			for i := range all {
+				testutils.AssertEqual(t, all[i].Locations(), decodedAll[i].Locations())
+			}
+			testutils.AssertEqual(t, len(ErrorsFrom(err)), len(ErrorsFrom(decoded)))
+		})
+	}
+
+	t.Run("sentinel errors are not preserved", func(t *testing.T) {
+		decoded := gobRoundTrip(t, Errorf("reading config: %w", errSentinel))
+		testutils.AssertFalse(t, Is(decoded, errSentinel))
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		testutils.AssertNil(t, gobRoundTrip(t, nil))
+	})
+
+	t.Run("stream of errors", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		enc := gob.NewEncoder(buf)
+		testutils.AssertNil(t, Encode(enc, New("a")))
+		testutils.AssertNil(t, Encode(enc, nil))
+		testutils.AssertNil(t, Encode(enc, NewWithFrame("b")))
+
+		dec := gob.NewDecoder(buf)
+		for _, msg := range []string{"a", "", "b"} {
+			err, decodeErr := Decode(dec)
+			testutils.AssertNil(t, decodeErr)
+			if msg == "" {
+				testutils.AssertNil(t, err)
+			} else {
+				testutils.AssertEqual(t, msg, err.Error())
+			}
+		}
+		_, decodeErr := Decode(dec)
+		testutils.AssertNotNil(t, decodeErr)
+	})
+
+	t.Run("build info", func(t *testing.T) {
+		info := BuildInfo{Path: "acme/agent", Version: "v1.4.2", Revision: "abc1234"}
+		serializedBuildInfo.Store(&info)
+		defer serializedBuildInfo.Store(nil)
+
+		decoded := gobRoundTrip(t, NewWithFrame("failed"))
+		decodedInfo, ok := BuildInfoFrom(decoded)
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, info, decodedInfo)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, decodeErr := Decode(gob.NewDecoder(bytes.NewBufferString("not gob")))
+		testutils.AssertNotNil(t, decodeErr)
+	})
+}
@@ -0,0 +1,129 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// GoroutineStack is the stack of a single goroutine, as it appears in a
+// goroutine dump (eg, from runtime.Stack with all set, or a panic with
+// GOTRACEBACK=all): a header with the goroutine's ID and state, followed
+// by its frames.
+//
+// Printing with the `%+v` verb mirrors the runtime's layout:
+//
+//	goroutine 42 [chan receive, 3 minutes]:
+//	github.com/secureworks/errors.Example
+//		/src/example.go:10
+//
+// Use GoroutineStacksFromBytes to parse a dump.
+type GoroutineStack struct {
+	ID     int
+	State  string
+	Frames Frames
+}
+
+var _ interface { // Assert interface implementation.
+	fmt.Formatter
+	json.Marshaler
+} = GoroutineStack{}
+
+func (g GoroutineStack) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		switch {
+		case s.Flag('+'):
+			fmt.Fprintf(s, "goroutine %d [%s]:", g.ID, g.State)
+			g.Frames.Format(s, verb)
+		case s.Flag('#'):
+			fmt.Fprintf(s, "errors.GoroutineStack{ID:%d, State:%q, Frames:%#v}", g.ID, g.State, g.Frames)
+		default:
+			fmt.Fprintf(s, "goroutine %d [%s]", g.ID, g.State)
+		}
+	case 's':
+		fmt.Fprintf(s, "goroutine %d [%s]", g.ID, g.State)
+	case 'q':
+		fmt.Fprintf(s, `"goroutine %d [%s]"`, g.ID, g.State)
+	default:
+		// empty
+	}
+}
+
+// MarshalJSON marshals the GoroutineStack as a JSON object:
+//
+//	{"id":42,"state":"chan receive","frames":[{"function":"..."}]}
+func (g GoroutineStack) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ID     int    `json:"id"`
+		State  string `json:"state"`
+		Frames Frames `json:"frames"`
+	}{g.ID, g.State, g.Frames})
+}
+
+var errMalformedGoroutine = New("malformed goroutine stack")
+
+// goroutineHeader matches the line that starts each goroutine's stack in
+// a dump, capturing the ID and state. Some runtime settings add details
+// (eg, "gp=0x... m=0") between the two.
+var goroutineHeader = regexp.MustCompile(`^goroutine (\d+)(?: [^\[]*)? \[([^\]]*)\]:$`)
+
+// isGoroutineHeader reports whether the line is a goroutine header.
+func isGoroutineHeader(line []byte) bool {
+	return bytes.HasPrefix(line, []byte("goroutine ")) && goroutineHeader.Match(line)
+}
+
+// GoroutineStacksFromBytes parses a goroutine dump provided as bytes
+// into a list of GoroutineStacks. Each goroutine in the text must start
+// with a header line, and is followed by its frames in the format
+// expected by FramesFromBytes. Goroutines may be separated by blank
+// lines.
+//
+// Returns the goroutines parsed so far along with an error if one is
+// encountered.
+func GoroutineStacksFromBytes(byt []byte) (stacks []GoroutineStack, err error) {
+	if _, err = limitParseBytes(byt); err != nil {
+		return nil, err
+	}
+	byt = StripANSI(byt)
+
+	var (
+		current *GoroutineStack
+		body    [][]byte
+	)
+	flush := func() error {
+		if current == nil {
+			return nil
+		}
+		ff, err := FramesFromBytes(bytes.Join(body, []byte{'\n'}))
+		current.Frames = ff
+		stacks = append(stacks, *current)
+		return err
+	}
+
+	for _, line := range bytes.Split(bytes.TrimSpace(byt), []byte{'\n'}) {
+		if m := goroutineHeader.FindSubmatch(bytes.TrimSpace(line)); m != nil {
+			if err = flush(); err != nil {
+				return stacks, err
+			}
+			id, err := strconv.Atoi(string(m[1]))
+			if err != nil {
+				return stacks, fmt.Errorf("%w: %q: unparsable goroutine ID: %s", errMalformedGoroutine, line, err)
+			}
+			current = &GoroutineStack{ID: id, State: string(m[2])}
+			body = body[:0]
+			continue
+		}
+		if current == nil {
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			return nil, fmt.Errorf("%w: %q: missing goroutine header", errMalformedGoroutine, line)
+		}
+		body = append(body, line)
+	}
+	err = flush()
+	return stacks, err
+}
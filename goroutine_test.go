@@ -0,0 +1,120 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+const goroutineDump = `goroutine 42 [chan receive, 3 minutes]:
+github.com/secureworks/errors.Example
+	/src/example.go:10 +0x1d
+main.main
+	/src/main.go:20
+
+goroutine 7 gp=0xc000007c00 m=nil [select]:
+github.com/secureworks/errors.Other
+	/src/other.go:30 +0x2f
+`
+
+func TestGoroutineStack(t *testing.T) {
+	g := GoroutineStack{
+		ID:    42,
+		State: "chan receive, 3 minutes",
+		Frames: Frames{
+			NewFrame("github.com/secureworks/errors.Example", "/src/example.go", 10),
+			NewFrame("main.main", "/src/main.go", 20),
+		},
+	}
+
+	t.Run("formats", func(t *testing.T) {
+		testutils.AssertEqual(t, "goroutine 42 [chan receive, 3 minutes]", fmt.Sprintf("%v", g))
+		testutils.AssertEqual(t, "goroutine 42 [chan receive, 3 minutes]", fmt.Sprintf("%s", g))
+		testutils.AssertEqual(t, `"goroutine 42 [chan receive, 3 minutes]"`, fmt.Sprintf("%q", g))
+		testutils.AssertEqual(t,
+			`errors.GoroutineStack{ID:42, State:"chan receive, 3 minutes", Frames:errors.Frames{example.go:10 main.go:20}}`,
+			fmt.Sprintf("%#v", g))
+		testutils.AssertEqual(t, `goroutine 42 [chan receive, 3 minutes]:
+github.com/secureworks/errors.Example
+	/src/example.go:10
+main.main
+	/src/main.go:20`, fmt.Sprintf("%+v", g))
+	})
+
+	t.Run("marshals JSON", func(t *testing.T) {
+		byt, err := json.Marshal(g)
+		testutils.AssertNil(t, err)
+		testutils.AssertEqual(t,
+			`{"id":42,"state":"chan receive, 3 minutes","frames":[`+
+				`{"function":"github.com/secureworks/errors.Example","file":"/src/example.go","line":10},`+
+				`{"function":"main.main","file":"/src/main.go","line":20}]}`,
+			string(byt))
+	})
+
+	t.Run("round trips", func(t *testing.T) {
+		stacks, err := GoroutineStacksFromBytes([]byte(fmt.Sprintf("%+v", g)))
+		testutils.AssertNil(t, err)
+		testutils.AssertEqual(t, 1, len(stacks))
+		testutils.AssertEqual(t, fmt.Sprintf("%+v", g), fmt.Sprintf("%+v", stacks[0]))
+	})
+}
+
+func TestGoroutineStacksFromBytes(t *testing.T) {
+	t.Run("parses a dump", func(t *testing.T) {
+		stacks, err := GoroutineStacksFromBytes([]byte(goroutineDump))
+		testutils.AssertNil(t, err)
+		testutils.AssertEqual(t, 2, len(stacks))
+
+		testutils.AssertEqual(t, 42, stacks[0].ID)
+		testutils.AssertEqual(t, "chan receive, 3 minutes", stacks[0].State)
+		testutils.AssertEqual(t, "[example.go:10 main.go:20]", fmt.Sprintf("%s", stacks[0].Frames))
+
+		testutils.AssertEqual(t, 7, stacks[1].ID)
+		testutils.AssertEqual(t, "select", stacks[1].State)
+		testutils.AssertEqual(t, "[other.go:30]", fmt.Sprintf("%s", stacks[1].Frames))
+	})
+
+	t.Run("parses a runtime dump", func(t *testing.T) {
+		buf := make([]byte, 1<<16)
+		buf = buf[:runtime.Stack(buf, false)]
+		stacks, err := GoroutineStacksFromBytes(buf)
+		testutils.AssertNil(t, err)
+		testutils.AssertEqual(t, 1, len(stacks))
+		testutils.AssertEqual(t, "running", stacks[0].State)
+		testutils.AssertTrue(t, strings.HasPrefix(
+			stacks[0].Frames[0].(*frame).function,
+			"github.com/secureworks/errors.TestGoroutineStacksFromBytes.func2"))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		stacks, err := GoroutineStacksFromBytes([]byte("\n"))
+		testutils.AssertNil(t, err)
+		testutils.AssertEqual(t, 0, len(stacks))
+	})
+
+	t.Run("missing header", func(t *testing.T) {
+		_, err := GoroutineStacksFromBytes([]byte("main.main\n\t/src/main.go:20\n"))
+		testutils.AssertTrue(t, Is(err, errMalformedGoroutine))
+	})
+
+	t.Run("malformed frames", func(t *testing.T) {
+		stacks, err := GoroutineStacksFromBytes([]byte("goroutine 1 [running]:\nmain.main\n\t/src/main.go:x\n"))
+		testutils.AssertTrue(t, Is(err, errMalformedFrame))
+		testutils.AssertEqual(t, 1, len(stacks))
+	})
+}
+
+func TestFramesFromBytes_goroutineHeaders(t *testing.T) {
+	ff, err := FramesFromBytes([]byte(goroutineDump))
+	testutils.AssertNil(t, err)
+	testutils.AssertEqual(t, "[example.go:10 main.go:20 other.go:30]", fmt.Sprintf("%s", ff))
+
+	err, ok := ErrorFromBytes([]byte("stalled\n" + goroutineDump))
+	testutils.AssertTrue(t, ok)
+	testutils.AssertEqual(t, "stalled", err.Error())
+	testutils.AssertEqual(t, 3, len(FramesFrom(err)))
+}
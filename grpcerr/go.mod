@@ -0,0 +1,16 @@
+module github.com/secureworks/errors/grpcerr
+
+go 1.20
+
+require (
+	github.com/secureworks/errors v0.2.1-0.20261015182823-2e706f4cf2f1
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240318140521-94a12d6c2237
+	google.golang.org/grpc v1.64.0
+)
+
+require (
+	golang.org/x/sys v0.18.0 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
+)
+
+replace github.com/secureworks/errors => ../
@@ -0,0 +1,83 @@
+// Package grpcerr carries errors from the github.com/secureworks/errors
+// package across gRPC boundaries, with their frames, as status details:
+//
+//	// On the server:
+//	return nil, grpcerr.ToStatus(err, codes.Internal).Err()
+//
+//	// On the client:
+//	_, err := client.Get(ctx, req)
+//	err = grpcerr.FromStatus(status.Convert(err))
+//	fmt.Printf("%+v", err) // Prints the frames from the server.
+//
+// The error is attached as an errdetails.DebugInfo detail, with the
+// error marshaled by errors.ToJSON as its detail, and its frames in the
+// `%+v` format as its stack entries for clients that do not use this
+// package. The frames are those returned by errors.FramesFrom, and the
+// errors of any multierror in the chain are included the same way.
+//
+// Do not send frames to clients that should not see them (eg, outside
+// your organization): they include the paths and functions of your
+// code.
+package grpcerr
+
+import (
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/secureworks/errors"
+)
+
+// ToStatus returns a status with the code and the message of the error,
+// with the error and its frames attached as a detail. If the error is
+// nil, the status has the code codes.OK and no message, the same as
+// status.Convert.
+//
+// If the detail cannot be attached, the status has no details.
+func ToStatus(err error, code codes.Code) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+	st := status.New(code, err.Error())
+	byt, jsonErr := errors.ToJSON(err)
+	if jsonErr != nil {
+		return st
+	}
+	info := &errdetails.DebugInfo{Detail: string(byt)}
+	for _, fr := range errors.FramesFrom(err) {
+		info.StackEntries = append(info.StackEntries, fmt.Sprintf("%+v", fr))
+	}
+	withDetails, detailsErr := st.WithDetails(info)
+	if detailsErr != nil {
+		return st
+	}
+	return withDetails
+}
+
+// FromStatus returns the error attached to the status by ToStatus, with
+// the same messages, frames (see FramesFrom) and multierrors as the
+// error it was created from. The frames are synthetic (see
+// errors.NewFrame), so that the error prints the frames of the server
+// with the `%+v` verb. The types of the errors in the chain are not
+// preserved.
+//
+// If the status has no error attached by ToStatus, FromStatus returns
+// an error with the message of the status. If the status is nil or has
+// the code codes.OK, it returns nil.
+func FromStatus(st *status.Status) error {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.DebugInfo)
+		if !ok {
+			continue
+		}
+		if err, ok := errors.ErrorFromJSON([]byte(info.Detail)); ok {
+			return err
+		}
+	}
+	return errors.New(st.Message())
+}
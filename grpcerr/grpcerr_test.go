@@ -0,0 +1,80 @@
+package grpcerr
+
+import (
+	"fmt"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/secureworks/errors"
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+var (
+	frMain = errors.NewFrame("main.main", "/src/svc/main.go", 10)
+	frGet  = errors.NewFrame("github.com/acme/svc.(*Server).Get", "/src/svc/server.go", 42)
+	frRead = errors.NewFrame("github.com/acme/svc/store.Read", "/src/svc/store/read.go", 30)
+)
+
+// roundTrip sends the status through its wire form, as a client would
+// receive it.
+func roundTrip(t *testing.T, st *status.Status) *status.Status {
+	t.Helper()
+	return status.Convert(status.FromProto(st.Proto()).Err())
+}
+
+func TestToStatusFromStatus(t *testing.T) {
+	cases := map[string]error{
+		"frames": errors.WithFrames(
+			fmt.Errorf("getting record: %w", errors.NewWithFrames("not found", errors.Frames{frRead})),
+			errors.Frames{frGet, frMain}),
+		"stack trace": errors.NewWithStackTrace("failed"),
+		"multierror": errors.WithFrames(errors.NewMultiError(
+			errors.NewWithFrames("a", errors.Frames{frRead}),
+			errors.New("b"),
+		), errors.Frames{frGet}),
+		"no frames": errors.New("failed"),
+	}
+	for name, err := range cases {
+		t.Run(name, func(t *testing.T) {
+			st := roundTrip(t, ToStatus(err, codes.NotFound))
+			testutils.AssertEqual(t, codes.NotFound, st.Code())
+			testutils.AssertEqual(t, err.Error(), st.Message())
+
+			decoded := FromStatus(st)
+			testutils.AssertEqual(t, err.Error(), decoded.Error())
+			testutils.AssertEqual(t, errors.FramesFrom(err).Locations(), errors.FramesFrom(decoded).Locations())
+			testutils.AssertEqual(t, fmt.Sprintf("%+v", err), fmt.Sprintf("%+v", decoded))
+			testutils.AssertEqual(t, len(errors.ErrorsFromChain(err)), len(errors.ErrorsFromChain(decoded)))
+		})
+	}
+
+	t.Run("stack entries", func(t *testing.T) {
+		err := errors.NewWithFrames("failed", errors.Frames{frGet, frMain})
+		details := ToStatus(err, codes.Internal).Details()
+		testutils.AssertEqual(t, 1, len(details))
+		testutils.AssertEqual(t, []string{
+			"github.com/acme/svc.(*Server).Get\n\t/src/svc/server.go:42",
+			"main.main\n\t/src/svc/main.go:10",
+		}, details[0].(*errdetails.DebugInfo).StackEntries)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		st := ToStatus(nil, codes.Internal)
+		testutils.AssertEqual(t, codes.OK, st.Code())
+		testutils.AssertNil(t, FromStatus(st))
+		testutils.AssertNil(t, FromStatus(nil))
+	})
+
+	t.Run("status without the detail", func(t *testing.T) {
+		err := FromStatus(status.New(codes.Unavailable, "try again"))
+		testutils.AssertEqual(t, "try again", err.Error())
+		testutils.AssertEqual(t, 0, len(errors.FramesFrom(err)))
+
+		st, detailsErr := status.New(codes.Internal, "failed").WithDetails(&errdetails.DebugInfo{Detail: "not json"})
+		testutils.AssertNil(t, detailsErr)
+		testutils.AssertEqual(t, "failed", FromStatus(st).Error())
+	})
+}
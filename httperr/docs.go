@@ -0,0 +1,31 @@
+// Package httperr writes errors as HTTP responses, with only their
+// public message in the body, while the details (eg, frames) go to the
+// logs:
+//
+//	func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+//		if err := h.serve(w, r); err != nil {
+//			httperr.Write(w, err, httperr.WithLogger(func(err error, status int, details string) {
+//				log.Printf("%s %s: %d: %s", r.Method, r.URL, status, details)
+//			}))
+//		}
+//	}
+//
+// The status of the response is set on the error chain with
+// WithStatus, or by any error in the chain with an HTTPStatus method,
+// and otherwise is 500 Internal Server Error (see WithDefaultStatus):
+//
+//	return httperr.WithStatus(errors.WithMessage(err, "no such user"), http.StatusNotFound)
+//
+// # Response body
+//
+// The body is a JSON object (see Body) with the message of the error,
+// ie the result of its Error method, so that messages hidden with
+// errors.WithMessage or errors.Mask are not sent to clients:
+//
+//	{"error":"no such user"}
+//
+// The errors of a multierror in the chain are included in the message
+// as the multierror lists them, or else as an array of objects of
+// their own (see WithMultiErrors). Frames are only included in the body
+// when asked for, eg in development (see WithFramesInBody).
+package httperr
@@ -0,0 +1,183 @@
+package httperr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/secureworks/errors"
+)
+
+// WithStatus wraps the error with the HTTP status to respond with,
+// which Write uses. If the error is nil, WithStatus returns nil.
+func WithStatus(err error, status int) error {
+	if err == nil {
+		return nil
+	}
+	return &withStatus{error: err, status: status}
+}
+
+// withStatus is an error annotated with an HTTP status.
+type withStatus struct {
+	error
+	status int
+}
+
+func (w *withStatus) Unwrap() error { return w.error }
+
+// HTTPStatus returns the HTTP status to respond with.
+func (w *withStatus) HTTPStatus() int { return w.status }
+
+func (w *withStatus) Format(s fmt.State, verb rune) {
+	fmt.Fprintf(s, fmt.FormatString(s, verb), w.error)
+}
+
+// StatusFrom returns the HTTP status of the outermost error in the
+// chain that has an HTTPStatus method (eg, one wrapped by WithStatus)
+// and returns a valid status. The second result is false if there is
+// none.
+func StatusFrom(err error) (status int, ok bool) {
+	var statusErr interface{ HTTPStatus() int }
+	if !errors.As(err, &statusErr) {
+		return 0, false
+	}
+	status = statusErr.HTTPStatus()
+	if status < 100 || status > 999 {
+		return 0, false
+	}
+	return status, true
+}
+
+// Body is the JSON body of a response written by Write.
+type Body struct {
+	// Error is the message of the error.
+	Error string `json:"error"`
+
+	// Errors are the errors of a multierror in the chain, if configured
+	// with MultiErrorsArray.
+	Errors []Body `json:"errors,omitempty"`
+
+	// Frames are the frames of the error (see errors.FramesFrom), if
+	// configured with WithFramesInBody.
+	Frames errors.Frames `json:"frames,omitempty"`
+}
+
+// MultiErrorMode sets how the errors of a multierror are included in
+// the body of a response.
+type MultiErrorMode int
+
+const (
+	// MultiErrorsJoined includes the errors of a multierror only in the
+	// message, as the multierror lists them (eg, "[err1; err2]").
+	MultiErrorsJoined MultiErrorMode = iota
+
+	// MultiErrorsArray also includes the errors of a multierror in the
+	// chain as an array, each with its own message (and frames). A
+	// multierror below a wrapper that replaces its message (eg,
+	// errors.WithMessage) is not included.
+	MultiErrorsArray
+)
+
+// Option configures Write.
+type Option func(*options)
+
+type options struct {
+	status      int
+	logger      func(err error, status int, details string)
+	frames      bool
+	multiErrors MultiErrorMode
+}
+
+// WithDefaultStatus sets the HTTP status of the response if the error
+// does not have one (see StatusFrom). It is 500 Internal Server Error
+// by default.
+func WithDefaultStatus(status int) Option {
+	return func(o *options) { o.status = status }
+}
+
+// WithLogger sets a function that Write calls with the error, the
+// status of the response and the details of the error: the error
+// formatted with the `%+v` verb, which includes its frames.
+func WithLogger(fn func(err error, status int, details string)) Option {
+	return func(o *options) { o.logger = fn }
+}
+
+// WithFramesInBody sets whether the frames of the error are included in
+// the body of the response, eg in development. They are not by
+// default, so that clients do not see the internals of the service.
+func WithFramesInBody(include bool) Option {
+	return func(o *options) { o.frames = include }
+}
+
+// WithMultiErrors sets how the errors of a multierror are included in
+// the body of the response. It is MultiErrorsJoined by default.
+func WithMultiErrors(mode MultiErrorMode) Option {
+	return func(o *options) { o.multiErrors = mode }
+}
+
+// Write writes the error as a JSON response, with the status of the
+// error or the default status, and calls the logger if one is set. If
+// the error is nil, nothing is written.
+func Write(w http.ResponseWriter, err error, opts ...Option) {
+	if err == nil {
+		return
+	}
+	o := options{status: http.StatusInternalServerError}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	status := o.status
+	if s, ok := StatusFrom(err); ok {
+		status = s
+	}
+	if o.logger != nil {
+		o.logger(err, status, fmt.Sprintf("%+v", err))
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(newBody(err, &o))
+}
+
+// newBody returns the body for the error: its message, and its frames
+// and the errors of a multierror in the chain if configured.
+func newBody(err error, o *options) Body {
+	body := Body{Error: err.Error()}
+	if o.frames {
+		body.Frames = errors.FramesFrom(err)
+	}
+	if o.multiErrors != MultiErrorsArray {
+		return body
+	}
+	merr := multiErrorFrom(err)
+	if merr == nil {
+		return body
+	}
+	for _, member := range merr.Unwrap() {
+		if member != nil {
+			body.Errors = append(body.Errors, newBody(member, o))
+		}
+	}
+	return body
+}
+
+// multiErrorFrom returns the first multierror in the chain of the error,
+// or nil if there is none. It stops at any wrapper that replaces the
+// message of the errors below it (eg, errors.WithMessage), so that the
+// body does not include messages that the wrapper hides.
+func multiErrorFrom(err error) interface{ Unwrap() []error } {
+	for err != nil {
+		if merr, ok := err.(interface{ Unwrap() []error }); ok {
+			return merr
+		}
+		next := errors.Unwrap(err)
+		if next == nil || !strings.Contains(err.Error(), next.Error()) {
+			return nil
+		}
+		err = next
+	}
+	return nil
+}
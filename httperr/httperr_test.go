@@ -0,0 +1,126 @@
+package httperr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/secureworks/errors"
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+func write(t *testing.T, err error, opts ...Option) (*httptest.ResponseRecorder, Body) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	Write(rec, err, opts...)
+	var body Body
+	testutils.AssertNil(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return rec, body
+}
+
+func TestWrite(t *testing.T) {
+	t.Run("writes the public message", func(t *testing.T) {
+		err := errors.WithMessage(errors.NewWithFrame("connection refused to db-1"), "unavailable")
+		rec, body := write(t, err)
+		testutils.AssertEqual(t, http.StatusInternalServerError, rec.Code)
+		testutils.AssertEqual(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+		testutils.AssertEqual(t, `{"error":"unavailable"}`+"\n", rec.Body.String())
+		testutils.AssertEqual(t, "unavailable", body.Error)
+	})
+
+	t.Run("status", func(t *testing.T) {
+		err := WithStatus(errors.New("no such user"), http.StatusNotFound)
+		rec, _ := write(t, errors.Errorf("lookup: %w", err))
+		testutils.AssertEqual(t, http.StatusNotFound, rec.Code)
+
+		rec, _ = write(t, errors.New("bad input"), WithDefaultStatus(http.StatusBadRequest))
+		testutils.AssertEqual(t, http.StatusBadRequest, rec.Code)
+
+		// The outermost status wins.
+		rec, _ = write(t, WithStatus(err, http.StatusForbidden))
+		testutils.AssertEqual(t, http.StatusForbidden, rec.Code)
+
+		// Invalid statuses are ignored.
+		rec, _ = write(t, WithStatus(errors.New("err"), 42))
+		testutils.AssertEqual(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	t.Run("logs the details", func(t *testing.T) {
+		err := WithStatus(errors.NewWithFrame("failed"), http.StatusConflict)
+		var logged struct {
+			err     error
+			status  int
+			details string
+		}
+		write(t, err, WithLogger(func(err error, status int, details string) {
+			logged.err, logged.status, logged.details = err, status, details
+		}))
+		testutils.AssertEqual(t, err, logged.err)
+		testutils.AssertEqual(t, http.StatusConflict, logged.status)
+		testutils.AssertEqual(t, fmt.Sprintf("%+v", err), logged.details)
+		testutils.AssertMatch(t, `^failed\n.*httperr\.TestWrite\.func3\n\t.+/httperr_test\.go:\d+$`, logged.details)
+	})
+
+	t.Run("frames in body", func(t *testing.T) {
+		err := errors.NewWithFrame("failed")
+		_, body := write(t, err)
+		testutils.AssertNil(t, body.Frames)
+
+		_, body = write(t, err, WithFramesInBody(true))
+		testutils.AssertEqual(t, 1, len(body.Frames))
+		testutils.AssertEqual(t, errors.FramesFrom(err).Locations(), body.Frames.Locations())
+	})
+
+	t.Run("multierrors", func(t *testing.T) {
+		err := errors.Errorf("batch: %w", errors.NewMultiError(
+			errors.New("a"), errors.WithMessage(errors.NewWithFrame("b internal"), "b")))
+		rec, body := write(t, err)
+		testutils.AssertEqual(t, `{"error":"batch: [a; b]"}`+"\n", rec.Body.String())
+
+		_, body = write(t, err, WithMultiErrors(MultiErrorsArray))
+		testutils.AssertEqual(t, "batch: [a; b]", body.Error)
+		testutils.AssertEqual(t, []Body{{Error: "a"}, {Error: "b"}}, body.Errors)
+
+		_, body = write(t, err, WithMultiErrors(MultiErrorsArray), WithFramesInBody(true))
+		testutils.AssertEqual(t, 0, len(body.Errors[0].Frames))
+		testutils.AssertEqual(t, 1, len(body.Errors[1].Frames))
+	})
+
+	t.Run("multierror with a replaced message", func(t *testing.T) {
+		inner := errors.NewMultiError(errors.New("db password rejected"), errors.New("b"))
+		err := WithStatus(errors.WithMessage(inner, "unavailable"), http.StatusServiceUnavailable)
+		rec, body := write(t, err, WithMultiErrors(MultiErrorsArray))
+		testutils.AssertEqual(t, `{"error":"unavailable"}`+"\n", rec.Body.String())
+		testutils.AssertEqual(t, 0, len(body.Errors))
+
+		err = errors.WithFrame(errors.WithMessage(errors.WithFrame(inner), "unavailable"))
+		_, body = write(t, err, WithMultiErrors(MultiErrorsArray))
+		testutils.AssertEqual(t, 0, len(body.Errors))
+	})
+
+	t.Run("nil writes nothing", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		Write(rec, nil)
+		testutils.AssertFalse(t, rec.Flushed)
+		testutils.AssertEqual(t, 0, rec.Body.Len())
+		testutils.AssertEqual(t, "", rec.Header().Get("Content-Type"))
+	})
+}
+
+func TestWithStatus(t *testing.T) {
+	testutils.AssertNil(t, WithStatus(nil, http.StatusNotFound))
+
+	inner := errors.NewWithFrame("not found")
+	err := WithStatus(inner, http.StatusNotFound)
+	testutils.AssertEqual(t, "not found", err.Error())
+	testutils.AssertTrue(t, errors.Is(err, inner))
+	testutils.AssertEqual(t, fmt.Sprintf("%+v", inner), fmt.Sprintf("%+v", err))
+
+	status, ok := StatusFrom(err)
+	testutils.AssertTrue(t, ok)
+	testutils.AssertEqual(t, http.StatusNotFound, status)
+	_, ok = StatusFrom(inner)
+	testutils.AssertFalse(t, ok)
+}
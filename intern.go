@@ -0,0 +1,123 @@
+package errors
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+
+	"github.com/secureworks/errors/runtimeutil"
+)
+
+// internStacksSize is the most distinct stack traces that are interned:
+// the least recently captured are forgotten first.
+const internStacksSize = 1024
+
+var (
+	internStacks atomic.Bool
+	stacks       = newStackCache(internStacksSize)
+)
+
+// InternStacks sets whether identical stack traces share their frames,
+// for the whole program. If true, the stack traces captured by this
+// package (eg, by WithStackTrace or CallStack) are looked up by their
+// program counters, and errors created with the same call stack share
+// one immutable list of frames instead of each having their own. This
+// saves memory when many errors are created on the same code path and
+// kept around (eg, in a retry queue); formatting and FramesFrom are
+// unchanged.
+//
+// The frames of the 1024 most recently captured stack traces are kept;
+// interned frames are resolved when they are first captured, rather
+// than when they are first formatted. Setting false forgets them all.
+func InternStacks(intern bool) {
+	internStacks.Store(intern)
+	if !intern {
+		stacks.reset()
+	}
+}
+
+// stackKey identifies a stack trace by its program counters.
+type stackKey struct {
+	pcs [runtimeutil.MaxStackDepth]uintptr
+	n   int
+}
+
+// stackCache is a concurrency-safe LRU cache of interned stack traces.
+type stackCache struct {
+	size int
+
+	mu     sync.Mutex
+	stacks map[stackKey]*list.Element
+	recent *list.List // Of *internedStack, most recently used first.
+}
+
+type internedStack struct {
+	key    stackKey
+	frames frames
+}
+
+func newStackCache(size int) *stackCache {
+	return &stackCache{
+		size:   size,
+		stacks: make(map[stackKey]*list.Element),
+		recent: list.New(),
+	}
+}
+
+// get returns the interned frames for the key, if any.
+func (c *stackCache) get(key *stackKey) (frames, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.stacks[*key]; ok {
+		c.recent.MoveToFront(el)
+		return el.Value.(*internedStack).frames, true
+	}
+	return nil, false
+}
+
+// add interns the frames for the key, returning the frames interned if
+// another goroutine added them first.
+func (c *stackCache) add(key *stackKey, ff frames) frames {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.stacks[*key]; ok {
+		c.recent.MoveToFront(el)
+		return el.Value.(*internedStack).frames
+	}
+	if c.recent.Len() >= c.size {
+		oldest := c.recent.Back()
+		c.recent.Remove(oldest)
+		delete(c.stacks, oldest.Value.(*internedStack).key)
+	}
+	c.stacks[*key] = c.recent.PushFront(&internedStack{key: *key, frames: ff})
+	return ff
+}
+
+func (c *stackCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stacks = make(map[stackKey]*list.Element)
+	c.recent.Init()
+}
+
+// getInternedStack is the same as getStack, but returns the interned
+// frames for the stack.
+//
+//go:noinline
+func getInternedStack(skipCallers int) frames {
+	key := new(stackKey)
+	key.n = len(runtimeutil.GetStackPCs(skipCallers-1, key.pcs[:]))
+	countFramesCaptured(key.n)
+	if ff, ok := stacks.get(key); ok {
+		return ff
+	}
+	st := runtimeutil.StackFromPCs(key.pcs[:key.n])
+	ff := make(frames, len(st))
+	for i, fr := range st {
+		ff[i] = &frame{pc: fr.PC}
+		// Resolve the frame now, since interned frames are shared and so
+		// must not be changed once they are added.
+		ff[i].Location()
+	}
+	return stacks.add(key, ff)
+}
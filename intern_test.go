@@ -0,0 +1,143 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+func newInternedError(msg string) error { return NewWithStackTrace(msg) }
+
+func TestInternStacks(t *testing.T) {
+	InternStacks(true)
+	defer InternStacks(false)
+
+	t.Run("shares frames", func(t *testing.T) {
+		var errs [2]error
+		for i := range errs {
+			errs[i] = newInternedError(fmt.Sprintf("err %d", i))
+		}
+		ff0, ff1 := errs[0].(*withStackTrace).frames, errs[1].(*withStackTrace).frames
+		testutils.AssertTrue(t, &ff0[0] == &ff1[0])
+
+		other := newInternedError("other") // A different line.
+		testutils.AssertFalse(t, &ff0[0] == &other.(*withStackTrace).frames[0])
+	})
+
+	t.Run("formatting is unchanged", func(t *testing.T) {
+		var errs [2]error
+		for i := range errs {
+			InternStacks(i == 1)
+			errs[i] = newInternedError("failed")
+		}
+		InternStacks(true)
+		err, interned := errs[0], errs[1]
+
+		testutils.AssertEqual(t, FramesFrom(err).Locations(), FramesFrom(interned).Locations())
+		testutils.AssertEqual(t, fmt.Sprintf("%+v", err), fmt.Sprintf("%+v", interned))
+		testutils.AssertEqual(t, err.(*withStackTrace).StackTrace(), interned.(*withStackTrace).StackTrace())
+	})
+
+	t.Run("bounds the cache", func(t *testing.T) {
+		c := newStackCache(2)
+		keys := make([]*stackKey, 3)
+		for i := range keys {
+			keys[i] = &stackKey{n: 1}
+			keys[i].pcs[0] = uintptr(i + 1)
+			c.add(keys[i], frames{&frame{pc: uintptr(i + 1)}})
+		}
+		testutils.AssertEqual(t, 2, len(c.stacks))
+		testutils.AssertEqual(t, 2, c.recent.Len())
+		_, ok := c.get(keys[0])
+		testutils.AssertFalse(t, ok)
+		ff, ok := c.get(keys[2])
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, uintptr(3), ff[0].pc)
+
+		// Adding the same stack again keeps the first frames.
+		testutils.AssertEqual(t, ff, c.add(keys[2], frames{&frame{pc: 3}}))
+	})
+
+	t.Run("forgets stacks when disabled", func(t *testing.T) {
+		_ = newInternedError("failed")
+		testutils.AssertNotEqual(t, 0, len(stacks.stacks))
+		InternStacks(false)
+		testutils.AssertEqual(t, 0, len(stacks.stacks))
+		InternStacks(true)
+	})
+
+	t.Run("concurrent use", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := newInternedError("failed")
+				_ = fmt.Sprintf("%+v", err)
+				_ = OriginString(err)
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+// BenchmarkInternStacks reports the heap in use by 10k errors created
+// on the same code path, with and without interning.
+func BenchmarkInternStacks(b *testing.B) {
+	for _, intern := range []bool{false, true} {
+		b.Run(fmt.Sprintf("intern=%t", intern), func(b *testing.B) {
+			InternStacks(intern)
+			defer InternStacks(false)
+			b.ReportAllocs()
+			var heap uint64
+			for i := 0; i < b.N; i++ {
+				var before, after runtime.MemStats
+				runtime.GC()
+				runtime.ReadMemStats(&before)
+				errs := make([]error, 10000)
+				for j := range errs {
+					errs[j] = newInternedError("failed")
+				}
+				runtime.GC()
+				runtime.ReadMemStats(&after)
+				heap += after.HeapAlloc - before.HeapAlloc
+				runtime.KeepAlive(errs)
+			}
+			b.ReportMetric(float64(heap)/float64(b.N), "heap-B/10k-errors")
+		})
+	}
+}
+
+func TestInternStacks_unmarshal(t *testing.T) {
+	InternStacks(true)
+	defer InternStacks(false)
+
+	err, other := newInternedError("failed"), newInternedError("failed")
+	locations := FramesFrom(other).Locations()
+	byt, jsonErr := json.Marshal(Frames{NewFrame("main.main", "/src/main.go", 10)})
+	testutils.AssertNil(t, jsonErr)
+
+	t.Run("into an interned frame", func(t *testing.T) {
+		ff := FramesFrom(err)
+		testutils.AssertNotNil(t, json.Unmarshal(byt[1:len(byt)-1], ff[0]))
+		testutils.AssertEqual(t, locations, FramesFrom(other).Locations())
+	})
+
+	t.Run("into the frames of an error", func(t *testing.T) {
+		ff := FramesFrom(err)
+		testutils.AssertNil(t, json.Unmarshal(byt, &ff))
+		testutils.AssertEqual(t, "main.main", LocationOf(ff[0]).Function)
+		testutils.AssertEqual(t, locations, FramesFrom(other).Locations())
+		testutils.AssertEqual(t, locations, FramesFrom(err).Locations())
+	})
+
+	t.Run("into a slice of frames", func(t *testing.T) {
+		ff := []Frame(FramesFrom(err))
+		testutils.AssertNotNil(t, json.Unmarshal(byt, &ff))
+		testutils.AssertEqual(t, locations, FramesFrom(other).Locations())
+	})
+}
@@ -0,0 +1,29 @@
+//go:build go1.23
+
+package errors
+
+import (
+	"iter"
+)
+
+// All returns an iterator over the errors in the MultiError, with their
+// indexes, without copying them like Unwrap:
+//
+//	for i, err := range merr.All() {
+//		// ...
+//	}
+//
+// A nil MultiError has no errors. Errors appended to the MultiError
+// while iterating are not included.
+func (merr *MultiError) All() iter.Seq2[int, error] {
+	return func(yield func(int, error) bool) {
+		if merr == nil {
+			return
+		}
+		for i, err := range merr.errors[:len(merr.errors):len(merr.errors)] {
+			if !yield(i, err) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+//go:build go1.23
+
+package errors
+
+import (
+	"testing"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+func TestMultiErrorAll(t *testing.T) {
+	merr := NewMultiError(errBasic, errSentinel, New("other"))
+
+	var errs []error
+	for i, err := range merr.All() {
+		testutils.AssertEqual(t, merr.At(i), err)
+		errs = append(errs, err)
+	}
+	testutils.AssertEqual(t, merr.Unwrap(), errs)
+
+	t.Run("break", func(t *testing.T) {
+		n := 0
+		for range merr.All() {
+			n++
+			break
+		}
+		testutils.AssertEqual(t, 1, n)
+	})
+
+	t.Run("nil and empty", func(t *testing.T) {
+		for _, merr := range []*MultiError{nil, NewMultiError()} {
+			for range merr.All() {
+				t.Fatal("unexpected error")
+			}
+		}
+	})
+}
+
+func BenchmarkMultiErrorAll(b *testing.B) {
+	errs := make([]error, 1000)
+	for i := range errs {
+		errs[i] = errBasic
+	}
+	merr := NewMultiError(errs...)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, err := range merr.All() {
+			_ = err
+		}
+	}
+}
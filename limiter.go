@@ -0,0 +1,118 @@
+package errors
+
+import (
+	"container/list"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// detailLimiterSize is the most errors a DetailLimiter keeps counts
+// for: the least recently seen are forgotten first.
+const detailLimiterSize = 1024
+
+// DetailLimiter formats recurring errors for logs, with the details
+// (the frames, as printed with the `%+v` verb) for only the first few
+// occurrences of an error in an interval. Later occurrences are printed
+// with only their message, and a note of how often the error was seen:
+//
+//	failed to connect (details suppressed, seen 412 times in 1m0s)
+//
+// Errors are recurring if they are the same (see Same). Counts are
+// kept for the 1024 most recently seen errors.
+//
+// A DetailLimiter is safe for concurrent use.
+type DetailLimiter struct {
+	window time.Duration
+	burst  int
+	now    func() time.Time
+
+	mu     sync.Mutex
+	counts map[string][]*list.Element // By the messages of the key.
+	recent *list.List                 // Of *detailCount, most recently seen first.
+}
+
+// detailCount counts an error in the current interval.
+type detailCount struct {
+	key   sameKey
+	start time.Time
+	seen  int
+}
+
+// NewDetailLimiter returns a DetailLimiter that prints the details of
+// the first burst occurrences of an error in each interval of the
+// given length, starting with the first occurrence. If burst is 0 or
+// negative the details are always suppressed.
+func NewDetailLimiter(window time.Duration, burst int) *DetailLimiter {
+	return &DetailLimiter{
+		window: window,
+		burst:  burst,
+		now:    time.Now,
+		counts: make(map[string][]*list.Element),
+		recent: list.New(),
+	}
+}
+
+// Format writes the error to the writer, with the `%+v` verb if its
+// details are not suppressed, or with its message and a note of how
+// often it was seen if they are.
+func (l *DetailLimiter) Format(w io.Writer, err error) {
+	if err == nil {
+		io.WriteString(w, "<nil>")
+		return
+	}
+	seen := l.count(err)
+	if seen <= l.burst {
+		fmt.Fprintf(w, "%+v", err)
+		return
+	}
+	fmt.Fprintf(w, "%s (details suppressed, seen %d times in %s)",
+		safeError(err, 'v'), seen, l.window)
+}
+
+// count counts an occurrence of the error, returning how many times it
+// has been seen in the current interval.
+func (l *DetailLimiter) count(err error) int {
+	key := sameKeyOf(err, SameOptions{})
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, el := range l.counts[key.messages] {
+		c := el.Value.(*detailCount)
+		if !c.key.matches(key) {
+			continue
+		}
+		l.recent.MoveToFront(el)
+		if now.Sub(c.start) >= l.window {
+			c.start, c.seen = now, 0
+		}
+		c.seen++
+		return c.seen
+	}
+	if l.recent.Len() >= detailLimiterSize {
+		l.remove(l.recent.Back())
+	}
+	el := l.recent.PushFront(&detailCount{key: key, start: now, seen: 1})
+	l.counts[key.messages] = append(l.counts[key.messages], el)
+	return 1
+}
+
+// remove removes the count of the element.
+func (l *DetailLimiter) remove(el *list.Element) {
+	l.recent.Remove(el)
+	messages := el.Value.(*detailCount).key.messages
+	els := l.counts[messages]
+	for i := range els {
+		if els[i] == el {
+			els = append(els[:i], els[i+1:]...)
+			break
+		}
+	}
+	if len(els) == 0 {
+		delete(l.counts, messages)
+		return
+	}
+	l.counts[messages] = els
+}
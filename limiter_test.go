@@ -0,0 +1,108 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+// newTestLimiter returns a DetailLimiter with a clock that is moved
+// forward with the returned function.
+func newTestLimiter(window time.Duration, burst int) (*DetailLimiter, func(time.Duration)) {
+	l := NewDetailLimiter(window, burst)
+	now := time.Unix(0, 0)
+	l.now = func() time.Time { return now }
+	return l, func(d time.Duration) { now = now.Add(d) }
+}
+
+func formatWith(l *DetailLimiter, err error) string {
+	buf := new(bytes.Buffer)
+	l.Format(buf, err)
+	return buf.String()
+}
+
+func TestDetailLimiter(t *testing.T) {
+	ff := Frames{NewFrame("github.com/secureworks/errors.Example", "/src/example.go", 10)}
+	err := NewWithFrames("failed to connect", ff)
+	detailed := fmt.Sprintf("%+v", err)
+
+	t.Run("suppresses details after the burst", func(t *testing.T) {
+		l, _ := newTestLimiter(time.Minute, 2)
+		testutils.AssertEqual(t, detailed, formatWith(l, err))
+		testutils.AssertEqual(t, detailed, formatWith(l, err))
+		testutils.AssertEqual(t, "failed to connect (details suppressed, seen 3 times in 1m0s)", formatWith(l, err))
+		testutils.AssertEqual(t, "failed to connect (details suppressed, seen 4 times in 1m0s)", formatWith(l, err))
+	})
+
+	t.Run("resets after the window", func(t *testing.T) {
+		l, advance := newTestLimiter(time.Minute, 1)
+		testutils.AssertEqual(t, detailed, formatWith(l, err))
+		advance(59 * time.Second)
+		testutils.AssertEqual(t, "failed to connect (details suppressed, seen 2 times in 1m0s)", formatWith(l, err))
+		advance(time.Second)
+		testutils.AssertEqual(t, detailed, formatWith(l, err))
+	})
+
+	t.Run("counts recurring errors together", func(t *testing.T) {
+		l, _ := newTestLimiter(time.Minute, 1)
+		testutils.AssertEqual(t, detailed, formatWith(l, err))
+		testutils.AssertEqual(t, "failed to connect (details suppressed, seen 2 times in 1m0s)",
+			formatWith(l, NewWithFrames("failed to connect", ff)))
+
+		otherOrigin := NewWithFrames("failed to connect", Frames{NewFrame("github.com/secureworks/errors.Other", "/src/other.go", 20)})
+		testutils.AssertEqual(t, fmt.Sprintf("%+v", otherOrigin), formatWith(l, otherOrigin))
+		otherMessage := NewWithFrames("failed to read", ff)
+		testutils.AssertEqual(t, fmt.Sprintf("%+v", otherMessage), formatWith(l, otherMessage))
+		testutils.AssertEqual(t, "dialing: failed to connect (details suppressed, seen 3 times in 1m0s)",
+			formatWith(l, fmt.Errorf("dialing: %w", New("failed to connect"))))
+	})
+
+	t.Run("no burst", func(t *testing.T) {
+		l, _ := newTestLimiter(time.Minute, 0)
+		testutils.AssertEqual(t, "failed to connect (details suppressed, seen 1 times in 1m0s)", formatWith(l, err))
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		l, _ := newTestLimiter(time.Minute, 1)
+		testutils.AssertEqual(t, "<nil>", formatWith(l, nil))
+	})
+
+	t.Run("bounds the counts", func(t *testing.T) {
+		l, _ := newTestLimiter(time.Minute, 1)
+		formatWith(l, err)
+		for i := 0; i < detailLimiterSize; i++ {
+			formatWith(l, New(fmt.Sprintf("err %c%c%c", 'a'+i/676, 'a'+i/26%26, 'a'+i%26)))
+		}
+		testutils.AssertEqual(t, detailLimiterSize, len(l.counts))
+		testutils.AssertEqual(t, detailLimiterSize, l.recent.Len())
+		// The first error was forgotten, so its details are printed again.
+		testutils.AssertEqual(t, detailed, formatWith(l, err))
+		testutils.AssertEqual(t, detailLimiterSize, len(l.counts))
+	})
+
+	t.Run("concurrent use", func(t *testing.T) {
+		l := NewDetailLimiter(time.Hour, 5)
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		var details int
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				out := formatWith(l, err)
+				if !strings.Contains(out, "details suppressed") {
+					mu.Lock()
+					details++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		testutils.AssertEqual(t, 5, details)
+	})
+}
@@ -0,0 +1,127 @@
+package errors
+
+import "strings"
+
+// Links splits an error chain into its links: an independent error for
+// each layer of the chain that adds message context, from the outermost
+// to the innermost, so that each can be handled (eg, counted) on its
+// own. Each link has only the message context added by its layer, and
+// the frames annotated on that layer and any wrappers directly above it
+// that only add frames:
+//
+//	err := errors.Errorf("reading config: %w", errors.NewWithFrame("open failed"))
+//	links := errors.Links(err)
+//	links[0].Error() // => "reading config"
+//	links[1].Error() // => "open failed"
+//	errors.FramesFrom(links[1]) // => the frame for NewWithFrame
+//
+// The message context of a layer is its message, less the message of
+// the error it wraps (and the ": " separating them) if it ends with it.
+// The links are new errors, so they do not reference the original chain
+// or its errors: use Unwrap to inspect the original error types.
+//
+// If a multierror is found in the chain, Links continues into each of
+// its errors in order, as if each were the rest of the chain. A
+// MultiError with a message is a link itself; otherwise frames on
+// wrappers directly above the multierror are dropped, since they do not
+// belong to any one of its errors.
+//
+// If the given error is nil, a nil slice is returned.
+func Links(err error) []error {
+	var links []error
+	appendLinks(&links, err)
+	return links
+}
+
+// FullMessage returns the message of the error chain, down to its root,
+// including the message of every error that hides the message of the
+// error it wraps (eg, using WithMessage):
+//
+//	err := errors.WithMessage(os.ErrNotExist, "failed to open customer")
+//	err.Error()             // => "failed to open customer"
+//	errors.FullMessage(err) // => "failed to open customer: file does not exist"
+//
+// An error whose message includes the message of the error it wraps
+// (eg, from Errorf) is taken as is, and an error whose message does not
+// is followed by ": " and the message of the error it wraps, so for a
+// chain of errors created with Errorf the result is the same as Error.
+// A multierror in the chain is included using its Error method. If the
+// given error is nil, an empty string is returned.
+func FullMessage(err error) string {
+	return fullMessage(err, 0)
+}
+
+func fullMessage(err error, depth int) string {
+	if err == nil {
+		return ""
+	}
+	msg := safeError(err, 'v')
+	if _, ok := err.(multierror); ok {
+		return msg
+	}
+	cause := unwrapAt(err, depth)
+	if cause == nil {
+		return msg
+	}
+	causeMsg := safeError(cause, 'v')
+	full := fullMessage(cause, depth+1)
+	if i := strings.LastIndex(msg, causeMsg); i >= 0 {
+		return msg[:i] + full + msg[i+len(causeMsg):]
+	}
+	return msg + ": " + full
+}
+
+// LinksMulti returns the links of an error chain as a MultiError. See
+// Links.
+func LinksMulti(err error) *MultiError {
+	return NewMultiError(Links(err)...)
+}
+
+// appendLinks appends the links of the error chain to the list.
+func appendLinks(links *[]error, err error) {
+	var ff Frames
+	for depth := 0; err != nil; err, depth = unwrapAt(err, depth), depth+1 {
+		if framesErr, ok := err.(framer); ok {
+			ff = prependFrame(ff, framesErr.Frames())
+		}
+
+		if merr, ok := err.(multierror); ok {
+			if mm, ok := err.(*MultiError); ok && mm.message != "" {
+				*links = append(*links, newLink(mm.message, ff))
+			}
+			for _, err := range unwrapMulti(merr) {
+				appendLinks(links, err)
+			}
+			return
+		}
+
+		msg, ok := messageContext(err, unwrapAt(err, depth))
+		if !ok {
+			continue // Only adds frames.
+		}
+		*links = append(*links, newLink(msg, ff))
+		ff = nil
+	}
+}
+
+// messageContext returns the message context added by a layer of an
+// error chain, given the error it wraps, or false if it adds none.
+func messageContext(err, cause error) (msg string, ok bool) {
+	msg = safeError(err, 'v')
+	if cause == nil {
+		return msg, true
+	}
+	causeMsg := safeError(cause, 'v')
+	if msg == causeMsg {
+		return "", false
+	}
+	return strings.TrimSuffix(msg, ": "+causeMsg), true
+}
+
+// newLink returns an error with the message and frames.
+func newLink(msg string, ff Frames) error {
+	if len(ff) == 0 {
+		return New(msg)
+	}
+	return WithFrames(New(msg), ff)
+}
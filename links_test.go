@@ -0,0 +1,140 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+func TestLinks(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		testutils.AssertNil(t, Links(nil))
+		testutils.AssertEqual(t, 0, len(LinksMulti(nil).Unwrap()))
+	})
+
+	t.Run("single error", func(t *testing.T) {
+		links := Links(errSentinel)
+		testutils.AssertEqual(t, 1, len(links))
+		testutils.AssertEqual(t, "sentinel err", links[0].Error())
+		testutils.AssertFalse(t, links[0] == errSentinel)
+	})
+
+	t.Run("splits message context", func(t *testing.T) {
+		err := fmt.Errorf("outer: %w", fmt.Errorf("middle: %w", errSentinel))
+		links := Links(err)
+		testutils.AssertEqual(t, 3, len(links))
+		testutils.AssertEqual(t, "outer", links[0].Error())
+		testutils.AssertEqual(t, "middle", links[1].Error())
+		testutils.AssertEqual(t, "sentinel err", links[2].Error())
+		for _, link := range links {
+			testutils.AssertNil(t, Unwrap(link))
+			testutils.AssertFalse(t, Is(link, errSentinel))
+		}
+	})
+
+	t.Run("keeps frames with their layer", func(t *testing.T) {
+		inner := WithFrames(New("open failed"), Frames{NewFrame("inner", "inner.go", 1)})
+		err := WithFrames(
+			fmt.Errorf("reading config: %w", inner),
+			Frames{NewFrame("outer", "outer.go", 2)},
+		)
+		err = WithFrames(err, Frames{NewFrame("outermost", "outer.go", 3)})
+
+		links := Links(err)
+		testutils.AssertEqual(t, 2, len(links))
+		testutils.AssertEqual(t, "reading config", links[0].Error())
+		testutils.AssertEqual(t, "[outer.go:2 outer.go:3]", fmt.Sprintf("%s", FramesFrom(links[0])))
+		testutils.AssertEqual(t, "open failed", links[1].Error())
+		testutils.AssertEqual(t, "[inner.go:1]", fmt.Sprintf("%s", FramesFrom(links[1])))
+	})
+
+	t.Run("works with Errorf", func(t *testing.T) {
+		err := Errorf("reading config: %w", NewWithFrame("open failed"))
+		links := Links(err)
+		testutils.AssertEqual(t, 2, len(links))
+		testutils.AssertEqual(t, "reading config", links[0].Error())
+		testutils.AssertEqual(t, 1, len(FramesFrom(links[0])))
+		testutils.AssertEqual(t, "open failed", links[1].Error())
+		testutils.AssertEqual(t, 1, len(FramesFrom(links[1])))
+	})
+
+	t.Run("keeps replaced messages", func(t *testing.T) {
+		err := fmt.Errorf("outer: %w", WithMessage(errSentinel, "masked"))
+		links := Links(err)
+		testutils.AssertEqual(t, 3, len(links))
+		testutils.AssertEqual(t, "outer", links[0].Error())
+		testutils.AssertEqual(t, "masked", links[1].Error())
+		testutils.AssertEqual(t, "sentinel err", links[2].Error())
+	})
+
+	t.Run("does not alias the chain", func(t *testing.T) {
+		ff := Frames{NewFrame("inner", "inner.go", 1)}
+		err := WithFrames(errSentinel, ff)
+		links := Links(err)
+		testutils.AssertFalse(t, FramesFrom(links[0])[0] == FramesFrom(err)[0])
+		_ = WithMessage(links[0], "masked")
+		testutils.AssertEqual(t, "sentinel err", err.Error())
+	})
+
+	t.Run("continues into multierrors", func(t *testing.T) {
+		err := fmt.Errorf("outer: %w", WithFrame(NewMultiError(
+			fmt.Errorf("a: %w", errSentinel),
+			New("b"),
+		)))
+		links := Links(err)
+		testutils.AssertEqual(t, 4, len(links))
+		testutils.AssertEqual(t, "outer", links[0].Error())
+		testutils.AssertEqual(t, "a", links[1].Error())
+		testutils.AssertEqual(t, "sentinel err", links[2].Error())
+		testutils.AssertEqual(t, "b", links[3].Error())
+		for _, link := range links {
+			testutils.AssertEqual(t, 0, len(FramesFrom(link)))
+		}
+
+		links = Links(WithFrames(NewMultiErrorMsg("2 failed", New("a"), New("b")), Frames{NewFrame("f", "f.go", 1)}))
+		testutils.AssertEqual(t, 3, len(links))
+		testutils.AssertEqual(t, "2 failed", links[0].Error())
+		testutils.AssertEqual(t, 1, len(FramesFrom(links[0])))
+	})
+
+	t.Run("as a MultiError", func(t *testing.T) {
+		merr := LinksMulti(fmt.Errorf("outer: %w", errSentinel))
+		testutils.AssertEqual(t, "[outer; sentinel err]", merr.Error())
+	})
+}
+
+func TestFullMessage(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"nil", nil, ""},
+		{"single error", errSentinel, "sentinel err"},
+		{"frames", WithStackTrace(WithFrame(errSentinel)), "sentinel err"},
+		{"replaced message", WithMessage(errSentinel, "failed to open customer"), "failed to open customer: sentinel err"},
+		{"replaced in the middle", fmt.Errorf("retry (%w) later", WithMessage(errSentinel, "masked")), "retry (masked: sentinel err) later"},
+		{"replaced messages", fmt.Errorf("outer: %w", WithFrame(WithMessage(WithMessage(errSentinel, "inner"), "middle"))), "outer: middle: inner: sentinel err"},
+		{"multierror", WithMessage(NewMultiError(New("a"), WithMessage(New("b"), "c")), "failed"), "failed: [a; c]"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			testutils.AssertEqual(t, tt.expected, FullMessage(tt.err))
+		})
+	}
+
+	t.Run("matches Errorf chains", func(t *testing.T) {
+		errs := []error{
+			fmt.Errorf("outer: %w", fmt.Errorf("middle: %w", errSentinel)),
+			Errorf("reading config: %w", WithFrame(Errorf("open: %w", errSentinel))),
+			fmt.Errorf("outer: %w: %w", errSentinel, New("other")),
+			fmt.Errorf("%w", errSentinel),
+			fmt.Errorf("%w happened", WithFrame(fmt.Errorf("[%w]", errSentinel))),
+			fmt.Errorf("no cause: %v", errSentinel),
+		}
+		for _, err := range errs {
+			testutils.AssertEqual(t, err.Error(), FullMessage(err))
+		}
+	})
+}
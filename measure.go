@@ -0,0 +1,341 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrorStats are measures of the size of an error, as returned by
+// Measure, and limits on them, as given to Clip.
+type ErrorStats struct {
+	// Depth is the most Unwrap steps from the error to any error in it,
+	// counting a step into the errors of a multierror as one.
+	Depth int
+
+	// Branches is the number of errors in the multierrors of the error,
+	// including those of multierrors nested in them.
+	Branches int
+
+	// Frames is the number of frames of all the errors in the error,
+	// counting the frames (or the stack trace) of each wrapper that has
+	// them.
+	Frames int
+
+	// MessageBytes is the length of the message of the error, in bytes.
+	MessageBytes int
+}
+
+// Measure returns the size of the error, eg to refuse errors that are
+// too large before they are serialized or logged. It traverses the whole
+// error (including the errors of multierrors) once, stopping at the
+// maximum set with SetMaxUnwrapDepth and at multierrors that contain
+// themselves. See Clip to reduce an error that is too large.
+//
+// A nil error has a size of zero.
+func Measure(err error) ErrorStats {
+	var stats ErrorStats
+	if err != nil {
+		stats.MessageBytes = len(safeError(err, 'v'))
+	}
+	measure(err, 0, nil, &stats)
+	return stats
+}
+
+// measure adds the size of the error to the stats, given the number of
+// Unwrap steps to the error and the multierrors it is in.
+func measure(err error, depth int, above []error, stats *ErrorStats) {
+	for ; err != nil; err, depth = unwrapAt(err, depth), depth+1 {
+		if err == errUnwrapDepthExceeded {
+			return
+		}
+		if depth > stats.Depth {
+			stats.Depth = depth
+		}
+		if trace := stackTraceOf(err); len(trace) > 0 {
+			stats.Frames += len(trace)
+		} else if framesErr, ok := err.(framer); ok {
+			stats.Frames += len(framesErr.Frames())
+		}
+
+		merr, ok := err.(multierror)
+		if !ok {
+			continue
+		}
+		if containsMultiError(above, err) {
+			return
+		}
+		above = append(above[:len(above):len(above)], err)
+		errs := unwrapMulti(merr)
+		stats.Branches += len(errs)
+		for _, err := range errs {
+			measure(err, depth+1, above, stats)
+		}
+		return
+	}
+}
+
+// Clip returns a copy of the error reduced to the limits, eg to keep
+// pathological errors from taking down a log pipeline:
+//
+//	err = errors.Clip(err, errors.ErrorStats{Depth: 32, Branches: 100, Frames: 500, MessageBytes: 16 << 10})
+//
+// A limit of 0 (or fewer) means there is no limit. If the error is
+// within the limits (see Measure), it is returned as is.
+//
+// Otherwise, like Opaque, the copy keeps the messages and frames of the
+// error but does not match it with As or Is: each error chain is
+// squashed into a single error with its message and frames, except that
+// the errors of multierrors are kept (as a MultiError, and clipped in
+// turn). Then frames past the limit are dropped, errors of multierrors
+// past the limit are dropped (as are whole multierrors that are too
+// deep), and messages are shortened, ending with "...". The copy prints
+// what was clipped on a final line when printed with the `%+v` verb:
+//
+//	request failed: [connection refused; connection reset by peer]
+//	main.main
+//		/path/to/main.go:10
+//	(clipped 2 levels, 30 branches, 120 frames)
+//
+// Use ClippedFrom to get what was clipped.
+func Clip(err error, limits ErrorStats) error {
+	if err == nil {
+		return nil
+	}
+	stats := Measure(err)
+	if withinLimits(stats, limits) {
+		return err
+	}
+
+	c := &clipper{limits: limits}
+	clipped := c.clip(err, 0, 0, nil)
+	if limits.MessageBytes > 0 {
+		if msg := clipped.Error(); len(msg) > limits.MessageBytes {
+			clipped.message, clipped.wrapsMessage = clipMessage(msg, limits.MessageBytes), false
+		}
+	}
+	reduced := Measure(clipped)
+	clipped.clipped = &ErrorStats{
+		Depth:        clippedBy(stats.Depth, reduced.Depth),
+		Branches:     clippedBy(stats.Branches, reduced.Branches),
+		Frames:       clippedBy(stats.Frames, reduced.Frames),
+		MessageBytes: clippedBy(stats.MessageBytes, reduced.MessageBytes),
+	}
+	return clipped
+}
+
+// ClippedFrom returns what was clipped from an error by Clip: how much
+// less deep the error is, and how many fewer branches, frames and bytes
+// of messages it has. The second result is false if the error was not
+// clipped.
+func ClippedFrom(err error) (clipped ErrorStats, ok bool) {
+	for depth := 0; err != nil; depth++ {
+		if c, ok := err.(*clippedError); ok && c.clipped != nil {
+			return *c.clipped, true
+		}
+		err = unwrapAt(err, depth)
+	}
+	return ErrorStats{}, false
+}
+
+func withinLimits(stats, limits ErrorStats) bool {
+	return (limits.Depth <= 0 || stats.Depth <= limits.Depth) &&
+		(limits.Branches <= 0 || stats.Branches <= limits.Branches) &&
+		(limits.Frames <= 0 || stats.Frames <= limits.Frames) &&
+		(limits.MessageBytes <= 0 || stats.MessageBytes <= limits.MessageBytes)
+}
+
+// clippedBy returns how much less the measure is after clipping.
+func clippedBy(before, after int) int {
+	if after > before {
+		return 0
+	}
+	return before - after
+}
+
+// containsMultiError reports whether the multierror is one of the
+// multierrors.
+func containsMultiError(merrs []error, merr error) bool {
+	for _, m := range merrs {
+		if sameMultiError(m, merr) {
+			return true
+		}
+	}
+	return false
+}
+
+// clipMessage shortens the message to at most limit bytes, ending with
+// "..." and without splitting a character.
+func clipMessage(msg string, limit int) string {
+	if limit <= 0 || len(msg) <= limit {
+		return msg
+	}
+	const ellipsis = "..."
+	n, suffix := limit-len(ellipsis), ellipsis
+	if n < 0 {
+		n, suffix = limit, ""
+	}
+	for n > 0 && !utf8.RuneStart(msg[n]) {
+		n--
+	}
+	return msg[:n] + suffix
+}
+
+// clipper builds the copy of an error returned by Clip, keeping count
+// of the branches and frames that are left of the limits.
+type clipper struct {
+	limits   ErrorStats
+	branches int
+	frames   int
+}
+
+// clip returns a copy of the error chain, given the depth of the copy
+// and the number of Unwrap steps to the error in the original, and the
+// multierrors it is in.
+func (c *clipper) clip(err error, clippedDepth, depth int, above []error) *clippedError {
+	msg := safeError(err, 'v')
+	clipped := &clippedError{
+		message: msg,
+		frames:  c.clipFrames(FramesFrom(err)),
+	}
+
+	for ; err != nil; err, depth = unwrapAt(err, depth), depth+1 {
+		if _, ok := err.(multierror); ok {
+			break
+		}
+	}
+	// The errors of a multierror in the chain are two levels down in the
+	// copy, under a MultiError.
+	merr, ok := err.(multierror)
+	if !ok || (c.limits.Depth > 0 && clippedDepth+2 > c.limits.Depth) || containsMultiError(above, err) {
+		clipped.message = clipMessage(msg, c.limits.MessageBytes)
+		return clipped
+	}
+	above = append(above[:len(above):len(above)], err)
+
+	branches := &MultiError{}
+	for _, err := range unwrapMulti(merr) {
+		if c.limits.Branches > 0 && c.branches >= c.limits.Branches {
+			break
+		}
+		c.branches++
+		branches.errors = append(branches.errors, c.clip(err, clippedDepth+2, depth+1, above))
+	}
+	if len(branches.errors) > 0 {
+		clipped.error = branches
+		if prefix, ok := strings.CutSuffix(msg, safeError(err, 'v')); ok {
+			clipped.message, clipped.wrapsMessage = prefix, true
+		}
+	}
+
+	clipped.message = clipMessage(clipped.message, c.limits.MessageBytes)
+	return clipped
+}
+
+// clipFrames returns as many of the frames as are left of the limit.
+func (c *clipper) clipFrames(ff Frames) Frames {
+	if c.limits.Frames > 0 && len(ff) > c.limits.Frames-c.frames {
+		ff = ff[:c.limits.Frames-c.frames]
+	}
+	c.frames += len(ff)
+	if len(ff) == 0 {
+		return nil
+	}
+	return ff
+}
+
+// clippedError implements the copy of an error chain returned by Clip,
+// with its message and frames, and the clipped errors of a multierror
+// in the chain.
+type clippedError struct {
+	message string
+	frames  Frames
+	error   *MultiError // Nil if there are none.
+
+	// wrapsMessage is whether the message is followed by the message of
+	// the multierror, as with `fmt.Errorf("...: %w", merr)`.
+	wrapsMessage bool
+
+	// clipped is what was clipped, for the copy of the whole error.
+	clipped *ErrorStats
+
+	formatGuard
+}
+
+var _ interface { // Assert interface implementation.
+	error
+	framer
+	Unwrap() error
+	fmt.Formatter
+} = (*clippedError)(nil)
+
+func (w *clippedError) Error() string {
+	if w.wrapsMessage {
+		return w.message + w.error.Error()
+	}
+	return w.message
+}
+
+func (w *clippedError) Unwrap() error {
+	if w.error == nil {
+		return nil
+	}
+	return w.error
+}
+
+// Frames returns the frames that were kept of the error chain.
+func (w *clippedError) Frames() Frames { return w.frames }
+
+func (w *clippedError) Format(s fmt.State, verb rune) {
+	if !w.enterFormat(s, verb) {
+		return
+	}
+	defer w.leaveFormat()
+
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			io.WriteString(s, w.Error())
+			formatFrames(s, verb, w)
+			if w.clipped != nil {
+				io.WriteString(s, "\n(clipped "+w.clipped.clippedString()+")")
+			}
+			formatBranches(s, w.Unwrap())
+			writeAnnotations(s, w)
+			return
+		}
+		if s.Flag('#') {
+			fmt.Fprintf(s, "&errors.clippedError{%q}", w.Error())
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, w.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", w.Error())
+	default:
+		// empty
+	}
+}
+
+// clippedString returns the measures that were clipped, eg "2 levels,
+// 120 frames", leaving out those that were not.
+func (s ErrorStats) clippedString() string {
+	var parts []string
+	for _, part := range []struct {
+		n    int
+		unit string
+	}{
+		{s.Depth, "levels"},
+		{s.Branches, "branches"},
+		{s.Frames, "frames"},
+		{s.MessageBytes, "message bytes"},
+	} {
+		if part.n > 0 {
+			parts = append(parts, strconv.Itoa(part.n)+" "+part.unit)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
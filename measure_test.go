@@ -0,0 +1,167 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+func TestMeasure(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		testutils.AssertEqual(t, ErrorStats{}, Measure(nil))
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		err := fmt.Errorf("b: %w", NewWithFrame("a"))
+		testutils.AssertEqual(t, ErrorStats{Depth: 2, Frames: 1, MessageBytes: 4}, Measure(err))
+	})
+
+	t.Run("stack trace", func(t *testing.T) {
+		err := NewWithStackTrace("a")
+		testutils.AssertEqual(t, len(FramesFrom(err)), Measure(err).Frames)
+	})
+
+	t.Run("multierror", func(t *testing.T) {
+		err := Join(NewWithFrame("a"), fmt.Errorf("c: %w", Join(New("d"), New("e"))))
+		testutils.AssertEqual(t, ErrorStats{
+			Depth:        3,
+			Branches:     4,
+			Frames:       1,
+			MessageBytes: len(err.Error()),
+		}, Measure(err))
+	})
+
+	t.Run("cyclic multierror", func(t *testing.T) {
+		err := &cyclicMultiError{errs: []error{New("a")}}
+		err.errs = append(err.errs, err)
+		testutils.AssertEqual(t, ErrorStats{Depth: 1, Branches: 2, MessageBytes: 6}, Measure(err))
+	})
+
+	t.Run("cyclic chain", func(t *testing.T) {
+		SetMaxUnwrapDepth(10)
+		t.Cleanup(func() { SetMaxUnwrapDepth(0) })
+		cyclic := &cyclicError{}
+		cyclic.next = cyclic
+		testutils.AssertEqual(t, Depth(cyclic), Measure(cyclic).Depth)
+	})
+}
+
+// enormousError returns a deep error chain, with frames, over a
+// multierror with many errors with long messages and stack traces, and
+// a deep chain of nested multierrors.
+func enormousError() error {
+	var errs []error
+	for i := 0; i < 500; i++ {
+		errs = append(errs, NewWithStackTrace(fmt.Sprintf("%d: %s", i, strings.Repeat("x", 4096))))
+	}
+	var nested error = New("innermost")
+	for i := 0; i < 100; i++ {
+		nested = Join(New(fmt.Sprintf("sibling %d", i)), fmt.Errorf("nested %d: %w", i, nested))
+	}
+	errs = append(errs, nested)
+
+	err := Join(errs...)
+	for i := 0; i < 300; i++ {
+		err = WithFrame(fmt.Errorf("layer %d: %w", i, err))
+	}
+	return err
+}
+
+func TestClip(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		testutils.AssertNil(t, Clip(nil, ErrorStats{Depth: 1}))
+	})
+
+	t.Run("within limits", func(t *testing.T) {
+		err := fmt.Errorf("b: %w", NewWithFrame("a"))
+		testutils.AssertEqual(t, err, Clip(err, ErrorStats{}))
+		testutils.AssertEqual(t, err, Clip(err, ErrorStats{Depth: 2, Frames: 1, MessageBytes: 4}))
+		_, ok := ClippedFrom(err)
+		testutils.AssertFalse(t, ok)
+	})
+
+	t.Run("enormous error", func(t *testing.T) {
+		err := enormousError()
+		limits := ErrorStats{Depth: 8, Branches: 50, Frames: 100, MessageBytes: 1024}
+		stats := Measure(err)
+		testutils.AssertTrue(t, stats.Depth > limits.Depth)
+		testutils.AssertTrue(t, stats.Branches > limits.Branches)
+		testutils.AssertTrue(t, stats.Frames > limits.Frames)
+		testutils.AssertTrue(t, stats.MessageBytes > limits.MessageBytes)
+
+		clipped := Clip(err, limits)
+		reduced := Measure(clipped)
+		testutils.AssertTrue(t, reduced.Depth <= limits.Depth)
+		testutils.AssertEqual(t, limits.Branches, reduced.Branches)
+		testutils.AssertEqual(t, limits.Frames, reduced.Frames)
+		testutils.AssertEqual(t, limits.MessageBytes, reduced.MessageBytes)
+		testutils.AssertTrue(t, strings.HasSuffix(clipped.Error(), "..."))
+
+		dropped, ok := ClippedFrom(clipped)
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, ErrorStats{
+			Depth:        stats.Depth - reduced.Depth,
+			Branches:     stats.Branches - reduced.Branches,
+			Frames:       stats.Frames - reduced.Frames,
+			MessageBytes: stats.MessageBytes - reduced.MessageBytes,
+		}, dropped)
+		testutils.AssertMatch(t,
+			`\n\(clipped \d+ levels, \d+ branches, \d+ frames, \d+ message bytes\)$`,
+			fmt.Sprintf("%+v", clipped),
+		)
+		testutils.AssertFalse(t, Is(clipped, err))
+	})
+
+	t.Run("branches", func(t *testing.T) {
+		err := fmt.Errorf("request failed: %w", Join(New("refused"), New("reset"), New("timeout")))
+		clipped := Clip(err, ErrorStats{Branches: 2})
+		testutils.AssertEqual(t, "request failed: [refused; reset]", clipped.Error())
+		testutils.AssertEqual(t, []string{"refused", "reset"}, errorStrings(ErrorsFrom(Unwrap(clipped))))
+
+		dropped, ok := ClippedFrom(clipped)
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, ErrorStats{Branches: 1, MessageBytes: len("; timeout")}, dropped)
+	})
+
+	t.Run("depth", func(t *testing.T) {
+		err := Join(New("a"), fmt.Errorf("x: %w", Join(New("b"), New("c"))))
+		clipped := Clip(err, ErrorStats{Depth: 2})
+		testutils.AssertEqual(t, "[a; x: [b; c]]", clipped.Error())
+		testutils.AssertEqual(t, ErrorStats{Depth: 2, Branches: 2, MessageBytes: 14}, Measure(clipped))
+
+		dropped, ok := ClippedFrom(clipped)
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, ErrorStats{Depth: 1, Branches: 2}, dropped)
+	})
+
+	t.Run("frames", func(t *testing.T) {
+		err := Errorf("wrapped: %w", NewWithStackTrace("a"))
+		clipped := Clip(err, ErrorStats{Frames: 1})
+		testutils.AssertEqual(t, "wrapped: a", clipped.Error())
+		testutils.AssertEqual(t, FramesFrom(err)[:1], FramesFrom(clipped))
+		testutils.AssertMatch(t, `^wrapped: a\n.+\n\t.+:\d+\n\(clipped \d+ levels, \d+ frames\)$`, fmt.Sprintf("%+v", clipped))
+	})
+
+	t.Run("messages", func(t *testing.T) {
+		clipped := Clip(New(strings.Repeat("é", 10)), ErrorStats{MessageBytes: 8})
+		testutils.AssertEqual(t, "éé...", clipped.Error())
+		clipped = Clip(New("abcdef"), ErrorStats{MessageBytes: 2})
+		testutils.AssertEqual(t, "ab", clipped.Error())
+	})
+
+	t.Run("cyclic multierror", func(t *testing.T) {
+		err := &cyclicMultiError{errs: []error{New("a")}}
+		err.errs = append(err.errs, err)
+		clipped := Clip(err, ErrorStats{Branches: 1})
+		testutils.AssertEqual(t, "[a]", clipped.Error())
+	})
+}
+
+func errorStrings(errs []error) (strs []string) {
+	for _, err := range errs {
+		strs = append(strs, err.Error())
+	}
+	return
+}
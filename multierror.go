@@ -9,6 +9,12 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
 )
 
 // A simple interface for identifying an error wrapper for multiple
@@ -30,7 +36,8 @@ type multierror interface {
 //
 // MultiError includes helpers for managing groups of errors using Go
 // patterns. They are not synchronized for concurrent use, but can be
-// used in concurrent code if the user manages synchronization.
+// used in concurrent code if the user manages synchronization, or use
+// a SafeMultiError to collect errors from goroutines.
 //
 // The MultiError pattern is for top-level collection of error groups
 // only, and are flattened when appended: no errors contained in its
@@ -39,6 +46,10 @@ type multierror interface {
 // they are progressively built). This flattening is not recursive,
 // however: if a multierror is wrapped inside another error, it is not
 // flattened, since this could cause us to lose information or context.
+// Use NewMultiErrorGrouped to build a MultiError without flattening.
+//
+// A MultiError may also have a message, to summarize its errors: see
+// NewMultiErrorMsg.
 //
 // Unlike some error collection / multiple-error packages, we rely on an
 // exported MultiError type to make it obvious how it should be handled
@@ -62,7 +73,10 @@ type multierror interface {
 // For simple error-joining, use Append or AppendInto, which only speak
 // in the error interface.
 type MultiError struct {
-	errors []error
+	errors  []error
+	message string
+
+	formatGuard
 }
 
 var _ interface { // Assert interface implementation.
@@ -76,15 +90,21 @@ var _ interface { // Assert interface implementation.
 // than the number of errors passed to the function.
 //
 // If any of the given errors is a multierror, it is flattened into the
-// new MultiError, allowing "append-like" behavior.
+// new MultiError, allowing "append-like" behavior. If the first error is
+// a MultiError with a message, the new MultiError keeps its message.
 func NewMultiError(errs ...error) (merr *MultiError) {
 	merr = new(MultiError)
+	if len(errs) > 0 {
+		if mm, ok := errs[0].(*MultiError); ok && mm != nil {
+			merr.message = mm.message
+		}
+	}
 	for _, err := range errs {
 		if err == nil {
 			continue
 		}
 		if mm, ok := err.(multierror); ok {
-			for _, err := range mm.Unwrap() {
+			for _, err := range unwrapMulti(mm) {
 				if err == nil { // Sanity check: we don't know the implementation.
 					continue
 				}
@@ -97,12 +117,64 @@ func NewMultiError(errs ...error) (merr *MultiError) {
 	return
 }
 
+// NewMultiErrorMsg returns a MultiError from a group of errors, like
+// NewMultiError, with a message that summarizes them. The message is
+// the start of the error message, followed by a colon and the list of
+// error messages:
+//
+//	merr := errors.NewMultiErrorMsg("2 of 7 uploads failed", err1, err2)
+//	merr.Error() // => "2 of 7 uploads failed: [err1; err2]"
+//
+// When printed with the `%+v` verb, the message is the header of the
+// list of errors instead of "multiple errors".
+//
+// The message is kept when the MultiError is the destination of
+// Append, AppendInto or NewMultiError, and by ErrorOrNil even if there
+// is only one error.
+func NewMultiErrorMsg(msg string, errs ...error) (merr *MultiError) {
+	merr = NewMultiError(errs...)
+	merr.message = msg
+	return
+}
+
+// NewMultiErrorGrouped returns a MultiError from a group of errors,
+// like NewMultiError, except that any multierror given is not
+// flattened: it is kept as a single error in the new MultiError, so
+// the grouping is preserved. Nil error values are not included.
+//
+//	merr := errors.NewMultiErrorGrouped(stage1Err, stage2Err)
+//	len(merr.Unwrap()) // 2, even if each stage returned many errors.
+func NewMultiErrorGrouped(errs ...error) (merr *MultiError) {
+	merr = new(MultiError)
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		merr.errors = append(merr.errors, err)
+	}
+	return
+}
+
 func (merr *MultiError) Error() string {
 	buf := new(bytes.Buffer)
+	io.WriteString(buf, merr.prefix())
 	formatMessages(buf, merr, [2]string{"[", "]"})
 	return buf.String()
 }
 
+// Message returns the message given to NewMultiErrorMsg, if any.
+func (merr *MultiError) Message() string {
+	return merr.message
+}
+
+// prefix returns the message formatted to precede the list of errors.
+func (merr *MultiError) prefix() string {
+	if merr.message == "" {
+		return ""
+	}
+	return merr.message + ": "
+}
+
 // Unwrap returns the underlying value of the MultiError: a slice of
 // errors. It returns a nil slice if the error is nil or has no errors.
 //
@@ -113,10 +185,149 @@ func (merr *MultiError) Error() string {
 //		// ...
 //	}
 //
-// Do not modify the returned errors and expect the MultiError to remain
-// stable.
+// The returned slice is a copy, so callers are free to modify it (eg,
+// sort it for display) without affecting the MultiError, but
+// modifications to the errors themselves may race.
 func (merr *MultiError) Unwrap() []error {
-	return merr.errors
+	if len(merr.errors) == 0 {
+		return nil
+	}
+	errs := make([]error, len(merr.errors))
+	copy(errs, merr.errors)
+	return errs
+}
+
+// Len returns the number of errors in the MultiError, without copying
+// them like Unwrap. A nil MultiError has none.
+func (merr *MultiError) Len() int {
+	if merr == nil {
+		return 0
+	}
+	return len(merr.errors)
+}
+
+// At returns the i-th error in the MultiError, without copying them like
+// Unwrap. It panics if i is out of range, like indexing a slice:
+//
+//	for i := 0; i < merr.Len(); i++ {
+//		inspect(merr.At(i))
+//	}
+//
+// On Go 1.23 and later, All returns an iterator over the errors.
+func (merr *MultiError) At(i int) error {
+	return merr.errors[i]
+}
+
+// First returns the first error in the MultiError, or nil if it has
+// none (or is nil).
+func (merr *MultiError) First() error {
+	if merr.Len() == 0 {
+		return nil
+	}
+	return merr.errors[0]
+}
+
+// Last returns the last error in the MultiError, or nil if it has none
+// (or is nil).
+func (merr *MultiError) Last() error {
+	if merr.Len() == 0 {
+		return nil
+	}
+	return merr.errors[len(merr.errors)-1]
+}
+
+// Sequential returns a view of the MultiError for code that walks error
+// chains with the single-error Unwrap, which returns nil for the
+// MultiError itself since it only implements Unwrap() []error. Calling
+// Unwrap on the view returns an error standing for each error in the
+// MultiError in turn, and then nil, so that a loop like this one visits
+// all of them:
+//
+//	for err := merr.Sequential(); err != nil; err = errors.Unwrap(err) {
+//		log.Print(err) // The MultiError, then each of its errors.
+//	}
+//
+// The view has the message of the MultiError, and each error after it
+// has the message of the error it stands for. They are printed like
+// those errors too, including with the `%+v` verb. They are not those
+// errors, however, so code that checks the type of each error of the
+// chain does not find them: use As instead, since each error after the
+// view matches what the error it stands for (or any error in its chain)
+// matches with As and Is. The view itself matches the MultiError with
+// Is. So Is and As have the same results for the view as they do for
+// the MultiError, except that the view does not match *MultiError with
+// As.
+//
+// FramesFrom, Origin and HasFrames treat each error after the view as
+// the error it stands for, and so return the frames of the first error
+// in the MultiError for the view, as they would for the chain of the
+// first error: they do not continue with the other errors.
+//
+// The view has the errors the MultiError had when it was created. It is
+// nil if there were none.
+func (merr *MultiError) Sequential() error {
+	if merr.Len() == 0 {
+		return nil
+	}
+	return &sequentialError{merr: merr, errs: merr.errors, i: -1}
+}
+
+// sequentialError implements the view of a MultiError returned by
+// Sequential: the view itself, if i is -1, or the error standing for
+// the i-th error of the MultiError.
+type sequentialError struct {
+	merr *MultiError
+	errs []error
+	i    int
+}
+
+// current returns the error that the sequentialError stands for.
+func (w *sequentialError) current() error {
+	if w.i < 0 {
+		return w.merr
+	}
+	return w.errs[w.i]
+}
+
+func (w *sequentialError) Error() string { return w.current().Error() }
+
+func (w *sequentialError) Unwrap() error {
+	if w.i+1 >= len(w.errs) {
+		return nil
+	}
+	return &sequentialError{merr: w.merr, errs: w.errs, i: w.i + 1}
+}
+
+// Is matches what the error it stands for matches, or the MultiError
+// for the view itself.
+func (w *sequentialError) Is(target error) bool {
+	if w.i < 0 {
+		return target == error(w.merr)
+	}
+	return Is(w.errs[w.i], target)
+}
+
+// As matches what the error it stands for matches, except for the view
+// itself.
+func (w *sequentialError) As(target interface{}) bool {
+	if w.i < 0 {
+		return false
+	}
+	return As(w.errs[w.i], target)
+}
+
+func (w *sequentialError) Format(s fmt.State, verb rune) {
+	fmt.Fprintf(s, fmt.FormatString(s, verb), w.current())
+}
+
+// sequentialMember returns the error of a MultiError that an error in
+// its Sequential view stands for, if it is one, so that the frames of
+// the view are those of the error. Otherwise it returns the error.
+func sequentialMember(err error) error {
+	if w, ok := err.(*sequentialError); ok && w.i >= 0 {
+		return w.errs[w.i]
+	}
+	return err
 }
 
 // Errors is the version v0.1 interface for multierrors. This pre-dated
@@ -130,8 +341,14 @@ func (merr *MultiError) Errors() []error {
 
 // ErrorOrNil is used to get a clean error interface for reflection, nil
 // checking and other comparisons. If the MultiError is empty it returns
-// nil, and if there is a single error then it is unnested. Otherwise,
-// it returns a MultiError retyped for the error interface.
+// nil, and if there is a single error then it is unnested (unless the
+// MultiError has a message). Otherwise, it returns a MultiError retyped
+// for the error interface.
+//
+// Since a single error is unnested, whether the result is a MultiError
+// depends on how many errors there are: use AsError if callers expect
+// a MultiError (eg, with errors.As) however many errors there are.
+// Append, Join and JoinWrapped unnest single errors like ErrorOrNil.
 //
 // Retrieving the MultiError is simple, since NewMultiError flattens
 // MultiErrors passed to it:
@@ -143,56 +360,459 @@ func (merr *MultiError) ErrorOrNil() error {
 	if len(merr.errors) == 0 {
 		return nil
 	}
-	if len(merr.errors) == 1 {
+	if len(merr.errors) == 1 && merr.message == "" {
 		return merr.errors[0]
 	}
 	return merr
 }
 
+// AsError returns the MultiError retyped for the error interface, or
+// nil if it is empty. Unlike ErrorOrNil, a single error is not
+// unnested, so the result is always a MultiError if it is not nil:
+//
+//	err := errors.NewMultiError(e1).AsError()
+//	var merr *errors.MultiError
+//	errors.As(err, &merr) // => true
+func (merr *MultiError) AsError() error {
+	if len(merr.errors) == 0 {
+		return nil
+	}
+	return merr
+}
+
+// Filter returns a new MultiError of the errors for which pred returns
+// true, with the same message, or an empty MultiError if it is nil. The
+// MultiError is not modified, so it may still be used by other callers:
+//
+//	unexpected := merr.Filter(func(err error) bool {
+//		return !errors.Is(err, context.Canceled)
+//	})
+//	return unexpected.ErrorOrNil()
+func (merr *MultiError) Filter(pred func(error) bool) *MultiError {
+	if merr == nil {
+		return &MultiError{}
+	}
+	filtered := &MultiError{message: merr.message}
+	for _, err := range merr.errors {
+		if pred(err) {
+			filtered.errors = append(filtered.errors, err)
+		}
+	}
+	return filtered
+}
+
+// WithoutMatching returns the error without the errors that match any
+// of the targets, according to Is. If there is a multierror in the
+// error chain, its matching errors are removed and the rest are returned
+// like ErrorOrNil: nil if every error matched, or the remaining error if
+// there is only one. The wrappers above the multierror are dropped, as
+// with ErrorsFromChain, unless none of its errors matched. Otherwise the
+// error is returned, or nil if it matches. The given error is not
+// modified.
+//
+//	err = errors.WithoutMatching(err, context.Canceled, ErrSkipped)
+func WithoutMatching(err error, targets ...error) error {
+	if err == nil {
+		return nil
+	}
+	matches := func(err error) bool {
+		for _, target := range targets {
+			if Is(err, target) {
+				return true
+			}
+		}
+		return false
+	}
+	var mm multierror
+	wrapped := false
+	for depth, next := 0, err; next != nil; next, depth = unwrapAt(next, depth), depth+1 {
+		if m, ok := next.(multierror); ok {
+			mm, wrapped = m, depth > 0
+			break
+		}
+	}
+	if mm == nil {
+		if matches(err) {
+			return nil
+		}
+		return err
+	}
+	merr, ok := mm.(*MultiError)
+	if !ok {
+		merr = NewMultiErrorGrouped(unwrapMulti(mm)...)
+	}
+	filtered := merr.Filter(func(err error) bool { return !matches(err) })
+	if wrapped && len(filtered.errors) == len(merr.errors) {
+		return err
+	}
+	return filtered.ErrorOrNil()
+}
+
+// Sort returns a new MultiError with the errors sorted by less, with the
+// same message. The sort is stable, and the MultiError is not modified,
+// so it may still be used by other callers. The errors themselves are
+// not changed, so they keep their frames and match Is and As the same.
+// A nil MultiError returns an empty one.
+func (merr *MultiError) Sort(less func(a, b error) bool) *MultiError {
+	if merr == nil {
+		return &MultiError{}
+	}
+	sorted := &MultiError{message: merr.message, errors: merr.Unwrap()}
+	sort.SliceStable(sorted.errors, func(i, j int) bool {
+		return less(sorted.errors[i], sorted.errors[j])
+	})
+	return sorted
+}
+
+// SortByMessage returns a new MultiError with the errors sorted by their
+// messages (see Sort), eg so that errors collected from goroutines are
+// in the same order from run to run.
+func (merr *MultiError) SortByMessage() *MultiError {
+	return merr.Sort(func(a, b error) bool { return safeError(a, 'v') < safeError(b, 'v') })
+}
+
+// AtLeast reports whether at least n of the errors of the MultiError
+// match the target, according to Is (so an error may wrap the target
+// anywhere in its chain). A nil or empty MultiError has no errors that
+// match. This suits quorum-style decisions:
+//
+//	// Fail if fewer than 2 of 3 replicas were written.
+//	if merr.AtLeast(2, ErrReplicaDown) {
+//		return merr
+//	}
+func (merr *MultiError) AtLeast(n int, target error) bool {
+	return merr.countMatching(target) >= n
+}
+
+// AtMost reports whether at most n of the errors of the MultiError match
+// the target, according to Is. A nil or empty MultiError has no errors
+// that match.
+func (merr *MultiError) AtMost(n int, target error) bool {
+	return merr.countMatching(target) <= n
+}
+
+// countMatching returns the number of errors of the MultiError that
+// match the target, according to Is.
+func (merr *MultiError) countMatching(target error) (count int) {
+	if merr == nil {
+		return 0
+	}
+	for _, err := range merr.errors {
+		if Is(err, target) {
+			count++
+		}
+	}
+	return
+}
+
+// Unique returns a new MultiError with only the first of the errors
+// that are the same (see Same), with the same message as the
+// MultiError. The message of an error that was repeated is followed by
+// a count:
+//
+//	merr.Unique().Error() // => "[connection refused (x17); timeout]"
+//
+// The errors kept are wrapped but not changed, so they still match Is
+// and As, and have the frames of their first occurrence. The MultiError
+// is not modified. A nil MultiError returns an empty one.
+func (merr *MultiError) Unique() *MultiError {
+	return merr.UniqueSame(SameOptions{})
+}
+
+// UniqueBy is the same as Unique, but errors are the same if key
+// returns the same value for them (eg, an error code), rather than if
+// they are the same according to Same.
+func (merr *MultiError) UniqueBy(key func(error) string) *MultiError {
+	index := make(map[string]int)
+	return merr.unique(func(err error, next int) (int, bool) {
+		k := key(err)
+		if i, ok := index[k]; ok {
+			return i, true
+		}
+		index[k] = next
+		return next, false
+	})
+}
+
+// UniqueSame is the same as Unique, but errors are the same according
+// to SameWith with the options.
+func (merr *MultiError) UniqueSame(opts SameOptions) *MultiError {
+	return merr.unique(newSameIndex(opts).lookup)
+}
+
+// unique implements Unique with a lookup function that returns the
+// index of the first error that an error is the same as, if any, or
+// else records the error with the next index.
+func (merr *MultiError) unique(lookup func(err error, next int) (int, bool)) *MultiError {
+	if merr == nil {
+		return &MultiError{}
+	}
+	unique := &MultiError{message: merr.message}
+	counts := make([]int, 0, len(merr.errors))
+	for _, err := range merr.errors {
+		if i, ok := lookup(err, len(unique.errors)); ok {
+			counts[i]++
+			continue
+		}
+		unique.errors = append(unique.errors, err)
+		counts = append(counts, 1)
+	}
+	for i, count := range counts {
+		if count > 1 {
+			unique.errors[i] = &withCount{error: unique.errors[i], count: count}
+		}
+	}
+	return unique
+}
+
+// Dedupe returns the error with only the first of the errors that are
+// the same, if it is a multierror (see MultiError.Unique). Otherwise the
+// error is returned as it is. A multierror is returned as a MultiError,
+// even if only one error is left.
+func Dedupe(err error) error {
+	merr, ok := err.(*MultiError)
+	if ok && merr == nil {
+		return err
+	}
+	if !ok {
+		mm, ok := err.(multierror)
+		if !ok {
+			return err
+		}
+		merr = NewMultiErrorGrouped(unwrapMulti(mm)...)
+	}
+	return merr.Unique().AsError()
+}
+
+// withCount implements an error type for an error that was repeated,
+// with the number of times it occurred.
+type withCount struct {
+	error error
+	count int
+
+	formatGuard
+}
+
+func (w *withCount) Error() string { return fmt.Sprintf("%s (x%d)", w.error, w.count) }
+
+func (w *withCount) Unwrap() error { return w.error }
+
+func (w *withCount) Format(s fmt.State, verb rune) {
+	if !w.enterFormat(s, verb) {
+		return
+	}
+	defer w.leaveFormat()
+
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "%v (x%d)", w.error, w.count)
+			formatFrames(s, verb, w)
+			formatBranches(s, w.error)
+			writeAnnotations(s, w)
+			return
+		}
+		if s.Flag('#') {
+			fmt.Fprintf(s, "&errors.withCount{%q}", w.Error())
+			return
+		}
+		fallthrough
+	case 's':
+		fmt.Fprintf(s, "%s (x%d)", safeError(w.error, verb), w.count)
+	case 'q':
+		fmt.Fprintf(s, "%q", fmt.Sprintf("%s (x%d)", safeError(w.error, verb), w.count))
+	default:
+		// empty
+	}
+}
+
 func (merr *MultiError) Format(s fmt.State, verb rune) {
+	if !merr.enterFormat(s, verb) {
+		return
+	}
+	defer merr.leaveFormat()
+
 	switch verb {
 	case 'v':
 		switch {
 		case s.Flag('+'):
-			size := len(merr.Unwrap())
-			if size < 1 {
-				io.WriteString(s, "empty errors: []")
-				return
-			}
-			buf := new(bytes.Buffer)
-			io.WriteString(s, "multiple errors:\n")
-			for i, err := range merr.errors {
-				if i > 0 {
-					io.WriteString(s, "\n")
-				}
-				fmt.Fprintf(buf, "\n* error %d of %d: %+v", i+1, size, err)
-				s.Write(buf.Bytes())
-				buf.Reset()
+			var opts FormatOptions
+			if p := multiErrorFormat.Load(); p != nil {
+				opts = *p
 			}
-			io.WriteString(s, "\n")
+			merr.formatErrors(s, opts)
 		case s.Flag('#'):
-			io.WriteString(s, "*errors.MultiError")
-			formatMessages(s, merr, [2]string{"{", "}"})
+			io.WriteString(s, "*errors.MultiError{"+merr.prefix())
+			formatMessages(s, merr, [2]string{"", "}"})
 		default:
+			io.WriteString(s, merr.prefix())
 			formatMessages(s, merr, [2]string{"[", "]"})
 		}
 	case 's':
+		io.WriteString(s, merr.prefix())
 		formatMessages(s, merr, [2]string{"[", "]"})
 	case 'q':
-		formatMessages(s, merr, [2]string{`"[`, `]"`})
+		io.WriteString(s, `"`+merr.prefix())
+		formatMessages(s, merr, [2]string{"[", `]"`})
 	default:
 		// empty
 	}
 }
 
+// FormatOptions configures the layout of a MultiError printed with the
+// `%+v` verb (see SetMultiErrorFormat) or with FormatMultiError. The
+// zero FormatOptions is the default layout:
+//
+//	multiple errors:
+//
+//	* error 1 of 2: a
+//	pkg.Function
+//		/path/to/file.go:10
+//
+//	* error 2 of 2: b
+type FormatOptions struct {
+	// Header is printed before the errors of a MultiError without a
+	// message, instead of "multiple errors".
+	Header string
+
+	// MemberPrefix returns the text printed before the i-th (from 1) of
+	// n errors, instead of "* error i of n: ".
+	MemberPrefix func(i, n int) string
+
+	// Indent is printed before the lines after the first of each error
+	// (eg, its frames), instead of nothing (or two spaces, for a group
+	// of errors).
+	Indent string
+
+	// Compact removes the blank lines between the header and the errors.
+	Compact bool
+
+	// NoTrailingNewline removes the newline after the last error.
+	NoTrailingNewline bool
+}
+
+var multiErrorFormat atomic.Pointer[FormatOptions]
+
+// SetMultiErrorFormat sets the layout of MultiErrors printed with the
+// `%+v` verb for the whole program, returning the previous layout. This
+// includes MultiErrors wrapped in the chain of another error. The zero
+// FormatOptions restores the default layout.
+func SetMultiErrorFormat(opts FormatOptions) (previous FormatOptions) {
+	if p := multiErrorFormat.Swap(&opts); p != nil {
+		previous = *p
+	}
+	return
+}
+
+// FormatMultiError writes the MultiError to the writer as it is printed
+// with the `%+v` verb, with the layout configured by the options, eg to
+// embed it in other output:
+//
+//	errors.FormatMultiError(w, merr, errors.FormatOptions{
+//		Indent:            "    ",
+//		Compact:           true,
+//		NoTrailingNewline: true,
+//	})
+//
+// The options also apply to the MultiErrors in its list of errors, but
+// those wrapped in the chain of one of its errors are printed with the
+// layout set with SetMultiErrorFormat.
+func FormatMultiError(w io.Writer, merr *MultiError, opts FormatOptions) {
+	fmt.Fprintf(w, "%+v", multiErrorFormatter{merr: merr, opts: opts})
+}
+
+// multiErrorFormatter prints a MultiError with the `%+v` verb with the
+// layout configured by the options.
+type multiErrorFormatter struct {
+	merr *MultiError
+	opts FormatOptions
+}
+
+func (f multiErrorFormatter) Format(s fmt.State, verb rune) {
+	if !f.merr.enterFormat(s, verb) {
+		return
+	}
+	defer f.merr.leaveFormat()
+	f.merr.formatErrors(s, f.opts)
+}
+
+// formatErrors writes the MultiError as it is printed with the `%+v`
+// verb, with the layout configured by the options.
+func (merr *MultiError) formatErrors(w io.Writer, opts FormatOptions) {
+	size := len(merr.errors)
+	if size < 1 {
+		if merr.message != "" {
+			io.WriteString(w, merr.message+": []")
+			return
+		}
+		io.WriteString(w, "empty errors: []")
+		return
+	}
+	header := merr.message
+	if header == "" {
+		header = opts.Header
+	}
+	if header == "" {
+		header = "multiple errors"
+	}
+	io.WriteString(w, header+":")
+	if !opts.Compact {
+		io.WriteString(w, "\n")
+	}
+
+	buf := new(bytes.Buffer)
+	for i, err := range merr.errors {
+		if i > 0 && !opts.Compact {
+			io.WriteString(w, "\n")
+		}
+		buf.WriteString("\n")
+		if opts.MemberPrefix != nil {
+			buf.WriteString(opts.MemberPrefix(i+1, size))
+		} else {
+			fmt.Fprintf(buf, "* error %d of %d: ", i+1, size)
+		}
+		indent := opts.Indent
+		if mm, ok := err.(*MultiError); ok && mm != nil {
+			fmt.Fprintf(buf, "%+v", multiErrorFormatter{merr: mm, opts: opts})
+		} else {
+			fmt.Fprintf(buf, "%+v", err)
+		}
+		if _, ok := err.(multierror); ok && indent == "" {
+			// Indent a group of errors under its slot.
+			indent = "  "
+		}
+		if indent != "" {
+			w.Write(indentLines(buf.Bytes(), indent))
+		} else {
+			w.Write(buf.Bytes())
+		}
+		buf.Reset()
+	}
+	if !opts.NoTrailingNewline {
+		io.WriteString(w, "\n")
+	}
+}
+
+// indentLines indents every non-empty line after the first (after the
+// leading newline), and drops any trailing newlines.
+func indentLines(byt []byte, indent string) []byte {
+	lines := bytes.Split(bytes.TrimRight(byt, "\n"), []byte("\n"))
+	for i := 2; i < len(lines); i++ {
+		if len(lines[i]) > 0 {
+			lines[i] = append([]byte(indent), lines[i]...)
+		}
+	}
+	return bytes.Join(lines, []byte("\n"))
+}
+
 func formatMessages(w io.Writer, merr multierror, delimiters [2]string) {
 	first := true
 	io.WriteString(w, delimiters[0])
-	for _, err := range merr.Unwrap() {
+	for _, err := range unwrapMulti(merr) {
 		if !first {
 			io.WriteString(w, "; ")
 		}
-		io.WriteString(w, err.Error())
+		io.WriteString(w, safeError(err, 'v'))
 		first = false
 	}
 	io.WriteString(w, delimiters[1])
@@ -213,6 +833,9 @@ func ErrorsFrom(err error) []error {
 	if err == nil {
 		return nil
 	}
+	if merr, ok := err.(*MultiError); ok {
+		return merr.Unwrap() // Already a copy.
+	}
 	if merr, ok := err.(multierror); ok {
 		errs := merr.Unwrap()
 
@@ -223,11 +846,163 @@ func ErrorsFrom(err error) []error {
 	return []error{err}
 }
 
+// ErrorsFromChain is the same as ErrorsFrom, but it unwraps the first
+// multierror found in the error chain, rather than only the given
+// error: so the errors of a wrapped multierror (eg, with WithFrame or
+// Errorf and the %w verb) are returned. If there is no multierror in
+// the chain, the returned slice contains just the error that was
+// passed in. If the given error is nil, a nil slice is returned.
+//
+// Wrappers that can't be unwrapped (Mask, Opaque) hide the multierror,
+// the same as they hide it from As.
+func ErrorsFromChain(err error) []error {
+	for depth, next := 0, err; next != nil; next, depth = unwrapAt(next, depth), depth+1 {
+		if _, ok := next.(multierror); ok {
+			return ErrorsFrom(next)
+		}
+	}
+	return ErrorsFrom(err)
+}
+
+// ErrorsFromAll returns every error that the supplied error is composed
+// of, descending into multierrors wherever they are in the error chain,
+// including in wrappers (eg, WithStackTrace or Errorf and the %w verb)
+// and in the errors of other multierrors. It never returns a
+// multierror: an empty multierror adds no errors. If the given error is
+// nil, a nil slice is returned.
+//
+// The errors are returned depth-first in the order of the multierrors,
+// so that:
+//
+//	err := errors.NewMultiError(
+//		errors.New("err1"),
+//		errors.WithFrame(errors.Join(errors.New("err2"), errors.New("err3"))),
+//		errors.New("err4"),
+//	)
+//	errors.ErrorsFromAll(err) // [err1 err2 err3 err4]
+//
+// Each error is returned as it is found in its multierror, with any
+// wrappers, unless the wrappers have a multierror in their chain: then
+// they are dropped for its errors, the same as with ErrorsFromChain. If
+// an error appears more than once (eg, in two multierrors), it is
+// returned for each. A multierror that has itself in its errors is a
+// cycle, and adds no errors the second time it is found.
+func ErrorsFromAll(err error) []error {
+	var all []error
+	errorsFromBranches(err, 0, nil, &all)
+	return all
+}
+
+// errorsFromBranches appends the errors of each branch of the error to
+// the list, given the number of Unwrap steps to the error and the
+// multierrors above it.
+func errorsFromBranches(err error, depth int, above []error, all *[]error) {
+	branch := err
+	for ; err != nil; err, depth = unwrapAt(err, depth), depth+1 {
+		merr, ok := err.(multierror)
+		if !ok {
+			continue
+		}
+		if containsMultiError(above, err) {
+			return
+		}
+		above = append(above, err)
+		for _, member := range unwrapMulti(merr) {
+			errorsFromBranches(member, depth+1, above, all)
+		}
+		return
+	}
+	if branch != nil {
+		*all = append(*all, branch)
+	}
+}
+
+// sameMultiError reports whether the errors are the same multierror,
+// without panicking on multierror types that are not comparable.
+func sameMultiError(a, b error) bool {
+	if reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+	return a == b
+}
+
+// unwrapMulti returns the errors in a multierror for reading only,
+// without copying the errors of a MultiError.
+func unwrapMulti(merr multierror) []error {
+	if mm, ok := merr.(*MultiError); ok {
+		return mm.errors
+	}
+	return merr.Unwrap()
+}
+
+// FramesFromAll extracts the Frames for each branch of an error tree:
+// for each error in a multierror (descending into nested multierrors)
+// it returns the Frames that FramesFrom would return if the error chain
+// from the root down to that error were a single chain. For an error
+// that is not composed of other errors, the result has a single element
+// with its Frames. If the given error is nil, a nil slice is returned.
+//
+//	err := errors.WithFrame(errors.NewMultiError(
+//		errors.NewWithFrame("err1"),
+//		errors.NewWithStackTrace("err2"),
+//	))
+//	all := errors.FramesFromAll(err)
+//	// all[0]: the frame for err1, then the frame for the wrapper.
+//	// all[1]: the stack trace for err2, which takes precedence.
+func FramesFromAll(err error) []Frames {
+	if err == nil {
+		return nil
+	}
+	var all []Frames
+	framesFromBranches(err, nil, false, &all)
+	return all
+}
+
+// framesFromBranches appends the Frames for each branch of the error to
+// the list, given the Frames of the branch above it.
+func framesFromBranches(err error, above Frames, aboveTrace bool, all *[]Frames) {
+	ff := FramesFrom(err)
+	trace := hasStackTrace(err)
+	switch {
+	case trace: // The deepest stack trace takes precedence.
+	case aboveTrace:
+		ff = append(Frames(nil), above...)
+	default:
+		ff = append(ff, above...)
+	}
+
+	for depth := 0; err != nil; err, depth = unwrapAt(err, depth), depth+1 {
+		if merr, ok := err.(multierror); ok && len(unwrapMulti(merr)) > 0 {
+			for _, branch := range unwrapMulti(merr) {
+				framesFromBranches(branch, ff, trace || aboveTrace, all)
+			}
+			return
+		}
+	}
+	*all = append(*all, ff)
+}
+
+// hasStackTrace reports whether the error chain, up to any multierror,
+// has a stack trace that FramesFrom would use.
+func hasStackTrace(err error) bool {
+	for depth := 0; err != nil; err, depth = unwrapAt(err, depth), depth+1 {
+		if len(stackTraceOf(err)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // Append is a version of NewMultiError optimized for the common case of
 // merging a small group of errors and expecting the outcome to be an
 // error or nil, akin to the standard library's errors.Join (and it is,
 // in fact, used for this library's implementation of Join).
 //
+// The result is the result of ErrorOrNil: nil if all the errors are
+// nil, the error itself if only one is not nil, or a MultiError
+// otherwise. Use NewMultiError(errs...).AsError() to always get a
+// MultiError.
+//
 // The following pattern may also be used to record failure of deferred
 // operations without losing information about the original error.
 //
@@ -252,15 +1027,64 @@ func Append(errs ...error) error {
 		singleErr = errs[0]
 	}
 	if singleErr != nil {
+		countAppend(len(errs) == 2 && errs[0] == nil)
 		// Ensure we flatten.
-		if mm, ok := singleErr.(multierror); ok {
-			return NewMultiError(mm.Unwrap()...).ErrorOrNil()
+		if _, ok := singleErr.(multierror); ok {
+			return NewMultiError(singleErr).ErrorOrNil()
 		}
 		return singleErr
 	}
 
 	// Do the work.
-	return NewMultiError(errs...).ErrorOrNil()
+	err := NewMultiError(errs...).ErrorOrNil()
+	countAppend(err != nil && anyNonNil(errs[1:]))
+	return err
+}
+
+// anyNonNil reports whether any of the errors is not nil.
+func anyNonNil(errs []error) bool {
+	for _, err := range errs {
+		if err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Appendf appends an error with message context to the receiving error,
+// like Append, and returns the result. The error appended is formatted
+// like Errorf, so it and any errors it wraps with the `%w` verb are
+// annotated with the caller's frame:
+//
+//	err = errors.Appendf(err, "closing output: %w", f.Close())
+//
+// If the format wraps errors with the `%w` verb and they are all nil,
+// nothing is appended, so the above appends nothing if Close succeeds.
+func Appendf(receivingErr error, format string, values ...interface{}) error {
+	if wrapsOnlyNil(format, values) {
+		return Append(receivingErr)
+	}
+	return Append(receivingErr, errorf(format, values, false, 0))
+}
+
+// wrapsOnlyNil reports whether the format wraps errors with the `%w`
+// verb, and they are all nil.
+func wrapsOnlyNil(format string, values []interface{}) bool {
+	verbs, err := parseFormatString(format, len(values))
+	if err != nil {
+		return false
+	}
+	var numWrapped int
+	for _, v := range verbs {
+		if v.letter != 'w' {
+			continue
+		}
+		numWrapped++
+		if values[v.idx] != nil {
+			return false
+		}
+	}
+	return numWrapped > 0
 }
 
 // AppendInto appends an error into the destination of an error pointer
@@ -295,17 +1119,137 @@ func AppendInto(receivingErr *error, appendingErr error) bool {
 		// because suggesting that the pointer must be non-nil may
 		// confuse users into thinking that the error that it points
 		// to must be non-nil.
+		panic(NewWithStackTrace(appendIntoNilMessage))
+	}
+
+	if appendingErr == nil {
+		return false
+	}
+	*receivingErr = Append(*receivingErr, appendingErr)
+	return true
+}
+
+// appendIntoNilMessage is the message of the error AppendInto panics
+// with, and TryAppendInto returns, when the receiving pointer is nil.
+const appendIntoNilMessage = "errors.AppendInto used incorrectly: receiving pointer must not be nil"
+
+// TryAppendInto is the same as AppendInto, except that it returns an
+// error instead of panicking if the receiving pointer is nil, eg in
+// generated code where it may be:
+//
+//	if _, err := errors.TryAppendInto(dst, closeErr); err != nil {
+//		log.Print(err)
+//	}
+//
+// The error has the same message as the one AppendInto panics with, and
+// is annotated with the caller's frame. Nothing is appended when it is
+// returned, so appended is false.
+func TryAppendInto(receivingErr *error, appendingErr error) (appended bool, err error) {
+	if receivingErr == nil {
+		return false, &withFrames{
+			error:  New(appendIntoNilMessage),
+			frames: frames{getFrame(3)},
+		}
+	}
+	return AppendInto(receivingErr, appendingErr), nil
+}
+
+// AppendIntof appends an error with message context into the
+// destination of an error pointer, like AppendInto, and returns whether
+// an error was appended. The error appended is formatted like Errorf
+// (see Appendf), and nothing is appended if the format wraps errors
+// with the `%w` verb and they are all nil. This suits deferred calls:
+//
+//	func writeOutput(..) (err error) {
+//		f := createOutput()
+//		defer func() { errors.AppendIntof(&err, "closing output: %w", f.Close()) }()
+//		// ...
+//	}
+func AppendIntof(receivingErr *error, format string, values ...interface{}) bool {
+	if receivingErr == nil {
 		panic(NewWithStackTrace(
-			"errors.AppendInto used incorrectly: receiving pointer must not be nil"))
+			"errors.AppendIntof used incorrectly: receiving pointer must not be nil"))
+	}
+
+	if wrapsOnlyNil(format, values) {
+		return false
+	}
+	*receivingErr = Append(*receivingErr, errorf(format, values, false, 0))
+	return true
+}
+
+// AppendIntoFramed appends an error into the destination of an error
+// pointer, like AppendInto, but first annotates the error with the
+// caller's frame if it has no frames of its own (see HasFrames). This
+// gives errors appended in a loop, eg from the standard library, the
+// location they were collected at, without annotating errors that
+// already have one:
+//
+//	var err error
+//	for _, path := range paths {
+//		errors.AppendIntoFramed(&err, os.Remove(path))
+//	}
+func AppendIntoFramed(receivingErr *error, appendingErr error) bool {
+	if receivingErr == nil {
+		panic(NewWithStackTrace(
+			"errors.AppendIntoFramed used incorrectly: receiving pointer must not be nil"))
 	}
 
 	if appendingErr == nil {
 		return false
 	}
+	if !HasFrames(appendingErr) {
+		appendingErr = &withFrames{
+			error:  appendingErr,
+			frames: frames{getFrame(3)},
+		}
+	}
 	*receivingErr = Append(*receivingErr, appendingErr)
 	return true
 }
 
+// AppendIntoMulti appends an error into the destination of an error
+// pointer, like AppendInto, and returns the resulting MultiError along
+// with whether the error being appended was non-nil.
+//
+// Unlike AppendInto, which leaves the destination as the result of
+// ErrorOrNil (nil, a single error, or a MultiError), AppendIntoMulti
+// guarantees that the destination holds a *MultiError afterwards: any
+// error already there is promoted into one (or flattened, if it is a
+// multierror), and the appended error is added to it in place. This
+// lets the caller inspect the accumulated errors without type
+// assertions:
+//
+//	var err error
+//	for _, item := range items {
+//		merr, _ := errors.AppendIntoMulti(&err, process(item))
+//		if len(merr.Unwrap()) >= maxErrors {
+//			break
+//		}
+//	}
+//	return errors.NewMultiError(err).ErrorOrNil()
+//
+// Note that the destination is non-nil afterwards even if no errors
+// were appended, so use ErrorOrNil before returning it.
+func AppendIntoMulti(receivingErr *error, appendingErr error) (*MultiError, bool) {
+	if receivingErr == nil {
+		panic(NewWithStackTrace(
+			"errors.AppendIntoMulti used incorrectly: receiving pointer must not be nil"))
+	}
+
+	merr, ok := (*receivingErr).(*MultiError)
+	if !ok || merr == nil {
+		merr = NewMultiError(*receivingErr)
+		*receivingErr = merr
+	}
+	if appendingErr == nil {
+		return merr, false
+	}
+	merr.errors = append(merr.errors, NewMultiError(appendingErr).errors...)
+	countAppend(true)
+	return merr, true
+}
+
 // ErrorResulter is a function that may fail with an error. Use it with
 // AppendResult to append the result of calling the function into an
 // error. This allows you to conveniently defer capture of failing
@@ -362,3 +1306,251 @@ type ErrorResulter func() error
 func AppendResult(receivingErr *error, resulterFn ErrorResulter) {
 	AppendInto(receivingErr, resulterFn())
 }
+
+// AppendResultNamed appends the result of calling the given
+// ErrorResulter into the provided error pointer, like AppendResult, but
+// first wraps a non-nil result with the name of what failed and a frame
+// for the caller, so that the errors of deferred cleanups can be told
+// apart:
+//
+//	defer errors.AppendResultNamed(&err, "close input", in.Close)
+//	defer errors.AppendResultNamed(&err, "rollback", tx.Rollback)
+//	// ...
+//	err.Error() // => "[rollback: tx done; close input: file already closed]"
+//
+// The message of the wrapped error is the name, a colon and the message
+// of the result. If deferred, the frame is where the function that
+// deferred it returned (see AnnotateOnReturn). A nil result is not
+// wrapped, so it costs no more than with AppendResult.
+func AppendResultNamed(receivingErr *error, name string, resulterFn ErrorResulter) {
+	if receivingErr == nil {
+		panic(NewWithStackTrace(
+			"errors.AppendResultNamed used incorrectly: receiving pointer must not be nil"))
+	}
+
+	err := resulterFn()
+	if err == nil {
+		return
+	}
+	countCreated(statsAppendResultNamed)
+	*receivingErr = Append(*receivingErr, &withFrames{
+		error:  fmt.Errorf("%s: %w", name, err),
+		frames: frames{getFrame(3)},
+	})
+}
+
+// AppendResultf appends the result of calling the given ErrorResulter
+// into the provided error pointer, like AppendResultNamed, but wraps a
+// non-nil result with a formatted message, like Errorf, so that the
+// errors of deferred cleanups can be told apart:
+//
+//	defer errors.AppendResultf(&err, in.Close, "closing input %s", inPath)
+//	defer errors.AppendResultf(&err, out.Close, "closing output %s", outPath)
+//	// ...
+//	err.Error() // => "closing output b.txt: file already closed"
+//
+// The message of the wrapped error is the formatted message, a colon
+// and the message of the result. If deferred, the frame is where the
+// function that deferred it returned (see AnnotateOnReturn). A nil
+// result is not wrapped, and AppendResultf allocates nothing for it.
+// Note that, as with any function formatting values like fmt.Sprintf,
+// the caller moves values that are not constants to the heap when it
+// passes them (eg, a path read at runtime, but not a literal or an int
+// under 256): pass the values that the message needs, and no more, on
+// the hottest paths.
+func AppendResultf(receivingErr *error, resulterFn ErrorResulter, format string, values ...interface{}) {
+	if receivingErr == nil {
+		panic(NewWithStackTrace(
+			"errors.AppendResultf used incorrectly: receiving pointer must not be nil"))
+	}
+
+	err := resulterFn()
+	if err == nil {
+		return
+	}
+	countCreated(statsAppendResultNamed)
+	*receivingErr = Append(*receivingErr, &withFrames{
+		error:  fmt.Errorf("%s: %w", fmt.Sprintf(format, values...), err),
+		frames: frames{getFrame(3)},
+	})
+}
+
+// WrapAllf wraps each error in the slice with the formatted message and
+// a frame for the caller, like calling Errorf on each:
+//
+//	errors.Errorf("batch %d: %w", id, err)
+//
+// Nil errors are skipped. The errors share a single frame, so the call
+// stack is only looked up once however many errors there are. A new
+// slice is returned, which is nil if there were no errors.
+func WrapAllf(errs []error, format string, values ...interface{}) []error {
+	return wrapAll(errs, format, values, 1)
+}
+
+// JoinWrapped wraps each error in the slice like WrapAllf and returns
+// the results as a MultiError, using ErrorOrNil: it returns nil if there
+// were no errors, and a single error unnested.
+//
+//	return errors.JoinWrapped(errs, "request %s", reqID)
+func JoinWrapped(errs []error, format string, values ...interface{}) error {
+	return NewMultiError(wrapAll(errs, format, values, 1)...).ErrorOrNil()
+}
+
+// wrapAll implements WrapAllf, skipping the given number of callers
+// when capturing the frame.
+func wrapAll(errs []error, format string, values []interface{}, skipCallers int) (wrapped []error) {
+	var (
+		fr  *frame
+		msg string
+	)
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if fr == nil {
+			fr = getFrame(3 + skipCallers)
+			msg = fmt.Sprintf(format, values...)
+		}
+		wrapped = append(wrapped, &withFrames{
+			error:  fmt.Errorf("%s: %w", msg, err),
+			frames: frames{fr},
+		})
+	}
+	return wrapped
+}
+
+// SafeMultiError collects errors like a MultiError, but is safe for
+// concurrent use, eg as the accumulator of errors from a fan-in of
+// workers, without any locking by the caller:
+//
+//	var merr errors.SafeMultiError
+//	var wg sync.WaitGroup
+//	for _, item := range items {
+//		wg.Add(1)
+//		go func(item Item) {
+//			defer wg.Done()
+//			merr.Append(process(item))
+//		}(item)
+//	}
+//	wg.Wait()
+//	return merr.ErrorOrNil()
+//
+// The zero value is an empty SafeMultiError ready to use. It must not
+// be copied after first use.
+type SafeMultiError struct {
+	mu   sync.Mutex
+	merr MultiError
+}
+
+// Append adds the error, unless it is nil. A multierror is flattened,
+// like with NewMultiError.
+func (s *SafeMultiError) Append(err error) {
+	if err == nil {
+		return
+	}
+	errs := NewMultiError(err).errors
+	countAppend(true)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.merr.errors = append(s.merr.errors, errs...)
+}
+
+// Len returns the number of errors added so far.
+func (s *SafeMultiError) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.merr.errors)
+}
+
+// Unwrap returns a copy of the errors added so far, or a nil slice if
+// there are none.
+func (s *SafeMultiError) Unwrap() []error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.merr.Unwrap()
+}
+
+// ErrorOrNil returns the errors added so far like MultiError.ErrorOrNil:
+// nil if there are none, a single error unnested, or otherwise a new
+// MultiError of them, which is not changed by later calls to Append.
+func (s *SafeMultiError) ErrorOrNil() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return NewMultiErrorGrouped(s.merr.errors...).ErrorOrNil()
+}
+
+// MultiError deserialization.
+
+var errMalformedMultiError = New("malformed multierror: missing errors")
+
+// multiErrorMarker matches the line that starts each error in the
+// output of printing a MultiError with the `%+v` verb.
+var multiErrorMarker = regexp.MustCompile(`^\* error (\d+) of (\d+): `)
+
+// multiErrorFromBytes parses the text as the output of printing a
+// MultiError with the `%+v` verb, and reports whether it has that
+// layout. Each error is parsed with ParseErrorFromBytes, and the first
+// problem found while parsing is returned.
+func multiErrorFromBytes(byt []byte) (merr *MultiError, ok bool, parseErr error) {
+	lines := bytes.Split(bytes.TrimRight(byt, "\n"), []byte("\n"))
+	if len(lines) == 1 && bytes.Equal(lines[0], []byte("empty errors: []")) {
+		return NewMultiError(), true, nil
+	}
+	if len(lines) < 3 || len(lines[1]) > 0 || !bytes.HasSuffix(lines[0], []byte(":")) {
+		return nil, false, nil
+	}
+	n, size, ok := parseMultiErrorMarker(lines[2])
+	if !ok || n != 1 {
+		return nil, false, nil
+	}
+
+	var members [][][]byte
+	for _, line := range lines[2:] {
+		if n, m, ok := parseMultiErrorMarker(line); ok && n == len(members)+1 && m == size {
+			members = append(members, [][]byte{line[len(multiErrorMarker.Find(line)):]})
+			continue
+		}
+		members[len(members)-1] = append(members[len(members)-1], line)
+	}
+
+	errs := make([]error, 0, len(members))
+	for i, member := range members {
+		if i < len(members)-1 && len(member[len(member)-1]) == 0 {
+			member = member[:len(member)-1] // Separator.
+		}
+		if len(member) > 1 && len(member[1]) == 0 {
+			// A group of errors is indented under its slot.
+			for j := 2; j < len(member); j++ {
+				member[j] = bytes.TrimPrefix(member[j], []byte("  "))
+			}
+		}
+		err, memberErr := ParseErrorFromBytes(bytes.Join(member, []byte("\n")))
+		if memberErr != nil && parseErr == nil {
+			parseErr = memberErr
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) != size && parseErr == nil {
+		parseErr = errMalformedMultiError
+	}
+
+	merr = NewMultiErrorGrouped(errs...)
+	if header := string(lines[0][:len(lines[0])-1]); header != "multiple errors" {
+		merr.message = header
+	}
+	return merr, true, parseErr
+}
+
+// parseMultiErrorMarker parses the position of an error in a MultiError
+// from the line that starts it.
+func parseMultiErrorMarker(line []byte) (n int, size int, ok bool) {
+	match := multiErrorMarker.FindSubmatch(line)
+	if match == nil {
+		return 0, 0, false
+	}
+	n, err1 := strconv.Atoi(string(match[1]))
+	size, err2 := strconv.Atoi(string(match[2]))
+	return n, size, err1 == nil && err2 == nil
+}
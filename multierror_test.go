@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"sort"
+	"sync"
 	"testing"
 
 	"github.com/secureworks/errors/internal/testutils"
@@ -114,6 +116,49 @@ func TestMultiError(t *testing.T) {
 	})
 }
 
+func TestNewMultiErrorGrouped(t *testing.T) {
+	err1 := New("err 1")
+	err2 := New("err 2")
+	err3 := New("err 3")
+
+	stage1 := NewMultiError(err1, err2)
+	stage2 := &multierrorType{msg: "err", errs: []error{err3}}
+	merr := NewMultiErrorGrouped(nilError(), stage1, nilError(), stage2)
+
+	t.Run("retains groups and removes nil errors", func(t *testing.T) {
+		errs := merr.Unwrap()
+		testutils.AssertEqual(t, 2, len(errs))
+		testutils.AssertEqual(t, stage1, errs[0])
+		testutils.AssertEqual(t, stage2, errs[1])
+	})
+
+	t.Run("ErrorsFrom returns groups", func(t *testing.T) {
+		errs := ErrorsFrom(merr)
+		testutils.AssertEqual(t, 2, len(errs))
+		testutils.AssertEqual(t, 2, len(ErrorsFrom(errs[0])))
+	})
+
+	t.Run("matches leaves", func(t *testing.T) {
+		testutils.AssertTrue(t, Is(merr, err2))
+		testutils.AssertTrue(t, Is(merr, err3))
+	})
+
+	t.Run("formats groups", func(t *testing.T) {
+		merr := NewMultiErrorGrouped(stage1, err3)
+		testutils.AssertEqual(t, "[[err 1; err 2]; err 3]", merr.Error())
+		testutils.AssertLinesMatch(t, merr, "%+v", `multiple errors:
+
+\* error 1 of 2: multiple errors:
+
+  \* error 1 of 2: err 1
+
+  \* error 2 of 2: err 2
+
+\* error 2 of 2: err 3
+`)
+	})
+}
+
 func TestMultiErrorErrorOrNil(t *testing.T) {
 	t.Run("returns nil when empty errors list", func(t *testing.T) {
 		testutils.AssertNil(t, NewMultiError().ErrorOrNil())
@@ -602,3 +647,1265 @@ func TestAppendResult(t *testing.T) {
 		testutils.AssertNil(t, err)
 	})
 }
+
+func TestWrapAllf(t *testing.T) {
+	t.Run("skips nils", func(t *testing.T) {
+		testutils.AssertNil(t, WrapAllf(nil, "batch"))
+		testutils.AssertNil(t, WrapAllf([]error{nil, nil}, "batch"))
+	})
+
+	t.Run("wraps each error", func(t *testing.T) {
+		err1, err2 := New("err1"), New("err2")
+		errs := []error{err1, nil, err2}
+		wrapped := WrapAllf(errs, "batch %d", 7)
+		testutils.AssertEqual(t, 2, len(wrapped))
+		testutils.AssertEqual(t, "batch 7: err1", wrapped[0].Error())
+		testutils.AssertEqual(t, "batch 7: err2", wrapped[1].Error())
+		testutils.AssertTrue(t, Is(wrapped[0], err1))
+		testutils.AssertTrue(t, Is(wrapped[1], err2))
+		testutils.AssertEqual(t, []error{err1, nil, err2}, errs)
+	})
+
+	t.Run("shares a frame for the caller", func(t *testing.T) {
+		wrapped := WrapAllf([]error{New("err1"), New("err2")}, "batch")
+		for _, err := range wrapped {
+			ff := FramesFrom(err)
+			testutils.AssertEqual(t, 1, len(ff))
+			function, _, _ := ff[0].Location()
+			testutils.AssertEqual(t, "github.com/secureworks/errors.TestWrapAllf.func3", function)
+		}
+		testutils.AssertEqual(t, FramesFrom(wrapped[0])[0], FramesFrom(wrapped[1])[0])
+	})
+}
+
+func TestJoinWrapped(t *testing.T) {
+	t.Run("nil when no errors", func(t *testing.T) {
+		testutils.AssertNil(t, JoinWrapped(nil, "batch"))
+		testutils.AssertNil(t, JoinWrapped([]error{nil}, "batch"))
+	})
+
+	t.Run("single error is unnested", func(t *testing.T) {
+		err := JoinWrapped([]error{nil, errSentinel}, "batch %d", 7)
+		testutils.AssertEqual(t, "batch 7: sentinel err", err.Error())
+		_, ok := err.(*MultiError)
+		testutils.AssertFalse(t, ok)
+		function, _, _ := FramesFrom(err)[0].Location()
+		testutils.AssertEqual(t, "github.com/secureworks/errors.TestJoinWrapped.func2", function)
+	})
+
+	t.Run("joins errors", func(t *testing.T) {
+		err := JoinWrapped([]error{New("err1"), New("err2")}, "batch")
+		testutils.AssertEqual(t, "[batch: err1; batch: err2]", err.Error())
+		testutils.AssertEqual(t, 2, len(ErrorsFrom(err)))
+		function, _, _ := FramesFrom(ErrorsFrom(err)[1])[0].Location()
+		testutils.AssertEqual(t, "github.com/secureworks/errors.TestJoinWrapped.func3", function)
+	})
+}
+
+func TestFramesFromAll(t *testing.T) {
+	fr := func(name string) Frames {
+		return Frames{NewFrame(name, name+".go", 1)}
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		testutils.AssertNil(t, FramesFromAll(nil))
+	})
+
+	t.Run("single error", func(t *testing.T) {
+		all := FramesFromAll(WithFrames(errSentinel, fr("a")))
+		testutils.AssertEqual(t, 1, len(all))
+		testutils.AssertEqual(t, "[a.go:1]", fmt.Sprintf("%s", all[0]))
+
+		all = FramesFromAll(errSentinel)
+		testutils.AssertEqual(t, 1, len(all))
+		testutils.AssertEqual(t, 0, len(all[0]))
+	})
+
+	t.Run("one per branch", func(t *testing.T) {
+		err := WithFrames(NewMultiError(
+			WithFrames(WithFrames(New("err1"), fr("b")), fr("c")),
+			New("err2"),
+			WithFrames(New("err3"), fr("d")),
+		), fr("a"))
+		testutils.AssertEqual(t, 0, len(FramesFrom(NewMultiError(New("err1"), New("err2")))))
+
+		all := FramesFromAll(err)
+		testutils.AssertEqual(t, 3, len(all))
+		testutils.AssertEqual(t, "[b.go:1 c.go:1 a.go:1]", fmt.Sprintf("%s", all[0]))
+		testutils.AssertEqual(t, "[a.go:1]", fmt.Sprintf("%s", all[1]))
+		testutils.AssertEqual(t, "[d.go:1 a.go:1]", fmt.Sprintf("%s", all[2]))
+	})
+
+	t.Run("nested multierrors", func(t *testing.T) {
+		err := NewMultiErrorGrouped(
+			WithFrames(NewMultiErrorGrouped(
+				WithFrames(New("err1"), fr("c")),
+				New("err2"),
+			), fr("b")),
+			WithFrames(New("err3"), fr("a")),
+		)
+		all := FramesFromAll(err)
+		testutils.AssertEqual(t, 3, len(all))
+		testutils.AssertEqual(t, "[c.go:1 b.go:1]", fmt.Sprintf("%s", all[0]))
+		testutils.AssertEqual(t, "[b.go:1]", fmt.Sprintf("%s", all[1]))
+		testutils.AssertEqual(t, "[a.go:1]", fmt.Sprintf("%s", all[2]))
+	})
+
+	t.Run("stack traces take precedence", func(t *testing.T) {
+		trace := NewWithStackTrace("err1")
+		err := WithFrames(NewMultiError(
+			WithFrames(trace, fr("b")),
+			New("err2"),
+		), fr("a"))
+		all := FramesFromAll(err)
+		testutils.AssertEqual(t, 2, len(all))
+		testutils.AssertEqual(t, FramesFrom(trace), all[0])
+		testutils.AssertEqual(t, "[a.go:1]", fmt.Sprintf("%s", all[1]))
+
+		err = WithStackTrace(NewMultiError(WithFrames(New("err1"), fr("b")), New("err2")))
+		all = FramesFromAll(err)
+		testutils.AssertEqual(t, 2, len(all))
+		testutils.AssertEqual(t, FramesFrom(err), all[0])
+		testutils.AssertEqual(t, FramesFrom(err), all[1])
+	})
+
+	t.Run("empty multierror", func(t *testing.T) {
+		all := FramesFromAll(WithFrames(NewMultiError(), fr("a")))
+		testutils.AssertEqual(t, 1, len(all))
+		testutils.AssertEqual(t, "[a.go:1]", fmt.Sprintf("%s", all[0]))
+	})
+}
+
+func TestAppendIntoMulti(t *testing.T) {
+	t.Run("panics if first is nil", func(t *testing.T) {
+		err := func() (err error) {
+			defer func() {
+				err = recover().(error)
+			}()
+			_, _ = AppendIntoMulti(nil, New("err"))
+			return
+		}()
+
+		testutils.AssertNotNil(t, err)
+		testutils.AssertEqual(t,
+			`errors.AppendIntoMulti used incorrectly: receiving pointer must not be nil`,
+			err.Error())
+		withTrace, ok := err.(interface{ Frames() Frames })
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, 4, len(withTrace.Frames()))
+	})
+
+	t.Run("promotes nil to an empty MultiError", func(t *testing.T) {
+		var err error
+		merr, ok := AppendIntoMulti(&err, nil)
+		testutils.AssertFalse(t, ok)
+		testutils.AssertEqual(t, 0, len(merr.Unwrap()))
+		testutils.AssertTrue(t, err == error(merr))
+		testutils.AssertNil(t, merr.ErrorOrNil())
+	})
+
+	t.Run("promotes a single error", func(t *testing.T) {
+		err1, err2 := New("err 1"), New("err 2")
+		err := err1
+		merr, ok := AppendIntoMulti(&err, err2)
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, []error{err1, err2}, merr.Unwrap())
+		testutils.AssertTrue(t, err == error(merr))
+	})
+
+	t.Run("appends in place", func(t *testing.T) {
+		err1, err2, err3 := New("err 1"), New("err 2"), New("err 3")
+		var err error = NewMultiError(err1)
+		merr1, _ := AppendIntoMulti(&err, err2)
+		merr2, _ := AppendIntoMulti(&err, NewMultiError(err3, nil))
+		testutils.AssertTrue(t, merr1 == merr2)
+		testutils.AssertEqual(t, []error{err1, err2, err3}, merr2.Unwrap())
+	})
+
+	t.Run("flattens other multierrors", func(t *testing.T) {
+		err1, err2 := New("err 1"), New("err 2")
+		var err error = NamedFromMap(map[string]error{"a": err1})
+		merr, _ := AppendIntoMulti(&err, err2)
+		testutils.AssertEqual(t, 2, len(merr.Unwrap()))
+		testutils.AssertEqual(t, "[a: err 1; err 2]", err.Error())
+	})
+}
+
+func TestNewMultiErrorMsg(t *testing.T) {
+	err1, err2 := New("err1"), New("err2")
+
+	t.Run("prefixes the message", func(t *testing.T) {
+		merr := NewMultiErrorMsg("2 of 7 uploads failed", err1, nil, err2)
+		testutils.AssertEqual(t, "2 of 7 uploads failed", merr.Message())
+		testutils.AssertEqual(t, "2 of 7 uploads failed: [err1; err2]", merr.Error())
+		testutils.AssertEqual(t, []error{err1, err2}, merr.Unwrap())
+		testutils.AssertEqual(t, "", NewMultiError(err1).Message())
+	})
+
+	t.Run("formats", func(t *testing.T) {
+		merr := NewMultiErrorMsg("failed", err1, err2)
+		testutils.AssertEqual(t, "failed: [err1; err2]", fmt.Sprintf("%s", merr))
+		testutils.AssertEqual(t, "failed: [err1; err2]", fmt.Sprintf("%v", merr))
+		testutils.AssertEqual(t, `"failed: [err1; err2]"`, fmt.Sprintf("%q", merr))
+		testutils.AssertEqual(t, "*errors.MultiError{failed: err1; err2}", fmt.Sprintf("%#v", merr))
+		testutils.AssertEqual(t, "*errors.MultiError{err1; err2}", fmt.Sprintf("%#v", NewMultiError(err1, err2)))
+		testutils.AssertEqual(t, "failed:\n\n* error 1 of 2: err1\n\n* error 2 of 2: err2\n", fmt.Sprintf("%+v", merr))
+		testutils.AssertEqual(t, "failed: []", fmt.Sprintf("%+v", NewMultiErrorMsg("failed")))
+	})
+
+	t.Run("keeps the message for a single error", func(t *testing.T) {
+		err := NewMultiErrorMsg("failed", err1).ErrorOrNil()
+		testutils.AssertEqual(t, "failed: [err1]", err.Error())
+		testutils.AssertNil(t, NewMultiErrorMsg("failed").ErrorOrNil())
+	})
+
+	t.Run("keeps the message as the destination", func(t *testing.T) {
+		var err error = NewMultiErrorMsg("failed", err1)
+		testutils.AssertEqual(t, "failed: [err1; err2]", Append(err, err2).Error())
+		testutils.AssertEqual(t, "failed: [err1]", Append(err, nil).Error())
+		testutils.AssertEqual(t, "failed: [err1]", Append(nil, err).Error())
+		testutils.AssertEqual(t, "[err2; err1]", Append(err2, err).Error())
+
+		AppendInto(&err, err2)
+		testutils.AssertEqual(t, "failed: [err1; err2]", err.Error())
+		merr, _ := AppendIntoMulti(&err, New("err3"))
+		testutils.AssertEqual(t, "failed", merr.Message())
+	})
+}
+
+func BenchmarkMultiError(b *testing.B) {
+	merr := NewMultiError(New("err1"), New("err2"), New("err3"), errSentinel)
+	b.Run("Unwrap", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = merr.Unwrap()
+		}
+	})
+	b.Run("Is", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = Is(merr, errSentinel)
+		}
+	})
+	b.Run("Error", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = merr.Error()
+		}
+	})
+}
+
+func TestMultiError_Unwrap_copies(t *testing.T) {
+	errB, errA, errC := New("b"), New("a"), New("c")
+	merr := NewMultiError(errB, errA, errC)
+
+	t.Run("modifying the result does not affect the MultiError", func(t *testing.T) {
+		errs := merr.Unwrap()
+		errs[0] = nil
+		testutils.AssertEqual(t, []error{errB, errA, errC}, merr.Unwrap())
+		testutils.AssertNil(t, NewMultiError().Unwrap())
+	})
+
+	t.Run("sorting while formatting does not race", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					_ = fmt.Sprintf("%+v", merr)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					for _, errs := range [][]error{merr.Unwrap(), ErrorsFrom(merr)} {
+						sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		testutils.AssertEqual(t, "[b; a; c]", merr.Error())
+	})
+}
+
+func TestAppendIntoFramed(t *testing.T) {
+	t.Run("panics if first is nil", func(t *testing.T) {
+		err := func() (err error) {
+			defer func() {
+				err = recover().(error)
+			}()
+			_ = AppendIntoFramed(nil, New("err"))
+			return
+		}()
+		testutils.AssertEqual(t,
+			`errors.AppendIntoFramed used incorrectly: receiving pointer must not be nil`,
+			err.Error())
+	})
+
+	t.Run("ignores nil", func(t *testing.T) {
+		var err error
+		testutils.AssertFalse(t, AppendIntoFramed(&err, nil))
+		testutils.AssertNil(t, err)
+	})
+
+	t.Run("adds the caller frame to errors without frames", func(t *testing.T) {
+		var err error
+		var callers []Frame
+		for _, msg := range []string{"a", "b"} {
+			callers = append(callers, Caller())
+			testutils.AssertTrue(t, AppendIntoFramed(&err, New(msg)))
+		}
+		errs := ErrorsFrom(err)
+		testutils.AssertEqual(t, 2, len(errs))
+		for i, err := range errs {
+			ff := FramesFrom(err)
+			testutils.AssertEqual(t, 1, len(ff))
+			expected := LocationOf(callers[i])
+			expected.Line++
+			testutils.AssertEqual(t, expected, LocationOf(ff[0]))
+		}
+		testutils.AssertEqual(t, "[a; b]", err.Error())
+	})
+
+	t.Run("does not add frames to errors with frames", func(t *testing.T) {
+		withFrame := NewWithFrames("a", Frames{NewFrame("fn", "file.go", 1)})
+		withTrace := NewWithStackTrace("b")
+		wrapped := fmt.Errorf("c: %w", withFrame)
+
+		var err error
+		AppendIntoFramed(&err, withFrame)
+		AppendIntoFramed(&err, withTrace)
+		AppendIntoFramed(&err, wrapped)
+		errs := ErrorsFrom(err)
+		testutils.AssertTrue(t, errs[0] == withFrame)
+		testutils.AssertTrue(t, errs[1] == withTrace)
+		testutils.AssertTrue(t, errs[2] == wrapped)
+	})
+
+	t.Run("keeps the original error", func(t *testing.T) {
+		var err error
+		AppendIntoFramed(&err, errSentinel)
+		testutils.AssertTrue(t, Is(err, errSentinel))
+		testutils.AssertEqual(t, "sentinel err", err.Error())
+	})
+}
+
+func TestErrorFromBytes_multiError(t *testing.T) {
+	withFrames := func(msg string, line int) error {
+		return NewWithFrames(msg, Frames{
+			NewFrame("github.com/secureworks/errors.Example", "/src/example.go", line),
+			NewFrame("github.com/secureworks/errors.Other", "/src/other.go", line+1),
+		})
+	}
+	cases := []struct {
+		name string
+		err  *MultiError
+	}{
+		{"empty", NewMultiError()},
+		{"without frames", NewMultiError(New("a"), New("b"))},
+		{"with frames", NewMultiError(withFrames("a", 10), New("b"), withFrames("c", 20))},
+		{"with colons", NewMultiError(New("read: a: b"), withFrames("write: c:", 10))},
+		{"with message", NewMultiErrorMsg("2 uploads failed", New("a"), withFrames("b", 10))},
+		{"single", NewMultiError(withFrames("a", 10))},
+		{"grouped", NewMultiErrorGrouped(
+			withFrames("a", 10),
+			NewMultiError(New("b"), withFrames("c", 20)),
+			NewMultiErrorMsg("stage 2", New("d")),
+		)},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			text := fmt.Sprintf("%+v", tt.err)
+			err, parseErr := ParseErrorFromBytes([]byte(text))
+			testutils.AssertNil(t, parseErr)
+			merr, ok := err.(*MultiError)
+			testutils.AssertTrue(t, ok)
+			testutils.AssertEqual(t, len(tt.err.errors), len(merr.errors))
+			testutils.AssertEqual(t, tt.err.Error(), merr.Error())
+			testutils.AssertEqual(t, text, fmt.Sprintf("%+v", merr))
+		})
+	}
+
+	t.Run("missing errors", func(t *testing.T) {
+		text := fmt.Sprintf("%+v", NewMultiError(New("a"), New("b")))
+		text = text[:len(text)-len("* error 2 of 2: b\n")]
+		err, parseErr := ParseErrorFromBytes([]byte(text))
+		testutils.AssertEqual(t, errMalformedMultiError, parseErr)
+		testutils.AssertEqual(t, "[a]", err.Error())
+	})
+
+	t.Run("not a multierror", func(t *testing.T) {
+		for _, text := range []string{"multiple errors:", "err:\n\nnot a list", "err:\n\n* error 2 of 2: a"} {
+			err, _ := ParseErrorFromBytes([]byte(text))
+			_, ok := err.(*MultiError)
+			testutils.AssertFalse(t, ok)
+		}
+	})
+}
+
+func TestMultiErrorAsError(t *testing.T) {
+	t.Run("returns nil when empty", func(t *testing.T) {
+		testutils.AssertNil(t, NewMultiError().AsError())
+		testutils.AssertNil(t, NewMultiError(nil, nil).AsError())
+	})
+	t.Run("returns the MultiError when one error", func(t *testing.T) {
+		merr := NewMultiError(errBasic)
+		err := merr.AsError()
+		testutils.AssertEqual(t, error(merr), err)
+		var target *MultiError
+		testutils.AssertTrue(t, As(err, &target))
+	})
+	t.Run("returns the MultiError when errors", func(t *testing.T) {
+		merr := NewMultiError(errBasic, errBasic)
+		testutils.AssertEqual(t, error(merr), merr.AsError())
+	})
+}
+
+// TestMultiError_singleErrorUnnesting pins which helpers unnest a single
+// error and which always return a MultiError.
+func TestMultiError_singleErrorUnnesting(t *testing.T) {
+	isMultiError := func(err error) bool {
+		var merr *MultiError
+		return As(err, &merr)
+	}
+	unnested := map[string]error{
+		"ErrorOrNil":  NewMultiError(errBasic).ErrorOrNil(),
+		"Append":      Append(nil, errBasic),
+		"Join":        Join(errBasic, nil),
+		"JoinWrapped": JoinWrapped([]error{nil, errBasic}, "wrapped"),
+	}
+	for name, err := range unnested {
+		t.Run(name, func(t *testing.T) {
+			testutils.AssertFalse(t, isMultiError(err))
+		})
+	}
+	testutils.AssertTrue(t, isMultiError(NewMultiError(errBasic).AsError()))
+	testutils.AssertTrue(t, isMultiError(NewMultiErrorMsg("failed", errBasic).ErrorOrNil()))
+	for name, err := range map[string]error{
+		"ErrorOrNil": NewMultiError(errBasic, errBasic).ErrorOrNil(),
+		"Append":     Append(errBasic, errBasic),
+		"Join":       Join(errBasic, errBasic),
+	} {
+		t.Run(name+" with errors", func(t *testing.T) {
+			testutils.AssertTrue(t, isMultiError(err))
+		})
+	}
+}
+
+type matrixError struct{}
+
+func (matrixError) Error() string { return "typed" }
+
+// TestWrappedMultiError locks the behavior of each wrapper over a
+// multierror, as documented in the package docs.
+func TestWrappedMultiError(t *testing.T) {
+	fa := Frames{NewFrame("github.com/secureworks/errors.A", "/src/a.go", 1)}
+	errSentinel := NewWithFrames("a", fa)
+	merr := NewMultiError(errSentinel, matrixError{})
+
+	cases := []struct {
+		name    string
+		wrap    func(error) error
+		matches bool   // Is and As find the errors of the multierror.
+		frames  int    // Length of FramesFrom, or -1 for a stack trace.
+		format  string // Pattern for the `%+v` output.
+	}{
+		{
+			name:    "WithFrame",
+			wrap:    func(err error) error { return WithFrame(err) },
+			matches: true,
+			frames:  1,
+			format:  `(?s)^\[a; typed\]\n\S+TestWrappedMultiError\S*\n\t\S+multierror_test.go:\d+\n\nmultiple errors:\n\n\* error 1 of 2: a\ngithub.com/secureworks/errors.A\n\t/src/a.go:1\n\n\* error 2 of 2: typed\n$`,
+		},
+		{
+			name:    "Errorf",
+			wrap:    func(err error) error { return Errorf("wrapped: %w", err) },
+			matches: true,
+			frames:  1,
+			format:  `(?s)^wrapped: \[a; typed\]\n\S+TestWrappedMultiError\S*\n\t\S+multierror_test.go:\d+\n\nmultiple errors:\n\n\* error 1 of 2: a\n.*\* error 2 of 2: typed\n$`,
+		},
+		{
+			name:    "WithStackTrace",
+			wrap:    func(err error) error { return WithStackTrace(err) },
+			matches: true,
+			frames:  -1,
+			format:  `(?s)^\[a; typed\]\n\S+TestWrappedMultiError\S*\n.*\n\nmultiple errors:\n\n\* error 1 of 2: a\n.*\* error 2 of 2: typed\n$`,
+		},
+		{
+			name:    "WithMessage",
+			wrap:    func(err error) error { return WithMessage(err, "failed") },
+			matches: true,
+			format:  `^failed$`,
+		},
+		{
+			name:    "fmt.Errorf",
+			wrap:    func(err error) error { return fmt.Errorf("wrapped: %w", err) },
+			matches: true,
+			format:  `^wrapped: \[a; typed\]$`,
+		},
+		{
+			name:   "Mask",
+			wrap:   Mask,
+			format: `^\[a; typed\]$`,
+		},
+		{
+			name:   "Opaque",
+			wrap:   Opaque,
+			format: `^\[a; typed\]$`,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.wrap(merr)
+
+			var target matrixError
+			var targetMulti *MultiError
+			testutils.AssertEqual(t, tt.matches, Is(err, errSentinel))
+			testutils.AssertEqual(t, tt.matches, As(err, &target))
+			testutils.AssertEqual(t, tt.matches, As(err, &targetMulti))
+
+			testutils.AssertEqual(t, []error{err}, ErrorsFrom(err))
+			if tt.matches {
+				testutils.AssertEqual(t, []error{errSentinel, matrixError{}}, ErrorsFromChain(err))
+			} else {
+				testutils.AssertEqual(t, []error{err}, ErrorsFromChain(err))
+			}
+
+			ff := FramesFrom(err)
+			all := FramesFromAll(err)
+			if tt.frames < 0 {
+				// The stack trace takes precedence over the frames of each member.
+				testutils.AssertTrue(t, len(ff) > 1)
+				testutils.AssertEqual(t, []Frames{ff, ff}, all)
+			} else if tt.matches {
+				testutils.AssertEqual(t, tt.frames, len(ff))
+				testutils.AssertEqual(t, 2, len(all))
+				testutils.AssertEqual(t, append(fa.Locations(), ff.Locations()...), all[0].Locations())
+				testutils.AssertEqual(t, ff.Locations(), all[1].Locations())
+			} else {
+				testutils.AssertEqual(t, tt.frames, len(ff))
+				testutils.AssertEqual(t, []Frames{nil}, all)
+			}
+
+			testutils.AssertMatch(t, tt.format, fmt.Sprintf("%+v", err))
+		})
+	}
+
+	t.Run("ErrorsFromChain", func(t *testing.T) {
+		testutils.AssertNil(t, ErrorsFromChain(nil))
+		err := WithFrame(New("err"))
+		testutils.AssertEqual(t, []error{err}, ErrorsFromChain(err))
+
+		// The first multierror in the chain is unwrapped, not any nested in it.
+		nested := NewMultiErrorGrouped(New("a"), NewMultiErrorGrouped(New("b"), New("c")))
+		testutils.AssertEqual(t, 2, len(ErrorsFromChain(WithFrame(nested))))
+	})
+}
+
+func TestAppendResultNamed(t *testing.T) {
+	t.Run("panics if first is nil", func(t *testing.T) {
+		err := func() (err error) {
+			defer func() {
+				err = recover().(error)
+			}()
+			AppendResultNamed(nil, "close", newTestCloser(nil).Close)
+			return
+		}()
+		testutils.AssertEqual(t,
+			`errors.AppendResultNamed used incorrectly: receiving pointer must not be nil`,
+			err.Error())
+	})
+
+	t.Run("nil appends nil", func(t *testing.T) {
+		err := func() (e error) {
+			defer AppendResultNamed(&e, "close", newTestCloser(nil).Close)
+			return
+		}()
+		testutils.AssertNil(t, err)
+	})
+
+	t.Run("names the results", func(t *testing.T) {
+		var returned Frame
+		err := func() (e error) {
+			defer AppendResultNamed(&e, "close input", newTestCloser(errBasic).Close)
+			defer AppendResultNamed(&e, "release lock", newTestCloser(nil).Close)
+			defer AppendResultNamed(&e, "rollback", newTestCloser(errSentinel).Close)
+			returned = Caller()
+			return
+		}()
+		testutils.AssertEqual(t, "[rollback: sentinel err; close input: new err]", err.Error())
+		testutils.AssertTrue(t, Is(err, errBasic))
+		testutils.AssertTrue(t, Is(err, errSentinel))
+
+		errs := ErrorsFrom(err)
+		testutils.AssertEqual(t, 2, len(errs))
+		for _, err := range errs {
+			ff := FramesFrom(err)
+			testutils.AssertEqual(t, 1, len(ff))
+			testutils.AssertEqual(t, LocationOf(returned).Function, LocationOf(ff[0]).Function)
+		}
+	})
+
+	t.Run("appends to an error", func(t *testing.T) {
+		err := func() (e error) {
+			defer AppendResultNamed(&e, "close", newTestCloser(errSentinel).Close)
+			return errBasic
+		}()
+		testutils.AssertEqual(t, "[new err; close: sentinel err]", err.Error())
+	})
+}
+
+func TestSafeMultiError(t *testing.T) {
+	t.Run("zero value is empty", func(t *testing.T) {
+		var merr SafeMultiError
+		testutils.AssertEqual(t, 0, merr.Len())
+		testutils.AssertNil(t, merr.Unwrap())
+		testutils.AssertNil(t, merr.ErrorOrNil())
+	})
+
+	t.Run("appends and flattens", func(t *testing.T) {
+		var merr SafeMultiError
+		merr.Append(nil)
+		merr.Append(errBasic)
+		testutils.AssertEqual(t, errBasic, merr.ErrorOrNil())
+
+		merr.Append(NewMultiError(errSentinel, New("other")))
+		testutils.AssertEqual(t, 3, merr.Len())
+		testutils.AssertEqual(t, "[new err; sentinel err; other]", merr.ErrorOrNil().Error())
+	})
+
+	t.Run("results are copies", func(t *testing.T) {
+		var merr SafeMultiError
+		merr.Append(errBasic)
+		merr.Append(errSentinel)
+		err, errs := merr.ErrorOrNil(), merr.Unwrap()
+		errs[0] = nil
+		merr.Append(New("later"))
+		testutils.AssertEqual(t, "[new err; sentinel err]", err.Error())
+		testutils.AssertEqual(t, errBasic, merr.Unwrap()[0])
+	})
+
+	t.Run("concurrent use", func(t *testing.T) {
+		var merr SafeMultiError
+		var wg sync.WaitGroup
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				merr.Append(fmt.Errorf("err %d", i))
+				merr.Append(nil)
+				_ = merr.Len()
+				_ = merr.Unwrap()
+				if err := merr.ErrorOrNil(); err != nil {
+					_ = err.Error()
+				}
+			}(i)
+		}
+		wg.Wait()
+		testutils.AssertEqual(t, 100, merr.Len())
+		testutils.AssertEqual(t, 100, len(ErrorsFrom(merr.ErrorOrNil())))
+	})
+}
+
+func TestMultiErrorFilter(t *testing.T) {
+	errWrapped := Errorf("wrapped: %w", errSentinel)
+	merr := NewMultiErrorMsg("failed", errBasic, errWrapped, errSentinel)
+
+	filtered := merr.Filter(func(err error) bool { return !Is(err, errSentinel) })
+	testutils.AssertEqual(t, "failed: [new err]", filtered.Error())
+	testutils.AssertEqual(t, 3, len(merr.Unwrap()))
+
+	none := merr.Filter(func(error) bool { return false })
+	testutils.AssertNil(t, none.ErrorOrNil())
+	testutils.AssertEqual(t, "failed", none.Message())
+}
+
+func TestWithoutMatching(t *testing.T) {
+	errWrapped := Errorf("wrapped: %w", errSentinel)
+
+	t.Run("nil", func(t *testing.T) {
+		testutils.AssertNil(t, WithoutMatching(nil, errSentinel))
+	})
+
+	t.Run("single error", func(t *testing.T) {
+		testutils.AssertNil(t, WithoutMatching(errWrapped, errBasic, errSentinel))
+		testutils.AssertEqual(t, errBasic, WithoutMatching(errBasic, errSentinel))
+		testutils.AssertEqual(t, errBasic, WithoutMatching(errBasic))
+	})
+
+	t.Run("multierror", func(t *testing.T) {
+		merr := NewMultiError(errBasic, errWrapped, errSentinel)
+		testutils.AssertEqual(t, errBasic, WithoutMatching(merr, errSentinel))
+		testutils.AssertNil(t, WithoutMatching(merr, errSentinel, errBasic))
+		testutils.AssertEqual(t, 3, len(merr.Unwrap()))
+
+		err := WithoutMatching(NewMultiError(errBasic, errWrapped, New("other")), errSentinel)
+		testutils.AssertEqual(t, "[new err; other]", err.Error())
+	})
+
+	t.Run("keeps the message", func(t *testing.T) {
+		err := WithoutMatching(NewMultiErrorMsg("failed", errBasic, errSentinel), errSentinel)
+		testutils.AssertEqual(t, "failed: [new err]", err.Error())
+	})
+
+	t.Run("standard library multierror", func(t *testing.T) {
+		joined := fmt.Errorf("%w; %w; %w", errBasic, errWrapped, New("other"))
+		err := WithoutMatching(joined, errSentinel)
+		testutils.AssertEqual(t, "[new err; other]", err.Error())
+	})
+}
+
+func TestMultiErrorUnique(t *testing.T) {
+	errRefused := NewWithFrame("connection refused")
+	merr := NewMultiErrorMsg("failed", errRefused, errSentinel, New("connection refused"), errRefused)
+
+	unique := merr.Unique()
+	testutils.AssertEqual(t, "failed: [connection refused (x3); sentinel err]", unique.Error())
+	testutils.AssertEqual(t, 4, len(merr.Unwrap()))
+	testutils.AssertTrue(t, Is(unique, errRefused))
+	testutils.AssertTrue(t, Is(unique, errSentinel))
+	testutils.AssertEqual(t, errSentinel, unique.Unwrap()[1])
+	testutils.AssertEqual(t, FramesFrom(errRefused).Locations(), FramesFrom(unique.Unwrap()[0]).Locations())
+
+	first := unique.Unwrap()[0]
+	testutils.AssertEqual(t, "connection refused (x3)", fmt.Sprintf("%v", first))
+	testutils.AssertEqual(t, `"connection refused (x3)"`, fmt.Sprintf("%q", first))
+	testutils.AssertEqual(t, `&errors.withCount{"connection refused (x3)"}`, fmt.Sprintf("%#v", first))
+	testutils.AssertMatch(t, `^connection refused \(x3\)\ngithub.com/secureworks/errors\.TestMultiErrorUnique\n`, fmt.Sprintf("%+v", first))
+
+	t.Run("by key", func(t *testing.T) {
+		unique := NewMultiError(New("a: 1"), New("b: 1"), New("a: 2")).UniqueBy(func(err error) string {
+			return err.Error()[:1]
+		})
+		testutils.AssertEqual(t, "[a: 1 (x2); b: 1]", unique.Error())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		testutils.AssertNil(t, NewMultiError().Unique().ErrorOrNil())
+	})
+}
+
+func TestDedupe(t *testing.T) {
+	testutils.AssertNil(t, Dedupe(nil))
+	testutils.AssertEqual(t, errBasic, Dedupe(errBasic))
+
+	err := Dedupe(NewMultiError(errBasic, errBasic))
+	testutils.AssertEqual(t, "[new err (x2)]", err.Error())
+	testutils.AssertTrue(t, Is(err, errBasic))
+
+	err = Dedupe(fmt.Errorf("%w; %w", errBasic, errBasic))
+	testutils.AssertEqual(t, "[new err (x2)]", err.Error())
+}
+
+func TestFormatMultiError(t *testing.T) {
+	framed := func(msg string) error {
+		return NewWithFrames(msg, Frames{NewFrame("pkg.Fn", "pkg/fn.go", 3)})
+	}
+	merr := NewMultiErrorGrouped(framed("a"), NewMultiError(New("b"), framed("c")))
+
+	format := func(opts FormatOptions) string {
+		buf := new(byteWriter)
+		FormatMultiError(buf, merr, opts)
+		return buf.String()
+	}
+
+	t.Run("default", func(t *testing.T) {
+		testutils.AssertEqual(t, fmt.Sprintf("%+v", merr), format(FormatOptions{}))
+	})
+
+	t.Run("options", func(t *testing.T) {
+		testutils.AssertEqual(t, ""+
+			"failures:\n"+
+			"- (1/2) a\n"+
+			"    pkg.Fn\n"+
+			"    \tpkg/fn.go:3\n"+
+			"- (2/2) failures:\n"+
+			"    - (1/2) b\n"+
+			"    - (2/2) c\n"+
+			"        pkg.Fn\n"+
+			"        \tpkg/fn.go:3",
+			format(FormatOptions{
+				Header:            "failures",
+				MemberPrefix:      func(i, n int) string { return fmt.Sprintf("- (%d/%d) ", i, n) },
+				Indent:            "    ",
+				Compact:           true,
+				NoTrailingNewline: true,
+			}))
+	})
+
+	t.Run("message", func(t *testing.T) {
+		buf := new(byteWriter)
+		FormatMultiError(buf, NewMultiErrorMsg("2 uploads failed", New("a"), New("b")), FormatOptions{Header: "failures", Compact: true})
+		testutils.AssertEqual(t, "2 uploads failed:\n* error 1 of 2: a\n* error 2 of 2: b\n", buf.String())
+	})
+
+	t.Run("nested in a chain", func(t *testing.T) {
+		chained := NewMultiError(New("a"), WithFrames(NewMultiError(New("b"), framed("c")), Frames{NewFrame("pkg.Wrap", "pkg/wrap.go", 7)}))
+		opts := FormatOptions{Compact: true, NoTrailingNewline: true, Indent: "  "}
+		previous := SetMultiErrorFormat(opts)
+		defer SetMultiErrorFormat(previous)
+
+		testutils.AssertEqual(t, ""+
+			"multiple errors:\n"+
+			"* error 1 of 2: a\n"+
+			"* error 2 of 2: [b; c]\n"+
+			"  pkg.Wrap\n"+
+			"  \tpkg/wrap.go:7\n"+
+			"\n"+
+			"  multiple errors:\n"+
+			"  * error 1 of 2: b\n"+
+			"  * error 2 of 2: c\n"+
+			"    pkg.Fn\n"+
+			"    \tpkg/fn.go:3",
+			fmt.Sprintf("%+v", chained))
+
+		SetMultiErrorFormat(FormatOptions{})
+		buf := new(byteWriter)
+		FormatMultiError(buf, chained, opts)
+		testutils.AssertEqual(t, fmt.Sprintf("%+v", chained), "multiple errors:\n\n* error 1 of 2: a\n\n"+
+			"* error 2 of 2: [b; c]\npkg.Wrap\n\tpkg/wrap.go:7\n\n"+
+			"multiple errors:\n\n* error 1 of 2: b\n\n* error 2 of 2: c\npkg.Fn\n\tpkg/fn.go:3\n\n")
+		testutils.AssertEqual(t, ""+
+			"multiple errors:\n"+
+			"* error 1 of 2: a\n"+
+			"* error 2 of 2: [b; c]\n"+
+			"  pkg.Wrap\n"+
+			"  \tpkg/wrap.go:7\n"+
+			"\n"+
+			"  multiple errors:\n"+
+			"\n"+
+			"  * error 1 of 2: b\n"+
+			"\n"+
+			"  * error 2 of 2: c\n"+
+			"  pkg.Fn\n"+
+			"  \tpkg/fn.go:3",
+			buf.String())
+	})
+}
+
+// byteWriter collects what is written to it.
+type byteWriter []byte
+
+func (b *byteWriter) Write(p []byte) (int, error) {
+	*b = append(*b, p...)
+	return len(p), nil
+}
+
+func (b *byteWriter) String() string { return string(*b) }
+
+func TestMultiErrorAtLeastAtMost(t *testing.T) {
+	merr := NewMultiError(
+		errSentinel,
+		WithFrame(errSentinel),
+		Errorf("replica 3: %w", Errorf("writing: %w", errSentinel)),
+		errBasic,
+	)
+	testutils.AssertTrue(t, merr.AtLeast(3, errSentinel))
+	testutils.AssertFalse(t, merr.AtLeast(4, errSentinel))
+	testutils.AssertTrue(t, merr.AtMost(3, errSentinel))
+	testutils.AssertFalse(t, merr.AtMost(2, errSentinel))
+	testutils.AssertTrue(t, merr.AtLeast(1, errBasic))
+	testutils.AssertTrue(t, merr.AtMost(0, io.EOF))
+
+	t.Run("nested multierror is one branch", func(t *testing.T) {
+		merr := NewMultiErrorGrouped(NewMultiError(errSentinel, errSentinel), errSentinel)
+		testutils.AssertTrue(t, merr.AtLeast(2, errSentinel))
+		testutils.AssertFalse(t, merr.AtLeast(3, errSentinel))
+	})
+
+	t.Run("nil and empty", func(t *testing.T) {
+		for _, merr := range []*MultiError{nil, NewMultiError()} {
+			testutils.AssertTrue(t, merr.AtLeast(0, errSentinel))
+			testutils.AssertFalse(t, merr.AtLeast(1, errSentinel))
+			testutils.AssertTrue(t, merr.AtMost(0, errSentinel))
+		}
+	})
+}
+
+func TestAppendf(t *testing.T) {
+	t.Run("appends with a frame", func(t *testing.T) {
+		err := Appendf(errBasic, "closing output: %w", errSentinel)
+		errs := ErrorsFrom(err)
+		testutils.AssertEqual(t, 2, len(errs))
+		testutils.AssertEqual(t, "closing output: sentinel err", errs[1].Error())
+		testutils.AssertTrue(t, Is(err, errSentinel))
+		testutils.AssertMatch(t, `errors\.TestAppendf\.func1$`, LocationOf(FramesFrom(errs[1])[0]).Function)
+	})
+
+	t.Run("nil receiver", func(t *testing.T) {
+		err := Appendf(nil, "closing output: %w", errSentinel)
+		testutils.AssertEqual(t, "closing output: sentinel err", err.Error())
+		testutils.AssertEqual(t, 1, len(ErrorsFrom(err)))
+	})
+
+	t.Run("nil wrapped error", func(t *testing.T) {
+		testutils.AssertNil(t, Appendf(nil, "closing output: %w", nil))
+		testutils.AssertEqual(t, errBasic, Appendf(errBasic, "closing output: %w", nil))
+		testutils.AssertEqual(t, errBasic, Appendf(errBasic, "%w and %w", nil, nil))
+		testutils.AssertEqual(t, 2, len(ErrorsFrom(Appendf(errBasic, "%w and %w", nil, errSentinel))))
+	})
+
+	t.Run("without wrapping", func(t *testing.T) {
+		err := Appendf(NewMultiError(errBasic, errSentinel), "%d items failed", 3)
+		testutils.AssertEqual(t, "[new err; sentinel err; 3 items failed]", err.Error())
+	})
+}
+
+func TestAppendIntof(t *testing.T) {
+	var err error
+	testutils.AssertFalse(t, AppendIntof(&err, "closing output: %w", nil))
+	testutils.AssertNil(t, err)
+	testutils.AssertTrue(t, AppendIntof(&err, "closing output: %w", errSentinel))
+	testutils.AssertTrue(t, AppendIntof(&err, "closing input: %w", errBasic))
+	testutils.AssertEqual(t, "[closing output: sentinel err; closing input: new err]", err.Error())
+	testutils.AssertMatch(t, `errors\.TestAppendIntof$`, LocationOf(FramesFrom(ErrorsFrom(err)[0])[0]).Function)
+
+	defer func() { testutils.AssertNotNil(t, recover()) }()
+	AppendIntof(nil, "closing output: %w", errSentinel)
+}
+
+func TestMultiErrorUniqueSame(t *testing.T) {
+	merr := NewMultiError(Errorf("dial: %w", errBasic), Errorf("dial: %w", errBasic), Errorf("retry 2: %w", errBasic))
+	testutils.AssertEqual(t, 1, len(merr.Unique().Unwrap()))
+	testutils.AssertEqual(t, "[dial: new err (x3)]", merr.UniqueSame(SameOptions{}).Error())
+	testutils.AssertEqual(t, 2, len(merr.UniqueSame(SameOptions{FullMessages: true}).Unwrap()))
+}
+
+func TestMultiErrorSort(t *testing.T) {
+	errC := WithFrame(New("c"))
+	merr := NewMultiErrorMsg("failed", errC, Errorf("a: %w", errSentinel), New("b"), New("a: other"))
+
+	sorted := merr.SortByMessage()
+	testutils.AssertEqual(t, "failed: [a: other; a: sentinel err; b; c]", sorted.Error())
+	testutils.AssertEqual(t, "failed: [c; a: sentinel err; b; a: other]", merr.Error())
+	testutils.AssertEqual(t, errC, sorted.Unwrap()[3])
+	testutils.AssertEqual(t, FramesFrom(errC).Locations(), FramesFrom(sorted.Unwrap()[3]).Locations())
+	testutils.AssertTrue(t, Is(sorted, errSentinel))
+
+	t.Run("stable", func(t *testing.T) {
+		sorted := merr.Sort(func(a, b error) bool { return len(a.Error()) < len(b.Error()) })
+		testutils.AssertEqual(t, "failed: [c; b; a: other; a: sentinel err]", sorted.Error())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		testutils.AssertNil(t, NewMultiError().SortByMessage().ErrorOrNil())
+	})
+}
+
+func TestMultiErrorLenAt(t *testing.T) {
+	merr := NewMultiError(errBasic, errSentinel)
+	testutils.AssertEqual(t, 2, merr.Len())
+	testutils.AssertEqual(t, errBasic, merr.At(0))
+	testutils.AssertEqual(t, errSentinel, merr.At(1))
+	testutils.AssertEqual(t, 0, (*MultiError)(nil).Len())
+	testutils.AssertEqual(t, 0, NewMultiError().Len())
+
+	defer func() { testutils.AssertNotNil(t, recover()) }()
+	merr.At(2)
+}
+
+// cyclicMultiError is a multierror that can have itself in its errors.
+type cyclicMultiError struct{ errs []error }
+
+func (merr *cyclicMultiError) Error() string   { return "cyclic" }
+func (merr *cyclicMultiError) Unwrap() []error { return merr.errs }
+
+func TestErrorsFromAll(t *testing.T) {
+	err1, err2, err3, err4 := New("err1"), New("err2"), New("err3"), New("err4")
+
+	t.Run("nil", func(t *testing.T) {
+		testutils.AssertNil(t, ErrorsFromAll(nil))
+	})
+
+	t.Run("single error", func(t *testing.T) {
+		wrapped := WithMessage(err1, "wrapped")
+		testutils.AssertEqual(t, []error{wrapped}, ErrorsFromAll(wrapped))
+	})
+
+	t.Run("empty multierror", func(t *testing.T) {
+		testutils.AssertEqual(t, 0, len(ErrorsFromAll(NewMultiError())))
+		testutils.AssertEqual(t, 0, len(ErrorsFromAll(WithStackTrace(NewMultiError()))))
+	})
+
+	t.Run("nested through wrappers", func(t *testing.T) {
+		wrapped := fmt.Errorf("wrap: %w", err4)
+		err := WithStackTrace(NewMultiError(
+			err1,
+			WithFrame(Join(err2, fmt.Errorf("joined: %w", NewMultiError(err3)))),
+			wrapped,
+		))
+		testutils.AssertEqual(t, []error{err1, err2, err3, wrapped}, ErrorsFromAll(err))
+	})
+
+	t.Run("stdlib multierrors", func(t *testing.T) {
+		err := fmt.Errorf("%w; %w; %w", err1, fmt.Errorf("%w; %w", err2, err3), err4)
+		testutils.AssertEqual(t, []error{err1, err2, err3, err4}, ErrorsFromAll(err))
+	})
+
+	t.Run("repeated errors", func(t *testing.T) {
+		shared := NewMultiError(err1, err2)
+		err := &cyclicMultiError{errs: []error{shared, shared}}
+		testutils.AssertEqual(t, []error{err1, err2, err1, err2}, ErrorsFromAll(err))
+	})
+
+	t.Run("cycles", func(t *testing.T) {
+		err := &cyclicMultiError{errs: []error{err1}}
+		err.errs = append(err.errs, fmt.Errorf("wrap: %w", err), err2)
+		testutils.AssertEqual(t, []error{err1, err2}, ErrorsFromAll(err))
+	})
+}
+
+func TestTryAppendInto(t *testing.T) {
+	t.Run("nil receiver", func(t *testing.T) {
+		var panicErr error
+		func() {
+			defer func() { panicErr = recover().(error) }()
+			AppendInto(nil, errBasic)
+		}()
+
+		appended, err := TryAppendInto(nil, errBasic)
+		testutils.AssertFalse(t, appended)
+		testutils.AssertNotNil(t, err)
+		testutils.AssertEqual(t, panicErr.Error(), err.Error())
+		ff := FramesFrom(err)
+		testutils.AssertEqual(t, 1, len(ff))
+		testutils.AssertEqual(t, "github.com/secureworks/errors.TestTryAppendInto.func1", LocationOf(ff[0]).Function)
+
+		_, err = TryAppendInto(nil, nil)
+		testutils.AssertNotNil(t, err)
+	})
+
+	t.Run("nil appendee", func(t *testing.T) {
+		var err error
+		appended, tryErr := TryAppendInto(&err, nil)
+		testutils.AssertFalse(t, appended)
+		testutils.AssertNil(t, tryErr)
+		testutils.AssertNil(t, err)
+	})
+
+	t.Run("same as AppendInto", func(t *testing.T) {
+		for _, appending := range []error{
+			errSentinel,
+			NewMultiError(New("a"), New("b")),
+			fmt.Errorf("%w; %w", New("a"), New("b")),
+		} {
+			for _, initial := range []error{nil, errBasic, NewMultiError(errBasic, New("c"))} {
+				want, got := initial, initial
+				wantAppended := AppendInto(&want, appending)
+				appended, err := TryAppendInto(&got, appending)
+				testutils.AssertNil(t, err)
+				testutils.AssertEqual(t, wantAppended, appended)
+				testutils.AssertEqual(t, ErrorsFrom(want), ErrorsFrom(got))
+			}
+		}
+	})
+}
+
+func TestAppendResultf(t *testing.T) {
+	t.Run("panics if first is nil", func(t *testing.T) {
+		err := func() (err error) {
+			defer func() {
+				err = recover().(error)
+			}()
+			AppendResultf(nil, newTestCloser(nil).Close, "close")
+			return
+		}()
+		testutils.AssertEqual(t,
+			`errors.AppendResultf used incorrectly: receiving pointer must not be nil`,
+			err.Error())
+	})
+
+	t.Run("nil appends nil", func(t *testing.T) {
+		closer := newTestCloser(nil)
+		var allocs float64
+		err := func() (e error) {
+			allocs = testing.AllocsPerRun(100, func() {
+				AppendResultf(&e, closer.Close, "closing %s (%d)", "a.txt", 1)
+			})
+			return
+		}()
+		testutils.AssertNil(t, err)
+		testutils.AssertEqual(t, float64(0), allocs)
+	})
+
+	t.Run("wraps the results", func(t *testing.T) {
+		var returned Frame
+		err := func() (e error) {
+			for i, closeErr := range []error{errBasic, nil, errSentinel} {
+				defer AppendResultf(&e, newTestCloser(closeErr).Close, "closing file %d", i)
+			}
+			returned = Caller()
+			return
+		}()
+		testutils.AssertEqual(t, "[closing file 2: sentinel err; closing file 0: new err]", err.Error())
+		testutils.AssertTrue(t, Is(err, errBasic))
+		testutils.AssertTrue(t, Is(err, errSentinel))
+
+		errs := ErrorsFrom(err)
+		testutils.AssertEqual(t, 2, len(errs))
+		for _, err := range errs {
+			ff := FramesFrom(err)
+			testutils.AssertEqual(t, 1, len(ff))
+			testutils.AssertEqual(t, LocationOf(returned).Function, LocationOf(ff[0]).Function)
+		}
+	})
+
+	t.Run("appends to an error", func(t *testing.T) {
+		err := func() (e error) {
+			defer AppendResultf(&e, newTestCloser(errSentinel).Close, "closing %q", "a.txt")
+			return errBasic
+		}()
+		testutils.AssertEqual(t, `[new err; closing "a.txt": sentinel err]`, err.Error())
+	})
+}
+
+func TestMultiErrorFirstLast(t *testing.T) {
+	var merr *MultiError
+	testutils.AssertNil(t, merr.First())
+	testutils.AssertNil(t, merr.Last())
+	testutils.AssertNil(t, NewMultiError().First())
+	testutils.AssertNil(t, NewMultiError().Last())
+
+	merr = NewMultiError(errBasic, errSentinel)
+	testutils.AssertEqual(t, errBasic, merr.First())
+	testutils.AssertEqual(t, errSentinel, merr.Last())
+	testutils.AssertEqual(t, errBasic, NewMultiError(errBasic).Last())
+}
+
+func TestMultiErrorSequential(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		var merr *MultiError
+		testutils.AssertNil(t, merr.Sequential())
+		testutils.AssertNil(t, NewMultiError().Sequential())
+	})
+
+	t.Run("walks the errors", func(t *testing.T) {
+		merr := NewMultiError(errBasic, fmt.Errorf("wrapped: %w", errSentinel))
+		var msgs []string
+		for err := merr.Sequential(); err != nil; err = Unwrap(err) {
+			msgs = append(msgs, err.Error())
+		}
+		testutils.AssertEqual(t, []string{
+			"[new err; wrapped: sentinel err]",
+			"new err",
+			"wrapped: sentinel err",
+		}, msgs)
+		testutils.AssertEqual(t, 2, Depth(merr.Sequential()))
+	})
+
+	t.Run("Is and As", func(t *testing.T) {
+		inner := NewMultiError(errBasic, errSentinel)
+		merr := NewMultiErrorGrouped(New("first"), fmt.Errorf("wrapped: %w", inner))
+		view := merr.Sequential()
+		testutils.AssertTrue(t, Is(view, merr))
+		testutils.AssertTrue(t, Is(view, errSentinel))
+		testutils.AssertFalse(t, Is(view, New("new err")))
+
+		var target *MultiError
+		testutils.AssertTrue(t, As(view, &target))
+		testutils.AssertEqual(t, inner, target)
+		testutils.AssertFalse(t, As(NewMultiError(errBasic).Sequential(), &target))
+	})
+
+	t.Run("frames of the first error", func(t *testing.T) {
+		first := NewWithFrame("first")
+		second := NewWithStackTrace("second")
+		merr := NewMultiError(first, second)
+		view := merr.Sequential()
+
+		ff := FramesFrom(view)
+		testutils.AssertEqual(t, 1, len(ff))
+		testutils.AssertEqual(t, FramesFrom(first), ff)
+		testutils.AssertEqual(t, FramesFrom(merr.First()), ff)
+		origin, ok := Origin(view)
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, ff[0], origin)
+		testutils.AssertTrue(t, HasFrames(view))
+		testutils.AssertEqual(t, FramesFrom(second), FramesFrom(Unwrap(Unwrap(view))))
+
+		testutils.AssertEqual(t, 0, len(FramesFrom(NewMultiError(errBasic, second).Sequential())))
+	})
+
+	t.Run("formats like the errors", func(t *testing.T) {
+		merr := NewMultiError(NewWithFrame("first"), errBasic)
+		view := merr.Sequential()
+		testutils.AssertEqual(t, fmt.Sprintf("%+v", merr), fmt.Sprintf("%+v", view))
+		testutils.AssertEqual(t, fmt.Sprintf("%+v", merr.First()), fmt.Sprintf("%+v", Unwrap(view)))
+		testutils.AssertEqual(t, `"new err"`, fmt.Sprintf("%q", Unwrap(Unwrap(view))))
+	})
+
+	t.Run("keeps the errors it was created with", func(t *testing.T) {
+		merr := NewMultiError(errBasic)
+		view := merr.Sequential()
+		merr = Append(merr, errSentinel).(*MultiError)
+		testutils.AssertEqual(t, 2, merr.Len())
+		testutils.AssertEqual(t, 1, Depth(view))
+	})
+}
+
+func TestErrorFromBytes_wrappedMultiError(t *testing.T) {
+	t.Run("round trips", func(t *testing.T) {
+		err := WithFrame(NewMultiError(NewWithFrame("a"), New("b")))
+		parsed, parseErr := ParseErrorFromBytes([]byte(fmt.Sprintf("%+v", err)))
+		testutils.AssertNil(t, parseErr)
+		testutils.AssertEqual(t, err.Error(), parsed.Error())
+		testutils.AssertEqual(t, fmt.Sprintf("%+v", err), fmt.Sprintf("%+v", parsed))
+		testutils.AssertEqual(t, LocationOf(FramesFrom(err)[0]), LocationOf(FramesFrom(parsed)[0]))
+
+		errs := ErrorsFromChain(parsed)
+		testutils.AssertEqual(t, 2, len(errs))
+		testutils.AssertEqual(t, 1, len(FramesFrom(errs[0])))
+		testutils.AssertEqual(t, 0, len(FramesFrom(errs[1])))
+	})
+
+	t.Run("with a message", func(t *testing.T) {
+		err := Errorf("batch: %w", NewMultiError(NewWithFrame("a"), New("b")))
+		parsed, parseErr := ParseErrorFromBytes([]byte(fmt.Sprintf("%+v", err)))
+		testutils.AssertNil(t, parseErr)
+		testutils.AssertEqual(t, "batch: [a; b]", parsed.Error())
+		testutils.AssertEqual(t, fmt.Sprintf("%+v", err), fmt.Sprintf("%+v", parsed))
+		testutils.AssertEqual(t, 2, len(ErrorsFromChain(parsed)))
+	})
+
+	t.Run("without frames", func(t *testing.T) {
+		err := WithFrame(NewMultiError(New("a"), New("b")))
+		parsed, parseErr := ParseErrorFromBytes([]byte(fmt.Sprintf("%+v", err)))
+		testutils.AssertNil(t, parseErr)
+		testutils.AssertEqual(t, fmt.Sprintf("%+v", err), fmt.Sprintf("%+v", parsed))
+	})
+}
+
+func TestWithoutMatching_wrapped(t *testing.T) {
+	errDisk := New("disk full")
+
+	t.Run("with frame", func(t *testing.T) {
+		err := WithoutMatching(WithFrame(NewMultiError(errSentinel, errDisk)), errSentinel)
+		testutils.AssertEqual(t, errDisk, err)
+	})
+
+	t.Run("with message", func(t *testing.T) {
+		err := WithoutMatching(Errorf("batch: %w", NewMultiError(errSentinel, errDisk, errBasic)), errSentinel)
+		testutils.AssertEqual(t, "[disk full; new err]", err.Error())
+	})
+
+	t.Run("all match", func(t *testing.T) {
+		testutils.AssertNil(t, WithoutMatching(WithFrame(NewMultiError(errSentinel, errSentinel)), errSentinel))
+	})
+
+	t.Run("none match", func(t *testing.T) {
+		wrapped := WithFrame(NewMultiError(errBasic, errDisk))
+		testutils.AssertEqual(t, wrapped, WithoutMatching(wrapped, errSentinel))
+	})
+}
+
+func TestMultiError_nilReceiver(t *testing.T) {
+	var merr *MultiError
+	testutils.AssertNil(t, merr.Filter(func(error) bool { return true }).ErrorOrNil())
+	testutils.AssertNil(t, merr.Unique().ErrorOrNil())
+	testutils.AssertNil(t, merr.UniqueBy(func(err error) string { return err.Error() }).ErrorOrNil())
+}
+
+func TestMultiError_sortNilReceiver(t *testing.T) {
+	var merr *MultiError
+	testutils.AssertNil(t, merr.Sort(func(a, b error) bool { return true }).ErrorOrNil())
+	testutils.AssertNil(t, merr.SortByMessage().ErrorOrNil())
+}
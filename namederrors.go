@@ -0,0 +1,227 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// NamedErrors is a list of errors, each with a label, such as the
+// results of validating fields keyed by the field name. It is built on
+// MultiError: it implements the multierror interface, and its errors
+// are formatted the same way, except that each is prefixed with its
+// label. Errors are always ordered by label so that output is stable.
+//
+// The zero value is ready to use:
+//
+//	ne := new(errors.NamedErrors)
+//	ne.Set("email", errInvalidAddress)
+//	ne.Error() // => "[email: invalid address]"
+//
+// Like MultiError, NamedErrors is not synchronized for concurrent use,
+// and is never nil when it has no errors: use ErrorOrNil to get a clean
+// error interface.
+type NamedErrors struct {
+	errors map[string]error
+	keys   []string // The labels in the order they were set.
+
+	formatGuard
+}
+
+var _ interface { // Assert interface implementation.
+	error
+	multierror
+	fmt.Formatter
+	json.Marshaler
+} = (*NamedErrors)(nil)
+
+// NamedFromMap returns NamedErrors from a map of labels to errors. Nil
+// error values are not included. The errors are set in order of their
+// labels (see Keys).
+func NamedFromMap(errs map[string]error) *NamedErrors {
+	labels := make([]string, 0, len(errs))
+	for label := range errs {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	ne := new(NamedErrors)
+	for _, label := range labels {
+		ne.Set(label, errs[label])
+	}
+	return ne
+}
+
+// Set sets the error for the label, replacing any error already set
+// for it. Setting a nil error removes the label.
+func (ne *NamedErrors) Set(label string, err error) {
+	if err == nil {
+		if _, ok := ne.errors[label]; ok {
+			delete(ne.errors, label)
+			for i, key := range ne.keys {
+				if key == label {
+					ne.keys = append(ne.keys[:i:i], ne.keys[i+1:]...)
+					break
+				}
+			}
+		}
+		return
+	}
+	if ne.errors == nil {
+		ne.errors = make(map[string]error)
+	}
+	if _, ok := ne.errors[label]; !ok {
+		ne.keys = append(ne.keys, label)
+	}
+	ne.errors[label] = err
+}
+
+// Get returns the error set for the label, or nil if there is none.
+func (ne *NamedErrors) Get(label string) error {
+	return ne.errors[label]
+}
+
+// Len returns the number of errors.
+func (ne *NamedErrors) Len() int {
+	return len(ne.errors)
+}
+
+// Labels returns the labels that have errors, in sorted order.
+func (ne *NamedErrors) Labels() []string {
+	labels := make([]string, 0, len(ne.errors))
+	for label := range ne.errors {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// Keys returns the labels that have errors, in the order they were
+// first set (eg, the order of the records of a batch that failed),
+// rather than in sorted order like Labels. A label that was removed
+// and set again is in the order it was set again.
+//
+//	for _, id := range ne.Keys() {
+//		log.Printf("record %s: %v", id, ne.Get(id))
+//	}
+func (ne *NamedErrors) Keys() []string {
+	return append([]string(nil), ne.keys...)
+}
+
+func (ne *NamedErrors) Error() string {
+	return ne.multiError().Error()
+}
+
+// Unwrap returns the errors, each wrapped with its label, in order of
+// the labels. It returns a nil slice if there are no errors. The
+// wrapped errors can be unwrapped, so Is and As work as usual.
+func (ne *NamedErrors) Unwrap() []error {
+	return ne.multiError().errors
+}
+
+// ErrorOrNil is used to get a clean error interface for reflection, nil
+// checking and other comparisons. If there are no errors it returns
+// nil, and if there is a single error then it is returned wrapped with
+// its label. Otherwise, it returns the NamedErrors retyped for the
+// error interface.
+func (ne *NamedErrors) ErrorOrNil() error {
+	switch len(ne.errors) {
+	case 0:
+		return nil
+	case 1:
+		return ne.Unwrap()[0]
+	default:
+		return ne
+	}
+}
+
+func (ne *NamedErrors) Format(s fmt.State, verb rune) {
+	if !ne.enterFormat(s, verb) {
+		return
+	}
+	defer ne.leaveFormat()
+
+	if verb == 'v' && s.Flag('#') {
+		io.WriteString(s, "*errors.NamedErrors")
+		formatMessages(s, ne, [2]string{"{", "}"})
+		return
+	}
+	ne.multiError().Format(s, verb)
+}
+
+// MarshalJSON marshals the errors as a JSON object of labels to error
+// messages:
+//
+//	{"email":"invalid address"}
+func (ne *NamedErrors) MarshalJSON() ([]byte, error) {
+	msgs := make(map[string]string, len(ne.errors))
+	for label, err := range ne.errors {
+		msgs[label] = safeError(err, 'v')
+	}
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(msgs); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// multiError returns a MultiError of the labeled errors.
+func (ne *NamedErrors) multiError() *MultiError {
+	merr := new(MultiError)
+	for _, label := range ne.Labels() {
+		merr.errors = append(merr.errors, &withLabel{error: ne.errors[label], label: label})
+	}
+	return merr
+}
+
+// Label error wrapper.
+
+// withLabel implements an error type annotated with a label, used for
+// the errors in NamedErrors.
+type withLabel struct {
+	error error
+	label string
+
+	formatGuard
+}
+
+var _ interface { // Assert interface implementation.
+	error
+	Unwrap() error
+	fmt.Formatter
+} = (*withLabel)(nil)
+
+func (w *withLabel) Error() string { return w.label + ": " + w.error.Error() }
+
+func (w *withLabel) Unwrap() error { return w.error }
+
+func (w *withLabel) Format(s fmt.State, verb rune) {
+	if !w.enterFormat(s, verb) {
+		return
+	}
+	defer w.leaveFormat()
+
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			io.WriteString(s, w.label+": ")
+			fmt.Fprintf(s, "%+v", w.error)
+			return
+		}
+		if s.Flag('#') {
+			fmt.Fprintf(s, "&errors.withLabel{%q}", w.Error())
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, w.label+": "+safeError(w.error, verb))
+	case 'q':
+		fmt.Fprintf(s, "%q", w.label+": "+safeError(w.error, verb))
+	default:
+		// empty
+	}
+}
@@ -0,0 +1,125 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+func TestNamedErrors(t *testing.T) {
+	errEmail := New("invalid address")
+	errName := New("required")
+
+	t.Run("zero value is empty", func(t *testing.T) {
+		ne := new(NamedErrors)
+		testutils.AssertEqual(t, 0, ne.Len())
+		testutils.AssertNil(t, ne.Get("email"))
+		testutils.AssertNil(t, ne.Unwrap())
+		testutils.AssertNil(t, ne.ErrorOrNil())
+		testutils.AssertEqual(t, "[]", ne.Error())
+	})
+
+	t.Run("sets and gets errors by label", func(t *testing.T) {
+		ne := new(NamedErrors)
+		ne.Set("email", errName)
+		ne.Set("email", errEmail)
+		ne.Set("name", errName)
+		testutils.AssertEqual(t, 2, ne.Len())
+		testutils.AssertEqual(t, errEmail, ne.Get("email"))
+		testutils.AssertEqual(t, errName, ne.Get("name"))
+
+		ne.Set("name", nil)
+		testutils.AssertEqual(t, 1, ne.Len())
+		testutils.AssertNil(t, ne.Get("name"))
+	})
+
+	t.Run("orders errors by label", func(t *testing.T) {
+		ne := NamedFromMap(map[string]error{
+			"name":  errName,
+			"email": errEmail,
+			"age":   nil,
+		})
+		testutils.AssertEqual(t, []string{"email", "name"}, ne.Labels())
+		testutils.AssertEqual(t, "[email: invalid address; name: required]", ne.Error())
+		testutils.AssertEqual(t, "email: invalid address", ne.Unwrap()[0].Error())
+	})
+
+	t.Run("unwraps labeled errors", func(t *testing.T) {
+		ne := NamedFromMap(map[string]error{"email": errEmail, "name": errName})
+		testutils.AssertTrue(t, Is(ne, errEmail))
+		testutils.AssertTrue(t, Is(ne, errName))
+		testutils.AssertEqual(t, errEmail, Unwrap(ne.Unwrap()[0]))
+
+		var target *NamedErrors
+		testutils.AssertTrue(t, As(ne.ErrorOrNil(), &target))
+		testutils.AssertEqual(t, errEmail, target.Get("email"))
+	})
+
+	t.Run("single error keeps its label", func(t *testing.T) {
+		ne := NamedFromMap(map[string]error{"email": errEmail})
+		err := ne.ErrorOrNil()
+		testutils.AssertEqual(t, "email: invalid address", err.Error())
+		testutils.AssertTrue(t, Is(err, errEmail))
+	})
+
+	t.Run("formats", func(t *testing.T) {
+		ne := NamedFromMap(map[string]error{"email": errEmail, "name": errName})
+		testutils.AssertEqual(t, "[email: invalid address; name: required]", fmt.Sprintf("%v", ne))
+		testutils.AssertEqual(t, `"[email: invalid address; name: required]"`, fmt.Sprintf("%q", ne))
+		testutils.AssertEqual(t,
+			`*errors.NamedErrors{email: invalid address; name: required}`,
+			fmt.Sprintf("%#v", ne))
+		testutils.AssertEqual(t, `&errors.withLabel{"email: invalid address"}`, fmt.Sprintf("%#v", ne.Unwrap()[0]))
+	})
+
+	t.Run("formats with frames", func(t *testing.T) {
+		ne := NamedFromMap(map[string]error{
+			"email": WithFrames(errEmail, Frames{NewFrame("example.Validate", "/src/example.go", 10)}),
+			"name":  errName,
+		})
+		testutils.AssertEqual(t, `multiple errors:
+
+* error 1 of 2: email: invalid address
+example.Validate
+	/src/example.go:10
+
+* error 2 of 2: name: required
+`, fmt.Sprintf("%+v", ne))
+	})
+
+	t.Run("keys in the order set", func(t *testing.T) {
+		ne := new(NamedErrors)
+		testutils.AssertEqual(t, 0, len(ne.Keys()))
+		for _, id := range []string{"rec-3", "rec-1", "rec-2", "rec-4"} {
+			ne.Set(id, errName)
+		}
+		ne.Set("rec-1", errEmail)
+		ne.Set("rec-2", nil)
+		ne.Set("rec-5", nil)
+		testutils.AssertEqual(t, []string{"rec-3", "rec-1", "rec-4"}, ne.Keys())
+		testutils.AssertEqual(t, []string{"rec-1", "rec-3", "rec-4"}, ne.Labels())
+		testutils.AssertEqual(t, errEmail, ne.Get("rec-1"))
+
+		ne.Set("rec-2", errName)
+		keys := ne.Keys()
+		testutils.AssertEqual(t, []string{"rec-3", "rec-1", "rec-4", "rec-2"}, keys)
+		keys[0] = "changed"
+		testutils.AssertEqual(t, "rec-3", ne.Keys()[0])
+
+		testutils.AssertEqual(t, []string{"email", "name"},
+			NamedFromMap(map[string]error{"name": errName, "email": errEmail}).Keys())
+	})
+
+	t.Run("marshals JSON", func(t *testing.T) {
+		ne := NamedFromMap(map[string]error{"name": errName, "email": New("invalid <address>")})
+		byt, err := ne.MarshalJSON()
+		testutils.AssertNil(t, err)
+		testutils.AssertEqual(t, `{"email":"invalid <address>","name":"required"}`, string(byt))
+
+		byt, err = json.Marshal(new(NamedErrors))
+		testutils.AssertNil(t, err)
+		testutils.AssertEqual(t, `{}`, string(byt))
+	})
+}
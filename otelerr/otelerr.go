@@ -0,0 +1,87 @@
+// Package otelerr records errors from the github.com/secureworks/errors
+// package on OpenTelemetry spans, with the frames captured in the error.
+//
+// trace.Span.RecordError records the message of an error and, at most,
+// the stack of the goroutine recording it, not the frames of the error.
+// Record instead sets the exception attributes of the span from the
+// error chain:
+//
+//	span := trace.SpanFromContext(ctx)
+//	otelerr.Record(span, err)
+//
+// The exception.stacktrace attribute has the frames returned by
+// errors.FramesFrom, in the `%+v` format of errors.Frame ("function",
+// a newline and a tab, then "file:line"), separated by newlines. If the
+// error chain has a multierror, an "exception" event is added to the
+// span for each of its errors, with the same attributes.
+package otelerr
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/secureworks/errors"
+)
+
+// The attribute keys of the OpenTelemetry semantic conventions for
+// exceptions, and the name of their event.
+const (
+	MessageKey    = attribute.Key("exception.message")
+	TypeKey       = attribute.Key("exception.type")
+	StacktraceKey = attribute.Key("exception.stacktrace")
+
+	ExceptionEvent = "exception"
+)
+
+// Record sets the exception attributes of the span for the error, and
+// adds an exception event for each error of a multierror in the chain.
+// It does nothing if the error is nil, if it has no frames (see
+// errors.HasFrames), or if the span is not recording: use
+// trace.Span.RecordError for errors without frames. The
+// exception.stacktrace attribute of the events is only set if their
+// error has frames.
+func Record(span trace.Span, err error) {
+	if err == nil || span == nil || !span.IsRecording() || !errors.HasFrames(err) {
+		return
+	}
+	span.SetAttributes(attributes(err)...)
+	var merr interface{ Unwrap() []error }
+	if !errors.As(err, &merr) {
+		return
+	}
+	for _, member := range merr.Unwrap() {
+		if member == nil {
+			continue
+		}
+		span.AddEvent(ExceptionEvent, trace.WithAttributes(attributes(member)...))
+	}
+}
+
+func attributes(err error) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		MessageKey.String(err.Error()),
+		TypeKey.String(reflect.TypeOf(err).String()),
+	}
+	if ff := errors.FramesFrom(err); len(ff) > 0 {
+		attrs = append(attrs, StacktraceKey.String(Stacktrace(ff)))
+	}
+	return attrs
+}
+
+// Stacktrace renders the frames in the format of the
+// exception.stacktrace attribute: each frame in the `%+v` format,
+// separated by newlines.
+func Stacktrace(ff errors.Frames) string {
+	b := new(strings.Builder)
+	for i, fr := range ff {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(b, "%+v", fr)
+	}
+	return b.String()
+}
@@ -0,0 +1,113 @@
+package otelerr
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/secureworks/errors"
+)
+
+var (
+	frMain = errors.NewFrame("main.main", "/src/svc/main.go", 10)
+	frRead = errors.NewFrame("github.com/acme/svc/store.Read", "/src/svc/store/read.go", 30)
+)
+
+// record records the error on a new span, and returns the ended span.
+func record(t *testing.T, err error) sdktrace.ReadOnlySpan {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	_, span := provider.Tracer("test").Start(context.Background(), "op")
+	Record(span, err)
+	span.End()
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	return spans[0]
+}
+
+func attributeMap(attrs []attribute.KeyValue) map[attribute.Key]string {
+	m := make(map[attribute.Key]string, len(attrs))
+	for _, kv := range attrs {
+		m[kv.Key] = kv.Value.AsString()
+	}
+	return m
+}
+
+func TestRecord(t *testing.T) {
+	t.Run("frames", func(t *testing.T) {
+		err := errors.WithFrames(fmt.Errorf("reading: %w", errors.NewWithFrames("not found", errors.Frames{frRead})), errors.Frames{frMain})
+		attrs := attributeMap(record(t, err).Attributes())
+		expected := map[attribute.Key]string{
+			MessageKey: "reading: not found",
+			TypeKey:    "*errors.withFrames",
+			StacktraceKey: "github.com/acme/svc/store.Read\n\t/src/svc/store/read.go:30\n" +
+				"main.main\n\t/src/svc/main.go:10",
+		}
+		if fmt.Sprint(expected) != fmt.Sprint(attrs) {
+			t.Errorf("expected %v, got %v", expected, attrs)
+		}
+	})
+
+	t.Run("without frames", func(t *testing.T) {
+		span := record(t, errors.Join(fmt.Errorf("failed"), errors.New("b")))
+		if len(span.Attributes()) != 0 || len(span.Events()) != 0 {
+			t.Errorf("expected nothing recorded, got %v %v", span.Attributes(), span.Events())
+		}
+	})
+
+	t.Run("multierror events", func(t *testing.T) {
+		err := errors.NewMultiError(errors.NewWithFrames("a", errors.Frames{frRead}), errors.New("b"))
+		span := record(t, errors.WithFrames(err, errors.Frames{frMain}))
+		events := span.Events()
+		if len(events) != 2 {
+			t.Fatalf("expected 2 events, got %d", len(events))
+		}
+		for i, expected := range []map[attribute.Key]string{
+			{MessageKey: "a", TypeKey: "*errors.withFrames", StacktraceKey: "github.com/acme/svc/store.Read\n\t/src/svc/store/read.go:30"},
+			{MessageKey: "b", TypeKey: "*errors.errorString"},
+		} {
+			if events[i].Name != ExceptionEvent {
+				t.Errorf("event %d: expected name %q, got %q", i, ExceptionEvent, events[i].Name)
+			}
+			if got := attributeMap(events[i].Attributes); fmt.Sprint(expected) != fmt.Sprint(got) {
+				t.Errorf("event %d: expected %v, got %v", i, expected, got)
+			}
+		}
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		span := record(t, nil)
+		if len(span.Attributes()) != 0 || len(span.Events()) != 0 {
+			t.Errorf("expected nothing recorded, got %v %v", span.Attributes(), span.Events())
+		}
+	})
+
+	t.Run("does not allocate without frames", func(t *testing.T) {
+		provider := sdktrace.NewTracerProvider()
+		_, span := provider.Tracer("test").Start(context.Background(), "op")
+		defer span.End()
+		if !span.IsRecording() {
+			t.Fatal("expected a recording span")
+		}
+		err := fmt.Errorf("failed: %w", errors.New("not found"))
+		if allocs := testing.AllocsPerRun(100, func() { Record(span, err) }); allocs != 0 {
+			t.Errorf("expected no allocations, got %v", allocs)
+		}
+	})
+
+	t.Run("does not allocate if not recording", func(t *testing.T) {
+		span := trace.SpanFromContext(context.Background())
+		err := errors.NewWithStackTrace("failed")
+		if allocs := testing.AllocsPerRun(100, func() { Record(span, err) }); allocs != 0 {
+			t.Errorf("expected no allocations, got %v", allocs)
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// FromPanic converts a value recovered from a panic into an error with
+// a stack trace, or returns nil if the value is nil. Call it in a
+// deferred function:
+//
+//	defer func() {
+//		if r := recover(); r != nil {
+//			err = errors.FromPanic(r)
+//		}
+//	}()
+//
+// The stack trace starts where the panic happened, rather than in the
+// deferred function. The message of the error is "panic: " followed by
+// the value (using its Error method if it is an error), and the error
+// prints the value with the `%#v` verb on a line after its stack trace
+// when printed with `%+v` (the original value, if it was panicked
+// again), so that the fields of a struct value are not lost. Use
+// PanicValueFrom to get the value back. ErrorFromBytes skips that line,
+// since the value cannot be parsed back.
+//
+// If the value is an error, the result wraps it, so Is and As match it
+// as if it had been returned instead of panicking. If the value is an
+// error converted by FromPanic (eg, it was panicked again after being
+// recovered), its message is not prefixed again, and its stack trace
+// (which is deeper) is the one used by FramesFrom.
+func FromPanic(r interface{}) error {
+	if r == nil {
+		return nil
+	}
+	return newPanicError(r, 1)
+}
+
+// Recover converts a panic into an error set on the given pointer, eg a
+// named error result, the same as FromPanic. It must be deferred
+// directly, since it calls recover:
+//
+//	func handle() (err error) {
+//		defer errors.Recover(&err)
+//		// ...
+//	}
+//
+// If there is no panic, Recover does nothing.
+func Recover(errp *error) {
+	if r := recover(); r != nil {
+		*errp = newPanicError(r, 1)
+	}
+}
+
+// PanicValueFrom returns the value that was recovered from a panic and
+// converted into an error in the chain by FromPanic or Recover. If the
+// value was panicked again after being converted, the original value
+// is returned. The second result is false if there is none.
+func PanicValueFrom(err error) (value interface{}, ok bool) {
+	for depth := 0; err != nil; err, depth = unwrapAt(err, depth), depth+1 {
+		if p, isPanic := err.(*panicError); isPanic {
+			value, ok = p.value, true
+		}
+	}
+	return
+}
+
+// panicError is an error converted from a value recovered from a
+// panic, with the stack trace from where the panic happened.
+type panicError struct {
+	value  interface{}
+	frames frames
+
+	formatGuard
+}
+
+var _ interface { // Assert interface implementation.
+	error
+	stackTracer
+	framer
+	Unwrap() error
+	fmt.Formatter
+} = (*panicError)(nil)
+
+//go:noinline
+func newPanicError(r interface{}, skipCallers int) *panicError {
+	countCreated(statsFromPanic)
+	return &panicError{value: r, frames: panicFrames(getStack(3 + skipCallers))}
+}
+
+// panicFrames returns the frames of the stack from where the panic
+// happened: the frames up to the runtime's panic function, and the
+// runtime frames directly after it (eg, for a nil pointer dereference),
+// are dropped.
+func panicFrames(ff frames) frames {
+	for i, fr := range ff {
+		if fr.getFunction() != "runtime.gopanic" {
+			continue
+		}
+		rest := ff[i+1:]
+		for len(rest) > 1 && strings.HasPrefix(rest[0].getFunction(), "runtime.") {
+			rest = rest[1:]
+		}
+		return rest
+	}
+	return ff
+}
+
+// panicValuePrefix starts the line with the panic value written after
+// the stack trace of a panic error printed with `%+v`.
+const panicValuePrefix = "panic value: "
+
+func (p *panicError) Error() string {
+	if err, ok := p.value.(error); ok {
+		if _, ok := PanicValueFrom(err); ok {
+			return safeError(err, 'v') // Panicked again.
+		}
+		return "panic: " + safeError(err, 'v')
+	}
+	return fmt.Sprintf("panic: %v", p.value)
+}
+
+// Unwrap returns the value recovered from the panic if it is an error,
+// otherwise nil.
+func (p *panicError) Unwrap() error {
+	err, _ := p.value.(error)
+	return err
+}
+
+// StackTrace returns the stack trace from where the panic happened.
+func (p *panicError) StackTrace() []uintptr {
+	return p.frames.StackTrace()
+}
+
+// Frames returns the frames of the stack trace from where the panic
+// happened.
+func (p *panicError) Frames() Frames {
+	return p.frames.Frames()
+}
+
+func (p *panicError) Format(s fmt.State, verb rune) {
+	if !p.enterFormat(s, verb) {
+		return
+	}
+	defer p.leaveFormat()
+
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			io.WriteString(s, safeError(p, verb))
+			formatFrames(s, verb, p)
+			value, _ := PanicValueFrom(p)
+			fmt.Fprintf(s, "\n%s%#v", panicValuePrefix, value)
+			return
+		}
+		if s.Flag('#') {
+			fmt.Fprintf(s, "&errors.panicError{%#v}", p.value)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, safeError(p, verb))
+	case 'q':
+		fmt.Fprintf(s, "%q", safeError(p, verb))
+	default:
+		// empty
+	}
+}
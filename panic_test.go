@@ -0,0 +1,187 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+type panicErr struct{}
+
+func (*panicErr) Error() string { panic("boom") }
+
+func TestFormatRecoversPanics(t *testing.T) {
+	cases := []struct {
+		name  string
+		error error
+	}{
+		{name: "WithFrame", error: WithFrame(&panicErr{})},
+		{name: "WithStackTrace", error: WithStackTrace(&panicErr{})},
+		{name: "WithMessage", error: WithMessage(&panicErr{}, "msg")},
+		{name: "MultiError", error: NewMultiError(New("err"), &panicErr{})},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, format := range []string{"%s", "%v", "%+v", "%q", "%#v"} {
+				msg := fmt.Sprintf(format, tt.error)
+				testutils.AssertNotEqual(t, "", msg, format)
+				testutils.AssertFalse(t, strings.Contains(msg, "Format method"), format, msg)
+			}
+		})
+	}
+
+	t.Run("renders a placeholder and frames", func(t *testing.T) {
+		err := WithFrames(&panicErr{}, Frames{NewFrame("pkg.Function", "/src/file.go", 10)})
+		testutils.AssertEqual(t, "%!s(PANIC=Error method: boom)", fmt.Sprintf("%s", err))
+		testutils.AssertEqual(t, `"%!q(PANIC=Error method: boom)"`, fmt.Sprintf("%q", err))
+		testutils.AssertEqual(t,
+			"%!v(PANIC=Error method: boom)\npkg.Function\n\t/src/file.go:10",
+			fmt.Sprintf("%+v", err))
+	})
+
+	t.Run("renders the other errors in a MultiError", func(t *testing.T) {
+		err := NewMultiError(New("err"), &panicErr{})
+		testutils.AssertEqual(t, "[err; %!v(PANIC=Error method: boom)]", err.Error())
+	})
+
+	t.Run("renders nil pointers", func(t *testing.T) {
+		var perr *panicErr
+		err := WithFrames(perr, nil)
+		testutils.AssertEqual(t, "<nil>", fmt.Sprintf("%s", err))
+	})
+}
+
+type panicPayload struct {
+	Code   int
+	Reason string
+}
+
+//go:noinline
+func panicWith(v interface{}) {
+	panic(v)
+}
+
+func recoverFrom(fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = FromPanic(r)
+		}
+	}()
+	fn()
+	return nil
+}
+
+func TestFromPanic(t *testing.T) {
+	t.Run("error", func(t *testing.T) {
+		errSentinel := &panicPayloadError{code: 7}
+		err := recoverFrom(func() { panicWith(Errorf("wrapped: %w", errSentinel)) })
+		testutils.AssertEqual(t, "panic: wrapped: payload 7", err.Error())
+		testutils.AssertTrue(t, Is(err, errSentinel))
+		var target *panicPayloadError
+		testutils.AssertTrue(t, As(err, &target))
+		testutils.AssertEqual(t, 7, target.code)
+
+		value, ok := PanicValueFrom(err)
+		testutils.AssertTrue(t, ok)
+		testutils.AssertTrue(t, Is(value.(error), errSentinel))
+	})
+
+	t.Run("string", func(t *testing.T) {
+		err := recoverFrom(func() { panicWith("boom") })
+		testutils.AssertEqual(t, "panic: boom", err.Error())
+		value, ok := PanicValueFrom(err)
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, "boom", value)
+		testutils.AssertNil(t, Unwrap(err))
+		testutils.AssertMatch(t, `(?s)^panic: boom\n.*\npanic value: "boom"$`, fmt.Sprintf("%+v", err))
+	})
+
+	t.Run("struct", func(t *testing.T) {
+		payload := panicPayload{Code: 42, Reason: "quota"}
+		err := recoverFrom(func() { panicWith(payload) })
+		testutils.AssertEqual(t, "panic: {42 quota}", err.Error())
+		value, ok := PanicValueFrom(WithFrame(err))
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, payload, value.(panicPayload))
+		testutils.AssertMatch(t,
+			`(?s)^panic: \{42 quota\}\ngithub.com/secureworks/errors\.panicWith\n.*`+
+				`\npanic value: errors\.panicPayload\{Code:42, Reason:"quota"\}$`,
+			fmt.Sprintf("%+v", err))
+		testutils.AssertEqual(t, `&errors.panicError{errors.panicPayload{Code:42, Reason:"quota"}}`, fmt.Sprintf("%#v", err))
+	})
+
+	t.Run("starts where the panic happened", func(t *testing.T) {
+		err := recoverFrom(func() { panicWith("boom") })
+		testutils.AssertEqual(t, "github.com/secureworks/errors.panicWith", LocationOf(FramesFrom(err)[0]).Function)
+
+		err = recoverFrom(func() {
+			var payload *panicPayload
+			_ = payload.Code // Runtime error.
+		})
+		testutils.AssertMatch(t, `^github.com/secureworks/errors\.TestFromPanic\.func\d+\.\d+$`, LocationOf(FramesFrom(err)[0]).Function)
+		var runtimeErr interface{ RuntimeError() }
+		testutils.AssertTrue(t, As(err, &runtimeErr))
+	})
+
+	t.Run("nested re-panics", func(t *testing.T) {
+		payload := panicPayload{Code: 1}
+		err := recoverFrom(func() {
+			inner := recoverFrom(func() { panicWith(payload) })
+			panicWith(WithFrame(inner))
+		})
+		testutils.AssertEqual(t, "panic: {1 }", err.Error())
+		value, ok := PanicValueFrom(err)
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, payload, value.(panicPayload))
+		testutils.AssertMatch(t, `(?s)^panic: \{1 \}\n.*\npanic value: errors\.panicPayload\{Code:1, Reason:""\}$`, fmt.Sprintf("%+v", err))
+
+		// The stack trace of the first panic is deepest in the chain.
+		ff := FramesFrom(err)
+		testutils.AssertEqual(t, "github.com/secureworks/errors.panicWith", LocationOf(ff[0]).Function)
+		testutils.AssertMatch(t, `\.TestFromPanic\.func\d+\.\d+\.\d+$`, LocationOf(ff[1]).Function)
+	})
+
+	t.Run("Recover", func(t *testing.T) {
+		fn := func() (err error) {
+			defer Recover(&err)
+			panicWith("boom")
+			return nil
+		}
+		err := fn()
+		testutils.AssertEqual(t, "panic: boom", err.Error())
+		testutils.AssertEqual(t, "github.com/secureworks/errors.panicWith", LocationOf(FramesFrom(err)[0]).Function)
+
+		fn = func() (err error) {
+			defer Recover(&err)
+			return New("failed")
+		}
+		testutils.AssertEqual(t, "failed", fn().Error())
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		testutils.AssertNil(t, FromPanic(nil))
+		_, ok := PanicValueFrom(New("failed"))
+		testutils.AssertFalse(t, ok)
+		_, ok = PanicValueFrom(nil)
+		testutils.AssertFalse(t, ok)
+	})
+	t.Run("round trip", func(t *testing.T) {
+		err := recoverFrom(func() { panicWith(panicPayload{Code: 42, Reason: "quota"}) })
+		parsed, ok := ErrorFromBytes([]byte(fmt.Sprintf("%+v", err)))
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, "panic: {42 quota}", parsed.Error())
+		testutils.AssertEqual(t, FramesFrom(err).Locations(), FramesFrom(parsed).Locations())
+
+		wrapped := WithCorrelationID(err, "req-1")
+		parsed, ok = ErrorFromBytes([]byte(fmt.Sprintf("%+v", wrapped)))
+		testutils.AssertTrue(t, ok)
+		id, _ := CorrelationIDFrom(parsed)
+		testutils.AssertEqual(t, "req-1", id)
+	})
+}
+
+type panicPayloadError struct{ code int }
+
+func (e *panicPayloadError) Error() string { return fmt.Sprintf("payload %d", e.code) }
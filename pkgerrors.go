@@ -0,0 +1,122 @@
+package errors
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// PkgCompat wraps the error so that it has the StackTrace method of the
+// errors from github.com/pkg/errors, for tooling that type-asserts for
+// it (eg, older Sentry SDKs and log enrichers), without this package
+// importing pkg/errors. The type parameter is pkg/errors' StackTrace
+// type:
+//
+//	err = errors.PkgCompat[pkgerrors.StackTrace](err)
+//	_, ok := err.(interface{ StackTrace() pkgerrors.StackTrace }) // => true
+//
+// The stack trace is the Frames of the error chain (see FramesFrom) as
+// return addresses, the convention used by pkg/errors, so pkg/errors
+// prints the same locations; synthetic frames are skipped. The error
+// wraps the given error, and has the same message and formatting. If
+// the given error is nil, PkgCompat returns nil.
+func PkgCompat[S ~[]F, F ~uintptr](err error) error {
+	if err == nil {
+		return nil
+	}
+	return &pkgCompat[S, F]{error: err}
+}
+
+// pkgCompat adds the StackTrace method of github.com/pkg/errors to an
+// error.
+type pkgCompat[S ~[]F, F ~uintptr] struct {
+	error
+	formatGuard
+}
+
+// StackTrace returns the Frames of the error chain as return addresses.
+func (w *pkgCompat[S, F]) StackTrace() S {
+	ff := FramesFrom(w.error)
+	st := make(S, 0, len(ff))
+	for _, fr := range ff {
+		if pc := PCFromFrame(fr); pc != 0 {
+			st = append(st, F(pc+1))
+		}
+	}
+	return st
+}
+
+// Cause returns the wrapped error, for pkg/errors.Cause.
+func (w *pkgCompat[S, F]) Cause() error { return w.error }
+
+func (w *pkgCompat[S, F]) Unwrap() error { return w.error }
+
+func (w *pkgCompat[S, F]) Format(s fmt.State, verb rune) {
+	if !w.enterFormat(s, verb) {
+		return
+	}
+	defer w.leaveFormat()
+
+	fmt.Fprintf(s, fmt.FormatString(s, verb), w.error)
+}
+
+// isPkgCompat marks the errors returned by PkgCompat, so that their
+// stack trace, which is only a copy of the Frames of the error they
+// wrap, is not read back as a stack trace.
+func (w *pkgCompat[S, F]) isPkgCompat() {}
+
+// stackTraceOf returns the stack trace of this error in the chain, as
+// program counters: from its StackTrace method if it implements
+// stackTracer, or if it has a StackTrace method that returns a slice of
+// uintptr-based values (eg, an error from github.com/pkg/errors).
+func stackTraceOf(err error) []uintptr {
+	if traceErr, ok := err.(stackTracer); ok {
+		return traceErr.StackTrace()
+	}
+	if _, ok := err.(interface{ isPkgCompat() }); ok {
+		return nil
+	}
+	return stackTraceFromMethod(err)
+}
+
+// pkgStackTraceMethods caches the index of the StackTrace method of the
+// types of errors, or -1 if they do not have one that returns a slice
+// of uintptr-based values.
+var pkgStackTraceMethods sync.Map // Of reflect.Type to int.
+
+// stackTraceFromMethod returns the program counters of the stack trace
+// of a github.com/pkg/errors error, or nil if it does not have one.
+func stackTraceFromMethod(err error) []uintptr {
+	typ := reflect.TypeOf(err)
+	index, ok := pkgStackTraceMethods.Load(typ)
+	if !ok {
+		index, _ = pkgStackTraceMethods.LoadOrStore(typ, pkgStackTraceMethod(typ))
+	}
+	if index.(int) < 0 {
+		return nil
+	}
+	v := reflect.ValueOf(err)
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil
+	}
+	st := v.Method(index.(int)).Call(nil)[0]
+	pcs := make([]uintptr, 0, st.Len())
+	for i := 0; i < st.Len(); i++ {
+		if pc := pcFromReturnAddress(uintptr(st.Index(i).Uint())); pc != 0 {
+			pcs = append(pcs, pc)
+		}
+	}
+	return pcs
+}
+
+func pkgStackTraceMethod(typ reflect.Type) int {
+	m, ok := typ.MethodByName("StackTrace")
+	if !ok || m.Type.NumIn() != 1 || m.Type.NumOut() != 1 {
+		return -1
+	}
+	out := m.Type.Out(0)
+	if out.Kind() != reflect.Slice || out.Elem().Kind() != reflect.Uintptr {
+		return -1
+	}
+	return m.Index
+}
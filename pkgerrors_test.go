@@ -0,0 +1,128 @@
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+// pkgFrame and pkgStackTrace mirror the github.com/pkg/errors Frame and
+// StackTrace types: return addresses as collected by runtime.Callers.
+type (
+	pkgFrame      uintptr
+	pkgStackTrace []pkgFrame
+)
+
+// location resolves the frame the same way pkg/errors does when it
+// prints a Frame.
+func (f pkgFrame) location() Location {
+	pc := uintptr(f) - 1
+	fn := runtime.FuncForPC(pc)
+	file, line := fn.FileLine(pc)
+	return Location{Function: fn.Name(), File: file, Line: line}
+}
+
+func pkgCallers() pkgStackTrace {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	st := make(pkgStackTrace, n)
+	for i, pc := range pcs[:n] {
+		st[i] = pkgFrame(pc)
+	}
+	return st
+}
+
+func TestFramesFromPkgErrors(t *testing.T) {
+	t.Run("matches pkg/errors locations", func(t *testing.T) {
+		st := pkgCallers()
+		ff := FramesFromPkgErrors(st)
+		testutils.AssertEqual(t, len(st), len(ff))
+		for i := range st {
+			testutils.AssertEqual(t, st[i].location(), LocationOf(ff[i]))
+		}
+		testutils.AssertEqual(t, "github.com/secureworks/errors.TestFramesFromPkgErrors.func1", LocationOf(ff[0]).Function)
+	})
+
+	t.Run("accepts uintptrs", func(t *testing.T) {
+		pcs := make([]uintptr, 1)
+		runtime.Callers(1, pcs)
+		ff := FramesFromPkgErrors(pcs)
+		testutils.AssertEqual(t, 1, len(ff))
+		testutils.AssertEqual(t, pkgFrame(pcs[0]).location(), LocationOf(ff[0]))
+	})
+
+	t.Run("unsupported values", func(t *testing.T) {
+		testutils.AssertNil(t, FramesFromPkgErrors(nil))
+		testutils.AssertNil(t, FramesFromPkgErrors(pkgStackTrace{}))
+		testutils.AssertNil(t, FramesFromPkgErrors([]int{1}))
+		testutils.AssertNil(t, FramesFromPkgErrors(pkgFrame(1)))
+	})
+
+	t.Run("PCFromFrame ignores other uintptr types", func(t *testing.T) {
+		testutils.AssertEqual(t, uintptr(0), PCFromFrame(pkgFrame(10)))
+	})
+}
+
+// pkgWithStack mirrors the error returned by github.com/pkg/errors'
+// WithStack.
+type pkgWithStack struct {
+	error
+	stack pkgStackTrace
+}
+
+func (w *pkgWithStack) StackTrace() pkgStackTrace { return w.stack }
+func (w *pkgWithStack) Cause() error              { return w.error }
+func (w *pkgWithStack) Unwrap() error             { return w.error }
+
+func pkgErrorsWithStack(err error) error {
+	return &pkgWithStack{error: err, stack: pkgCallers()[1:]}
+}
+
+func TestFramesFrom_pkgErrors(t *testing.T) {
+	err := pkgErrorsWithStack(New("failed"))
+	st := err.(*pkgWithStack).stack
+	locations := make([]Location, len(st))
+	for i := range st {
+		locations[i] = st[i].location()
+	}
+
+	testutils.AssertEqual(t, locations, FramesFrom(err).Locations())
+	testutils.AssertEqual(t, "github.com/secureworks/errors.TestFramesFrom_pkgErrors", locations[0].Function)
+	testutils.AssertTrue(t, HasFrames(err))
+	origin, ok := Origin(err)
+	testutils.AssertTrue(t, ok)
+	testutils.AssertEqual(t, locations[0], LocationOf(origin))
+
+	// The stack trace takes precedence, as any other stack trace does.
+	testutils.AssertEqual(t, locations, FramesFrom(WithFrame(err)).Locations())
+	testutils.AssertEqual(t, locations, FramesFrom(Errorf("wrapped: %w", err)).Locations())
+	testutils.AssertEqual(t, locations, FramesFrom(pkgErrorsWithStack(WithStackTrace(err))).Locations())
+
+	testutils.AssertEqual(t, 0, len(FramesFrom(&pkgWithStack{error: New("failed")})))
+}
+
+func TestPkgCompat(t *testing.T) {
+	testutils.AssertNil(t, PkgCompat[pkgStackTrace](nil))
+
+	errBase := New("failed")
+	err := WithFrames(WithFrame(errBase), Frames{NewFrame("pkg.Fn", "/src/pkg/fn.go", 1)})
+	compat := PkgCompat[pkgStackTrace](err)
+
+	tracer, ok := compat.(interface{ StackTrace() pkgStackTrace })
+	testutils.AssertTrue(t, ok)
+	st := tracer.StackTrace()
+	testutils.AssertEqual(t, 1, len(st)) // The synthetic frame is skipped.
+	testutils.AssertEqual(t, LocationOf(FramesFrom(err)[0]), st[0].location())
+
+	testutils.AssertEqual(t, err.Error(), compat.Error())
+	testutils.AssertEqual(t, fmt.Sprintf("%+v", err), fmt.Sprintf("%+v", compat))
+	testutils.AssertEqual(t, fmt.Sprintf("%q", err), fmt.Sprintf("%q", compat))
+	testutils.AssertTrue(t, Is(compat, errBase))
+	testutils.AssertEqual(t, err, compat.(interface{ Cause() error }).Cause())
+
+	// Not read back as a stack trace, which would drop synthetic frames.
+	testutils.AssertEqual(t, FramesFrom(err).Locations(), FramesFrom(compat).Locations())
+	testutils.AssertEqual(t, 0, len(FramesFrom(PkgCompat[pkgStackTrace](errBase))))
+}
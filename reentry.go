@@ -0,0 +1,65 @@
+package errors
+
+import (
+	"fmt"
+	stdruntime "runtime"
+	"sync/atomic"
+)
+
+// maxFormatReentry is how many times an error may be formatted at once
+// (eg, re-entrantly, while it is already being formatted) before the
+// goroutine is checked for a formatting cycle.
+const maxFormatReentry = 32
+
+// formatGuard breaks formatting cycles: an error whose formatting
+// formats the error itself again, eg through a value in its message
+// whose String method formats a field that (indirectly) contains the
+// error. Without it, the Format methods of this package would recurse
+// until the stack overflows.
+//
+// It counts how many times the error is being formatted at once. Only
+// if that is more than maxFormatReentry is the stack of the goroutine
+// checked for the cycle, so that formatting is cheap, and the same
+// error formatted concurrently by many goroutines is not mistaken for
+// one.
+type formatGuard struct {
+	formatting atomic.Int32
+}
+
+// enterFormat is called first by the Format method of an error. If the
+// error is being formatted in a cycle, it writes the "%!v(CYCLE)"
+// marker (for the verb) instead and returns false. Otherwise the caller
+// must call leaveFormat when it returns.
+func (g *formatGuard) enterFormat(s fmt.State, verb rune) bool {
+	if g.formatting.Add(1) <= maxFormatReentry || !inFormatCycle() {
+		return true
+	}
+	g.formatting.Add(-1)
+	fmt.Fprintf(s, "%%!%c(CYCLE)", verb)
+	return false
+}
+
+func (g *formatGuard) leaveFormat() {
+	g.formatting.Add(-1)
+}
+
+// inFormatCycle reports whether the Format method that called
+// enterFormat is on the stack of the goroutine more than
+// maxFormatReentry times.
+//
+//go:noinline
+func inFormatCycle() bool {
+	pcs := make([]uintptr, maxFormatReentry*64)
+	// Skip runtime.Callers, inFormatCycle and enterFormat.
+	frames := stdruntime.CallersFrames(pcs[:stdruntime.Callers(3, pcs)])
+	format, more := frames.Next()
+	count := 1
+	for more {
+		var fr stdruntime.Frame
+		fr, more = frames.Next()
+		if fr.Function == format.Function {
+			count++
+		}
+	}
+	return count > maxFormatReentry
+}
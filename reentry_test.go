@@ -0,0 +1,65 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+// cycleValue is a value whose String method formats an error that
+// contains the value, for a formatting cycle.
+type cycleValue struct {
+	err error
+}
+
+func (v *cycleValue) String() string { return fmt.Sprintf("%+v", v.err) }
+
+type cycleError struct {
+	value *cycleValue
+}
+
+func (e *cycleError) Error() string { return fmt.Sprintf("bad value: %v", e.value) }
+
+func TestFormatCycle(t *testing.T) {
+	cases := map[string]func(err error) error{
+		"stack trace":  WithStackTrace,
+		"frame":        WithFrame,
+		"multierror":   func(err error) error { return NewMultiError(err, New("other")) },
+		"named errors": func(err error) error { return NamedFromMap(map[string]error{"field": err}) },
+		"build info":   func(err error) error { return &withBuildInfo{error: err} },
+		"panic":        func(err error) error { return FromPanic(err) },
+		"pkg/errors":   PkgCompat[[]uintptr],
+	}
+	for name, wrap := range cases {
+		t.Run(name, func(t *testing.T) {
+			value := new(cycleValue)
+			err := wrap(&cycleError{value: value})
+			value.err = err
+
+			for _, format := range []string{"%v", "%+v", "%s", "%q"} {
+				out := fmt.Sprintf(format, err)
+				testutils.AssertTrue(t, strings.Contains(out, "(CYCLE)"))
+			}
+			testutils.AssertTrue(t, strings.Contains(err.Error(), "%!v(CYCLE)"))
+		})
+	}
+
+	t.Run("concurrent formatting is not a cycle", func(t *testing.T) {
+		err := NewWithFrame("failed")
+		expected := fmt.Sprintf("%+v", err)
+		// As if formatted by many goroutines at once.
+		err.(*withFrames).formatting.Store(maxFormatReentry * 2)
+		defer err.(*withFrames).formatting.Store(0)
+		testutils.AssertEqual(t, expected, fmt.Sprintf("%+v", err))
+	})
+}
+
+func BenchmarkFormatGuard(b *testing.B) {
+	err := WithMessage(NewWithFrame("failed"), "context")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = fmt.Sprintf("%v", err)
+	}
+}
@@ -0,0 +1,27 @@
+// Package runtimeutil provides helpers over the runtime package for
+// capturing the frames of the caller's stack, as used by the errors
+// package to annotate errors. They are useful on their own, eg to
+// record the caller in an audit log or to name a metric after the
+// calling function:
+//
+//	fr := runtimeutil.GetFrame(0)
+//	metrics.Inc(runtimeutil.FuncName(fr.Function))
+//
+// # Skipping callers
+//
+// The skip argument of each helper counts frames relative to the
+// caller of the helper, not the helper itself: with a skip of 0 the
+// first frame returned is the function that called the helper, with a
+// skip of 1 it is the function that called that, and so on. A helper
+// that wraps these for its own callers adds 1 to skip for each function
+// in between:
+//
+//	// callerName returns the name of the function that called it.
+//	func callerName() string {
+//		return runtimeutil.GetFrame(1).Function // Skip callerName.
+//	}
+//
+// The frames are runtime.Frame values, resolved when they are captured.
+// Inlined functions have frames of their own, the same as they do with
+// runtime.CallersFrames.
+package runtimeutil
@@ -0,0 +1,124 @@
+package runtimeutil
+
+import (
+	"runtime"
+	"strings"
+)
+
+// MaxStackDepth is the most program counters captured for a stack by
+// GetStack and the other helpers in this package.
+const MaxStackDepth = 32
+
+// GetFrame returns the frame of the caller's stack at skip, or an empty
+// runtime.Frame if the stack is not that deep. A skip of 0 is the
+// caller of GetFrame.
+func GetFrame(skip int) runtime.Frame {
+	var pcs [3]uintptr
+	frames, _ := callers(skip+1, pcs[:])
+	fr, ok := frames.Next()
+	if !ok {
+		return runtime.Frame{}
+	}
+	return fr
+}
+
+// GetStack returns the frames of the caller's stack, starting at skip.
+// A skip of 0 starts at the caller of GetStack. At most MaxStackDepth
+// program counters are captured.
+func GetStack(skip int) []runtime.Frame {
+	return stackUntil(skip+1, 0, nil)
+}
+
+// GetStackAtMost is the same as GetStack, but returns at most maxFrames
+// frames, resolving no more than it returns. A maxFrames of zero or
+// fewer is ignored.
+func GetStackAtMost(skip int, maxFrames int) []runtime.Frame {
+	return stackUntil(skip+1, maxFrames, nil)
+}
+
+// GetStackUntil is the same as GetStack, except that it stops after the
+// first frame for which stop returns true, so that the rest of the
+// stack is never resolved.
+func GetStackUntil(skip int, stop func(runtime.Frame) bool) []runtime.Frame {
+	return stackUntil(skip+1, 0, stop)
+}
+
+// GetStackPCs fills pcs with the program counters of the stack that
+// GetStack would return for the same skip, without resolving them: use
+// StackFromPCs to resolve them. Returns the program counters filled in.
+func GetStackPCs(skip int, pcs []uintptr) []uintptr {
+	return pcs[:callersPCs(skip+1, pcs)]
+}
+
+// StackFromPCs resolves program counters from GetStackPCs into the
+// frames of the stack.
+func StackFromPCs(pcs []uintptr) []runtime.Frame {
+	frames := runtime.CallersFrames(pcs)
+	ff := make([]runtime.Frame, 0, len(pcs))
+	for {
+		fr, ok := frames.Next()
+		if !ok {
+			break
+		}
+		ff = append(ff, fr)
+	}
+	return ff
+}
+
+// FuncName returns the name of a function without its package path,
+// given its fully-qualified name (eg, runtime.Frame.Function):
+//
+//	FuncName("github.com/acme/svc.(*Server).Serve") // => "(*Server).Serve"
+func FuncName(name string) string {
+	i := strings.LastIndex(name, "/")
+	name = name[i+1:]
+	i = strings.Index(name, ".")
+	return name[i+1:]
+}
+
+// stackUntil returns the frames of the stack starting at skip, where a
+// skip of 0 is the caller of stackUntil, stopping after maxFrames frames
+// (if positive) or the first frame for which stop (if not nil) returns
+// true.
+//
+//go:noinline
+func stackUntil(skip int, maxFrames int, stop func(runtime.Frame) bool) []runtime.Frame {
+	var pcs [MaxStackDepth]uintptr
+	frames, n := callers(skip+1, pcs[:])
+	if maxFrames > 0 && n > maxFrames {
+		n = maxFrames
+	}
+	ff := make([]runtime.Frame, 0, n)
+	for {
+		fr, ok := frames.Next()
+		if !ok {
+			break
+		}
+		ff = append(ff, fr)
+		if len(ff) == maxFrames || (stop != nil && stop(fr)) {
+			break
+		}
+	}
+	return ff
+}
+
+// callers returns the frames of the stack starting at skip, where a
+// skip of 0 is the caller of callers.
+//
+//go:noinline
+func callers(skip int, pcs []uintptr) (frames *runtime.Frames, n int) {
+	n = runtime.Callers(skip+1, pcs)
+	frames = runtime.CallersFrames(pcs[:n])
+	if _, ok := frames.Next(); !ok {
+		return &runtime.Frames{}, 0
+	}
+	return
+}
+
+// callersPCs is the same as callers, but skips the frame that callers
+// drops instead of resolving it.
+//
+//go:noinline
+func callersPCs(skip int, pcs []uintptr) int {
+	return runtime.Callers(skip+2, pcs)
+}
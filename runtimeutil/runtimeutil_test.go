@@ -1,4 +1,4 @@
-package runtime
+package runtimeutil
 
 import (
 	"fmt"
@@ -28,11 +28,6 @@ func StackCaller(skip int) []runtime.Frame {
 	return GetStack(skip)
 }
 
-var (
-	getFrameLine = 25 // Line no for the utility in the codebase.
-	getStackLine = 11 // Line no for the utility in the codebase.
-)
-
 func TestGetFrame(t *testing.T) {
 	var cs callerStruct
 	cases := []struct {
@@ -45,40 +40,33 @@ func TestGetFrame(t *testing.T) {
 		{
 			name:  "skip:0",
 			frame: cs.PtrFrameCaller(0),
-			fn:    `.+\/runtime\.GetFrame`,
-			file:  `.+\/runtime\.go`,
-			line:  getFrameLine,
+			fn:    `.+\/runtimeutil\.FrameCaller$`,
+			file:  `.+\/runtimeutil_test\.go`,
+			line:  24,
 		},
 		{
 			name:  "skip:1",
 			frame: cs.PtrFrameCaller(1),
-			fn:    `.+\/runtime\.FrameCaller`,
-			file:  `.+\/runtime_test\.go`,
-			line:  24,
+			fn:    `.+\/runtimeutil\.callerStruct\.PtrFrameCaller$`,
+			file:  `.+\/runtimeutil_test\.go`,
+			line:  16,
 		},
 		{
 			name:  "skip:2",
 			frame: cs.PtrFrameCaller(2),
-			fn:    `.+\/runtime\.callerStruct\.PtrFrameCaller`,
-			file:  `.+\/runtime_test\.go`,
-			line:  16,
+			fn:    `.+\/runtimeutil\.TestGetFrame$`,
+			file:  `.+\/runtimeutil_test\.go`,
+			line:  56,
 		},
 		{
 			name:  "skip:3",
 			frame: cs.PtrFrameCaller(3),
-			fn:    `.+\/runtime\.TestGetFrame`,
-			file:  `.+\/runtime_test\.go`,
-			line:  68,
-		},
-		{
-			name:  "skip:4",
-			frame: cs.PtrFrameCaller(4),
 			fn:    `testing\.tRunner`,
 			file:  `.+\/testing\/testing\.go`,
 		},
 		{
-			name:  "skip:5",
-			frame: cs.PtrFrameCaller(5), // Empty.
+			name:  "skip:4",
+			frame: cs.PtrFrameCaller(4), // Empty.
 			fn:    "",
 			file:  "",
 			line:  0,
@@ -105,24 +93,19 @@ func TestGetStack(t *testing.T) {
 		line int
 	}{
 		{
-			fn:   `.+\/runtime\.GetStack`,
-			file: `.+\/runtime\.go`,
-			line: getStackLine,
-		},
-		{
-			fn:   `.+\/runtime\.StackCaller`,
-			file: `.+\/runtime_test\.go`,
+			fn:   `.+\/runtimeutil\.StackCaller$`,
+			file: `.+\/runtimeutil_test\.go`,
 			line: 28,
 		},
 		{
-			fn:   `.+\/runtime\.callerStruct\.PtrStackCaller`,
-			file: `.+\/runtime_test\.go`,
+			fn:   `.+\/runtimeutil\.callerStruct\.PtrStackCaller$`,
+			file: `.+\/runtimeutil_test\.go`,
 			line: 20,
 		},
 		{
-			fn:   `.+\/runtime\.TestGetStack`,
-			file: `.+\/runtime_test\.go`,
-			line: 100,
+			fn:   `.+\/runtimeutil\.TestGetStack$`,
+			file: `.+\/runtimeutil_test\.go`,
+			line: 88,
 		},
 		{
 			fn:   `testing\.tRunner`,
@@ -139,6 +122,18 @@ func TestGetStack(t *testing.T) {
 			}
 		})
 	}
+
+	testutils.AssertEqual(t, len(stack)-2, len(cs.PtrStackCaller(2)))
+}
+
+func TestGetStackAtMost(t *testing.T) {
+	st := GetStackAtMost(0, 1)
+	testutils.AssertEqual(t, 1, len(st))
+	testutils.AssertEqual(t, "github.com/secureworks/errors/runtimeutil.TestGetStackAtMost", st[0].Function)
+
+	testutils.AssertEqual(t, len(GetStack(0)), len(GetStackAtMost(0, 0)))
+	testutils.AssertEqual(t, len(GetStack(0)), len(GetStackAtMost(0, MaxStackDepth)))
+	testutils.AssertEqual(t, "testing.tRunner", GetStackAtMost(1, 1)[0].Function)
 }
 
 func TestFuncName(t *testing.T) {
@@ -151,6 +146,8 @@ func TestFuncName(t *testing.T) {
 		{name: "funcname", want: "funcname"},
 		{name: "io.copyBuffer", want: "copyBuffer"},
 		{name: "main.(*R).Write", want: "(*R).Write"},
+		{name: "gopkg.in/yaml%2ev3.(*decoder).unmarshal", want: "(*decoder).unmarshal"},
+		{name: "github.com/acme/svc.run.func1", want: "run.func1"},
 	}
 	for _, tt := range cases {
 		t.Run(tt.name, func(t *testing.T) {
@@ -162,3 +159,28 @@ func TestFuncName(t *testing.T) {
 		})
 	}
 }
+
+func TestGetStackUntil(t *testing.T) {
+	var calls int
+	st := GetStackUntil(0, func(fr runtime.Frame) bool {
+		calls++
+		return calls == 2
+	})
+	testutils.AssertEqual(t, 2, len(st))
+	testutils.AssertEqual(t, "github.com/secureworks/errors/runtimeutil.TestGetStackUntil", st[0].Function)
+	testutils.AssertEqual(t, "testing.tRunner", st[1].Function)
+
+	st = GetStackUntil(0, func(runtime.Frame) bool { return false })
+	testutils.AssertEqual(t, len(GetStack(0)), len(st))
+}
+
+func TestGetStackPCs(t *testing.T) {
+	var pcs [MaxStackDepth]uintptr
+	st, pcSt := GetStack(0), StackFromPCs(GetStackPCs(0, pcs[:]))
+	testutils.AssertEqual(t, len(st), len(pcSt))
+	for i := range st {
+		testutils.AssertEqual(t, st[i].Function, pcSt[i].Function)
+		testutils.AssertEqual(t, st[i].File, pcSt[i].File)
+	}
+	testutils.AssertEqual(t, "github.com/secureworks/errors/runtimeutil.TestGetStackPCs", pcSt[0].Function)
+}
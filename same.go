@@ -0,0 +1,226 @@
+package errors
+
+import (
+	"strings"
+)
+
+// SameOptions configures how SameWith compares errors. The zero
+// SameOptions is the default of Same.
+type SameOptions struct {
+	// FullMessages compares the whole messages of the errors, rather than
+	// the end of those of their root causes, so that errors with
+	// different context (eg, added with Errorf) are not the same.
+	FullMessages bool
+
+	// ExactMessages compares messages as they are, rather than with the
+	// numbers and IDs in them normalized.
+	ExactMessages bool
+
+	// IgnoreOrigin does not compare the functions the errors originated
+	// in (see Origin), so that errors with the same message from
+	// anywhere are the same.
+	IgnoreOrigin bool
+}
+
+// Same reports whether two errors represent the same failure, even if
+// one or both of them were serialized and parsed (eg, with ToJSON and
+// ErrorFromJSON) or came from another process. This is meant for
+// deduplicating and grouping errors, and is unlike Is, which reports
+// whether an error chain matches a target: Same does not compare the
+// errors or their types at all.
+//
+// Two errors are the same if they originated in the same function (see
+// Origin; line numbers are not compared, since they change between
+// builds), and the messages of their root causes (the innermost error
+// of each chain, not traversing multierrors) are the same after the
+// last ": ", once numbers and hexadecimal IDs in them are normalized.
+// Only the end of the message is compared since the rest is usually
+// context, and a parsed error has a single message for its chain. So
+// wrapping an error with frames or with message context does not
+// change what it is the same as. An error without frames has no
+// origin, so only its message is compared: it is the same as an error
+// with frames that has the same message, wherever that originated.
+// A root cause that is a multierror is compared by its errors, in
+// order, instead. Two nil errors are the same.
+//
+// Use SameWith to tighten or loosen these criteria.
+func Same(a, b error) bool {
+	return SameWith(a, b, SameOptions{})
+}
+
+// SameWith is the same as Same, with the criteria configured by the
+// options.
+func SameWith(a, b error, opts SameOptions) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return sameKeyOf(a, opts).matches(sameKeyOf(b, opts))
+}
+
+// sameKey has what is compared to decide whether errors are the same:
+// the normalized messages of the errors, and separately the functions
+// they originated in, so that an error without an origin can match any.
+type sameKey struct {
+	messages string
+	origins  []string // Empty for errors without an origin.
+}
+
+func sameKeyOf(err error, opts SameOptions) sameKey {
+	var (
+		b   strings.Builder
+		key sameKey
+	)
+	writeSameKey(&b, &key, err, opts)
+	key.messages = b.String()
+	return key
+}
+
+// matches reports whether the errors with the keys are the same. The
+// origins are only compared where both errors have one.
+func (k sameKey) matches(other sameKey) bool {
+	if k.messages != other.messages || len(k.origins) != len(other.origins) {
+		return false
+	}
+	for i, origin := range k.origins {
+		if origin != "" && other.origins[i] != "" && origin != other.origins[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeSameKey(b *strings.Builder, key *sameKey, err error, opts SameOptions) {
+	msg := err
+	if !opts.FullMessages {
+		msg = rootCause(err)
+	}
+	if merr, ok := msg.(multierror); ok {
+		b.WriteByte('[')
+		for _, err := range unwrapMulti(merr) {
+			if err != nil {
+				writeSameKey(b, key, err, opts)
+				b.WriteByte(';')
+			}
+		}
+		b.WriteByte(']')
+		return
+	}
+
+	if !opts.IgnoreOrigin {
+		var function string
+		if origin, ok := Origin(err); ok {
+			function = LocationOf(origin).Function
+		}
+		key.origins = append(key.origins, function)
+	}
+	str := safeError(msg, 'v')
+	if !opts.FullMessages {
+		if i := strings.LastIndex(str, ": "); i >= 0 {
+			str = str[i+2:]
+		}
+	}
+	if !opts.ExactMessages {
+		str = normalizeMessage(str)
+	}
+	b.WriteString(str)
+	b.WriteByte(0)
+}
+
+// sameIndex indexes errors by the first of the errors added to it that
+// they are the same as.
+type sameIndex struct {
+	opts    SameOptions
+	indexed map[string][]sameIndexed // By the messages of the key.
+}
+
+type sameIndexed struct {
+	key   sameKey
+	index int
+}
+
+func newSameIndex(opts SameOptions) *sameIndex {
+	return &sameIndex{opts: opts, indexed: make(map[string][]sameIndexed)}
+}
+
+// lookup returns the index of the first error added that is the same as
+// the error, if any. Otherwise it adds the error with the next index.
+func (x *sameIndex) lookup(err error, next int) (index int, ok bool) {
+	key := sameKeyOf(err, x.opts)
+	for _, e := range x.indexed[key.messages] {
+		if e.key.matches(key) {
+			return e.index, true
+		}
+	}
+	x.indexed[key.messages] = append(x.indexed[key.messages], sameIndexed{key: key, index: next})
+	return next, false
+}
+
+// rootCause returns the innermost error of the chain, not traversing
+// multierrors.
+func rootCause(err error) error {
+	for depth := 0; ; depth++ {
+		next := unwrapAt(err, depth)
+		if next == nil || next == errUnwrapDepthExceeded {
+			return err
+		}
+		err = next
+	}
+}
+
+// normalizeMessage replaces the numbers and the hexadecimal IDs (words
+// of at least 8 hexadecimal digits and dashes, with at least one decimal
+// digit, eg from UUIDs or hashes) in the message with "#".
+func normalizeMessage(msg string) string {
+	var b strings.Builder
+	for i := 0; i < len(msg); {
+		if end := hexWordEnd(msg, i); end > i {
+			b.WriteByte('#')
+			i = end
+			continue
+		}
+		if isDigit(msg[i]) {
+			b.WriteByte('#')
+			for i < len(msg) && isDigit(msg[i]) {
+				i++
+			}
+			continue
+		}
+		b.WriteByte(msg[i])
+		i++
+	}
+	return b.String()
+}
+
+// hexWordEnd returns the end of the hexadecimal ID that starts at the
+// index of the message, if any, or else the index.
+func hexWordEnd(msg string, i int) int {
+	if i > 0 && isWordByte(msg[i-1]) {
+		return i
+	}
+	end, hexDigits, digits := i, 0, 0
+	for ; end < len(msg) && (isHexDigit(msg[end]) || msg[end] == '-'); end++ {
+		if isHexDigit(msg[end]) {
+			hexDigits++
+		}
+		if isDigit(msg[end]) {
+			digits++
+		}
+	}
+	for end > i && msg[end-1] == '-' {
+		end--
+	}
+	if hexDigits < 8 || digits == 0 || (end < len(msg) && isWordByte(msg[end])) {
+		return i
+	}
+	return end
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+func isWordByte(c byte) bool {
+	return isHexDigit(c) || ('g' <= c && c <= 'z') || ('G' <= c && c <= 'Z') || c == '_'
+}
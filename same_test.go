@@ -0,0 +1,111 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+	"testing/quick"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+//go:noinline
+func sameOriginA(msg string) error { return NewWithFrame(msg) }
+
+//go:noinline
+func sameOriginB(msg string) error { return NewWithFrame(msg) }
+
+func TestSame(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		testutils.AssertTrue(t, Same(nil, nil))
+		testutils.AssertFalse(t, Same(nil, errSentinel))
+		testutils.AssertFalse(t, Same(errSentinel, nil))
+	})
+
+	t.Run("context does not matter", func(t *testing.T) {
+		err := sameOriginA("connection refused")
+		testutils.AssertTrue(t, Same(err, sameOriginA("connection refused")))
+		testutils.AssertTrue(t, Same(err, Errorf("dialing: %w", WithFrame(err))))
+		testutils.AssertTrue(t, Same(err, WithMessage(err, "unavailable")))
+		testutils.AssertFalse(t, SameWith(err, Errorf("dialing: %w", err), SameOptions{FullMessages: true}))
+	})
+
+	t.Run("origin", func(t *testing.T) {
+		a, b := sameOriginA("connection refused"), sameOriginB("connection refused")
+		testutils.AssertFalse(t, Same(a, b))
+		testutils.AssertTrue(t, SameWith(a, b, SameOptions{IgnoreOrigin: true}))
+		testutils.AssertTrue(t, Same(a, New("connection refused")), "an error without frames has no origin to compare")
+		testutils.AssertTrue(t, Same(WithFrame(New("connection refused")), New("connection refused")))
+		testutils.AssertFalse(t, Same(NewMultiError(a, a), NewMultiError(New("connection refused"), b)))
+	})
+
+	t.Run("normalized messages", func(t *testing.T) {
+		a, b := New("user 12 not found in 6f1c2b9e-77d1-4c3e"), New("user 345 not found in 0a9b8c7d-1234-4d5e")
+		testutils.AssertTrue(t, Same(a, b))
+		testutils.AssertFalse(t, SameWith(a, b, SameOptions{ExactMessages: true}))
+		testutils.AssertFalse(t, Same(New("user not found"), New("group not found")))
+		testutils.AssertFalse(t, Same(New("decafbad"), New("facade12")), "words without numbers are kept")
+	})
+
+	t.Run("serialized", func(t *testing.T) {
+		err := Errorf("dialing: %w", sameOriginA("connection refused"))
+		parsed, _ := ErrorFromJSON(must(ToJSON(err)))
+		testutils.AssertTrue(t, Same(err, parsed))
+		parsed, _ = ErrorFromBytes(ErrorToBytes(err))
+		testutils.AssertTrue(t, Same(err, parsed))
+	})
+
+	t.Run("multierrors", func(t *testing.T) {
+		a := NewMultiError(New("a 1"), sameOriginA("b"))
+		testutils.AssertTrue(t, Same(a, fmt.Errorf("both: %w", NewMultiError(New("a 2"), sameOriginA("b")))))
+		testutils.AssertFalse(t, Same(a, NewMultiError(New("a 1"), sameOriginB("b"))))
+		testutils.AssertFalse(t, Same(a, NewMultiError(sameOriginA("b"), New("a 1"))))
+	})
+}
+
+func TestSame_properties(t *testing.T) {
+	optionSets := []SameOptions{{}, {FullMessages: true}, {ExactMessages: true}, {IgnoreOrigin: true}}
+	errs := func(msg string) []error {
+		return []error{
+			sameOriginA(msg),
+			sameOriginB(msg),
+			Errorf("context: %w", sameOriginA(msg)),
+			NewMultiError(sameOriginA(msg), sameOriginB(msg)),
+			WithFrame(fmt.Errorf("%s %d", msg, len(msg))),
+			New(msg),
+			fmt.Errorf("%s", msg),
+		}
+	}
+	wrappers := []func(error) error{
+		WithFrame,
+		func(err error) error { return Errorf("context: %w", err) },
+		func(err error) error { return fmt.Errorf("context: %w", err) },
+		func(err error) error { return WithMessage(err, "context") },
+		func(err error) error { return WithCorrelationID(err, "req-1") },
+	}
+
+	symmetric := func(a, b string) bool {
+		for _, opts := range optionSets {
+			for _, errA := range append(append(errs(a), errs(b)...), New(a), nil) {
+				for _, errB := range append(append(errs(a), errs(b)...), New(b), nil) {
+					if SameWith(errA, errB, opts) != SameWith(errB, errA, opts) {
+						return false
+					}
+				}
+			}
+		}
+		return true
+	}
+	testutils.AssertNil(t, quick.Check(symmetric, &quick.Config{MaxCount: 50}))
+
+	contextFree := func(msg string) bool {
+		for _, err := range errs(msg) {
+			for _, wrap := range wrappers {
+				if !Same(err, wrap(err)) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	testutils.AssertNil(t, quick.Check(contextFree, &quick.Config{MaxCount: 50}))
+}
@@ -0,0 +1,76 @@
+package errors
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Sampled returns a wrapper function for high-volume error paths, where
+// capturing a stack trace for every error is too expensive. Every Nth
+// error passed to the wrapper (starting with the first) has a stack
+// trace attached, like WithStackTrace; the rest only have a frame for
+// the caller, like WithFrame. If every is 1 all errors have a stack
+// trace, and if it is 0 or negative none do. Nil errors are returned
+// as nil and are not counted.
+//
+// The returned function is safe for concurrent use, and is intended to
+// be shared by a call site:
+//
+//	var sampleErr = errors.Sampled(1000)
+//
+//	func handle(req *Request) error {
+//		// ...
+//		return sampleErr(err)
+//	}
+//
+// Use StackOmitted to find out if the stack trace was left off an error,
+// for example to note "stack omitted (sampled 1/1000)" in logs.
+func Sampled(every int) func(error) error {
+	var count atomic.Uint64
+	return func(err error) error {
+		if err == nil {
+			return nil
+		}
+		if every > 0 && (count.Add(1)-1)%uint64(every) == 0 {
+			return &withStackTrace{
+				error:  err,
+				frames: getStack(3),
+			}
+		}
+		return &withSampledFrame{
+			withFrames: withFrames{
+				error:  err,
+				frames: frames{getFrame(3)},
+			},
+			every: every,
+		}
+	}
+}
+
+// StackOmitted reports whether a wrapper returned by Sampled left the
+// stack trace off the error chain, and returns the sampling rate of the
+// wrapper. A rate of 0 or less means the wrapper never captures a stack
+// trace.
+func StackOmitted(err error) (every int, ok bool) {
+	var sampled *withSampledFrame
+	if !As(err, &sampled) {
+		return 0, false
+	}
+	return sampled.every, true
+}
+
+// Sampled error wrapper.
+
+// withSampledFrame implements an error type annotated with a frame in
+// place of a stack trace that was omitted by sampling.
+type withSampledFrame struct {
+	withFrames
+	every int
+}
+
+var _ interface { // Assert interface implementation.
+	error
+	Unwrap() error
+	framer
+	fmt.Formatter
+} = (*withSampledFrame)(nil)
@@ -0,0 +1,118 @@
+package errors
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+func TestSampled(t *testing.T) {
+	hasStack := func(err error) bool {
+		_, ok := err.(stackTracer)
+		return ok
+	}
+
+	t.Run("nil is not counted", func(t *testing.T) {
+		sample := Sampled(2)
+		testutils.AssertNil(t, sample(nil))
+		testutils.AssertTrue(t, hasStack(sample(errSentinel)))
+	})
+
+	t.Run("samples every nth error", func(t *testing.T) {
+		sample := Sampled(3)
+		var got []bool
+		for i := 0; i < 7; i++ {
+			got = append(got, hasStack(sample(errSentinel)))
+		}
+		testutils.AssertEqual(t, []bool{true, false, false, true, false, false, true}, got)
+	})
+
+	t.Run("one always samples", func(t *testing.T) {
+		sample := Sampled(1)
+		for i := 0; i < 3; i++ {
+			err := sample(errSentinel)
+			testutils.AssertTrue(t, hasStack(err))
+			_, ok := StackOmitted(err)
+			testutils.AssertFalse(t, ok)
+		}
+	})
+
+	t.Run("zero and negative never sample", func(t *testing.T) {
+		for _, every := range []int{0, -1} {
+			sample := Sampled(every)
+			for i := 0; i < 3; i++ {
+				err := sample(errSentinel)
+				testutils.AssertFalse(t, hasStack(err))
+				rate, ok := StackOmitted(err)
+				testutils.AssertTrue(t, ok)
+				testutils.AssertEqual(t, every, rate)
+			}
+		}
+	})
+
+	t.Run("adds a frame or stack for the caller", func(t *testing.T) {
+		sample := Sampled(2)
+		for i := 0; i < 2; i++ {
+			err := sample(errSentinel)
+			testutils.AssertTrue(t, Is(err, errSentinel))
+			testutils.AssertEqual(t, "sentinel err", err.Error())
+			function, _, _ := FramesFrom(err)[0].Location()
+			testutils.AssertEqual(t, "github.com/secureworks/errors.TestSampled.func6", function)
+		}
+	})
+
+	t.Run("reports the omitted stack through the chain", func(t *testing.T) {
+		sample := Sampled(1000)
+		sample(errSentinel)
+		every, ok := StackOmitted(Errorf("wrap: %w", sample(errSentinel)))
+		testutils.AssertTrue(t, ok)
+		testutils.AssertEqual(t, 1000, every)
+
+		_, ok = StackOmitted(errSentinel)
+		testutils.AssertFalse(t, ok)
+	})
+
+	t.Run("is safe for concurrent use", func(t *testing.T) {
+		sample := Sampled(10)
+		var (
+			wg      sync.WaitGroup
+			mu      sync.Mutex
+			sampled int
+		)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					if hasStack(sample(errSentinel)) {
+						mu.Lock()
+						sampled++
+						mu.Unlock()
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		testutils.AssertEqual(t, 100, sampled)
+	})
+}
+
+func BenchmarkSampled(b *testing.B) {
+	b.Run("WithStackTrace", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = WithStackTrace(errSentinel)
+		}
+	})
+	b.Run("WithFrame", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = WithFrame(errSentinel)
+		}
+	})
+	b.Run("Sampled", func(b *testing.B) {
+		sample := Sampled(1000)
+		for i := 0; i < b.N; i++ {
+			_ = sample(errSentinel)
+		}
+	})
+}
@@ -0,0 +1,31 @@
+// Package sentryerr converts errors into exceptions for Sentry events,
+// with the frames of each error in the chain.
+//
+// The Sentry SDK for Go reads the stack trace of an error from its
+// StackTrace method, and only of the outermost error that has one, so
+// the frames added by errors.WithFrame, errors.Errorf etc are lost, as
+// are the errors of a MultiError. ExceptionsFrom instead walks the
+// whole error chain, and returns an exception for each error in it
+// with its own message:
+//
+//	event := sentry.NewEvent()
+//	event.Level = sentry.LevelError
+//	event.Exception = sentryerr.ExceptionsFrom(err)
+//	hub.CaptureEvent(event)
+//
+// This package is a module of its own, so that the errors package does
+// not depend on the Sentry SDK.
+//
+// # Exceptions
+//
+// The exceptions are ordered from the root cause to the outermost
+// error, as Sentry expects. Each error in the chain that has a message
+// of its own (eg, from fmt.Errorf or errors.WithMessage) is an
+// exception, with the frames added to the chain between it and its
+// cause: those errors.FramesFrom returns for it, without those it
+// returns for its cause. Errors that only add frames are part of the
+// exception for the error they wrap.
+//
+// The errors of a multierror are exceptions too, in an exception group
+// (see sentry.Mechanism) with the multierror as their parent.
+package sentryerr
@@ -0,0 +1,15 @@
+module github.com/secureworks/errors/sentryerr
+
+go 1.20
+
+require (
+	github.com/getsentry/sentry-go v0.29.1
+	github.com/secureworks/errors v0.2.1-0.20261015182823-2e706f4cf2f1
+)
+
+require (
+	golang.org/x/sys v0.18.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+)
+
+replace github.com/secureworks/errors => ../
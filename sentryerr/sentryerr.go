@@ -0,0 +1,155 @@
+package sentryerr
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+
+	"github.com/secureworks/errors"
+)
+
+// maxExceptions is the most exceptions returned for an error, to bound
+// the size of events for very long chains or very large multierrors;
+// maxLevelDepth bounds the errors with the same message in the chain.
+const (
+	maxExceptions = 100
+	maxLevelDepth = 1024
+)
+
+// ExceptionsFrom returns the exceptions for the errors in the chain,
+// ordered from the root cause to the outermost error. It returns nil
+// for a nil error.
+func ExceptionsFrom(err error) []sentry.Exception {
+	if err == nil {
+		return nil
+	}
+	c := &converter{}
+	c.convert(err, nil, "")
+	// Reverse, so that the root cause is first.
+	for i, j := 0, len(c.exceptions)-1; i < j; i, j = i+1, j-1 {
+		c.exceptions[i], c.exceptions[j] = c.exceptions[j], c.exceptions[i]
+	}
+	return c.exceptions
+}
+
+type converter struct {
+	exceptions []sentry.Exception // Outermost first.
+}
+
+// convert adds the exceptions for the error chain, with the parent
+// exception and the source of the error in the parent.
+func (c *converter) convert(err error, parentID *int, source string) {
+	for err != nil && len(c.exceptions) < maxExceptions {
+		id := len(c.exceptions)
+		msg := err.Error()
+
+		// Skip the errors in the chain with the same message, to the cause
+		// with a message of its own.
+		last, cause := err, errors.Unwrap(err)
+		for depth := 1; cause != nil && cause.Error() == msg && depth < maxLevelDepth; depth++ {
+			if _, ok := last.(interface{ Unwrap() []error }); ok {
+				break
+			}
+			last, cause = cause, errors.Unwrap(cause)
+		}
+		merr, isMulti := last.(interface{ Unwrap() []error })
+		if isMulti {
+			cause = nil
+		}
+
+		exc := sentry.Exception{
+			Type:  reflect.TypeOf(last).String(),
+			Value: msg,
+			Mechanism: &sentry.Mechanism{
+				Type:        "chained",
+				Source:      source,
+				ExceptionID: id,
+				ParentID:    parentID,
+			},
+		}
+		if parentID == nil {
+			exc.Mechanism.Type = "generic"
+		}
+		if ff := levelFrames(err, cause); len(ff) > 0 {
+			exc.Stacktrace = newStacktrace(ff)
+		}
+		if isMulti {
+			exc.Mechanism.IsExceptionGroup = true
+			c.exceptions = append(c.exceptions, exc)
+			for i, member := range merr.Unwrap() {
+				if member != nil {
+					c.convert(member, &id, "errors["+strconv.Itoa(i)+"]")
+				}
+			}
+			return
+		}
+		c.exceptions = append(c.exceptions, exc)
+		parentID, source, err = &id, "cause", cause
+	}
+}
+
+// levelFrames returns the frames of the errors in a level of the chain,
+// from the error to its cause (or nil): those that errors.FramesFrom
+// returns for the error, without those it returns for the cause. If the
+// frames of the cause are not among them (eg, if a stack trace in the
+// level is preferred), all of them are returned; if the frames are
+// those of the cause (eg, its stack trace is preferred), none are.
+func levelFrames(err, cause error) errors.Frames {
+	ff := errors.FramesFrom(err)
+	if cause == nil {
+		return ff
+	}
+	inner := errors.FramesFrom(cause)
+	if len(inner) > len(ff) {
+		return ff
+	}
+	for i, fr := range inner {
+		if errors.LocationOf(fr) != errors.LocationOf(ff[i]) {
+			return ff
+		}
+	}
+	return ff[len(inner):]
+}
+
+// newStacktrace returns the stack trace for the frames, in the reverse
+// order.
+func newStacktrace(ff errors.Frames) *sentry.Stacktrace {
+	st := &sentry.Stacktrace{Frames: make([]sentry.Frame, 0, len(ff))}
+	for i := len(ff) - 1; i >= 0; i-- {
+		if ff[i] == nil {
+			continue
+		}
+		function, file, line := ff[i].Location()
+		module, name := splitFunction(function)
+		st.Frames = append(st.Frames, sentry.Frame{
+			Function: name,
+			Module:   module,
+			Filename: file[strings.LastIndexAny(file, `/\`)+1:],
+			AbsPath:  file,
+			Lineno:   line,
+			InApp:    module != "" && !isStandardLibrary(module),
+		})
+	}
+	return st
+}
+
+// splitFunction splits a function name into its package path and its
+// name in the package, eg "github.com/acme/svc.(*Server).Handle" into
+// "github.com/acme/svc" and "(*Server).Handle".
+func splitFunction(function string) (module, name string) {
+	slash := strings.LastIndexByte(function, '/')
+	dot := strings.IndexByte(function[slash+1:], '.')
+	if dot < 0 {
+		return "", function
+	}
+	return function[:slash+1+dot], function[slash+1+dot+1:]
+}
+
+// isStandardLibrary reports whether the package is in the standard
+// library, ie if its first path element has no dot.
+func isStandardLibrary(module string) bool {
+	first, _, _ := strings.Cut(module, "/")
+	return !strings.Contains(first, ".") && first != "main"
+}
@@ -0,0 +1,145 @@
+package sentryerr
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+
+	"github.com/secureworks/errors"
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+func frames(locs ...errors.Location) errors.Frames {
+	ff := make(errors.Frames, len(locs))
+	for i, loc := range locs {
+		ff[i] = errors.NewFrame(loc.Function, loc.File, loc.Line)
+	}
+	return ff
+}
+
+var (
+	locMain   = errors.Location{Function: "main.main", File: "/src/svc/main.go", Line: 10}
+	locServe  = errors.Location{Function: "github.com/acme/svc.(*Server).Serve", File: "/src/svc/server.go", Line: 20}
+	locRead   = errors.Location{Function: "github.com/acme/svc/config.Read", File: "/src/svc/config/read.go", Line: 30}
+	locOpen   = errors.Location{Function: "os.Open", File: "/go/src/os/file.go", Line: 40}
+	locParse  = errors.Location{Function: "github.com/acme/svc/config.parse", File: `C:\src\svc\config\parse.go`, Line: 50}
+	locRunner = errors.Location{Function: "github.com/acme/svc.run.func1", File: "/src/svc/run.go", Line: 60}
+)
+
+func TestExceptionsFrom(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		testutils.AssertNil(t, ExceptionsFrom(nil))
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		root := errors.WithFrames(errors.New("not found"), frames(locOpen, locRead))
+		err := errors.WithFrames(fmt.Errorf("reading config: %w", root), frames(locServe))
+		err = errors.WithFrames(err, frames(locMain))
+
+		exceptions := ExceptionsFrom(err)
+		testutils.AssertEqual(t, 2, len(exceptions))
+
+		cause := exceptions[0]
+		testutils.AssertEqual(t, "*errors.errorString", cause.Type)
+		testutils.AssertEqual(t, "not found", cause.Value)
+		testutils.AssertEqual(t, []sentry.Frame{
+			{Function: "Read", Module: "github.com/acme/svc/config", Filename: "read.go", AbsPath: "/src/svc/config/read.go", Lineno: 30, InApp: true},
+			{Function: "Open", Module: "os", Filename: "file.go", AbsPath: "/go/src/os/file.go", Lineno: 40},
+		}, cause.Stacktrace.Frames)
+		testutils.AssertEqual(t, 1, cause.Mechanism.ExceptionID)
+		testutils.AssertEqual(t, 0, *cause.Mechanism.ParentID)
+		testutils.AssertEqual(t, "chained", cause.Mechanism.Type)
+		testutils.AssertEqual(t, "cause", cause.Mechanism.Source)
+
+		outer := exceptions[1]
+		testutils.AssertEqual(t, "*fmt.wrapError", outer.Type)
+		testutils.AssertEqual(t, "reading config: not found", outer.Value)
+		testutils.AssertEqual(t, []sentry.Frame{
+			{Function: "main", Module: "main", Filename: "main.go", AbsPath: "/src/svc/main.go", Lineno: 10, InApp: true},
+			{Function: "(*Server).Serve", Module: "github.com/acme/svc", Filename: "server.go", AbsPath: "/src/svc/server.go", Lineno: 20, InApp: true},
+		}, outer.Stacktrace.Frames)
+		testutils.AssertEqual(t, 0, outer.Mechanism.ExceptionID)
+		testutils.AssertNil(t, outer.Mechanism.ParentID)
+		testutils.AssertEqual(t, "generic", outer.Mechanism.Type)
+	})
+
+	t.Run("prefers stack traces", func(t *testing.T) {
+		err := errors.WithFrames(errors.WithStackTrace(errors.New("failed")), frames(locMain))
+		exceptions := ExceptionsFrom(err)
+		testutils.AssertEqual(t, 1, len(exceptions))
+		ff := exceptions[0].Stacktrace.Frames
+		testutils.AssertEqual(t, len(errors.FramesFrom(err)), len(ff))
+		testutils.AssertEqual(t, errors.LocationOf(errors.FramesFrom(err)[0]).Line, ff[len(ff)-1].Lineno)
+		testutils.AssertMatch(t, `^TestExceptionsFrom\.func\d+$`, ff[len(ff)-1].Function)
+	})
+
+	t.Run("stack trace of the cause", func(t *testing.T) {
+		err := errors.WithFrames(fmt.Errorf("reading: %w", errors.NewWithStackTrace("failed")), frames(locMain))
+		exceptions := ExceptionsFrom(err)
+		testutils.AssertEqual(t, 2, len(exceptions))
+		testutils.AssertEqual(t, len(errors.FramesFrom(err)), len(exceptions[0].Stacktrace.Frames))
+		testutils.AssertNil(t, exceptions[1].Stacktrace)
+	})
+
+	t.Run("synthetic frames with Windows paths", func(t *testing.T) {
+		exceptions := ExceptionsFrom(errors.WithFrames(errors.New("failed"), frames(locParse)))
+		testutils.AssertEqual(t, sentry.Frame{
+			Function: "parse", Module: "github.com/acme/svc/config", Filename: "parse.go",
+			AbsPath: `C:\src\svc\config\parse.go`, Lineno: 50, InApp: true,
+		}, exceptions[0].Stacktrace.Frames[0])
+	})
+
+	t.Run("no frames", func(t *testing.T) {
+		exceptions := ExceptionsFrom(errors.New("failed"))
+		testutils.AssertEqual(t, 1, len(exceptions))
+		testutils.AssertNil(t, exceptions[0].Stacktrace)
+	})
+
+	t.Run("multierror", func(t *testing.T) {
+		merr := errors.NewMultiError(
+			errors.WithFrames(errors.New("a"), frames(locRunner)),
+			fmt.Errorf("b: %w", errors.New("c")),
+		)
+		err := errors.WithFrames(fmt.Errorf("running: %w", merr), frames(locMain))
+
+		exceptions := ExceptionsFrom(err)
+		var values []string
+		for _, exc := range exceptions {
+			values = append(values, exc.Value)
+		}
+		testutils.AssertEqual(t, []string{"c", "b: c", "a", "[a; b: c]", "running: [a; b: c]"}, values)
+
+		group := exceptions[3]
+		testutils.AssertTrue(t, group.Mechanism.IsExceptionGroup)
+		testutils.AssertEqual(t, 1, group.Mechanism.ExceptionID)
+		testutils.AssertEqual(t, 0, *group.Mechanism.ParentID)
+
+		a := exceptions[2]
+		testutils.AssertEqual(t, 1, *a.Mechanism.ParentID)
+		testutils.AssertEqual(t, "errors[0]", a.Mechanism.Source)
+		testutils.AssertEqual(t, "run.func1", a.Stacktrace.Frames[0].Function)
+		b, c := exceptions[1], exceptions[0]
+		testutils.AssertEqual(t, 1, *b.Mechanism.ParentID)
+		testutils.AssertEqual(t, "errors[1]", b.Mechanism.Source)
+		testutils.AssertEqual(t, b.Mechanism.ExceptionID, *c.Mechanism.ParentID)
+		testutils.AssertEqual(t, "cause", c.Mechanism.Source)
+	})
+
+	t.Run("bounds the exceptions", func(t *testing.T) {
+		errs := make([]error, 2*maxExceptions)
+		for i := range errs {
+			errs[i] = fmt.Errorf("err %d", i)
+		}
+		testutils.AssertEqual(t, maxExceptions, len(ExceptionsFrom(errors.NewMultiError(errs...))))
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		byt, err := json.Marshal(ExceptionsFrom(errors.WithFrames(errors.New("failed"), frames(locMain))))
+		testutils.AssertNil(t, err)
+		testutils.AssertEqual(t,
+			`[{"type":"*errors.errorString","value":"failed","stacktrace":{"frames":[{"function":"main","module":"main","filename":"main.go","abs_path":"/src/svc/main.go","lineno":10,"in_app":true}]},"mechanism":{"type":"generic","exception_id":0}}]`,
+			string(byt))
+	})
+}
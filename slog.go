@@ -0,0 +1,77 @@
+//go:build go1.21
+
+package errors
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+)
+
+var _ = []slog.LogValuer{ // Assert interface implementation.
+	(*withStackTrace)(nil),
+	(*withFrames)(nil),
+	(*MultiError)(nil),
+}
+
+var slogOmitFrames atomic.Bool
+
+// SetSlogFrames sets whether the frames of errors are included in their
+// log/slog values (see SlogValue), for the whole program. The default is
+// true; set false to log errors with only their messages, eg in
+// production.
+func SetSlogFrames(include bool) {
+	slogOmitFrames.Store(!include)
+}
+
+// SlogValue returns the error as a log/slog group, with its message as
+// "msg" and its frames (see FramesFrom) as "frames", each formatted as
+// "function file:line". If the error chain has a multierror, its errors
+// are included as "errors", a group of the same groups keyed by their
+// index:
+//
+//	{"msg":"[a; b]","errors":{"0":{"msg":"a","frames":["main.main /src/main.go:10"]},"1":{"msg":"b"}}}
+//
+// The errors of this package implement slog.LogValuer with SlogValue,
+// so that logging them includes their frames:
+//
+//	slog.Error("failed", "err", err)
+//
+// Use SlogValue (eg, slog.Any("err", errors.SlogValue(err))) for errors
+// wrapped by other packages, such as with fmt.Errorf.
+func SlogValue(err error) slog.Value {
+	if err == nil {
+		return slog.AnyValue(nil)
+	}
+	return slogValue(newErrorJSON(err))
+}
+
+func slogValue(v *errorJSON) slog.Value {
+	attrs := []slog.Attr{slog.String("msg", v.Message)}
+	if len(v.Frames) > 0 && !slogOmitFrames.Load() {
+		ff := make([]string, len(v.Frames))
+		for i, fr := range v.Frames {
+			function, _, _ := fr.Location()
+			ff[i] = fmt.Sprintf("%s %v", escape(function), fr)
+		}
+		attrs = append(attrs, slog.Any("frames", ff))
+	}
+	if len(v.Errors) > 0 {
+		errs := make([]slog.Attr, len(v.Errors))
+		for i, member := range v.Errors {
+			errs[i] = slog.Attr{Key: strconv.Itoa(i), Value: slogValue(member)}
+		}
+		attrs = append(attrs, slog.Attr{Key: "errors", Value: slog.GroupValue(errs...)})
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue implements slog.LogValuer, returning SlogValue(w).
+func (w *withStackTrace) LogValue() slog.Value { return SlogValue(w) }
+
+// LogValue implements slog.LogValuer, returning SlogValue(w).
+func (w *withFrames) LogValue() slog.Value { return SlogValue(w) }
+
+// LogValue implements slog.LogValuer, returning SlogValue(merr).
+func (merr *MultiError) LogValue() slog.Value { return SlogValue(merr) }
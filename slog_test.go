@@ -0,0 +1,80 @@
+//go:build go1.21
+
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+// logJSON logs the error with a JSON handler, returning the "err" value.
+func logJSON(t *testing.T, err interface{}) interface{} {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	slog.New(slog.NewJSONHandler(buf, nil)).Error("failed", "err", err)
+	var record map[string]interface{}
+	testutils.AssertNil(t, json.Unmarshal(buf.Bytes(), &record))
+	return record["err"]
+}
+
+func TestSlogValue(t *testing.T) {
+	fr := NewFrame("github.com/acme/svc.Handle", "/src/svc/handle.go", 10)
+	err := NewWithFrames("not found", Frames{fr})
+
+	t.Run("frames", func(t *testing.T) {
+		testutils.AssertEqual(t, map[string]interface{}{
+			"msg":    "not found",
+			"frames": []interface{}{"github.com/acme/svc.Handle /src/svc/handle.go:10"},
+		}, logJSON(t, err))
+	})
+
+	t.Run("stack trace", func(t *testing.T) {
+		logged := logJSON(t, NewWithStackTrace("failed")).(map[string]interface{})
+		testutils.AssertEqual(t, "failed", logged["msg"])
+		testutils.AssertMatch(t, `^github\.com/secureworks/errors\.TestSlogValue\.func\d+ .+/slog_test\.go:\d+$`,
+			logged["frames"].([]interface{})[0].(string))
+	})
+
+	t.Run("multierror", func(t *testing.T) {
+		merr := NewMultiError(err, New("b"))
+		want := map[string]interface{}{
+			"msg": "[not found; b]",
+			"errors": map[string]interface{}{
+				"0": map[string]interface{}{
+					"msg":    "not found",
+					"frames": []interface{}{"github.com/acme/svc.Handle /src/svc/handle.go:10"},
+				},
+				"1": map[string]interface{}{"msg": "b"},
+			},
+		}
+		testutils.AssertEqual(t, want, logJSON(t, merr))
+
+		wrapped := logJSON(t, SlogValue(fmt.Errorf("wrapped: %w", merr))).(map[string]interface{})
+		testutils.AssertEqual(t, "wrapped: [not found; b]", wrapped["msg"])
+		testutils.AssertEqual(t, want["errors"], wrapped["errors"])
+	})
+
+	t.Run("wrapped by other packages", func(t *testing.T) {
+		wrapped := fmt.Errorf("handling: %w", err)
+		testutils.AssertEqual(t, "handling: not found", logJSON(t, wrapped))
+		testutils.AssertEqual(t, map[string]interface{}{
+			"msg":    "handling: not found",
+			"frames": []interface{}{"github.com/acme/svc.Handle /src/svc/handle.go:10"},
+		}, logJSON(t, SlogValue(wrapped)))
+	})
+
+	t.Run("without frames", func(t *testing.T) {
+		SetSlogFrames(false)
+		defer SetSlogFrames(true)
+		testutils.AssertEqual(t, map[string]interface{}{"msg": "not found"}, logJSON(t, err))
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		testutils.AssertNil(t, logJSON(t, SlogValue(nil)))
+	})
+}
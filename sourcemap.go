@@ -0,0 +1,61 @@
+package errors
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// sourceMapping is a substitution of a file path prefix.
+type sourceMapping struct {
+	from, to string
+}
+
+// sourceMappings is the substitution table for file paths, ordered by
+// the length of the prefix (longest first) and then by when the mapping
+// was added.
+var sourceMappings struct {
+	sync.RWMutex
+	table []sourceMapping
+}
+
+// AddSourceMapping adds a substitution for the file paths of frames
+// when they are formatted or marshaled: any file path that starts with
+// fromPrefix has it replaced with toPrefix. This lets stack traces
+// generated in a build sandbox or container be rewritten to paths that
+// exist where they are read, eg:
+//
+//	errors.AddSourceMapping("/proc/self/cwd/", "/home/me/src/svc/")
+//
+// If more than one mapping matches a path, the longest prefix wins. The
+// data stored in the frames is not changed, so Location continues to
+// return the original file path.
+func AddSourceMapping(fromPrefix, toPrefix string) {
+	sourceMappings.Lock()
+	defer sourceMappings.Unlock()
+	sourceMappings.table = append(sourceMappings.table, sourceMapping{from: fromPrefix, to: toPrefix})
+	sort.SliceStable(sourceMappings.table, func(i, j int) bool {
+		return len(sourceMappings.table[i].from) > len(sourceMappings.table[j].from)
+	})
+}
+
+// ResetSourceMappings removes all the substitutions added with
+// AddSourceMapping.
+func ResetSourceMappings() {
+	sourceMappings.Lock()
+	defer sourceMappings.Unlock()
+	sourceMappings.table = nil
+}
+
+// mapSource applies the first (longest) matching source mapping to the
+// file path.
+func mapSource(file string) string {
+	sourceMappings.RLock()
+	defer sourceMappings.RUnlock()
+	for _, m := range sourceMappings.table {
+		if strings.HasPrefix(file, m.from) {
+			return m.to + file[len(m.from):]
+		}
+	}
+	return file
+}
@@ -0,0 +1,48 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+func TestAddSourceMapping(t *testing.T) {
+	defer ResetSourceMappings()
+	AddSourceMapping("/proc/self/cwd/", "/home/me/svc/")
+	AddSourceMapping("/proc/self/cwd/external/", "/home/me/deps/")
+	AddSourceMapping("/app/", "")
+
+	cases := []struct {
+		file   string
+		mapped string
+	}{
+		{file: "/proc/self/cwd/db/query.go", mapped: "/home/me/svc/db/query.go"},
+		{file: "/proc/self/cwd/external/io_acme/db.go", mapped: "/home/me/deps/io_acme/db.go"},
+		{file: "/app/src/main.go", mapped: "src/main.go"},
+		{file: "/other/main.go", mapped: "/other/main.go"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.file, func(t *testing.T) {
+			fr := NewFrame("pkg.Function", tt.file, 10)
+
+			testutils.AssertEqual(t, tt.mapped+":10", fmt.Sprintf("%v", fr))
+			testutils.AssertEqual(t, "pkg.Function\n\t"+tt.mapped+":10", fmt.Sprintf("%+v", fr))
+
+			byt, err := json.Marshal(fr)
+			testutils.AssertNil(t, err)
+			testutils.AssertEqual(t, `{"function":"pkg.Function","file":"`+tt.mapped+`","line":10}`, string(byt))
+
+			// Stored data is unchanged.
+			_, file, _ := fr.Location()
+			testutils.AssertEqual(t, tt.file, file)
+		})
+	}
+
+	t.Run("reset", func(t *testing.T) {
+		ResetSourceMappings()
+		fr := NewFrame("pkg.Function", "/app/main.go", 10)
+		testutils.AssertEqual(t, "/app/main.go:10", fmt.Sprintf("%v", fr))
+	})
+}
@@ -0,0 +1,152 @@
+package errors
+
+import (
+	"sync/atomic"
+)
+
+// PackageStats are counters of the activity of this package, since
+// stats were enabled with EnableStats (or reset with ResetStats). They
+// are meant to be read by an exporter, eg for Prometheus or expvar:
+//
+//	errors.EnableStats(true)
+//	// ...
+//	stats := errors.Stats()
+//	framesCaptured.Set(float64(stats.FramesCaptured))
+type PackageStats struct {
+	// Created is the number of errors created by each constructor of this
+	// package, keyed by its name: "NewWithStackTrace", "WithStackTrace"
+	// (including WithStackTraceInModule), "NewWithFrame" (including
+	// NewWithFrameAt), "WithFrame" (including WithFrameAt), "WithFrames"
+	// (including NewWithFrames), "WithFileLine" and "Errorf" (including
+	// ErrorfAt, ErrorfAll and the functions that format errors like it,
+	// eg Appendf), "AnnotateOnReturn", "FromPanic" (including Recover),
+	// "AcrossGoroutine" and "AppendResultNamed" (including
+	// AppendResultf). Calls that other functions of this package make are
+	// included, eg Opaque calls WithFrames. Constructors that created no
+	// errors are left out.
+	Created map[string]uint64
+
+	// FramesCaptured is the number of frames captured from the call stack,
+	// by the constructors and by functions like Caller and CallStack.
+	FramesCaptured uint64
+
+	// MultiErrorAppends is the number of calls to Append (and the functions
+	// that use it, eg AppendInto and Join), AppendIntoMulti and
+	// SafeMultiError.Append that appended at least one non-nil error (for
+	// Append, one after the first, which the others are appended to).
+	MultiErrorAppends uint64
+
+	// Parsed is the number of errors parsed from their serializations,
+	// with ParseErrorFromBytes, ParseErrorFromJSON, Decode and the
+	// functions that use them (eg, ErrorFromBytes).
+	Parsed uint64
+}
+
+// statsConstructor identifies a constructor counted in PackageStats.
+type statsConstructor int
+
+const (
+	statsNewWithStackTrace statsConstructor = iota
+	statsWithStackTrace
+	statsNewWithFrame
+	statsWithFrame
+	statsWithFrames
+	statsWithFileLine
+	statsErrorf
+	statsAnnotateOnReturn
+	statsFromPanic
+	statsAcrossGoroutine
+	statsAppendResultNamed
+	numStatsConstructors
+)
+
+var statsConstructorNames = [numStatsConstructors]string{
+	statsNewWithStackTrace: "NewWithStackTrace",
+	statsWithStackTrace:    "WithStackTrace",
+	statsNewWithFrame:      "NewWithFrame",
+	statsWithFrame:         "WithFrame",
+	statsWithFrames:        "WithFrames",
+	statsWithFileLine:      "WithFileLine",
+	statsErrorf:            "Errorf",
+	statsAnnotateOnReturn:  "AnnotateOnReturn",
+	statsFromPanic:         "FromPanic",
+	statsAcrossGoroutine:   "AcrossGoroutine",
+	statsAppendResultNamed: "AppendResultNamed",
+}
+
+var stats struct {
+	enabled atomic.Bool
+
+	created        [numStatsConstructors]atomic.Uint64
+	framesCaptured atomic.Uint64
+	appends        atomic.Uint64
+	parsed         atomic.Uint64
+}
+
+// EnableStats sets whether the activity of this package is counted (see
+// Stats), for the whole program. The default is false. When disabled,
+// counting costs a single atomic load per call.
+func EnableStats(enable bool) {
+	stats.enabled.Store(enable)
+}
+
+// Stats returns the counters of the activity of this package. They are
+// all zero unless stats were enabled with EnableStats. Each counter is
+// read atomically, but not all of them at once.
+func Stats() PackageStats {
+	s := PackageStats{
+		Created:           make(map[string]uint64),
+		FramesCaptured:    stats.framesCaptured.Load(),
+		MultiErrorAppends: stats.appends.Load(),
+		Parsed:            stats.parsed.Load(),
+	}
+	for c := range stats.created {
+		if n := stats.created[c].Load(); n > 0 {
+			s.Created[statsConstructorNames[c]] = n
+		}
+	}
+	return s
+}
+
+// ResetStats sets the counters of the activity of this package to zero,
+// eg between tests. It does not enable or disable stats.
+func ResetStats() {
+	for c := range stats.created {
+		stats.created[c].Store(0)
+	}
+	stats.framesCaptured.Store(0)
+	stats.appends.Store(0)
+	stats.parsed.Store(0)
+}
+
+// countCreated counts an error created by the constructor, if stats are
+// enabled.
+func countCreated(c statsConstructor) {
+	if stats.enabled.Load() {
+		stats.created[c].Add(1)
+	}
+}
+
+// countFramesCaptured counts frames captured from the call stack, if
+// stats are enabled.
+func countFramesCaptured(n int) {
+	if stats.enabled.Load() {
+		stats.framesCaptured.Add(uint64(n))
+	}
+}
+
+// countAppend counts a call that appended errors to a multierror, if
+// stats are enabled and it did.
+func countAppend(appended bool) {
+	if appended && stats.enabled.Load() {
+		stats.appends.Add(1)
+	}
+}
+
+// countParsed counts an error parsed from its serialization, if stats
+// are enabled and it was.
+func countParsed(err error) {
+	if err != nil && stats.enabled.Load() {
+		stats.parsed.Add(1)
+	}
+}
@@ -0,0 +1,116 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+func TestStats(t *testing.T) {
+	EnableStats(true)
+	defer EnableStats(false)
+	ResetStats()
+	defer ResetStats()
+
+	t.Run("constructors", func(t *testing.T) {
+		defer ResetStats()
+		_ = NewWithStackTrace("err")
+		_ = WithStackTrace(errBasic)
+		_ = WithStackTraceInModule(errBasic)
+		_ = NewWithFrame("err")
+		_ = WithFrame(errBasic)
+		_ = WithFrameAt(errBasic, 0)
+		_ = NewWithFrames("err", Frames{NewFrame("fn", "file.go", 1)})
+		_ = WithFileLine(errBasic)
+		_ = Errorf("wrapped: %w", errBasic)
+		_ = WithFrame(nil)
+		_ = New("err")
+		_ = func() (err error) {
+			defer AnnotateOnReturn(&err)()
+			return errBasic
+		}()
+		_ = FromPanic("boom")
+		_ = func() (err error) {
+			defer Recover(&err)
+			panic("boom")
+		}()
+		_ = AcrossGoroutine(errBasic)
+		var appended error
+		AppendResultNamed(&appended, "close", func() error { return errBasic })
+		AppendResultf(&appended, func() error { return errBasic }, "close %d", 2)
+		AppendResultNamed(&appended, "close", func() error { return nil })
+
+		stats := Stats()
+		testutils.AssertEqual(t, map[string]uint64{
+			"NewWithStackTrace": 1,
+			"WithStackTrace":    2,
+			"NewWithFrame":      1,
+			"WithFrame":         2,
+			"WithFrames":        1,
+			"WithFileLine":      1,
+			"Errorf":            1,
+			"AnnotateOnReturn":  1,
+			"FromPanic":         2,
+			"AcrossGoroutine":   1,
+			"AppendResultNamed": 2,
+		}, stats.Created)
+		testutils.AssertTrue(t, stats.FramesCaptured > 5)
+	})
+
+	t.Run("frames captured", func(t *testing.T) {
+		defer ResetStats()
+		_ = Caller()
+		_ = CallerAt(0)
+		testutils.AssertEqual(t, uint64(2), Stats().FramesCaptured)
+		ResetStats()
+
+		ff := CallStack()
+		testutils.AssertEqual(t, uint64(len(ff)), Stats().FramesCaptured)
+		testutils.AssertEqual(t, 0, len(Stats().Created))
+	})
+
+	t.Run("multierror appends", func(t *testing.T) {
+		defer ResetStats()
+		var err error
+		AppendInto(&err, errBasic)
+		AppendInto(&err, nil)
+		_ = Append(err, errSentinel)
+		_ = Append(nil, nil)
+		_, _ = AppendIntoMulti(&err, errSentinel)
+		var merr SafeMultiError
+		merr.Append(errBasic)
+		merr.Append(nil)
+		testutils.AssertEqual(t, uint64(4), Stats().MultiErrorAppends)
+
+		ResetStats()
+		_ = Append(errBasic, nil)
+		_ = Append(errBasic)
+		_ = Append(NewMultiError(errBasic, errSentinel), nil, nil)
+		testutils.AssertEqual(t, uint64(0), Stats().MultiErrorAppends)
+		_ = Append(nil, errBasic)
+		testutils.AssertEqual(t, uint64(1), Stats().MultiErrorAppends)
+	})
+
+	t.Run("parsed", func(t *testing.T) {
+		defer ResetStats()
+		_, _ = ErrorFromBytes(ErrorToBytes(errBasic))
+		_, _ = ErrorFromBytes([]byte("<nil>"))
+		byt, _ := ToJSON(errBasic)
+		_, _ = ErrorFromJSON(byt)
+
+		var buf bytes.Buffer
+		testutils.AssertNil(t, Encode(gob.NewEncoder(&buf), errBasic))
+		_, _ = Decode(gob.NewDecoder(&buf))
+		testutils.AssertEqual(t, uint64(3), Stats().Parsed)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		EnableStats(false)
+		defer EnableStats(true)
+		_ = NewWithStackTrace("err")
+		_ = Append(errBasic, errSentinel)
+		testutils.AssertEqual(t, PackageStats{Created: map[string]uint64{}}, Stats())
+	})
+}
@@ -55,10 +55,10 @@ func As(err error, target interface{}) bool { return stderrors.As(err, target) }
 // The error formats as the concatenation of the strings obtained by
 // calling the Error method of each element of errs.
 //
-// A non-nil error returned by Join implements the Unwrap() []error
-// method.
-//
 // Since you are using the github.com/secureworks/errors package, be
 // aware that this returns a MultiError instead of the default standard
-// library's implementation.
+// library's implementation, and that a single non-nil error is returned
+// unwrapped, like Append: the result only implements the Unwrap() []error
+// method if there are two or more non-nil errors. Use
+// NewMultiError(errs...).AsError() to always get a MultiError.
 func Join(errs ...error) error { return Append(errs...) }
@@ -243,3 +243,74 @@ func TestJoin(t *testing.T) {
 	testutils.AssertEqual(t, []error{err1, err2, err3}, merr.(interface{ Unwrap() []error }).Unwrap())
 	testutils.AssertNil(t, Join(nil, nil, nil))
 }
+
+func TestFormat_stdlibJoin(t *testing.T) {
+	fa := Frames{NewFrame("github.com/secureworks/errors.A", "/src/a.go", 1)}
+	fd := Frames{NewFrame("github.com/secureworks/errors.D", "/src/d.go", 4)}
+	fw := Frames{NewFrame("github.com/secureworks/errors.W", "/src/w.go", 9)}
+
+	t.Run("prints the frames of each branch", func(t *testing.T) {
+		tree := stderrors.Join(
+			NewWithFrames("a", fa),
+			New("b"),
+			stderrors.Join(New("c"), NewWithFrames("d", fd)),
+		)
+		err := WithFrames(tree, fw)
+		testutils.AssertEqual(t, "a\nb\nc\nd\n"+
+			"github.com/secureworks/errors.W\n\t/src/w.go:9\n"+
+			"\n"+
+			"multiple errors:\n"+
+			"\n"+
+			"* error 1 of 3: a\ngithub.com/secureworks/errors.A\n\t/src/a.go:1\n"+
+			"\n"+
+			"* error 2 of 3: b\n"+
+			"\n"+
+			"* error 3 of 3: multiple errors:\n"+
+			"\n"+
+			"  * error 1 of 2: c\n"+
+			"\n"+
+			"  * error 2 of 2: d\n  github.com/secureworks/errors.D\n  \t/src/d.go:4\n",
+			fmt.Sprintf("%+v", err))
+		testutils.AssertEqual(t, "a\nb\nc\nd", fmt.Sprintf("%v", err))
+	})
+
+	t.Run("through wrappers", func(t *testing.T) {
+		tree := fmt.Errorf("joined: %w", stderrors.Join(New("a"), NewWithFrames("b", fa)))
+		for _, err := range []error{WithStackTrace(tree), WithFrame(tree)} {
+			testutils.AssertMatch(t, `(?s)^joined: a\nb\n.*\n\nmultiple errors:\n\n\* error 1 of 2: a\n\n\* error 2 of 2: b\ngithub.com/secureworks/errors.A\n\t/src/a.go:1\n$`,
+				fmt.Sprintf("%+v", err))
+		}
+	})
+
+	t.Run("fmt multiple %w", func(t *testing.T) {
+		err := WithFrames(fmt.Errorf("%w; %w", New("a"), NewWithFrames("b", fa)), fw)
+		testutils.AssertMatch(t, `\n\* error 2 of 2: b\ngithub.com/secureworks/errors.A\n`, fmt.Sprintf("%+v", err))
+	})
+
+	t.Run("not without frames", func(t *testing.T) {
+		err := WithFrames(stderrors.Join(New("a"), New("b")), fw)
+		testutils.AssertEqual(t, "a\nb\ngithub.com/secureworks/errors.W\n\t/src/w.go:9", fmt.Sprintf("%+v", err))
+	})
+
+	t.Run("multierrors from this package", func(t *testing.T) {
+		err := WithFrames(NewMultiError(NewWithFrames("a", fa), New("b")), fw)
+		testutils.AssertEqual(t, "[a; b]\ngithub.com/secureworks/errors.W\n\t/src/w.go:9\n"+
+			"\n"+
+			"multiple errors:\n"+
+			"\n"+
+			"* error 1 of 2: a\ngithub.com/secureworks/errors.A\n\t/src/a.go:1\n"+
+			"\n"+
+			"* error 2 of 2: b\n",
+			fmt.Sprintf("%+v", err))
+
+		err = WithFrames(NewMultiError(New("a"), New("b")), fw)
+		testutils.AssertEqual(t, "[a; b]\ngithub.com/secureworks/errors.W\n\t/src/w.go:9", fmt.Sprintf("%+v", err))
+	})
+
+	t.Run("FramesFromAll", func(t *testing.T) {
+		all := FramesFromAll(WithFrames(stderrors.Join(NewWithFrames("a", fa), New("b")), fw))
+		testutils.AssertEqual(t, 2, len(all))
+		testutils.AssertEqual(t, "[a.go:1 w.go:9]", fmt.Sprintf("%s", all[0]))
+		testutils.AssertEqual(t, "[w.go:9]", fmt.Sprintf("%s", all[1]))
+	})
+}
@@ -41,6 +41,11 @@
 //
 //	group, ctx := errors.NewGroup(ctx) // Same as errors.NewCoordinatedGroup.
 //
+// If the context given to the group is cancelled, the tasks usually
+// fail with the context's error: CancelledExternally distinguishes this
+// from a task failing on its own, and Wait prefixes the error with
+// "group cancelled by parent context".
+//
 // # ParallelGroup
 //
 // If you want all tasks to run to completion and have their errors
@@ -61,4 +66,10 @@
 //	// ...
 //	merr := group.WaitForMultiError()
 //	fmt.Println(merr.Unwrap())
+//
+// To run the same task a number of times, both groups provide GoN,
+// which passes each task its index and names its errors accordingly:
+//
+//	group := new(errors.ParallelGroup)
+//	group.GoN(50, probeRunner, "probe") // Errors are named "probe[0]" etc.
 package syncerr
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/secureworks/errors"
 )
@@ -34,10 +35,12 @@ type CoordinatedGroup struct {
 	wg   sync.WaitGroup
 	once sync.Once
 
+	parent context.Context
 	ctx    context.Context
 	cancel context.CancelFunc
 
-	err error
+	err      error
+	external atomic.Bool
 }
 
 var _ taskGroup = (*CoordinatedGroup)(nil)
@@ -47,7 +50,7 @@ var _ taskGroup = (*CoordinatedGroup)(nil)
 // when any subtask returns an error or when all tasks are complete.
 func NewCoordinatedGroup(ctx context.Context) (group *CoordinatedGroup, innerCtx context.Context) {
 	innerCtx, cancel := context.WithCancel(ctx)
-	g := &CoordinatedGroup{ctx: innerCtx, cancel: cancel}
+	g := &CoordinatedGroup{parent: ctx, ctx: innerCtx, cancel: cancel}
 	return g, innerCtx
 }
 
@@ -56,7 +59,7 @@ func NewCoordinatedGroup(ctx context.Context) (group *CoordinatedGroup, innerCtx
 // will be returned by Wait.
 //
 // Go also accepts a "list" of "task names" that are appended to any
-// errors this subtask generates.
+// errors this subtask generates, along with a frame for the call to Go.
 //
 // In order to keep the interface simpler, we do not enforce any
 // parameters on the given task runner: if you want to inject the
@@ -70,13 +73,35 @@ func NewCoordinatedGroup(ctx context.Context) (group *CoordinatedGroup, innerCtx
 // Use this pattern for running tasks that need any number of
 // parameters.
 func (g *CoordinatedGroup) Go(f func() error, taskNames ...string) {
+	g.goAt(callerWithNames(taskNames), f, taskNames)
+}
+
+// GoN registers and runs n subtasks for the CoordinatedGroup, each
+// running f with its index (from 0 to n-1). Errors are named with the
+// base name and the index, eg: "probe[3]". The first call to return a
+// non-nil error cancels the group, as with Go.
+func (g *CoordinatedGroup) GoN(n int, f func(i int) error, baseName string) {
+	caller := errors.CallerAt(1)
+	for i := 0; i < n; i++ {
+		i := i
+		g.goAt(caller, func() error { return f(i) }, []string{indexedName(baseName, i)})
+	}
+}
+
+// goAt registers and runs a subtask, wrapping errors with the given
+// task names and caller frame.
+func (g *CoordinatedGroup) goAt(caller errors.Frame, f func() error, taskNames []string) {
 	g.wg.Add(1)
 
 	go func() {
 		defer g.wg.Done()
 		if err := f(); err != nil {
 			g.once.Do(func() {
-				g.err = wrapWithNames(taskNames, err)
+				g.err = errors.WithDeadlineInfo(g.ctx, wrapWithNames(taskNames, caller, err))
+				if g.parent != nil && g.parent.Err() != nil {
+					g.external.Store(true)
+					g.err = fmt.Errorf("group %w", errors.CanceledByParent(g.err))
+				}
 				if g.cancel != nil {
 					g.cancel()
 				}
@@ -90,14 +115,33 @@ func (g *CoordinatedGroup) Go(f func() error, taskNames ...string) {
 //
 // Tasks are in charge of ending themselves if the group's context is
 // cancelled, in the case where they may not end on their own.
+//
+// If the context given to the group has a deadline, the first error is
+// annotated with how much of it remained when the error was returned
+// (see errors.WithDeadlineInfo).
+//
+// If the first error was returned after the context given to the group
+// was cancelled, the error is wrapped with the message "group cancelled
+// by parent context", since the task most likely failed because of the
+// cancellation. Use CancelledExternally to check for this.
 func (g *CoordinatedGroup) Wait() error {
 	g.wg.Wait()
+	if g.err == nil && g.parent != nil && g.parent.Err() != nil {
+		g.external.Store(true)
+	}
 	if g.cancel != nil {
 		g.cancel()
 	}
 	return g.err
 }
 
+// CancelledExternally reports whether the group was cancelled by the
+// context given to the group (its parent context), rather than by a
+// task returning an error. It is only meaningful after Wait returns.
+func (g *CoordinatedGroup) CancelledExternally() bool {
+	return g.external.Load()
+}
+
 // ParallelGroup is a collection of goroutines working on subtasks that
 // are part of the same overall task, and which return errors that need
 // to be handled or coalesced.
@@ -118,7 +162,7 @@ var _ taskGroup = (*ParallelGroup)(nil)
 // Go registers and runs a new subtask for the ParallelGroup.
 //
 // Go also accepts a "list" of "task names" that are appended to any
-// errors this subtask generates.
+// errors this subtask generates, along with a frame for the call to Go.
 //
 // In order to keep the interface simpler, we do not enforce any
 // parameters on the given task runner: if you want to inject a
@@ -133,19 +177,57 @@ var _ taskGroup = (*ParallelGroup)(nil)
 // parameters.
 func (g *ParallelGroup) Go(f func() error, taskNames ...string) {
 	g.wg.Add(1)
+	caller := callerWithNames(taskNames)
 
 	go func() {
 		defer g.wg.Done()
 		if err := f(); err != nil {
 			g.mu.Lock()
 			defer g.mu.Unlock()
-			g.err = errors.Append(g.err, wrapWithNames(taskNames, err))
+			g.err = errors.Append(g.err, wrapWithNames(taskNames, caller, err))
 		}
 	}()
 }
 
+// GoN registers and runs n subtasks for the ParallelGroup, each running
+// f with its index (from 0 to n-1). Errors are named with the base name
+// and the index, eg: "probe[3]".
+//
+// The errors from the n subtasks are added to the group together, in
+// order of their index, once they have all completed.
+func (g *ParallelGroup) GoN(n int, f func(i int) error, baseName string) {
+	if n <= 0 {
+		return
+	}
+	g.wg.Add(1)
+	caller := errors.CallerAt(1)
+
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := f(i); err != nil {
+				errs[i] = wrapWithNames([]string{indexedName(baseName, i)}, caller, err)
+			}
+		}(i)
+	}
+
+	go func() {
+		defer g.wg.Done()
+		wg.Wait()
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		g.err = errors.Append(append([]error{g.err}, errs...)...)
+	}()
+}
+
 // Wait blocks on either all workers completing or the group's context
-// being cancelled. All errors generated by the workers are returned.
+// being cancelled. All errors generated by the workers are returned,
+// combined with errors.Append: if only one worker failed its error is
+// returned alone, not in a MultiError. Use WaitForMultiError to always
+// get a MultiError.
 func (g *ParallelGroup) Wait() error {
 	g.wg.Wait()
 	return g.err
@@ -162,14 +244,41 @@ func (g *ParallelGroup) WaitForMultiError() *errors.MultiError {
 	return errors.NewMultiError(g.err)
 }
 
-// wrapWithNames adds identifiers to the error context for a task.
-func wrapWithNames(names []string, err error) error {
+// WaitForSortedMultiError is the same as WaitForMultiError, but the
+// errors are sorted by their messages (see errors.MultiError.SortByMessage),
+// rather than in the order the workers failed, so that they are the
+// same from run to run.
+func (g *ParallelGroup) WaitForSortedMultiError() *errors.MultiError {
+	return g.WaitForMultiError().SortByMessage()
+}
+
+// callerWithNames returns the frame that called Go on a group, if the
+// task has names to wrap its errors with. The frame must be captured
+// before the task's goroutine is started, since the goroutine's stack
+// does not include the call site.
+//
+//go:noinline
+func callerWithNames(names []string) errors.Frame {
+	if len(names) == 0 {
+		return nil
+	}
+	return errors.CallerAt(2)
+}
+
+// indexedName names one of a number of tasks run with GoN.
+func indexedName(baseName string, i int) string {
+	return fmt.Sprintf("%s[%d]", baseName, i)
+}
+
+// wrapWithNames adds identifiers to the error context for a task, along
+// with the frame where the task was registered.
+func wrapWithNames(names []string, caller errors.Frame, err error) error {
 	if len(names) == 0 {
 		return err
 	}
-	return errors.WithFrameAt(
+	return errors.WithFrames(
 		fmt.Errorf("%s: %w",
 			strings.Join(names, ": "),
 			err,
-		), 1)
+		), errors.Frames{caller})
 }
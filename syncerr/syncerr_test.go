@@ -145,6 +145,25 @@ func TestCoordinatedGroup_WrapName(t *testing.T) {
 
 	err := group.Wait()
 	testutils.AssertEqual(t, "worker: new err", err.Error())
+
+	// The frame is the call site of Go, not a frame inside syncerr.
+	ff := errors.FramesFrom(err)
+	testutils.AssertEqual(t, 1, len(ff))
+	function, _, _ := ff[0].Location()
+	testutils.AssertEqual(t, "github.com/secureworks/errors/syncerr.TestCoordinatedGroup_WrapName", function)
+}
+
+func TestParallelGroup_singleError(t *testing.T) {
+	err := errors.New("new err")
+
+	group := new(ParallelGroup)
+	group.Go(func() error { return err })
+	group.Go(func() error { return nil })
+
+	testutils.AssertEqual(t, err, group.Wait())
+	merr := group.WaitForMultiError()
+	testutils.AssertEqual(t, []error{err}, merr.Unwrap())
+	testutils.AssertEqual(t, error(merr), merr.AsError())
 }
 
 func TestParallelGroup_WrapName(t *testing.T) {
@@ -161,6 +180,29 @@ func TestParallelGroup_WrapName(t *testing.T) {
 	merr := group.WaitForMultiError()
 	testutils.AssertEqual(t,
 		[]string{"worker 0: new err: 1", "worker 3: new err: 2"}, sortedMessages(merr.Unwrap()))
+
+	// The frame is the call site of Go, not a frame inside syncerr.
+	for _, err := range merr.Unwrap() {
+		ff := errors.FramesFrom(err)
+		testutils.AssertEqual(t, 1, len(ff))
+		function, _, _ := ff[0].Location()
+		testutils.AssertEqual(t, "github.com/secureworks/errors/syncerr.TestParallelGroup_WrapName", function)
+	}
+}
+
+func TestParallelGroup_WaitForSortedMultiError(t *testing.T) {
+	group := new(ParallelGroup)
+	for _, msg := range []string{"c", "a", "b"} {
+		msg := msg
+		group.Go(func() error { return errors.NewWithFrame(msg) })
+	}
+
+	merr := group.WaitForSortedMultiError()
+	testutils.AssertEqual(t, "[a; b; c]", merr.Error())
+	for _, err := range merr.Unwrap() {
+		testutils.AssertEqual(t, 1, len(errors.FramesFrom(err)))
+	}
+	testutils.AssertNil(t, new(ParallelGroup).WaitForSortedMultiError().ErrorOrNil())
 }
 
 func sortedMessages(errs []error) (msgs []string) {
@@ -171,3 +213,142 @@ func sortedMessages(errs []error) (msgs []string) {
 	sort.Strings(msgs)
 	return
 }
+
+func TestParallelGroup_GoN(t *testing.T) {
+	group := new(ParallelGroup)
+	group.Go(func() error { return errors.New("other err") }, "other")
+	group.GoN(10, func(i int) error {
+		time.Sleep(time.Duration(10-i) * time.Millisecond) // Finish out of order.
+		if i%3 == 0 {
+			return errors.New("probe failed")
+		}
+		return nil
+	}, "probe")
+	group.GoN(0, func(i int) error { return errors.New("never run") }, "none")
+
+	merr := group.WaitForMultiError()
+	errs := merr.Unwrap()
+	testutils.AssertEqual(t, 5, len(errs))
+	testutils.AssertEqual(t, "other: other err", errs[0].Error())
+
+	// Ordered by index.
+	var msgs []string
+	for _, err := range errs[1:] {
+		msgs = append(msgs, err.Error())
+	}
+	testutils.AssertEqual(t, []string{
+		"probe[0]: probe failed",
+		"probe[3]: probe failed",
+		"probe[6]: probe failed",
+		"probe[9]: probe failed",
+	}, msgs)
+
+	// The frame is the call site of GoN.
+	function, _, _ := errors.FramesFrom(errs[1])[0].Location()
+	testutils.AssertEqual(t, "github.com/secureworks/errors/syncerr.TestParallelGroup_GoN", function)
+}
+
+func TestCoordinatedGroup_GoN(t *testing.T) {
+	group, ctx := NewCoordinatedGroup(context.Background())
+	group.GoN(5, func(i int) error {
+		if i == 2 {
+			return errors.New("probe failed")
+		}
+		<-ctx.Done()
+		return nil
+	}, "probe")
+
+	err := group.Wait()
+	testutils.AssertEqual(t, "probe[2]: probe failed", err.Error())
+	function, _, _ := errors.FramesFrom(err)[0].Location()
+	testutils.AssertEqual(t, "github.com/secureworks/errors/syncerr.TestCoordinatedGroup_GoN", function)
+}
+
+func TestCoordinatedGroup_CancelledExternally(t *testing.T) {
+	t.Run("parent cancelled", func(t *testing.T) {
+		parent, cancel := context.WithCancel(context.Background())
+		group, ctx := NewCoordinatedGroup(parent)
+		started := make(chan struct{})
+		group.Go(func() error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		}, "watcher")
+		group.Go(func() error {
+			<-ctx.Done()
+			time.Sleep(10 * time.Millisecond)
+			return ctx.Err()
+		}, "slow")
+		<-started
+		cancel()
+
+		err := group.Wait()
+		testutils.AssertTrue(t, group.CancelledExternally())
+		testutils.AssertTrue(t, errors.Is(err, context.Canceled))
+		testutils.AssertEqual(t, "group cancelled by parent context: watcher: context canceled", err.Error())
+	})
+
+	t.Run("parent cancelled without errors", func(t *testing.T) {
+		parent, cancel := context.WithCancel(context.Background())
+		group, ctx := NewCoordinatedGroup(parent)
+		group.Go(func() error {
+			<-ctx.Done()
+			return nil
+		})
+		cancel()
+
+		testutils.AssertNil(t, group.Wait())
+		testutils.AssertTrue(t, group.CancelledExternally())
+	})
+
+	t.Run("task failed", func(t *testing.T) {
+		group, ctx := NewCoordinatedGroup(context.Background())
+		errTask := errors.New("task err")
+		group.Go(func() error {
+			return errTask
+		}, "failing")
+		group.Go(func() error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, "watcher")
+
+		err := group.Wait()
+		testutils.AssertFalse(t, group.CancelledExternally())
+		testutils.AssertEqual(t, "failing: task err", err.Error())
+
+		// Waiting again does not change the result.
+		testutils.AssertEqual(t, err, group.Wait())
+		testutils.AssertFalse(t, group.CancelledExternally())
+	})
+
+	t.Run("zero value", func(t *testing.T) {
+		group := new(CoordinatedGroup)
+		group.Go(func() error { return nil })
+		testutils.AssertNil(t, group.Wait())
+		testutils.AssertFalse(t, group.CancelledExternally())
+	})
+}
+
+func TestCoordinatedGroup_DeadlineInfo(t *testing.T) {
+	t.Run("with a deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+		group, _ := NewCoordinatedGroup(ctx)
+		group.Go(func() error { return errors.New("failed") }, "task")
+		err := group.Wait()
+
+		info, ok := errors.DeadlineInfoFrom(err)
+		testutils.AssertTrue(t, ok)
+		testutils.AssertTrue(t, info.Remaining > 0)
+		testutils.AssertFalse(t, info.Done)
+		testutils.AssertEqual(t, "task: failed", err.Error())
+		testutils.AssertMatch(t, `^task: failed\ndeadline: .+ remaining\n`, fmt.Sprintf("%+v", err))
+	})
+
+	t.Run("without a deadline", func(t *testing.T) {
+		group, _ := NewCoordinatedGroup(context.Background())
+		group.Go(func() error { return errors.New("failed") })
+		_, ok := errors.DeadlineInfoFrom(group.Wait())
+		testutils.AssertFalse(t, ok)
+	})
+}
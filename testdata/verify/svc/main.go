@@ -0,0 +1,27 @@
+package main
+
+import "fmt"
+
+type server struct{}
+
+func (s *server) Serve(port int) error {
+	if port == 0 {
+		return fmt.Errorf("no port")
+	}
+	go func() {
+		fmt.Println("serving")
+	}()
+	return nil
+}
+
+func Map[K comparable, V any](m map[K]V) []K {
+	var keys []K
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func main() {
+	_ = (&server{}).Serve(8080)
+}
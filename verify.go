@@ -0,0 +1,193 @@
+package errors
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// Errors that describe why a frame does not match the source code, in
+// a FrameMismatch.
+var (
+	ErrSourceMissing     = New("source file not found")
+	ErrLineOutOfRange    = New("line is past the end of the source file")
+	ErrFunctionNotAtLine = New("line is not in the function")
+)
+
+// VerifyOptions configures how VerifyFrames finds the source code of
+// frames.
+type VerifyOptions struct {
+	// SourceRoot is the directory that the files of the frames are
+	// found in. If the file path of a frame (after any source mappings,
+	// see AddSourceMapping) does not exist, it is looked for in the
+	// SourceRoot, first as a whole and then without each of its leading
+	// directories in turn, so that eg "/build/acme/svc/main.go" is found
+	// as "svc/main.go" with a SourceRoot of "/home/me/src/acme".
+	SourceRoot string
+
+	// TrimPrefix is removed from the file path of a frame before it is
+	// looked for in the SourceRoot, if it starts with it.
+	TrimPrefix string
+}
+
+// FrameMismatch describes a frame that does not match the source code,
+// as reported by VerifyFrames.
+type FrameMismatch struct {
+	Index int    // The index of the frame in the Frames.
+	Frame Frame  // The frame.
+	File  string // The source file found for the frame, if any.
+	Err   error  // Why the frame does not match: see ErrSourceMissing etc.
+}
+
+// VerifyFrames checks that the Frames match the source code found as
+// configured by the options, returning the frames that do not. This is
+// a diagnostic for when the source code may have changed since the
+// binary that generated the frames was built (eg, to flag that line
+// numbers may be stale); it reads the source files and is not meant to
+// be called often.
+//
+// A frame matches if its file is found, its line is in the file, and
+// the closest function declaration above the line (the function that
+// contains it, if the line is correct) declares its function (or the
+// function that declares it, for a closure). This is a cheap textual
+// heuristic, not a parse of the Go source: eg, it does not handle
+// functions with a declaration that is not at the start of a line.
+// Frames without a file or line (eg, "unknown") are skipped.
+//
+// Note that the source of the standard library and other modules will
+// not be found in a SourceRoot for the main module.
+func VerifyFrames(ff Frames, opts VerifyOptions) []FrameMismatch {
+	var mismatches []FrameMismatch
+	sources := make(map[string][][]byte)
+	for i, fr := range ff {
+		if fr == nil {
+			continue
+		}
+		function, file, line := fr.Location()
+		if file == "" || file == "unknown" || line <= 0 {
+			continue
+		}
+		found, ok := findSource(mapSource(file), opts)
+		if !ok {
+			mismatches = append(mismatches, FrameMismatch{Index: i, Frame: fr, Err: ErrSourceMissing})
+			continue
+		}
+		lines, ok := sources[found]
+		if !ok {
+			byt, err := os.ReadFile(found)
+			if err != nil {
+				mismatches = append(mismatches, FrameMismatch{Index: i, Frame: fr, File: found, Err: ErrSourceMissing})
+				continue
+			}
+			lines = bytes.Split(byt, []byte("\n"))
+			sources[found] = lines
+		}
+		if line > len(lines) {
+			mismatches = append(mismatches, FrameMismatch{Index: i, Frame: fr, File: found, Err: ErrLineOutOfRange})
+			continue
+		}
+		if name := declaringFunction(function); name != "" && enclosingFunction(lines, line) != name {
+			mismatches = append(mismatches, FrameMismatch{Index: i, Frame: fr, File: found, Err: ErrFunctionNotAtLine})
+		}
+	}
+	return mismatches
+}
+
+// findSource finds the source file for a file path.
+func findSource(file string, opts VerifyOptions) (string, bool) {
+	if opts.TrimPrefix != "" && strings.HasPrefix(file, opts.TrimPrefix) {
+		file = strings.TrimPrefix(file[len(opts.TrimPrefix):], "/")
+	} else if isFile(file) {
+		return file, true
+	}
+	if opts.SourceRoot == "" {
+		return "", false
+	}
+	// File paths in frames always use forward slashes.
+	for rel := strings.TrimPrefix(path.Clean("/"+file), "/"); rel != ""; {
+		if candidate := filepath.Join(opts.SourceRoot, filepath.FromSlash(rel)); isFile(candidate) {
+			return candidate, true
+		}
+		i := strings.IndexByte(rel, '/')
+		if i < 0 {
+			break
+		}
+		rel = rel[i+1:]
+	}
+	return "", false
+}
+
+func isFile(name string) bool {
+	info, err := os.Stat(name)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// declaringFunction returns the name in the source code of the function
+// that declares the function with the given (runtime) name: the name
+// of the function or method, without its package or receiver, or the
+// name of the function that contains it if it is a closure. Returns an
+// empty string for functions without a declaration (eg, package-level
+// closures).
+func declaringFunction(function string) string {
+	name := function[strings.LastIndexByte(function, '/')+1:]
+	name = name[strings.IndexByte(name, '.')+1:] // Package name.
+	name = strings.ReplaceAll(name, "[...]", "") // Type parameters.
+	name = strings.TrimSuffix(name, "-fm")       // Method values.
+
+	parts := strings.Split(name, ".")
+	for len(parts) > 0 && isGeneratedName(parts[len(parts)-1]) {
+		parts = parts[:len(parts)-1]
+	}
+	if len(parts) == 0 || parts[len(parts)-1] == "glob" || strings.HasPrefix(parts[len(parts)-1], "(") {
+		return ""
+	}
+	return parts[len(parts)-1]
+}
+
+// isGeneratedName reports whether the part of a function name was added
+// by the compiler for a closure or an init function: eg, "func1", "2".
+func isGeneratedName(part string) bool {
+	part = strings.TrimPrefix(part, "func")
+	if part == "" {
+		return false
+	}
+	for _, r := range part {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// enclosingFunction returns the name declared by the closest function
+// declaration at or above the line (numbered from 1).
+func enclosingFunction(lines [][]byte, line int) string {
+	for i := line - 1; i >= 0; i-- {
+		if decl, ok := bytes.CutPrefix(lines[i], []byte("func ")); ok {
+			return declaredName(string(decl))
+		}
+	}
+	return ""
+}
+
+// declaredName returns the name in a function declaration, after the
+// "func " keyword.
+func declaredName(decl string) string {
+	if strings.HasPrefix(decl, "(") { // Receiver.
+		end := strings.IndexByte(decl, ')')
+		if end < 0 {
+			return ""
+		}
+		decl = strings.TrimLeft(decl[end+1:], " \t")
+	}
+	end := strings.IndexFunc(decl, func(r rune) bool {
+		return r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if end < 0 {
+		return decl
+	}
+	return decl[:end]
+}
@@ -0,0 +1,87 @@
+package errors
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+func TestVerifyFrames(t *testing.T) {
+	root := filepath.Join("testdata", "verify")
+	opts := VerifyOptions{SourceRoot: root}
+
+	t.Run("matching frames", func(t *testing.T) {
+		ff := Frames{
+			NewFrame("example.com/acme/svc.(*server).Serve", "/build/acme/svc/main.go", 9),
+			NewFrame("example.com/acme/svc.(*server).Serve.func1", "/build/acme/svc/main.go", 12),
+			NewFrame("example.com/acme/svc.Map[...]", "/build/acme/svc/main.go", 20),
+			NewFrame("main.main", "/build/acme/svc/main.go", 26),
+			NewFrame("main.main", "svc/main.go", 25),
+		}
+		testutils.AssertEqual(t, 0, len(VerifyFrames(ff, opts)))
+	})
+
+	t.Run("stale frames", func(t *testing.T) {
+		ff := Frames{
+			NewFrame("main.main", "/build/acme/svc/main.go", 26),
+			NewFrame("example.com/acme/svc.(*server).Serve", "/build/acme/svc/main.go", 20),
+			NewFrame("main.main", "/build/acme/svc/main.go", 100),
+			NewFrame("main.main", "/build/acme/svc/missing.go", 1),
+			NewFrame("example.com/acme/svc.Map", "/build/acme/svc/main.go", 4),
+		}
+		mismatches := VerifyFrames(ff, opts)
+		testutils.AssertEqual(t, 4, len(mismatches))
+
+		testutils.AssertEqual(t, 1, mismatches[0].Index)
+		testutils.AssertEqual(t, ErrFunctionNotAtLine, mismatches[0].Err)
+		testutils.AssertEqual(t, filepath.Join(root, "svc", "main.go"), mismatches[0].File)
+		testutils.AssertTrue(t, mismatches[0].Frame == ff[1])
+
+		testutils.AssertEqual(t, 2, mismatches[1].Index)
+		testutils.AssertEqual(t, ErrLineOutOfRange, mismatches[1].Err)
+
+		testutils.AssertEqual(t, 3, mismatches[2].Index)
+		testutils.AssertEqual(t, ErrSourceMissing, mismatches[2].Err)
+		testutils.AssertEqual(t, "", mismatches[2].File)
+
+		testutils.AssertEqual(t, 4, mismatches[3].Index)
+		testutils.AssertEqual(t, ErrFunctionNotAtLine, mismatches[3].Err)
+	})
+
+	t.Run("trims the prefix", func(t *testing.T) {
+		ff := Frames{NewFrame("main.main", "/build/acme/main.go", 26)}
+		testutils.AssertEqual(t, 1, len(VerifyFrames(ff, opts)))
+		opts := VerifyOptions{SourceRoot: filepath.Join(root, "svc"), TrimPrefix: "/build/acme"}
+		testutils.AssertEqual(t, 0, len(VerifyFrames(ff, opts)))
+	})
+
+	t.Run("skips unknown frames", func(t *testing.T) {
+		ff := Frames{NewFrame("", "", 0), NewFrame("main.main", "main.go", 0), nil}
+		testutils.AssertEqual(t, 0, len(VerifyFrames(ff, opts)))
+	})
+
+	t.Run("frames from the call stack", func(t *testing.T) {
+		ff := FramesFrom(NewWithFrame("err"))
+		ff = append(ff, FramesFrom(func() error { return NewWithFrame("err") }())...)
+		testutils.AssertEqual(t, 0, len(VerifyFrames(ff, VerifyOptions{})))
+	})
+}
+
+func TestDeclaringFunction(t *testing.T) {
+	cases := map[string]string{
+		"main.main": "main",
+		"github.com/secureworks/errors.FramesFrom":               "FramesFrom",
+		"github.com/secureworks/errors.(*MultiError).Format":     "Format",
+		"github.com/secureworks/errors.withFrames.Error-fm":      "Error",
+		"github.com/secureworks/errors.TestVerify.func1.2":       "TestVerify",
+		"github.com/secureworks/errors.Map[...]":                 "Map",
+		"github.com/secureworks/errors.(*Set[...]).Add.func1":    "Add",
+		"github.com/secureworks/errors.init.0":                   "init",
+		"github.com/secureworks/errors.glob..func1":              "",
+		"github.com/secureworks/errors.(*MultiError).Format.fn2": "fn2",
+	}
+	for function, expected := range cases {
+		testutils.AssertEqual(t, expected, declaringFunction(function), function)
+	}
+}
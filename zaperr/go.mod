@@ -0,0 +1,12 @@
+module github.com/secureworks/errors/zaperr
+
+go 1.20
+
+require (
+	github.com/secureworks/errors v0.2.1-0.20261015182823-2e706f4cf2f1
+	go.uber.org/zap v1.27.0
+)
+
+require go.uber.org/multierr v1.10.0 // indirect
+
+replace github.com/secureworks/errors => ../
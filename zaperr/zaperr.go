@@ -0,0 +1,106 @@
+// Package zaperr provides adapters to log errors from the
+// github.com/secureworks/errors package with go.uber.org/zap, as
+// structured objects rather than a single string formatted with `%+v`:
+//
+//	logger.Error("request failed", zaperr.Field(err))
+//
+// logs (with the JSON encoder):
+//
+//	{"msg":"request failed","error":{"message":"reading config: not found","type":"*errors.withFrames","frames":["main.run /src/main.go:42","main.main /src/main.go:10"]}}
+//
+// The frames are those returned by errors.FramesFrom. If the error chain
+// has a multierror, its errors are included as "errors", an array of the
+// same objects. Errors without frames (eg, from other packages) are
+// logged with only their message and type.
+package zaperr
+
+import (
+	"fmt"
+	"reflect"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/secureworks/errors"
+)
+
+// Field returns a zap.Field for the error with the key "error", or a
+// field that is skipped if the error is nil.
+func Field(err error) zap.Field {
+	return NamedField("error", err)
+}
+
+// NamedField returns a zap.Field for the error with the given key, or a
+// field that is skipped if the error is nil.
+func NamedField(key string, err error) zap.Field {
+	if err == nil {
+		return zap.Skip()
+	}
+	return zap.Object(key, Error{err})
+}
+
+// Error adapts an error to zapcore.ObjectMarshaler.
+type Error struct {
+	Err error
+}
+
+var _ zapcore.ObjectMarshaler = Error{}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler.
+func (e Error) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	if e.Err == nil {
+		enc.AddString("message", "<nil>")
+		return nil
+	}
+	enc.AddString("message", e.Err.Error())
+	enc.AddString("type", reflect.TypeOf(e.Err).String())
+	if ff := errors.FramesFrom(e.Err); len(ff) > 0 {
+		if err := enc.AddArray("frames", Frames(ff)); err != nil {
+			return err
+		}
+	}
+	if errs := membersOf(e.Err); len(errs) > 0 {
+		return enc.AddArray("errors", errorArray(errs))
+	}
+	return nil
+}
+
+// membersOf returns the errors of the first multierror in the chain.
+func membersOf(err error) []error {
+	var merr interface{ Unwrap() []error }
+	if !errors.As(err, &merr) {
+		return nil
+	}
+	return merr.Unwrap()
+}
+
+type errorArray []error
+
+func (errs errorArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if err := enc.AppendObject(Error{err}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Frames adapts errors.Frames to zapcore.ArrayMarshaler, as an array of
+// strings in the form "function file:line".
+type Frames errors.Frames
+
+var _ zapcore.ArrayMarshaler = Frames(nil)
+
+// MarshalLogArray implements zapcore.ArrayMarshaler.
+func (ff Frames) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, fr := range ff {
+		if fr == nil {
+			continue
+		}
+		enc.AppendString(fmt.Sprintf("%s %v", errors.LocationOf(fr).Function, fr))
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package zaperr_test
+
+import (
+	"fmt"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/secureworks/errors"
+	"github.com/secureworks/errors/zaperr"
+)
+
+// encode returns the JSON encoding of the fields, without a message.
+func encode(t testing.TB, fields ...zap.Field) string {
+	t.Helper()
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{})
+	buf, err := enc.EncodeEntry(zapcore.Entry{}, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer buf.Free()
+	return buf.String()
+}
+
+var errWithFrames = errors.NewWithFrames("not found", errors.Frames{
+	errors.NewFrame("github.com/acme/svc.read", "/src/svc/read.go", 20),
+	errors.NewFrame("main.main", "/src/svc/main.go", 10),
+})
+
+func TestField(t *testing.T) {
+	cases := []struct {
+		name  string
+		field zap.Field
+		want  string
+	}{
+		{
+			name:  "frames",
+			field: zaperr.Field(errWithFrames),
+			want:  `{"error":{"message":"not found","type":"*errors.withFrames","frames":["github.com/acme/svc.read /src/svc/read.go:20","main.main /src/svc/main.go:10"]}}` + "\n",
+		},
+		{
+			name:  "foreign error",
+			field: zaperr.Field(fmt.Errorf("failed")),
+			want:  `{"error":{"message":"failed","type":"*errors.errorString"}}` + "\n",
+		},
+		{
+			name:  "multierror",
+			field: zaperr.NamedField("err", errors.NewMultiError(errWithFrames, errors.New("b"))),
+			want: `{"err":{"message":"[not found; b]","type":"*errors.MultiError","errors":[` +
+				`{"message":"not found","type":"*errors.withFrames","frames":["github.com/acme/svc.read /src/svc/read.go:20","main.main /src/svc/main.go:10"]},` +
+				`{"message":"b","type":"*errors.errorString"}]}}` + "\n",
+		},
+		{
+			name:  "nil",
+			field: zaperr.Field(nil),
+			want:  "{}\n",
+		},
+		{
+			name:  "nil adapter",
+			field: zap.Object("error", zaperr.Error{}),
+			want:  `{"error":{"message":"<nil>"}}` + "\n",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := encode(t, tt.field); got != tt.want {
+				t.Errorf("want: %s, got: %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func BenchmarkField(b *testing.B) {
+	err := errors.WithFrame(errors.NewWithStackTrace("failed"))
+
+	b.Run("zaperr", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			encode(b, zaperr.Field(err))
+		}
+	})
+
+	b.Run("string", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			encode(b, zap.String("error", fmt.Sprintf("%+v", err)))
+		}
+	})
+}
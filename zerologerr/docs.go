@@ -0,0 +1,25 @@
+// Package zerologerr marshals errors for github.com/rs/zerolog, with the
+// frames of the error chain.
+//
+// zerolog logs an error with the value returned by its ErrorMarshalFunc,
+// which by default is the message, so the frames added by this package
+// are lost. Set it to Marshal to log an object instead:
+//
+//	zerolog.ErrorMarshalFunc = zerologerr.Marshal
+//
+//	log.Error().Err(err).Msg("request failed")
+//
+// logs:
+//
+//	{"level":"error","error":{"message":"reading config: not found","frames":[{"function":"main.run","file":"/src/main.go","line":42}],"causes":[{"message":"not found"}]},"message":"request failed"}
+//
+// The frames are those returned by errors.FramesFrom, marshaled the same
+// way as errors.Frames. The errors the error wraps (found with
+// errors.Unwrap) are included as "causes", with only their messages,
+// skipping those that only add frames to the error they wrap. If
+// the error chain has a multierror, its errors are included as
+// "errors", an array of the same objects as the error.
+//
+// This package only returns a value that zerolog marshals as JSON, so it
+// does not depend on zerolog.
+package zerologerr
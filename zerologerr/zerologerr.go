@@ -0,0 +1,49 @@
+package zerologerr
+
+import (
+	"github.com/secureworks/errors"
+)
+
+// Error is the value logged for an error by Marshal.
+type Error struct {
+	Message string        `json:"message"`
+	Frames  errors.Frames `json:"frames,omitempty"`
+	Causes  []Error       `json:"causes,omitempty"`
+	Errors  []Error       `json:"errors,omitempty"`
+}
+
+// Marshal returns the value to log for the error, with its message, the
+// frames of its chain, the messages of the errors it wraps as its
+// causes, and the errors of any multierror in the chain. It returns nil
+// if the error is nil. Marshal has the signature of
+// zerolog.ErrorMarshalFunc.
+func Marshal(err error) interface{} {
+	if err == nil {
+		return nil
+	}
+	return newError(err)
+}
+
+func newError(err error) Error {
+	v := Error{
+		Message: err.Error(),
+		Frames:  errors.FramesFrom(err),
+	}
+	msg := v.Message
+	for cause := errors.Unwrap(err); cause != nil; cause = errors.Unwrap(cause) {
+		if next := cause.Error(); next != msg {
+			v.Causes = append(v.Causes, Error{Message: next})
+			msg = next
+		}
+	}
+	var merr interface{ Unwrap() []error }
+	if !errors.As(err, &merr) {
+		return v
+	}
+	for _, member := range merr.Unwrap() {
+		if member != nil {
+			v.Errors = append(v.Errors, newError(member))
+		}
+	}
+	return v
+}
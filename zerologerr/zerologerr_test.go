@@ -0,0 +1,79 @@
+package zerologerr
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/secureworks/errors"
+	"github.com/secureworks/errors/internal/testutils"
+)
+
+var (
+	frMain = errors.NewFrame("main.main", "/src/main.go", 10)
+	frRun  = errors.NewFrame("main.run", "/src/main.go", 42)
+	frRead = errors.NewFrame("github.com/acme/svc/config.Read", "/src/config/read.go", 30)
+)
+
+// marshal returns the JSON that zerolog writes for the value from
+// Marshal.
+func marshal(t *testing.T, err error) string {
+	t.Helper()
+	byt, jsonErr := json.Marshal(Marshal(err))
+	testutils.AssertNil(t, jsonErr)
+	return string(byt)
+}
+
+func TestMarshal(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		testutils.AssertNil(t, Marshal(nil))
+	})
+
+	t.Run("without frames", func(t *testing.T) {
+		testutils.AssertEqual(t, `{"message":"failed"}`, marshal(t, fmt.Errorf("failed")))
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		err := errors.WithFrames(errors.New("not found"), errors.Frames{frRead})
+		err = errors.WithFrames(fmt.Errorf("reading config: %w", err), errors.Frames{frRun, frMain})
+		testutils.AssertEqual(t,
+			`{"message":"reading config: not found","frames":[`+
+				`{"function":"github.com/acme/svc/config.Read","file":"/src/config/read.go","line":30},`+
+				`{"function":"main.run","file":"/src/main.go","line":42},`+
+				`{"function":"main.main","file":"/src/main.go","line":10}],`+
+				`"causes":[{"message":"not found"}]}`,
+			marshal(t, err))
+	})
+
+	t.Run("frames match Frames.MarshalJSON", func(t *testing.T) {
+		err := errors.NewWithStackTrace("failed")
+		var v struct {
+			Frames json.RawMessage `json:"frames"`
+		}
+		testutils.AssertNil(t, json.Unmarshal([]byte(marshal(t, err)), &v))
+		expected, jsonErr := errors.FramesFrom(err).MarshalJSON()
+		testutils.AssertNil(t, jsonErr)
+		testutils.AssertEqual(t, string(expected), string(v.Frames))
+	})
+
+	t.Run("multierror errors", func(t *testing.T) {
+		merr := errors.NewMultiError(
+			errors.WithFrames(errors.New("a"), errors.Frames{frRead}),
+			errors.New("b"),
+		)
+		err := errors.WithFrames(fmt.Errorf("failed: %w", merr), errors.Frames{frMain})
+		testutils.AssertEqual(t,
+			`{"message":"failed: [a; b]","frames":[{"function":"main.main","file":"/src/main.go","line":10}],`+
+				`"causes":[{"message":"[a; b]"}],"errors":[`+
+				`{"message":"a","frames":[{"function":"github.com/acme/svc/config.Read","file":"/src/config/read.go","line":30}]},`+
+				`{"message":"b"}]}`,
+			marshal(t, err))
+	})
+
+	t.Run("nested multierror", func(t *testing.T) {
+		err := errors.NewMultiErrorGrouped(errors.New("a"), errors.NewMultiErrorGrouped(errors.New("b"), errors.New("c")))
+		testutils.AssertEqual(t,
+			`{"message":"[a; [b; c]]","errors":[{"message":"a"},{"message":"[b; c]","errors":[{"message":"b"},{"message":"c"}]}]}`,
+			marshal(t, err))
+	})
+}